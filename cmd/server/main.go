@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
 	"github.com/crdant/mbta-mcp-server/internal/server"
@@ -31,6 +34,7 @@ func main() {
 	log.Printf("Environment: %s", cfg.Environment)
 	log.Printf("MBTA API URL: %s", cfg.APIBaseURL)
 	log.Printf("Request timeout: %v", cfg.Timeout)
+	log.Printf("Transport: %s", cfg.Transport)
 
 	// Initialize MCP server
 	mcpServer, err := server.New(cfg)
@@ -42,11 +46,44 @@ func main() {
 	// Register default handlers for transit information
 	mcpServer.RegisterDefaultHandlers()
 
-	// Start the server with stdio protocol
-	log.Println("MBTA MCP Server started successfully")
-	log.Println("Using stdio protocol - input and output are on stdin/stdout")
-	if err := mcpServer.Start(); err != nil {
-		log.Fatalf("Error running MCP server: %v", err)
-		os.Exit(1)
+	// The stdio transport handles its own signals and exits when input closes
+	if !cfg.IsNetworkTransport() {
+		log.Println("MBTA MCP Server started successfully")
+		log.Println("Using stdio protocol - input and output are on stdin/stdout")
+		if err := mcpServer.Start(); err != nil {
+			log.Fatalf("Error running MCP server: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Network transports run until SIGTERM or SIGINT, then drain gracefully
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- mcpServer.Start()
+	}()
+	log.Printf("MBTA MCP Server listening on %s%s", cfg.ListenAddr, cfg.BasePath)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			log.Fatalf("Error running MCP server: %v", err)
+		}
+	case sig := <-sigCh:
+		log.Printf("Received %s, shutting down (timeout %v)", sig, cfg.ShutdownTimeout)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+
+		if err := mcpServer.Shutdown(ctx); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+		}
+		if err := <-errCh; err != nil {
+			log.Printf("Error running MCP server: %v", err)
+		}
+		log.Println("MBTA MCP Server stopped")
 	}
 }
@@ -2,7 +2,7 @@ module github.com/crdant/mbta-mcp-server
 
 go 1.24.1
 
-require github.com/mark3labs/mcp-go v0.27.1
+require github.com/mark3labs/mcp-go v0.30.1
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
@@ -6,28 +6,72 @@ import (
 	"time"
 )
 
+// Transport constants for the supported MCP transports
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "http"
+)
+
+// Data source constants for where static transit data comes from
+const (
+	DataSourceAPI  = "api"
+	DataSourceGTFS = "gtfs"
+)
+
 // Config holds the application configuration
 type Config struct {
-	APIKey      string
-	Debug       bool
-	LogLevel    string
-	Timeout     time.Duration
-	APIBaseURL  string
-	Environment string
+	APIKey               string
+	Debug                bool
+	LogLevel             string
+	Timeout              time.Duration
+	APIBaseURL           string
+	Environment          string
+	Transport            string
+	ListenAddr           string
+	BasePath             string
+	ShutdownTimeout      time.Duration
+	ResourcePollInterval time.Duration
+	CacheSize            int
+	CacheDir             string
+	RetryAttempts        int
+	RateLimit            int
+	DataSource           string
+	GTFSPath             string
+	StopIndexRefresh     time.Duration
+	GazetteerPath        string
 }
 
 // New creates a new configuration from environment variables
 func New() *Config {
 	return &Config{
-		APIKey:      getEnv("MBTA_API_KEY", ""),
-		Debug:       getEnvBool("DEBUG", false),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
-		Timeout:     time.Duration(getEnvInt("TIMEOUT_SECONDS", 30)) * time.Second,
-		APIBaseURL:  getEnv("MBTA_API_URL", "https://api-v3.mbta.com"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		APIKey:               getEnv("MBTA_API_KEY", ""),
+		Debug:                getEnvBool("DEBUG", false),
+		LogLevel:             getEnv("LOG_LEVEL", "info"),
+		Timeout:              time.Duration(getEnvInt("TIMEOUT_SECONDS", 30)) * time.Second,
+		APIBaseURL:           getEnv("MBTA_API_URL", "https://api-v3.mbta.com"),
+		Environment:          getEnv("ENVIRONMENT", "development"),
+		Transport:            getEnv("MCP_TRANSPORT", TransportStdio),
+		ListenAddr:           getEnv("MCP_LISTEN_ADDR", ":8080"),
+		BasePath:             getEnv("MCP_BASE_PATH", "/mcp"),
+		ShutdownTimeout:      time.Duration(getEnvInt("SHUTDOWN_TIMEOUT_SECONDS", 10)) * time.Second,
+		ResourcePollInterval: time.Duration(getEnvInt("RESOURCE_POLL_INTERVAL_SECONDS", 15)) * time.Second,
+		CacheSize:            getEnvInt("MBTA_CACHE_SIZE", 1000),
+		CacheDir:             getEnv("MBTA_CACHE_DIR", ""),
+		RetryAttempts:        getEnvInt("MBTA_RETRY_ATTEMPTS", 3),
+		RateLimit:            getEnvInt("MBTA_RATE_LIMIT", 0),
+		DataSource:           getEnv("MBTA_DATA_SOURCE", DataSourceAPI),
+		GTFSPath:             getEnv("MBTA_GTFS_PATH", ""),
+		StopIndexRefresh:     time.Duration(getEnvInt("MBTA_STOP_INDEX_REFRESH_MINUTES", 360)) * time.Minute,
+		GazetteerPath:        getEnv("MBTA_GAZETTEER_PATH", ""),
 	}
 }
 
+// IsNetworkTransport returns whether the configured transport listens on the network
+func (c *Config) IsNetworkTransport() bool {
+	return c.Transport == TransportSSE || c.Transport == TransportStreamableHTTP
+}
+
 // getEnv retrieves an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	value, exists := os.LookupEnv(key)
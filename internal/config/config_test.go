@@ -14,6 +14,9 @@ func TestNew(t *testing.T) {
 	originalTimeout := os.Getenv("TIMEOUT_SECONDS")
 	originalAPIURL := os.Getenv("MBTA_API_URL")
 	originalEnv := os.Getenv("ENVIRONMENT")
+	originalTransport := os.Getenv("MCP_TRANSPORT")
+	originalListenAddr := os.Getenv("MCP_LISTEN_ADDR")
+	originalBasePath := os.Getenv("MCP_BASE_PATH")
 
 	// Ensure we restore environment after test
 	defer func() {
@@ -23,6 +26,9 @@ func TestNew(t *testing.T) {
 		_ = os.Setenv("TIMEOUT_SECONDS", originalTimeout)
 		_ = os.Setenv("MBTA_API_URL", originalAPIURL)
 		_ = os.Setenv("ENVIRONMENT", originalEnv)
+		_ = os.Setenv("MCP_TRANSPORT", originalTransport)
+		_ = os.Setenv("MCP_LISTEN_ADDR", originalListenAddr)
+		_ = os.Setenv("MCP_BASE_PATH", originalBasePath)
 	}()
 
 	// Test with default values
@@ -33,6 +39,9 @@ func TestNew(t *testing.T) {
 		_ = os.Unsetenv("TIMEOUT_SECONDS")
 		_ = os.Unsetenv("MBTA_API_URL")
 		_ = os.Unsetenv("ENVIRONMENT")
+		_ = os.Unsetenv("MCP_TRANSPORT")
+		_ = os.Unsetenv("MCP_LISTEN_ADDR")
+		_ = os.Unsetenv("MCP_BASE_PATH")
 
 		config := New()
 
@@ -54,6 +63,45 @@ func TestNew(t *testing.T) {
 		if config.Environment != "development" {
 			t.Errorf("Expected Environment to be development, got %s", config.Environment)
 		}
+		if config.Transport != TransportStdio {
+			t.Errorf("Expected Transport to be stdio, got %s", config.Transport)
+		}
+		if config.ListenAddr != ":8080" {
+			t.Errorf("Expected ListenAddr to be :8080, got %s", config.ListenAddr)
+		}
+		if config.BasePath != "/mcp" {
+			t.Errorf("Expected BasePath to be /mcp, got %s", config.BasePath)
+		}
+		if config.ResourcePollInterval != 15*time.Second {
+			t.Errorf("Expected ResourcePollInterval to be 15s, got %v", config.ResourcePollInterval)
+		}
+		if config.CacheSize != 1000 {
+			t.Errorf("Expected CacheSize to be 1000, got %d", config.CacheSize)
+		}
+		if config.CacheDir != "" {
+			t.Errorf("Expected CacheDir to be empty, got %s", config.CacheDir)
+		}
+		if config.RetryAttempts != 3 {
+			t.Errorf("Expected RetryAttempts to be 3, got %d", config.RetryAttempts)
+		}
+		if config.RateLimit != 0 {
+			t.Errorf("Expected RateLimit to be 0 (automatic), got %d", config.RateLimit)
+		}
+		if config.DataSource != DataSourceAPI {
+			t.Errorf("Expected DataSource to be api, got %s", config.DataSource)
+		}
+		if config.GTFSPath != "" {
+			t.Errorf("Expected GTFSPath to be empty, got %s", config.GTFSPath)
+		}
+		if config.StopIndexRefresh != 6*time.Hour {
+			t.Errorf("Expected StopIndexRefresh to be 6h, got %v", config.StopIndexRefresh)
+		}
+		if config.GazetteerPath != "" {
+			t.Errorf("Expected GazetteerPath to be empty, got %s", config.GazetteerPath)
+		}
+		if config.IsNetworkTransport() {
+			t.Error("Expected stdio transport not to be a network transport")
+		}
 	})
 
 	// Test with custom values
@@ -64,6 +112,9 @@ func TestNew(t *testing.T) {
 		_ = os.Setenv("TIMEOUT_SECONDS", "60")
 		_ = os.Setenv("MBTA_API_URL", "http://test-api.example.com")
 		_ = os.Setenv("ENVIRONMENT", "test")
+		_ = os.Setenv("MCP_TRANSPORT", "http")
+		_ = os.Setenv("MCP_LISTEN_ADDR", "127.0.0.1:9090")
+		_ = os.Setenv("MCP_BASE_PATH", "/mbta")
 
 		config := New()
 
@@ -85,6 +136,18 @@ func TestNew(t *testing.T) {
 		if config.Environment != "test" {
 			t.Errorf("Expected Environment to be test, got %s", config.Environment)
 		}
+		if config.Transport != TransportStreamableHTTP {
+			t.Errorf("Expected Transport to be http, got %s", config.Transport)
+		}
+		if config.ListenAddr != "127.0.0.1:9090" {
+			t.Errorf("Expected ListenAddr to be 127.0.0.1:9090, got %s", config.ListenAddr)
+		}
+		if config.BasePath != "/mbta" {
+			t.Errorf("Expected BasePath to be /mbta, got %s", config.BasePath)
+		}
+		if !config.IsNetworkTransport() {
+			t.Error("Expected http transport to be a network transport")
+		}
 	})
 
 	// Test with invalid boolean value
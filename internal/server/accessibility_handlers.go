@@ -0,0 +1,193 @@
+// ABOUTME: This file implements the station accessibility handlers for the MCP server.
+// ABOUTME: It reports the elevators, escalators and ramps at a station with their current status.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerStationAccessibilityTools registers the station accessibility tools and handlers
+func (s *Server) registerStationAccessibilityTools() {
+	// Tool: GetStationAccessibility - elevators, escalators and ramps at a station
+	getStationAccessibilityTool := mcp.Tool{
+		Name:        "get_station_accessibility",
+		Description: "Get every elevator, escalator, ramp and other accessibility feature at an MBTA station, with whether each is in service and the elevator outage alerts affecting them",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"stop_id": map[string]any{
+					"type":        "string",
+					"description": "ID or name of the station, or of a stop within it, such as place-pktrm or Park Street. A name is matched to the closest stop, reported under resolved_stops",
+				},
+				"facility_type": map[string]any{
+					"type":        "string",
+					"description": "Only report facilities of this type",
+					"enum":        models.AccessibilityFacilityTypes,
+				},
+			},
+			Required: []string{"stop_id"},
+		},
+	}
+
+	// Register the station accessibility tool with its handler, wrapped with middleware
+	s.mcpServer.AddTool(getStationAccessibilityTool, s.wrapWithMiddleware(s.getStationAccessibilityHandler))
+}
+
+// getStationAccessibilityHandler handles requests for a station's accessibility features
+func (s *Server) getStationAccessibilityHandler(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+	resolved := resolvedStops{}
+	defer func() { result = resolved.annotate(result) }()
+
+	log.Printf("Received request for station accessibility: %s", request.Params.Name)
+
+	// Extract required parameters
+	args := request.GetArguments()
+	stopID, ok := args["stop_id"].(string)
+	if !ok || stopID == "" {
+		return createErrorResponse("Missing or invalid stop_id parameter"), nil
+	}
+
+	// Extract optional parameters
+	var facilityType string
+	if facilityTypeVal, ok := args["facility_type"].(string); ok && facilityTypeVal != "" {
+		facilityType = strings.ToUpper(facilityTypeVal)
+	}
+
+	stop, err := s.resolveStop(ctx, resolved, "stop_id", stopID)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to find stop: %v", err)), nil
+	}
+
+	log.Printf("Reporting accessibility features at %s", stop.ID)
+
+	report, err := mbta.StationAccessibility(ctx, s.api, stop.ID, time.Now())
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to get station accessibility: %v", err)), nil
+	}
+
+	return formatStationAccessibilityResponse(report, facilityType)
+}
+
+// formatStationAccessibilityResponse converts a station accessibility report
+// to a proper MCP response, optionally listing only one type of facility
+func formatStationAccessibilityResponse(report *models.StationAccessibility, facilityType string) (*mcp.CallToolResult, error) {
+	facilitiesData := make([]map[string]interface{}, 0, len(report.Facilities))
+	outOfService := 0
+	for _, status := range report.Facilities {
+		if facilityType != "" && status.Facility.Attributes.Type != facilityType {
+			continue
+		}
+		if !status.InService() {
+			outOfService++
+		}
+		facilitiesData = append(facilitiesData, formatFacilityStatus(status))
+	}
+
+	alertsData := make([]map[string]interface{}, 0, len(report.Alerts))
+	for _, alert := range report.Alerts {
+		alertsData = append(alertsData, formatAlert(alert, report.AsOf))
+	}
+
+	working, total := report.ElevatorsInService()
+	accessibilityData := map[string]interface{}{
+		"station_id":           report.Station.ID,
+		"station_name":         report.Station.Attributes.Name,
+		"wheelchair_boarding":  report.Station.Attributes.WheelchairBoarding,
+		"is_accessible":        report.Station.IsAccessible() && (total == 0 || working > 0),
+		"summary":              report.Summary(),
+		"elevator_count":       total,
+		"elevators_in_service": working,
+		"facility_count":       len(facilitiesData),
+		"out_of_service_count": outOfService,
+		"facilities":           facilitiesData,
+		"alerts":               alertsData,
+		"as_of":                report.AsOf.Format(time.RFC3339),
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(accessibilityData, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize station accessibility data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatFacilityStatus converts a facility and its status to a simplified format
+func formatFacilityStatus(status models.FacilityStatus) map[string]interface{} {
+	facility := status.Facility
+	facilityMap := map[string]interface{}{
+		"id":         facility.ID,
+		"name":       facility.GetName(),
+		"short_name": facility.Attributes.ShortName,
+		"type":       facility.Attributes.Type,
+		"status":     status.Status,
+		"in_service": status.InService(),
+	}
+
+	if stopID := facility.GetStopID(); stopID != "" {
+		facilityMap["stop_id"] = stopID
+	}
+	if facility.Attributes.Latitude != nil && facility.Attributes.Longitude != nil {
+		facilityMap["latitude"] = *facility.Attributes.Latitude
+		facilityMap["longitude"] = *facility.Attributes.Longitude
+	}
+	if len(facility.Attributes.Properties) > 0 {
+		facilityMap["properties"] = facilityProperties(facility.Attributes.Properties)
+	}
+	if status.Live != nil {
+		facilityMap["live"] = map[string]interface{}{
+			"properties": facilityProperties(status.Live.Attributes.Properties),
+			"updated_at": status.Live.Attributes.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	if len(status.Alerts) > 0 {
+		alertIDs := make([]string, len(status.Alerts))
+		for i, alert := range status.Alerts {
+			alertIDs[i] = alert.ID
+		}
+		facilityMap["alert_ids"] = alertIDs
+		facilityMap["outage"] = status.Alerts[0].Attributes.Header
+	}
+
+	return facilityMap
+}
+
+// facilityProperties collects facility properties by name, listing every
+// value of a property that appears more than once
+func facilityProperties(properties []models.FacilityProperty) map[string]interface{} {
+	propertyMap := make(map[string]interface{}, len(properties))
+	repeated := make(map[string]bool)
+	for _, property := range properties {
+		existing, ok := propertyMap[property.Name]
+		switch {
+		case !ok:
+			propertyMap[property.Name] = property.Value
+		case repeated[property.Name]:
+			propertyMap[property.Name] = append(existing.([]interface{}), property.Value)
+		default:
+			propertyMap[property.Name] = []interface{}{existing, property.Value}
+			repeated[property.Name] = true
+		}
+	}
+	return propertyMap
+}
@@ -0,0 +1,124 @@
+// ABOUTME: This file contains tests for the station accessibility handlers for the MCP server.
+// ABOUTME: It verifies facility statuses and outage alerts are reported for a station.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// stationFacilities is a facility and alert backend for Park Street, with
+// one elevator out of service
+type stationFacilities struct {
+	mbta.AlertDataSource
+}
+
+func (f *stationFacilities) GetFacilities(ctx context.Context, params map[string]string) ([]models.Facility, error) {
+	facility := func(id, name, facilityType string) models.Facility {
+		return models.Facility{
+			ID:   id,
+			Type: "facility",
+			Attributes: models.FacilityAttributes{
+				LongName:   name,
+				Type:       facilityType,
+				Properties: []models.FacilityProperty{{Name: "excludes-stop", Value: "70196"}, {Name: "excludes-stop", Value: "70197"}},
+			},
+			Relationships: map[string]interface{}{"stop": boardRelationship("stop", "place-pktrm")},
+		}
+	}
+	return []models.Facility{
+		facility("804", "Park Street Elevator 804", models.FacilityTypeElevator),
+		facility("823", "Park Street Elevator 823", models.FacilityTypeElevator),
+		facility("830", "Park Street Escalator 830", models.FacilityTypeEscalator),
+	}, nil
+}
+
+func (f *stationFacilities) GetLiveFacilities(ctx context.Context, params map[string]string) ([]models.LiveFacility, error) {
+	return nil, nil
+}
+
+func (f *stationFacilities) GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+	return []models.Alert{{
+		ID: "outage-804",
+		Attributes: models.AlertAttributes{
+			Header:         "Elevator 804 unavailable",
+			Effect:         models.AlertEffectElevatorOutage,
+			ActivePeriod:   []models.AlertPeriod{{Start: time.Now().Add(-time.Hour)}},
+			InformedEntity: []models.AlertEntity{{Stop: "place-pktrm", FacilityID: "804", Activities: []string{"USING_WHEELCHAIR"}}},
+		},
+	}}, nil
+}
+
+func TestGetStationAccessibilityHandler(t *testing.T) {
+	facilities := &stationFacilities{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{
+		StopDataSource:     parkStreetStops(),
+		FacilityDataSource: facilities,
+		AlertDataSource:    facilities,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("Validates parameters", func(t *testing.T) {
+		for _, args := range []map[string]any{{}, {"stop_id": ""}} {
+			if text, isError := callTool(t, server.getStationAccessibilityHandler, args); !isError {
+				t.Errorf("Expected an error for %v, got %s", args, text)
+			}
+		}
+	})
+
+	t.Run("Reports facilities with outages", func(t *testing.T) {
+		text, isError := callTool(t, server.getStationAccessibilityHandler, map[string]any{"stop_id": "70076"})
+		if isError {
+			t.Fatalf("Expected success, got %s", text)
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if data["station_id"] != "place-pktrm" || data["summary"] != "1 of 2 elevators at Park Street are out of service" {
+			t.Errorf("Expected Park Street with one elevator out, got %v", data)
+		}
+		if data["facility_count"] != float64(3) || data["out_of_service_count"] != float64(1) {
+			t.Errorf("Expected 3 facilities with 1 out of service, got %v and %v", data["facility_count"], data["out_of_service_count"])
+		}
+
+		elevator := data["facilities"].([]interface{})[0].(map[string]interface{})
+		if elevator["id"] != "804" || elevator["status"] != "out_of_service" || elevator["outage"] != "Elevator 804 unavailable" {
+			t.Errorf("Expected elevator 804 out of service, got %v", elevator)
+		}
+		excluded := elevator["properties"].(map[string]interface{})["excludes-stop"].([]interface{})
+		if len(excluded) != 2 {
+			t.Errorf("Expected both excluded stops, got %v", excluded)
+		}
+
+		alerts := data["alerts"].([]interface{})
+		if len(alerts) != 1 || alerts[0].(map[string]interface{})["effect"] != "ELEVATOR_OUTAGE" {
+			t.Errorf("Expected the elevator outage alert, got %v", alerts)
+		}
+	})
+
+	t.Run("Filters by facility type", func(t *testing.T) {
+		text, _ := callTool(t, server.getStationAccessibilityHandler, map[string]any{"stop_id": "place-pktrm", "facility_type": "escalator"})
+
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if data["facility_count"] != float64(1) || data["out_of_service_count"] != float64(0) {
+			t.Errorf("Expected only the working escalator, got %v", data["facilities"])
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta"
@@ -31,7 +32,7 @@ func (s *Server) registerServiceAlertTools() {
 				},
 				"stop_id": map[string]any{
 					"type":        "string",
-					"description": "Filter alerts by stop ID",
+					"description": "Filter alerts by stop ID; a station ID includes its platforms",
 				},
 				"effect": map[string]any{
 					"type":        "string",
@@ -83,7 +84,7 @@ func (s *Server) registerServiceAlertTools() {
 			Properties: map[string]any{
 				"stop_id": map[string]any{
 					"type":        "string",
-					"description": "Filter accessibility alerts by stop ID",
+					"description": "Filter accessibility alerts by stop ID; a station ID includes its platforms",
 				},
 			},
 		},
@@ -97,11 +98,8 @@ func (s *Server) registerServiceAlertTools() {
 func (s *Server) getAlertsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for service alerts: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	params := make(map[string]string)
 
 	// Process route_id filter
@@ -121,7 +119,7 @@ func (s *Server) getAlertsHandler(ctx context.Context, request mcp.CallToolReque
 			return createErrorResponse(fmt.Sprintf("Invalid stop_id parameter: %v", stopID)), nil
 		}
 		log.Printf("Filtering alerts by stop ID: %s", stopIDStr)
-		params["filter[stop]"] = stopIDStr
+		params["filter[stop]"] = strings.Join(s.expandStopID(ctx, stopIDStr), ",")
 	}
 
 	// Process effect filter
@@ -145,7 +143,7 @@ func (s *Server) getAlertsHandler(ctx context.Context, request mcp.CallToolReque
 	}
 
 	// Get alerts with the specified filters
-	alerts, err := client.GetAlerts(ctx, params)
+	alerts, err := s.api.GetAlerts(ctx, params)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve alerts: %v", err)), nil
 	}
@@ -190,14 +188,11 @@ func (s *Server) getAlertsHandler(ctx context.Context, request mcp.CallToolReque
 func (s *Server) getServiceDisruptionsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for service disruptions: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 
 	// Get service disruptions
-	disruptions, err := client.GetServiceDisruptions(ctx)
+	disruptions, err := mbta.ServiceDisruptions(ctx, s.api)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve service disruptions: %v", err)), nil
 	}
@@ -293,14 +288,11 @@ func (s *Server) getServiceDisruptionsHandler(ctx context.Context, request mcp.C
 func (s *Server) getAccessibilityAlertsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for accessibility alerts: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 
 	// Get accessibility alerts
-	alerts, err := client.GetAccessibilityAlerts(ctx)
+	alerts, err := mbta.AccessibilityAlerts(ctx, s.api)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve accessibility alerts: %v", err)), nil
 	}
@@ -314,12 +306,16 @@ func (s *Server) getAccessibilityAlertsHandler(ctx context.Context, request mcp.
 
 		log.Printf("Filtering accessibility alerts by stop ID: %s", stopIDStr)
 
-		// Filter alerts for the specified stop
+		// Filter alerts for the specified stop, or for a station and its platforms
+		stopIDs := make(map[string]bool)
+		for _, id := range s.expandStopID(ctx, stopIDStr) {
+			stopIDs[id] = true
+		}
 		filteredAlerts := make([]models.Alert, 0, len(alerts))
 		for _, alert := range alerts {
 			affectedStops := alert.GetAffectedStops()
 			for _, stop := range affectedStops {
-				if stop == stopIDStr {
+				if stopIDs[stop] {
 					filteredAlerts = append(filteredAlerts, alert)
 					break
 				}
@@ -363,79 +359,7 @@ func formatAlertsResponse(alerts []models.Alert) (*mcp.CallToolResult, error) {
 	alertsData := make([]map[string]interface{}, 0, len(alerts))
 
 	for _, alert := range alerts {
-		// Format alert data
-		alertMap := map[string]interface{}{
-			"id":             alert.ID,
-			"header":         alert.Attributes.Header,
-			"description":    alert.Attributes.Description,
-			"effect":         string(alert.Attributes.Effect),
-			"effect_name":    models.GetAlertEffectDescription(alert.Attributes.Effect),
-			"cause":          string(alert.Attributes.Cause),
-			"cause_name":     models.GetAlertCauseDescription(alert.Attributes.Cause),
-			"severity":       alert.Attributes.Severity,
-			"severity_name":  models.GetSeverityDescription(alert.Attributes.Severity),
-			"created_at":     alert.Attributes.CreatedAt,
-			"updated_at":     alert.Attributes.UpdatedAt,
-			"service_effect": alert.Attributes.ServiceEffect,
-			"timeframe":      alert.Attributes.Timeframe,
-			"lifecycle":      alert.Attributes.Lifecycle,
-			"is_active":      alert.IsActive(now),
-		}
-
-		// Add URL if available
-		if alert.Attributes.URL != "" {
-			alertMap["url"] = alert.Attributes.URL
-		}
-
-		// Format active periods
-		activePeriods := make([]map[string]interface{}, 0, len(alert.Attributes.ActivePeriod))
-		for _, period := range alert.Attributes.ActivePeriod {
-			periodMap := map[string]interface{}{}
-
-			if !period.Start.IsZero() {
-				periodMap["start"] = period.Start.Format(time.RFC3339)
-			}
-
-			if !period.End.IsZero() {
-				periodMap["end"] = period.End.Format(time.RFC3339)
-			}
-
-			// Check if this period is currently active
-			isActive := (period.Start.IsZero() || !now.Before(period.Start)) &&
-				(period.End.IsZero() || !now.After(period.End))
-			periodMap["is_active"] = isActive
-
-			activePeriods = append(activePeriods, periodMap)
-		}
-		alertMap["active_periods"] = activePeriods
-
-		// Add affected routes and stops
-		alertMap["affected_routes"] = alert.GetAffectedRoutes()
-		alertMap["affected_stops"] = alert.GetAffectedStops()
-
-		// Create a readable summary of activities affected
-		activities := make([]string, 0)
-		if alert.HasActivity("BOARD") {
-			activities = append(activities, "boarding")
-		}
-		if alert.HasActivity("EXIT") {
-			activities = append(activities, "exiting")
-		}
-		if alert.HasActivity("RIDE") {
-			activities = append(activities, "riding")
-		}
-		if alert.HasActivity("USING_WHEELCHAIR") {
-			activities = append(activities, "wheelchair access")
-		}
-		if alert.HasActivity("USING_ESCALATOR") {
-			activities = append(activities, "escalator use")
-		}
-
-		if len(activities) > 0 {
-			alertMap["affected_activities"] = activities
-		}
-
-		alertsData = append(alertsData, alertMap)
+		alertsData = append(alertsData, formatAlert(alert, now))
 	}
 
 	// Create JSON string response
@@ -454,3 +378,84 @@ func formatAlertsResponse(alerts []models.Alert) (*mcp.CallToolResult, error) {
 		},
 	}, nil
 }
+
+// formatAlert converts an alert to a simplified format, noting whether it's
+// active at the given time
+func formatAlert(alert models.Alert, now time.Time) map[string]interface{} {
+	// Format alert data
+	alertMap := map[string]interface{}{
+		"id":             alert.ID,
+		"header":         alert.Attributes.Header,
+		"description":    alert.Attributes.Description,
+		"effect":         string(alert.Attributes.Effect),
+		"effect_name":    models.GetAlertEffectDescription(alert.Attributes.Effect),
+		"cause":          string(alert.Attributes.Cause),
+		"cause_name":     models.GetAlertCauseDescription(alert.Attributes.Cause),
+		"severity":       alert.Attributes.Severity,
+		"severity_name":  models.GetSeverityDescription(alert.Attributes.Severity),
+		"created_at":     alert.Attributes.CreatedAt,
+		"updated_at":     alert.Attributes.UpdatedAt,
+		"service_effect": alert.Attributes.ServiceEffect,
+		"timeframe":      alert.Attributes.Timeframe,
+		"lifecycle":      alert.Attributes.Lifecycle,
+		"is_active":      alert.IsActive(now),
+	}
+
+	// Add URL if available
+	if alert.Attributes.URL != "" {
+		alertMap["url"] = alert.Attributes.URL
+	}
+
+	// Format active periods
+	activePeriods := make([]map[string]interface{}, 0, len(alert.Attributes.ActivePeriod))
+	for _, period := range alert.Attributes.ActivePeriod {
+		periodMap := map[string]interface{}{}
+
+		if !period.Start.IsZero() {
+			periodMap["start"] = period.Start.Format(time.RFC3339)
+		}
+
+		if !period.End.IsZero() {
+			periodMap["end"] = period.End.Format(time.RFC3339)
+		}
+
+		// Check if this period is currently active
+		isActive := (period.Start.IsZero() || !now.Before(period.Start)) &&
+			(period.End.IsZero() || !now.After(period.End))
+		periodMap["is_active"] = isActive
+
+		activePeriods = append(activePeriods, periodMap)
+	}
+	alertMap["active_periods"] = activePeriods
+
+	// Add affected routes and stops
+	alertMap["affected_routes"] = alert.GetAffectedRoutes()
+	alertMap["affected_stops"] = alert.GetAffectedStops()
+	if facilities := alert.GetAffectedFacilities(); len(facilities) > 0 {
+		alertMap["affected_facilities"] = facilities
+	}
+
+	// Create a readable summary of activities affected
+	activities := make([]string, 0)
+	if alert.HasActivity("BOARD") {
+		activities = append(activities, "boarding")
+	}
+	if alert.HasActivity("EXIT") {
+		activities = append(activities, "exiting")
+	}
+	if alert.HasActivity("RIDE") {
+		activities = append(activities, "riding")
+	}
+	if alert.HasActivity("USING_WHEELCHAIR") {
+		activities = append(activities, "wheelchair access")
+	}
+	if alert.HasActivity("USING_ESCALATOR") {
+		activities = append(activities, "escalator use")
+	}
+
+	if len(activities) > 0 {
+		alertMap["affected_activities"] = activities
+	}
+
+	return alertMap
+}
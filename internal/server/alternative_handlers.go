@@ -0,0 +1,220 @@
+// ABOUTME: This file implements the alternative route handlers for the MCP server.
+// ABOUTME: It re-plans trips around suspended service, shuttles and closed stations, explaining each detour.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerAlternativeRouteTools registers the alternative route tools and handlers
+func (s *Server) registerAlternativeRouteTools() {
+	// Tool: FindAlternatives - re-plans a trip around service disruptions
+	findAlternativesTool := mcp.Tool{
+		Name:        "find_alternatives",
+		Description: "Find alternative MBTA itineraries during service disruptions. Avoids suspended segments, stops replaced by shuttle buses and closed stations, and explains which alert caused each detour. Give a route_id to find ways around that route entirely; only whole routes can be avoided, not a single trip or leg of one.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"origin_stop_id": map[string]any{
+					"type":        "string",
+					"description": "The ID or name of the origin stop or station, such as place-sstat or South Station. A name is matched to the closest stop, reported under resolved_stops",
+				},
+				"destination_stop_id": map[string]any{
+					"type":        "string",
+					"description": "The ID or name of the destination stop or station, such as place-harsq or Harvard. A name is matched to the closest stop, reported under resolved_stops",
+				},
+				"route_id": map[string]any{
+					"type":        "string",
+					"description": "Optional: A route to avoid in both directions and at every stop, such as the route of a delayed leg",
+				},
+				"departure_time": map[string]any{
+					"type":        "string",
+					"description": "The desired departure time (ISO 8601 format, e.g. '2023-05-23T14:30:00Z'). If not provided, current time is used.",
+				},
+				"wheelchair_accessible": map[string]any{
+					"type":        "boolean",
+					"description": "Whether the trip must be wheelchair accessible, avoiding platforms with elevator outages, or whole stations when an outage doesn't say which platforms it affects",
+				},
+				"max_transfers": map[string]any{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of transfers allowed (default: %d)", mbta.DefaultMaxTransfers),
+					"minimum":     0,
+				},
+			},
+			Required: []string{"origin_stop_id", "destination_stop_id"},
+		},
+	}
+
+	// Register the alternatives tool with its handler, wrapped with middleware
+	s.mcpServer.AddTool(findAlternativesTool, s.wrapWithMiddleware(s.findAlternativesHandler))
+}
+
+// findAlternativesHandler handles requests for itineraries around service disruptions
+func (s *Server) findAlternativesHandler(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+	resolved := resolvedStops{}
+	defer func() { result = resolved.annotate(result) }()
+
+	log.Printf("Received request for alternative routes: %s", request.Params.Name)
+
+	// Extract required parameters
+	args := request.GetArguments()
+	originStopID, ok := args["origin_stop_id"].(string)
+	if !ok || originStopID == "" {
+		return createErrorResponse("Missing or invalid origin_stop_id parameter"), nil
+	}
+
+	destinationStopID, ok := args["destination_stop_id"].(string)
+	if !ok || destinationStopID == "" {
+		return createErrorResponse("Missing or invalid destination_stop_id parameter"), nil
+	}
+
+	// Extract optional parameters
+	departureTime := time.Now().In(mbta.ServiceLocation())
+	if value, ok := args["departure_time"].(string); ok && value != "" {
+		var err error
+		departureTime, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return createErrorResponse(fmt.Sprintf("Invalid departure_time format: %v", err)), nil
+		}
+	}
+
+	options := map[string]interface{}{
+		"avoid_disruptions": true,
+	}
+	if wheelchairAccessible, ok := args["wheelchair_accessible"].(bool); ok {
+		options["wheelchair_accessible"] = wheelchairAccessible
+	}
+	if maxTransfers, ok := args["max_transfers"].(float64); ok {
+		if maxTransfers < 0 {
+			return createErrorResponse("max_transfers must not be negative"), nil
+		}
+		options["max_transfers"] = int(maxTransfers)
+	}
+	routeID, _ := args["route_id"].(string)
+	if routeID != "" {
+		options["avoid_routes"] = []string{routeID}
+	}
+
+	// Stops may be given by name as well as by ID
+	originStop, err := s.resolveStop(ctx, resolved, "origin_stop_id", originStopID)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to find origin stop: %v", err)), nil
+	}
+	destinationStop, err := s.resolveStop(ctx, resolved, "destination_stop_id", destinationStopID)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to find destination stop: %v", err)), nil
+	}
+
+	log.Printf("Finding alternatives from %s to %s at %s", originStop.ID, destinationStop.ID, departureTime.Format(time.RFC3339))
+
+	tripPlans, err := s.api.PlanTrips(ctx, originStop.ID, destinationStop.ID, departureTime, options)
+	if err != nil {
+		var disrupted *mbta.DisruptedTripError
+		if errors.As(err, &disrupted) {
+			return formatDisruptedTripResponse(originStop, destinationStop, disrupted)
+		}
+		var inaccessible *mbta.InaccessibleTripError
+		if errors.As(err, &inaccessible) {
+			return formatInaccessibleTripResponse(originStop, destinationStop, inaccessible)
+		}
+		return createErrorResponse(fmt.Sprintf("Failed to find alternatives: %v", err)), nil
+	}
+	if len(tripPlans) == 0 {
+		return createErrorResponse("No trip plans found"), nil
+	}
+
+	response := tripPlansToMap(tripPlans, mbta.OptimizeFastest)
+	if routeID != "" {
+		response["avoided_route_id"] = routeID
+	}
+	if len(tripPlans[0].Detours) == 0 {
+		response["message"] = "No active service disruptions affect this trip."
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize trip plan data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatDisruptedTripResponse explains that every trip found uses service
+// that isn't running
+func formatDisruptedTripResponse(origin, destination *models.Stop, disrupted *mbta.DisruptedTripError) (*mcp.CallToolResult, error) {
+	response := map[string]interface{}{
+		"origin": map[string]string{
+			"id":   origin.ID,
+			"name": origin.Attributes.Name,
+		},
+		"destination": map[string]string{
+			"id":   destination.ID,
+			"name": destination.Attributes.Name,
+		},
+		"itinerary_count": 0,
+		"itineraries":     []interface{}{},
+		"message":         "No trip found that avoids current service disruptions.",
+		"detours":         formatDetours(disrupted.Detours),
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize trip plan data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatDetours converts the disruptions that ruled out usual itineraries to
+// a simplified format naming the alert behind each
+func formatDetours(detours []models.Detour) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(detours))
+	for _, detour := range detours {
+		stops := make([]map[string]string, 0, len(detour.Stops))
+		for _, stop := range detour.Stops {
+			stops = append(stops, map[string]string{
+				"id":   stop.ID,
+				"name": stop.Attributes.Name,
+			})
+		}
+
+		result = append(result, map[string]interface{}{
+			"alert_id":     detour.Alert.ID,
+			"effect":       detour.Alert.Attributes.Effect,
+			"header":       detour.Alert.Attributes.Header,
+			"route_id":     detour.RouteID,
+			"route_name":   detour.RouteName,
+			"closed_stops": stops,
+			"reason":       detour.Reason,
+		})
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// detouredTrips records trip planning options and answers with fixed results
+type detouredTrips struct {
+	mbta.TripDataSource
+	options map[string]interface{}
+	plans   []models.TripPlan
+	err     error
+}
+
+func (d *detouredTrips) PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error) {
+	d.options = options
+	return d.plans, d.err
+}
+
+func TestFindAlternativesHandler(t *testing.T) {
+	south := &models.Stop{ID: "place-sstat", Attributes: models.StopAttributes{Name: "South Station"}}
+	harvard := &models.Stop{ID: "place-harsq", Attributes: models.StopAttributes{Name: "Harvard"}}
+	detour := models.Detour{
+		Alert:     &models.Alert{ID: "shuttle-red", Attributes: models.AlertAttributes{Effect: models.AlertEffectShuttle, Header: "Shuttle buses replace Red Line trains"}},
+		RouteID:   "Red",
+		RouteName: "Red Line",
+		Stops:     []models.Stop{{ID: "place-pktrm", Attributes: models.StopAttributes{Name: "Park Street"}}},
+		Reason:    "Shuttle Bus Service on Red Line at Park Street: Shuttle buses replace Red Line trains",
+	}
+
+	trips := &detouredTrips{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("Detour", func(t *testing.T) {
+		now := time.Now()
+		trips.plans = []models.TripPlan{{
+			Origin:        south,
+			Destination:   harvard,
+			DepartureTime: now,
+			ArrivalTime:   now.Add(40 * time.Minute),
+			Legs:          []models.TripLeg{{Origin: south, Destination: harvard, RouteID: "1", DepartureTime: now, ArrivalTime: now.Add(40 * time.Minute)}},
+			Detours:       []models.Detour{detour},
+		}}
+
+		text, isError := callTool(t, server.findAlternativesHandler, map[string]any{
+			"origin_stop_id":      "South Station",
+			"destination_stop_id": "place-harsq",
+			"route_id":            "Red",
+		})
+		if isError {
+			t.Fatalf("Unexpected error %s", text)
+		}
+		if trips.options["avoid_disruptions"] != true {
+			t.Errorf("Expected disruptions to be avoided, got options %v", trips.options)
+		}
+		if routes, _ := trips.options["avoid_routes"].([]string); len(routes) != 1 || routes[0] != "Red" {
+			t.Errorf("Expected the Red Line to be avoided, got %v", trips.options["avoid_routes"])
+		}
+
+		var response struct {
+			AvoidedRouteID string                   `json:"avoided_route_id"`
+			ItineraryCount int                      `json:"itinerary_count"`
+			Detours        []map[string]interface{} `json:"detours"`
+		}
+		if err := json.Unmarshal([]byte(text), &response); err != nil {
+			t.Fatalf("Failed to parse response JSON: %v", err)
+		}
+		if response.AvoidedRouteID != "Red" || response.ItineraryCount != 1 {
+			t.Errorf("Expected 1 itinerary avoiding Red, got %s", text)
+		}
+		if len(response.Detours) != 1 || response.Detours[0]["alert_id"] != "shuttle-red" || response.Detours[0]["reason"] != detour.Reason {
+			t.Errorf("Expected the shuttle detour, got %v", response.Detours)
+		}
+	})
+
+	t.Run("No way around", func(t *testing.T) {
+		trips.plans = nil
+		trips.err = &mbta.DisruptedTripError{Origin: "place-sstat", Destination: "place-harsq", Detours: []models.Detour{detour}}
+
+		text, isError := callTool(t, server.findAlternativesHandler, map[string]any{
+			"origin_stop_id":      "place-sstat",
+			"destination_stop_id": "place-harsq",
+		})
+		if isError {
+			t.Fatalf("Expected an explanation, got error %s", text)
+		}
+		if !strings.Contains(text, `"itinerary_count": 0`) || !strings.Contains(text, detour.Reason) {
+			t.Errorf("Expected no itineraries and the shuttle detour, got %s", text)
+		}
+	})
+
+	t.Run("Missing destination", func(t *testing.T) {
+		if text, isError := callTool(t, server.findAlternativesHandler, map[string]any{"origin_stop_id": "place-sstat"}); !isError {
+			t.Errorf("Expected an error, got %s", text)
+		}
+	})
+}
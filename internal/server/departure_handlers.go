@@ -0,0 +1,207 @@
+// ABOUTME: This file implements the departure board handlers for the MCP server.
+// ABOUTME: It merges predictions and schedules into upcoming departures grouped by route and headsign.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Departure board defaults and limits
+const (
+	defaultDepartureWindow   = 30
+	maxDepartureWindow       = 180
+	defaultDeparturesPerLine = 5
+)
+
+// registerDepartureBoardTools registers the departure board tools and handlers
+func (s *Server) registerDepartureBoardTools() {
+	// Tool: GetDepartures - upcoming departures from a station or stop
+	getDeparturesTool := mcp.Tool{
+		Name:        "get_departures",
+		Description: "Get upcoming departures from an MBTA station and its platforms, using real-time predictions where available and scheduled times otherwise, grouped by route, direction and headsign",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"stop_id": map[string]any{
+					"type":        "string",
+					"description": "Station or stop ID, such as place-pktrm for Park Street",
+				},
+				"minutes": map[string]any{
+					"type":        "number",
+					"description": fmt.Sprintf("How many minutes ahead to look (default: %d, max: %d)", defaultDepartureWindow, maxDepartureWindow),
+				},
+				"route_id": map[string]any{
+					"type":        "string",
+					"description": "Only include departures on this route",
+				},
+				"direction_id": map[string]any{
+					"type":        "number",
+					"description": "Only include departures in this direction (0 or 1)",
+				},
+				"max_per_group": map[string]any{
+					"type":        "number",
+					"description": fmt.Sprintf("Maximum departures to list for each route, direction and headsign (default: %d)", defaultDeparturesPerLine),
+				},
+			},
+			Required: []string{"stop_id"},
+		},
+	}
+
+	// Register the departures tool with its handler, wrapped with middleware
+	s.mcpServer.AddTool(getDeparturesTool, s.wrapWithMiddleware(s.getDeparturesHandler))
+}
+
+// getDeparturesHandler handles requests for a stop's departure board
+func (s *Server) getDeparturesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("Received request for departures: %s", request.Params.Name)
+
+	// Extract required parameters
+	args := request.GetArguments()
+	stopID, ok := args["stop_id"].(string)
+	if !ok || stopID == "" {
+		return createErrorResponse("Missing or invalid stop_id parameter"), nil
+	}
+
+	// Extract optional parameters
+	window := defaultDepartureWindow
+	if minutes, ok := args["minutes"].(float64); ok {
+		if minutes < 1 || minutes > maxDepartureWindow {
+			return createErrorResponse(fmt.Sprintf("Invalid minutes parameter, must be between 1 and %d", maxDepartureWindow)), nil
+		}
+		window = int(minutes)
+	}
+
+	maxPerGroup := defaultDeparturesPerLine
+	if limit, ok := args["max_per_group"].(float64); ok && limit >= 1 {
+		maxPerGroup = int(limit)
+	}
+
+	filters := make(map[string]string)
+	if routeID, ok := args["route_id"].(string); ok && routeID != "" {
+		filters["filter[route]"] = routeID
+	}
+	if directionID, ok := args["direction_id"].(float64); ok {
+		if directionID != 0 && directionID != 1 {
+			return createErrorResponse("Invalid direction_id parameter, must be 0 or 1"), nil
+		}
+		filters["filter[direction_id]"] = strconv.Itoa(int(directionID))
+	}
+
+	log.Printf("Finding departures from %s in the next %d minutes", stopID, window)
+
+	now := time.Now().In(mbta.ServiceLocation())
+	stopIDs := strings.Join(s.expandStopID(ctx, stopID), ",")
+	departures, err := mbta.Departures(ctx, s.api, stopIDs, now, time.Duration(window)*time.Minute, filters)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to get departures: %v", err)), nil
+	}
+
+	return formatDeparturesResponse(stopID, now, window, departures, maxPerGroup)
+}
+
+// formatDeparturesResponse groups departures by route, direction and headsign,
+// ordering the groups by their next departure
+func formatDeparturesResponse(stopID string, now time.Time, window int, departures []models.Departure, maxPerGroup int) (*mcp.CallToolResult, error) {
+	type groupKey struct {
+		routeID     string
+		directionID int
+		headsign    string
+	}
+
+	var order []groupKey
+	groups := make(map[groupKey]map[string]interface{})
+	for _, departure := range departures {
+		key := groupKey{departure.RouteID, departure.DirectionID, departure.Headsign}
+		group, ok := groups[key]
+		if !ok {
+			group = map[string]interface{}{
+				"route_id":     departure.RouteID,
+				"route_name":   departure.RouteName,
+				"direction_id": departure.DirectionID,
+				"headsign":     departure.Headsign,
+				"departures":   []map[string]interface{}{},
+			}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		list := group["departures"].([]map[string]interface{})
+		if len(list) >= maxPerGroup {
+			continue
+		}
+		group["departures"] = append(list, formatDeparture(departure, now))
+	}
+
+	groupData := make([]map[string]interface{}, 0, len(order))
+	for _, key := range order {
+		groupData = append(groupData, groups[key])
+	}
+
+	departuresData := map[string]interface{}{
+		"stop_id":         stopID,
+		"as_of":           now.Format(time.RFC3339),
+		"window_minutes":  window,
+		"departure_count": len(departures),
+		"groups":          groupData,
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(departuresData, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize departure data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatDeparture converts a departure to a simplified format with a countdown
+func formatDeparture(departure models.Departure, now time.Time) map[string]interface{} {
+	departureMap := map[string]interface{}{
+		"trip_id":        departure.TripID,
+		"stop_id":        departure.StopID,
+		"status":         departure.Status,
+		"departure_time": departure.Time().Format(time.RFC3339),
+		"minutes":        departure.MinutesUntil(now),
+		"realtime":       departure.PredictedTime != nil,
+	}
+
+	if departure.ScheduledTime != nil {
+		departureMap["scheduled_time"] = departure.ScheduledTime.Format(time.RFC3339)
+	}
+	if departure.PredictedTime != nil {
+		departureMap["predicted_time"] = departure.PredictedTime.Format(time.RFC3339)
+	}
+	if delay := departure.DelayMinutes(); delay != 0 {
+		departureMap["delay_minutes"] = delay
+	}
+	if departure.Track != "" {
+		departureMap["track"] = departure.Track
+	}
+	if departure.VehicleID != "" {
+		departureMap["vehicle_id"] = departure.VehicleID
+	}
+	if departure.StatusText != "" {
+		departureMap["status_text"] = departure.StatusText
+	}
+
+	return departureMap
+}
@@ -0,0 +1,195 @@
+// ABOUTME: This file contains tests for the departure board handlers for the MCP server.
+// ABOUTME: It verifies parameter validation and grouping of merged departures.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// departureBoardAPI answers departure queries from fixed schedules and predictions
+type departureBoardAPI struct {
+	mbta.CompositeAPI
+	schedules   []models.Schedule
+	included    []models.Included
+	predictions []models.Prediction
+	params      map[string]string
+}
+
+func (a *departureBoardAPI) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	a.params = params
+	return a.schedules, a.included, nil
+}
+
+func (a *departureBoardAPI) GetPredictions(ctx context.Context, params map[string]string) ([]models.Prediction, error) {
+	return a.predictions, nil
+}
+
+func (a *departureBoardAPI) GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error) {
+	return nil, nil
+}
+
+// boardRelationship builds a to-one relationship
+func boardRelationship(resourceType, id string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{"id": id, "type": resourceType}}
+}
+
+// boardSchedule builds a Red Line schedule departing a number of minutes from now
+func boardSchedule(tripID string, minutes int) models.Schedule {
+	return models.Schedule{
+		ID:   "schedule-" + tripID,
+		Type: "schedule",
+		Attributes: models.ScheduleAttributes{
+			DepartureTime: time.Now().Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339),
+			StopSequence:  1,
+		},
+		Relationships: map[string]interface{}{
+			"route": boardRelationship("route", "Red"),
+			"trip":  boardRelationship("trip", tripID),
+			"stop":  boardRelationship("stop", "70075"),
+		},
+	}
+}
+
+// callDepartures calls the departures handler and decodes its response
+func callDepartures(t *testing.T, server *Server, args map[string]any) (map[string]interface{}, bool) {
+	t.Helper()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_departures"
+	request.Params.Arguments = args
+
+	result, err := server.getDeparturesHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &data); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return data, result.IsError
+}
+
+func TestGetDeparturesHandler(t *testing.T) {
+	predicted := time.Now().Add(6*time.Minute + 30*time.Second).Format(time.RFC3339)
+	api := &departureBoardAPI{
+		CompositeAPI: mbta.CompositeAPI{StopDataSource: parkStreetStops()},
+		schedules: []models.Schedule{
+			boardSchedule("ashmont-1", 4),
+			boardSchedule("braintree-1", 8),
+			boardSchedule("ashmont-2", 12),
+			boardSchedule("ashmont-3", 20),
+		},
+		included: []models.Included{
+			{ID: "ashmont-1", Type: "trip", Attributes: map[string]interface{}{"headsign": "Ashmont"}},
+			{ID: "ashmont-2", Type: "trip", Attributes: map[string]interface{}{"headsign": "Ashmont"}},
+			{ID: "ashmont-3", Type: "trip", Attributes: map[string]interface{}{"headsign": "Ashmont"}},
+			{ID: "braintree-1", Type: "trip", Attributes: map[string]interface{}{"headsign": "Braintree"}},
+			{ID: "Red", Type: "route", Attributes: map[string]interface{}{"long_name": "Red Line"}},
+		},
+		predictions: []models.Prediction{
+			{
+				ID:   "prediction-ashmont-1",
+				Type: "prediction",
+				Attributes: models.PredictionAttributes{
+					DepartureTime: &predicted,
+					StopSequence:  1,
+				},
+				Relationships: map[string]interface{}{
+					"route": boardRelationship("route", "Red"),
+					"trip":  boardRelationship("trip", "ashmont-1"),
+					"stop":  boardRelationship("stop", "70075"),
+				},
+			},
+			{
+				ID:   "prediction-ashmont-2",
+				Type: "prediction",
+				Attributes: models.PredictionAttributes{
+					Schedule:     models.ScheduleRelationshipCancelled,
+					StopSequence: 1,
+				},
+				Relationships: map[string]interface{}{
+					"trip": boardRelationship("trip", "ashmont-2"),
+				},
+			},
+		},
+	}
+
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("Validates parameters", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{},
+			{"stop_id": "place-pktrm", "minutes": 0.0},
+			{"stop_id": "place-pktrm", "minutes": 500.0},
+			{"stop_id": "place-pktrm", "direction_id": 2.0},
+		} {
+			if data, isError := callDepartures(t, server, args); !isError || data["error"] == nil {
+				t.Errorf("Expected an error for %v, got %v", args, data)
+			}
+		}
+	})
+
+	t.Run("Groups departures by headsign", func(t *testing.T) {
+		data, isError := callDepartures(t, server, map[string]any{
+			"stop_id":       "place-pktrm",
+			"minutes":       15.0,
+			"direction_id":  0.0,
+			"max_per_group": 2.0,
+		})
+		if isError {
+			t.Fatalf("Expected success, got %v", data)
+		}
+
+		if api.params["filter[stop]"] != "place-pktrm,70075,70076" || api.params["filter[direction_id]"] != "0" {
+			t.Errorf("Expected schedules for place-pktrm and its platforms in direction 0, got %v", api.params)
+		}
+		if data["window_minutes"] != float64(15) || data["departure_count"] != float64(3) {
+			t.Errorf("Expected 3 departures in 15 minutes, got %v in %v", data["departure_count"], data["window_minutes"])
+		}
+
+		groups := data["groups"].([]interface{})
+		if len(groups) != 2 {
+			t.Fatalf("Expected 2 groups, got %d", len(groups))
+		}
+
+		ashmont := groups[0].(map[string]interface{})
+		if ashmont["headsign"] != "Ashmont" || ashmont["route_name"] != "Red Line" {
+			t.Errorf("Expected the Ashmont group first, got %v", ashmont)
+		}
+		ashmontDepartures := ashmont["departures"].([]interface{})
+		if len(ashmontDepartures) != 2 {
+			t.Fatalf("Expected 2 Ashmont departures, got %d", len(ashmontDepartures))
+		}
+
+		next := ashmontDepartures[0].(map[string]interface{})
+		if next["status"] != "predicted" || next["minutes"] != float64(6) || next["delay_minutes"] != float64(3) || next["realtime"] != true {
+			t.Errorf("Expected a predicted departure in 6 minutes, 3 late, got %v", next)
+		}
+		cancelled := ashmontDepartures[1].(map[string]interface{})
+		if cancelled["status"] != "cancelled" || cancelled["realtime"] != false {
+			t.Errorf("Expected the cancelled trip, got %v", cancelled)
+		}
+
+		braintree := groups[1].(map[string]interface{})
+		first := braintree["departures"].([]interface{})[0].(map[string]interface{})
+		if braintree["headsign"] != "Braintree" || first["status"] != "scheduled" || first["minutes"] != float64(7) {
+			t.Errorf("Expected a scheduled Braintree departure in 7 minutes, got %v", braintree)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// ABOUTME: This file implements the diagnostic handlers for the MCP server.
+// ABOUTME: It defines tools that report on the server's use of the MBTA API.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// registerDiagnosticTools registers the diagnostic tools and handlers
+func (s *Server) registerDiagnosticTools() {
+	// Tool: GetAPIQuota - reports how much of the MBTA API quota is left
+	getAPIQuotaTool := mcp.Tool{
+		Name:        "get_api_quota",
+		Description: "Report MBTA API rate limit usage, client-side throttling, response cache statistics and per-method call metrics",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]any{},
+		},
+	}
+
+	// Register the quota tool with its handler, wrapped with middleware
+	s.mcpServer.AddTool(getAPIQuotaTool, s.wrapWithMiddleware(s.getAPIQuotaHandler))
+}
+
+// getAPIQuotaHandler handles requests for the API quota
+func (s *Server) getAPIQuotaHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("Received request for API quota: %s", request.Params.Name)
+
+	quota := s.limiter.Quota()
+
+	quotaData := map[string]interface{}{
+		"limit":              quota.Limit,
+		"remaining":          quota.Remaining,
+		"reported_by_api":    quota.Observed,
+		"available_tokens":   quota.AvailableTokens,
+		"throttled_requests": quota.ThrottledRequests,
+		"total_wait_seconds": math.Round(quota.TotalWait.Seconds()*10) / 10,
+	}
+
+	if quota.Limit > 0 {
+		quotaData["percent_used"] = math.Round(float64(quota.Limit-quota.Remaining) / float64(quota.Limit) * 100)
+	}
+
+	if !quota.ResetAt.IsZero() {
+		quotaData["reset_at"] = quota.ResetAt.Format(time.RFC3339)
+		quotaData["seconds_until_reset"] = max(0, int(math.Ceil(time.Until(quota.ResetAt).Seconds())))
+	}
+
+	if stats, ok := s.CacheStats(); ok {
+		quotaData["cache"] = map[string]interface{}{
+			"hits":          stats.Hits,
+			"revalidations": stats.Revalidations,
+			"misses":        stats.Misses,
+			"hit_rate":      math.Round(stats.HitRate()*1000) / 1000,
+		}
+	}
+
+	// Report per-method call counts and latency for the transit data backend
+	if s.metrics != nil {
+		calls := make(map[string]interface{})
+		for method, stats := range s.metrics.Snapshot() {
+			calls[method] = map[string]interface{}{
+				"calls":      stats.Calls,
+				"errors":     stats.Errors,
+				"average_ms": math.Round(float64(stats.AverageDuration().Microseconds())/100) / 10,
+				"max_ms":     math.Round(float64(stats.MaxDuration.Microseconds())/100) / 10,
+			}
+		}
+		quotaData["calls"] = calls
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(quotaData, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize quota data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,94 @@
+// ABOUTME: This file contains tests for the diagnostic MCP handlers.
+// ABOUTME: It verifies reporting of API quota, cache statistics and call metrics.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGetAPIQuotaHandler(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "1000")
+		w.Header().Set("X-Ratelimit-Remaining", "900")
+		w.Header().Set("X-Ratelimit-Reset", "4102444800")
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer apiServer.Close()
+
+	server, err := New(&config.Config{APIKey: "test-api-key", APIBaseURL: apiServer.URL, CacheSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.registerDiagnosticTools()
+
+	// Make the same API call twice so the second is served from the cache
+	for i := 0; i < 2; i++ {
+		if _, err := server.api.GetRoutes(context.Background()); err != nil {
+			t.Fatalf("GetRoutes returned error: %v", err)
+		}
+	}
+
+	request := mcp.CallToolRequest{
+		Params: struct {
+			Name      string    `json:"name"`
+			Arguments any       `json:"arguments,omitempty"`
+			Meta      *mcp.Meta `json:"_meta,omitempty"`
+		}{
+			Name:      "get_api_quota",
+			Arguments: map[string]any{},
+		},
+	}
+
+	result, err := server.getAPIQuotaHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Content is not TextContent, got: %T", result.Content[0])
+	}
+
+	var quota map[string]interface{}
+	if err := json.Unmarshal([]byte(textContent.Text), &quota); err != nil {
+		t.Fatalf("Failed to parse quota data: %v", err)
+	}
+
+	if quota["limit"] != float64(1000) || quota["remaining"] != float64(900) {
+		t.Errorf("Expected 900/1000 remaining, got %v/%v", quota["remaining"], quota["limit"])
+	}
+	if quota["reported_by_api"] != true {
+		t.Errorf("Expected quota to be reported by the API, got %v", quota["reported_by_api"])
+	}
+	if quota["percent_used"] != float64(10) {
+		t.Errorf("Expected 10 percent used, got %v", quota["percent_used"])
+	}
+	if _, ok := quota["reset_at"].(string); !ok {
+		t.Errorf("Expected reset_at to be reported, got %v", quota["reset_at"])
+	}
+
+	cache, ok := quota["cache"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected cache statistics, got %v", quota["cache"])
+	}
+	if cache["hits"] != float64(1) || cache["misses"] != float64(1) {
+		t.Errorf("Expected 1 hit and 1 miss, got %v", cache)
+	}
+
+	calls, ok := quota["calls"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected call metrics, got %v", quota["calls"])
+	}
+	routes, ok := calls["GetRoutes"].(map[string]interface{})
+	if !ok || routes["calls"] != float64(2) || routes["errors"] != float64(0) {
+		t.Errorf("Expected 2 GetRoutes calls without errors, got %v", calls["GetRoutes"])
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta"
@@ -25,14 +26,32 @@ func (s *Server) RegisterDefaultHandlers() {
 	// Set up vehicle tracking tools
 	s.registerVehicleTrackingTools()
 
+	// Set up departure board tools
+	s.registerDepartureBoardTools()
+
 	// Set up trip planning tools
 	s.registerTripPlanningTools()
 
+	// Set up alternative route tools
+	s.registerAlternativeRouteTools()
+
 	// Set up service alert tools
 	s.registerServiceAlertTools()
 
 	// Set up geographic query tools
 	s.registerGeographicQueryTools()
+
+	// Set up stop search tools
+	s.registerStopSearchTools()
+
+	// Set up station accessibility tools
+	s.registerStationAccessibilityTools()
+
+	// Set up diagnostic tools
+	s.registerDiagnosticTools()
+
+	// Set up live data resources
+	s.registerLiveResources()
 }
 
 // registerTransitInfoTools registers the basic transit information tools.
@@ -68,7 +87,7 @@ func (s *Server) registerTransitInfoTools() {
 			Properties: map[string]any{
 				"stop_id": map[string]any{
 					"type":        "string",
-					"description": "Filter by specific stop ID",
+					"description": "Filter by specific stop ID; a station is returned with its platforms and entrances",
 				},
 				"location_type": map[string]any{
 					"type":        "string",
@@ -98,7 +117,7 @@ func (s *Server) registerTransitInfoTools() {
 				},
 				"stop_id": map[string]any{
 					"type":        "string",
-					"description": "Filter schedules by stop ID",
+					"description": "Filter schedules by stop ID; a station ID includes its platforms",
 				},
 				"direction_id": map[string]any{
 					"type":        "string",
@@ -121,11 +140,8 @@ func (s *Server) registerTransitInfoTools() {
 func (s *Server) getRoutesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for routes: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract optional parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	routeType, hasRouteType := args["route_type"]
 	routeID, hasRouteID := args["route_id"]
 
@@ -144,7 +160,7 @@ func (s *Server) getRoutesHandler(ctx context.Context, request mcp.CallToolReque
 			return createErrorResponse(fmt.Sprintf("Invalid route_id parameter: %v", routeID)), nil
 		}
 
-		route, err := client.GetRoute(ctx, routeIDStr)
+		route, err := s.api.GetRoute(ctx, routeIDStr)
 		if err != nil {
 			return createErrorResponse(fmt.Sprintf("Failed to retrieve route %s: %v", routeIDStr, err)), nil
 		}
@@ -179,7 +195,7 @@ func (s *Server) getRoutesHandler(ctx context.Context, request mcp.CallToolReque
 	}
 
 	// Get all routes
-	routes, err := client.GetRoutes(ctx)
+	routes, err := s.api.GetRoutes(ctx)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve routes: %v", err)), nil
 	}
@@ -220,11 +236,8 @@ func (s *Server) getRoutesHandler(ctx context.Context, request mcp.CallToolReque
 func (s *Server) getStopsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for stops: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract optional parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	stopID, hasStopID := args["stop_id"]
 	locationType, hasLocationType := args["location_type"]
 	routeID, hasRouteID := args["route_id"]
@@ -247,7 +260,7 @@ func (s *Server) getStopsHandler(ctx context.Context, request mcp.CallToolReques
 			return createErrorResponse(fmt.Sprintf("Invalid stop_id parameter: %v", stopID)), nil
 		}
 
-		stop, err := client.GetStop(ctx, stopIDStr)
+		stop, err := s.api.GetStop(ctx, stopIDStr)
 		if err != nil {
 			return createErrorResponse(fmt.Sprintf("Failed to retrieve stop %s: %v", stopIDStr, err)), nil
 		}
@@ -277,12 +290,22 @@ func (s *Server) getStopsHandler(ctx context.Context, request mcp.CallToolReques
 			}
 		}
 
+		// A station is described along with its platforms and entrances
+		if stop.IsStation() {
+			hierarchy, err := s.api.GetStationHierarchy(ctx, stop.ID)
+			if err != nil {
+				log.Printf("Failed to resolve platforms of station %s: %v", stop.ID, err)
+			} else {
+				return formatStationResponse(hierarchy)
+			}
+		}
+
 		// For now, return a text response (in future, this would be proper structured data)
 		return formatStopResponse([]*models.Stop{stop})
 	}
 
 	// Get all stops
-	stops, err := client.GetStops(ctx)
+	stops, err := s.api.GetStops(ctx)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve stops: %v", err)), nil
 	}
@@ -316,7 +339,7 @@ func (s *Server) getStopsHandler(ctx context.Context, request mcp.CallToolReques
 			return createErrorResponse(fmt.Sprintf("Invalid route_id parameter: %v", routeID)), nil
 		}
 
-		routeStopIDs, err := client.GetStopsForRoute(ctx, routeIDStr)
+		routeStopIDs, err := s.api.GetStopsForRoute(ctx, routeIDStr)
 		if err != nil {
 			return createErrorResponse(fmt.Sprintf("Failed to retrieve stops for route %s: %v", routeIDStr, err)), nil
 		}
@@ -352,11 +375,8 @@ func (s *Server) getStopsHandler(ctx context.Context, request mcp.CallToolReques
 func (s *Server) getSchedulesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for schedules: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract optional parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	routeID, hasRouteID := args["route_id"]
 	stopID, hasStopID := args["stop_id"]
 	directionID, hasDirectionID := args["direction_id"]
@@ -392,7 +412,7 @@ func (s *Server) getSchedulesHandler(ctx context.Context, request mcp.CallToolRe
 		if !ok {
 			return createErrorResponse(fmt.Sprintf("Invalid stop_id parameter: %v", stopID)), nil
 		}
-		params["filter[stop]"] = stopIDStr
+		params["filter[stop]"] = strings.Join(s.expandStopID(ctx, stopIDStr), ",")
 	}
 
 	if hasDirectionID {
@@ -416,12 +436,12 @@ func (s *Server) getSchedulesHandler(ctx context.Context, request mcp.CallToolRe
 
 		params["filter[date]"] = dateStr
 	} else {
-		// Default to current date if not specified
-		params["filter[date]"] = time.Now().Format("2006-01-02")
+		// Default to the current service day if not specified
+		params["filter[date]"] = mbta.ServiceDayOf(time.Now()).String()
 	}
 
 	// Get schedules
-	schedules, included, err := client.GetSchedules(ctx, params)
+	schedules, included, err := s.api.GetSchedules(ctx, params)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve schedules: %v", err)), nil
 	}
@@ -471,32 +491,49 @@ func formatStopResponse(stops []*models.Stop) (*mcp.CallToolResult, error) {
 	// Convert the stops to a structured format
 	stopsData := make([]map[string]interface{}, 0, len(stops))
 	for _, stop := range stops {
-		stopMap := map[string]interface{}{
-			"id":                   stop.ID,
-			"name":                 stop.Attributes.Name,
-			"description":          stop.Attributes.Description,
-			"location_type":        stop.Attributes.LocationType,
-			"location_description": models.GetLocationTypeDescription(stop.Attributes.LocationType),
-			"municipality":         stop.Attributes.Municipality,
-			"latitude":             stop.Attributes.Latitude,
-			"longitude":            stop.Attributes.Longitude,
-			"wheelchair_boarding":  stop.Attributes.WheelchairBoarding,
-			"is_accessible":        stop.IsAccessible(),
-		}
+		stopsData = append(stopsData, formatStop(stop))
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(stopsData, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize stop data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatStationResponse converts a station and the stops within it to a
+// proper MCP response
+func formatStationResponse(hierarchy *models.StationHierarchy) (*mcp.CallToolResult, error) {
+	stationMap := formatStop(&hierarchy.Station)
 
-		// Add optional fields if they exist
-		if stop.Attributes.PlatformCode != "" {
-			stopMap["platform_code"] = stop.Attributes.PlatformCode
+	children := map[string][]models.Stop{
+		"platforms":      hierarchy.Platforms,
+		"entrances":      hierarchy.Entrances,
+		"boarding_areas": hierarchy.BoardingAreas,
+	}
+	for key, stops := range children {
+		if len(stops) == 0 {
+			continue
 		}
-		if stop.Attributes.PlatformName != "" {
-			stopMap["platform_name"] = stop.Attributes.PlatformName
+		stopsData := make([]map[string]interface{}, 0, len(stops))
+		for i := range stops {
+			stopsData = append(stopsData, formatStop(&stops[i]))
 		}
-
-		stopsData = append(stopsData, stopMap)
+		stationMap[key] = stopsData
 	}
 
-	// Create JSON string response
-	jsonBytes, err := json.MarshalIndent(stopsData, "", "  ")
+	// Create JSON string response, as a list like other stop responses
+	jsonBytes, err := json.MarshalIndent([]map[string]interface{}{stationMap}, "", "  ")
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to serialize stop data: %v", err)), nil
 	}
@@ -512,8 +549,52 @@ func formatStopResponse(stops []*models.Stop) (*mcp.CallToolResult, error) {
 	}, nil
 }
 
+// formatStop converts a stop to a simplified format
+func formatStop(stop *models.Stop) map[string]interface{} {
+	stopMap := map[string]interface{}{
+		"id":                   stop.ID,
+		"name":                 stop.Attributes.Name,
+		"description":          stop.Attributes.Description,
+		"location_type":        stop.Attributes.LocationType,
+		"location_description": models.GetLocationTypeDescription(stop.Attributes.LocationType),
+		"municipality":         stop.Attributes.Municipality,
+		"latitude":             stop.Attributes.Latitude,
+		"longitude":            stop.Attributes.Longitude,
+		"wheelchair_boarding":  stop.Attributes.WheelchairBoarding,
+		"is_accessible":        stop.IsAccessible(),
+	}
+
+	// Add optional fields if they exist
+	if stop.Attributes.PlatformCode != "" {
+		stopMap["platform_code"] = stop.Attributes.PlatformCode
+	}
+	if stop.Attributes.PlatformName != "" {
+		stopMap["platform_name"] = stop.Attributes.PlatformName
+	}
+	if parentID := stop.GetParentStationID(); parentID != "" {
+		stopMap["parent_station"] = parentID
+	}
+
+	return stopMap
+}
+
+// expandStopID returns a stop ID along with, for a station, the IDs of its
+// platforms. If the station can't be resolved the ID is used as given, so a
+// failed lookup never stops the request it's part of.
+func (s *Server) expandStopID(ctx context.Context, stopID string) []string {
+	stopIDs, err := mbta.ExpandStopIDs(ctx, s.api, stopID)
+	if err != nil {
+		log.Printf("Using stop %s without its platforms: %v", stopID, err)
+		return []string{stopID}
+	}
+	return stopIDs
+}
+
 // formatScheduleResponse converts schedule data to a proper MCP response
 func formatScheduleResponse(schedules []models.Schedule, included []models.Included) (*mcp.CallToolResult, error) {
+	// Index side-loaded resources so schedules can reference them
+	includedIndex := models.NewIncludedIndex(included)
+
 	// Convert the schedules to a structured format
 	schedulesData := make([]map[string]interface{}, 0, len(schedules))
 	for _, schedule := range schedules {
@@ -535,23 +616,25 @@ func formatScheduleResponse(schedules []models.Schedule, included []models.Inclu
 		}
 
 		// Extract relationship IDs
-		if routeData, ok := schedule.Relationships["route"]; ok {
-			if routeMap, ok := routeData.(map[string]interface{}); ok {
-				if dataMap, ok := routeMap["data"].(map[string]interface{}); ok {
-					if routeID, ok := dataMap["id"].(string); ok {
-						scheduleMap["route_id"] = routeID
-					}
-				}
+		if routeID := schedule.GetRouteID(); routeID != "" {
+			scheduleMap["route_id"] = routeID
+		}
+
+		if stopID := schedule.GetStopID(); stopID != "" {
+			scheduleMap["stop_id"] = stopID
+
+			// Add the stop name when the stop was side-loaded
+			if stop, ok := includedIndex.Stop(stopID); ok {
+				scheduleMap["stop_name"] = stop.Attributes.Name
 			}
 		}
 
-		if stopData, ok := schedule.Relationships["stop"]; ok {
-			if stopMap, ok := stopData.(map[string]interface{}); ok {
-				if dataMap, ok := stopMap["data"].(map[string]interface{}); ok {
-					if stopID, ok := dataMap["id"].(string); ok {
-						scheduleMap["stop_id"] = stopID
-					}
-				}
+		if tripID := schedule.GetTripID(); tripID != "" {
+			scheduleMap["trip_id"] = tripID
+
+			// Add the headsign when the trip was side-loaded
+			if trip, ok := includedIndex.Trip(tripID); ok {
+				scheduleMap["trip_headsign"] = trip.Attributes.Headsign
 			}
 		}
 
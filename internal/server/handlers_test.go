@@ -6,12 +6,15 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/mock"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -64,11 +67,9 @@ func TestGetRoutesHandler(t *testing.T) {
 		// Create a request for the routes handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_routes",
 				Arguments: map[string]any{},
@@ -109,11 +110,9 @@ func TestGetRoutesHandler(t *testing.T) {
 		// Create a request with route type filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_routes",
 				Arguments: map[string]any{
@@ -140,11 +139,9 @@ func TestGetRoutesHandler(t *testing.T) {
 		// Create a request with route ID filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_routes",
 				Arguments: map[string]any{
@@ -210,11 +207,9 @@ func TestGetRoutesHandler(t *testing.T) {
 		// Create a request for the routes handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_routes",
 				Arguments: map[string]any{},
@@ -299,11 +294,9 @@ func TestGetStopsHandler(t *testing.T) {
 		// Create a request for the stops handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_stops",
 				Arguments: map[string]any{},
@@ -344,11 +337,9 @@ func TestGetStopsHandler(t *testing.T) {
 		// Create a request with location type filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_stops",
 				Arguments: map[string]any{
@@ -375,11 +366,9 @@ func TestGetStopsHandler(t *testing.T) {
 		// Create a request with stop ID filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_stops",
 				Arguments: map[string]any{
@@ -406,11 +395,9 @@ func TestGetStopsHandler(t *testing.T) {
 		// Create a request with route ID filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_stops",
 				Arguments: map[string]any{
@@ -486,11 +473,9 @@ func TestGetSchedulesHandler(t *testing.T) {
 		// Create a request for the schedules handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_schedules",
 				Arguments: map[string]any{},
@@ -531,11 +516,9 @@ func TestGetSchedulesHandler(t *testing.T) {
 		// Create a request with route filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_schedules",
 				Arguments: map[string]any{
@@ -562,11 +545,9 @@ func TestGetSchedulesHandler(t *testing.T) {
 		// Create a request with stop filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_schedules",
 				Arguments: map[string]any{
@@ -593,11 +574,9 @@ func TestGetSchedulesHandler(t *testing.T) {
 		// Create a request with date filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_schedules",
 				Arguments: map[string]any{
@@ -649,11 +628,9 @@ func TestGetSchedulesHandler(t *testing.T) {
 		// Create a request with invalid date filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_schedules",
 				Arguments: map[string]any{
@@ -694,3 +671,147 @@ func TestErrorResponse(t *testing.T) {
 }
 
 // This uses the actual createErrorResponse function from handlers.go
+
+// stationStops is a stop backend that knows Park Street's Red Line platforms
+type stationStops struct {
+	mbta.StopDataSource
+	hierarchy *models.StationHierarchy
+}
+
+// parkStreetStops returns a stop backend for Park Street
+func parkStreetStops() *stationStops {
+	station := models.Stop{ID: "place-pktrm", Type: "stop", Attributes: models.StopAttributes{
+		Name:         "Park Street",
+		LocationType: models.LocationTypeStation,
+	}}
+	platform := func(id, name string) models.Stop {
+		return models.Stop{
+			ID:            id,
+			Type:          "stop",
+			Attributes:    models.StopAttributes{Name: "Park Street", PlatformName: name, LocationType: models.LocationTypePlatform},
+			Relationships: map[string]interface{}{"parent_station": map[string]interface{}{"data": map[string]interface{}{"id": "place-pktrm", "type": "stop"}}},
+		}
+	}
+	entrance := models.Stop{ID: "door-pktrm-tremont", Type: "stop", Attributes: models.StopAttributes{
+		Name:         "Park Street - Tremont Street",
+		LocationType: models.LocationTypeEntrance,
+	}}
+
+	return &stationStops{hierarchy: models.NewStationHierarchy(station, []models.Stop{
+		platform("70075", "Ashmont/Braintree"),
+		platform("70076", "Alewife"),
+		entrance,
+	})}
+}
+
+func (f *stationStops) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	if f.hierarchy.Station.ID == stopID {
+		return &f.hierarchy.Station, nil
+	}
+	for i := range f.hierarchy.Platforms {
+		if f.hierarchy.Platforms[i].ID == stopID {
+			return &f.hierarchy.Platforms[i], nil
+		}
+	}
+	return nil, fmt.Errorf("stop %s %w", stopID, mbta.ErrNotFound)
+}
+
+func (f *stationStops) GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error) {
+	if !f.hierarchy.Contains(stopID) {
+		return nil, fmt.Errorf("stop %s %w", stopID, mbta.ErrNotFound)
+	}
+	return f.hierarchy, nil
+}
+
+// stationScheduleAPI records the parameters of schedule and alert queries
+type stationScheduleAPI struct {
+	mbta.CompositeAPI
+	params map[string]string
+}
+
+func (a *stationScheduleAPI) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	a.params = params
+	return nil, nil, nil
+}
+
+func (a *stationScheduleAPI) GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+	a.params = params
+	return nil, nil
+}
+
+func TestStationExpansion(t *testing.T) {
+	api := &stationScheduleAPI{CompositeAPI: mbta.CompositeAPI{StopDataSource: parkStreetStops()}}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(api))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) string {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Handler returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Expected success, got %v", result.Content)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("Stations list their platforms and entrances", func(t *testing.T) {
+		var stops []map[string]interface{}
+		if err := json.Unmarshal([]byte(call(server.getStopsHandler, map[string]any{"stop_id": "place-pktrm"})), &stops); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if len(stops) != 1 {
+			t.Fatalf("Expected 1 station, got %d", len(stops))
+		}
+		platforms, _ := stops[0]["platforms"].([]interface{})
+		entrances, _ := stops[0]["entrances"].([]interface{})
+		if len(platforms) != 2 || len(entrances) != 1 {
+			t.Fatalf("Expected 2 platforms and 1 entrance, got %v", stops[0])
+		}
+		if parent := platforms[0].(map[string]interface{})["parent_station"]; parent != "place-pktrm" {
+			t.Errorf("Expected platforms to name their station, got %v", parent)
+		}
+	})
+
+	t.Run("Platforms name their station", func(t *testing.T) {
+		text := call(server.getStopsHandler, map[string]any{"stop_id": "70076"})
+		if !strings.Contains(text, `"parent_station": "place-pktrm"`) || strings.Contains(text, "platforms") {
+			t.Errorf("Expected the platform alone with its parent station, got %s", text)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		handler  func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		stopID   string
+		expected string
+	}{
+		{name: "Schedules for a station", handler: server.getSchedulesHandler, stopID: "place-pktrm", expected: "place-pktrm,70075,70076"},
+		{name: "Schedules for a platform", handler: server.getSchedulesHandler, stopID: "70075", expected: "70075"},
+		{name: "Alerts for a station", handler: server.getAlertsHandler, stopID: "place-pktrm", expected: "place-pktrm,70075,70076"},
+		{name: "Unknown stops are used as given", handler: server.getSchedulesHandler, stopID: "place-nowhere", expected: "place-nowhere"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call(tt.handler, map[string]any{"stop_id": tt.stopID})
+			if api.params["filter[stop]"] != tt.expected {
+				t.Errorf("Expected filter[stop] %s, got %s", tt.expected, api.params["filter[stop]"])
+			}
+		})
+	}
+
+	// Schedules default to the service day running now in Boston
+	call(server.getSchedulesHandler, map[string]any{"stop_id": "70075"})
+	if today := mbta.ServiceDayOf(time.Now()).String(); api.params["filter[date]"] != today {
+		t.Errorf("Expected filter[date] %s, got %s", today, api.params["filter[date]"])
+	}
+}
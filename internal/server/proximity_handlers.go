@@ -47,6 +47,10 @@ func (s *Server) registerGeographicQueryTools() {
 					"type":        "boolean",
 					"description": "If true, only return wheelchair accessible stations",
 				},
+				"route_type": map[string]any{
+					"type":        "number",
+					"description": "Only return stations served by this route type (0: light rail, 1: subway, 2: commuter rail, 3: bus, 4: ferry)",
+				},
 			},
 			Required: []string{"latitude", "longitude"},
 		},
@@ -60,11 +64,8 @@ func (s *Server) registerGeographicQueryTools() {
 func (s *Server) findNearbyStationsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for nearby stations: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract required parameters
-	args := request.Params.Arguments
+	args := request.GetArguments()
 
 	// Get latitude
 	latitude, ok := args["latitude"].(float64)
@@ -115,25 +116,23 @@ func (s *Server) findNearbyStationsHandler(ctx context.Context, request mcp.Call
 		wheelchairAccessible = wheelchairAccessibleVal
 	}
 
+	// Filter in the search so the closest matches fill max_results
+	filter := mbta.StopFilter{WheelchairAccessible: wheelchairAccessible}
+	if onlyStations {
+		filter.LocationTypes = []int{models.LocationTypeStation}
+	}
+	if routeTypeVal, ok := args["route_type"].(float64); ok {
+		filter.RouteTypes = []int{int(routeTypeVal)}
+	}
+
 	log.Printf("Searching for stations near (%f, %f) within %f km", latitude, longitude, radius)
 
 	// Find nearby stations
-	nearbyStations, err := client.FindNearbyStations(ctx, latitude, longitude, radius, maxResults, onlyStations)
+	nearbyStations, err := s.api.FindNearbyStops(ctx, latitude, longitude, radius, maxResults, filter)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to find nearby stations: %v", err)), nil
 	}
 
-	// Filter for wheelchair accessibility if requested
-	if wheelchairAccessible && len(nearbyStations) > 0 {
-		accessibleStations := make([]models.NearbyStation, 0, len(nearbyStations))
-		for _, station := range nearbyStations {
-			if station.Stop.IsAccessible() {
-				accessibleStations = append(accessibleStations, station)
-			}
-		}
-		nearbyStations = accessibleStations
-	}
-
 	// If no stations are found, inform the user
 	if len(nearbyStations) == 0 {
 		return &mcp.CallToolResult{
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
@@ -34,11 +35,9 @@ func TestFindNearbyStationsHandler(t *testing.T) {
 		// Create a request with missing parameters
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "find_nearby_stations",
 				Arguments: map[string]any{
@@ -81,11 +80,9 @@ func TestFindNearbyStationsHandler(t *testing.T) {
 		// Create a request with incorrect parameter types
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "find_nearby_stations",
 				Arguments: map[string]any{
@@ -217,3 +214,95 @@ func TestFindNearbyStationsResponse(t *testing.T) {
 		}
 	}
 }
+
+func TestFindNearbyStationsHandlerUsesStopIndex(t *testing.T) {
+	cfg := &config.Config{
+		Timeout:          30,
+		APIBaseURL:       "https://api-test.mbta.com",
+		StopIndexRefresh: time.Hour,
+	}
+
+	server, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if server.stopIndex == nil {
+		t.Fatal("Expected server to create a stop index")
+	}
+
+	station := func(id string, lat, lon float64, wheelchair int) models.Stop {
+		return models.Stop{
+			ID:   id,
+			Type: "stop",
+			Attributes: models.StopAttributes{
+				Name:               id,
+				Latitude:           lat,
+				Longitude:          lon,
+				LocationType:       models.LocationTypeStation,
+				WheelchairBoarding: wheelchair,
+			},
+		}
+	}
+	server.stopIndex.Build([]models.Stop{
+		station("place-dwnxg", 42.355518, -71.060225, models.WheelchairBoardingAccessible),
+		station("place-pktrm", 42.35639457, -71.0624242, models.WheelchairBoardingAccessible),
+		station("place-boyls", 42.35302, -71.06459, models.WheelchairBoardingInaccessible),
+	}, map[string][]int{
+		"place-dwnxg": {models.RouteTypeSubway},
+		"place-pktrm": {models.RouteTypeLightRail, models.RouteTypeSubway},
+		"place-boyls": {models.RouteTypeLightRail},
+	})
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		expected []string
+	}{
+		{
+			name:     "Route type",
+			args:     map[string]any{"route_type": float64(models.RouteTypeLightRail)},
+			expected: []string{"place-pktrm", "place-boyls"},
+		},
+		{
+			name:     "Wheelchair accessible fills max results",
+			args:     map[string]any{"wheelchair_accessible": true, "max_results": float64(2)},
+			expected: []string{"place-dwnxg", "place-pktrm"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{"latitude": 42.355, "longitude": -71.060}
+			for key, value := range tt.args {
+				args[key] = value
+			}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "find_nearby_stations"
+			request.Params.Arguments = args
+
+			response, err := server.findNearbyStationsHandler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			textContent, ok := response.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("Expected TextContent, got %T", response.Content[0])
+			}
+
+			var stations []map[string]interface{}
+			if err := json.Unmarshal([]byte(textContent.Text), &stations); err != nil {
+				t.Fatalf("Failed to parse response %q: %v", textContent.Text, err)
+			}
+			if len(stations) != len(tt.expected) {
+				t.Fatalf("Expected %d stations, got %d", len(tt.expected), len(stations))
+			}
+			for i, station := range stations {
+				if station["id"] != tt.expected[i] {
+					t.Errorf("Expected %s at %d, got %v", tt.expected[i], i, station["id"])
+				}
+			}
+		})
+	}
+}
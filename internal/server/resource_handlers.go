@@ -0,0 +1,438 @@
+// ABOUTME: This file implements MCP resources for live MBTA data.
+// ABOUTME: It serves resource templates and pushes update notifications to subscribed sessions.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Live resource URIs and templates
+const (
+	stopPredictionsTemplate = "mbta://stops/{id}/predictions"
+	routeVehiclesTemplate   = "mbta://routes/{id}/vehicles"
+	activeAlertsURI         = "mbta://alerts/active"
+)
+
+// stdioSessionID is the fixed session ID mcp-go assigns to the stdio client
+const stdioSessionID = "stdio"
+
+// registerLiveResources registers the live data resources and templates.
+func (s *Server) registerLiveResources() {
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			stopPredictionsTemplate,
+			"Stop predictions",
+			mcp.WithTemplateDescription("Real-time arrival and departure predictions for a stop"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readLiveResourceHandler,
+	)
+
+	s.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			routeVehiclesTemplate,
+			"Route vehicles",
+			mcp.WithTemplateDescription("Real-time locations of vehicles operating on a route"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readLiveResourceHandler,
+	)
+
+	s.mcpServer.AddResource(
+		mcp.NewResource(
+			activeAlertsURI,
+			"Active alerts",
+			mcp.WithResourceDescription("Service alerts that are currently in effect"),
+			mcp.WithMIMEType("application/json"),
+		),
+		s.readLiveResourceHandler,
+	)
+}
+
+// readLiveResourceHandler handles resources/read for the live data resources.
+func (s *Server) readLiveResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	log.Printf("Received request for resource %s", request.Params.URI)
+
+	text, _, err := s.fetchLiveResource(ctx, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     text,
+		},
+	}, nil
+}
+
+// parseLiveResourceURI splits a live resource URI into its kind and ID.
+// The kind is one of "predictions", "vehicles" or "alerts".
+func parseLiveResourceURI(uri string) (string, string, bool) {
+	if uri == activeAlertsURI {
+		return "alerts", "", true
+	}
+
+	path, ok := strings.CutPrefix(uri, "mbta://")
+	if !ok {
+		return "", "", false
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || parts[1] == "" {
+		return "", "", false
+	}
+
+	id, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return "", "", false
+	}
+
+	switch {
+	case parts[0] == "stops" && parts[2] == "predictions":
+		return "predictions", id, true
+	case parts[0] == "routes" && parts[2] == "vehicles":
+		return "vehicles", id, true
+	}
+
+	return "", "", false
+}
+
+// fetchLiveResource loads the current content of a live resource, along with a
+// fingerprint of the underlying API data that is used to detect changes.
+func (s *Server) fetchLiveResource(ctx context.Context, uri string) (string, string, error) {
+	kind, id, ok := parseLiveResourceURI(uri)
+	if !ok {
+		return "", "", fmt.Errorf("unknown resource URI: %s", uri)
+	}
+
+	var data any
+	var result *mcp.CallToolResult
+	switch kind {
+	case "predictions":
+		stopIDs := strings.Join(s.expandStopID(ctx, id), ",")
+		predictions, err := s.api.GetPredictions(ctx, map[string]string{"filter[stop]": stopIDs})
+		if err != nil {
+			return "", "", fmt.Errorf("error fetching predictions for stop %s: %w", id, err)
+		}
+		data = predictions
+		result, _ = formatPredictionsResponse(predictions)
+	case "vehicles":
+		vehicles, err := s.api.GetVehicles(ctx, map[string]string{"filter[route]": id})
+		if err != nil {
+			return "", "", fmt.Errorf("error fetching vehicles for route %s: %w", id, err)
+		}
+		data = vehicles
+		result, _ = formatVehiclesResponse(vehicles)
+	case "alerts":
+		alerts, err := mbta.ActiveAlerts(ctx, s.api)
+		if err != nil {
+			return "", "", fmt.Errorf("error fetching active alerts: %w", err)
+		}
+		data = alerts
+		result, _ = formatAlertsResponse(alerts)
+	}
+
+	text, err := toolResultText(result)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Fingerprint the raw data rather than the formatted text, which includes
+	// countdowns that change on every read
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", "", fmt.Errorf("error fingerprinting %s: %w", uri, err)
+	}
+	sum := sha256.Sum256(raw)
+
+	return text, hex.EncodeToString(sum[:]), nil
+}
+
+// toolResultText extracts the text of a formatted tool result.
+func toolResultText(result *mcp.CallToolResult) (string, error) {
+	if result == nil || len(result.Content) == 0 {
+		return "", fmt.Errorf("empty resource content")
+	}
+
+	content, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return "", fmt.Errorf("unexpected resource content type %T", result.Content[0])
+	}
+	if result.IsError {
+		return "", fmt.Errorf("%s", content.Text)
+	}
+
+	return content.Text, nil
+}
+
+// resourceSubscriptions tracks which sessions are subscribed to which resources
+// and the last observed fingerprint of each resource.
+type resourceSubscriptions struct {
+	mu           sync.Mutex
+	sessions     map[string]map[string]bool // URI -> session IDs
+	fingerprints map[string]string          // URI -> last fingerprint
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// newResourceSubscriptions creates an empty subscription registry.
+func newResourceSubscriptions() *resourceSubscriptions {
+	return &resourceSubscriptions{
+		sessions:     make(map[string]map[string]bool),
+		fingerprints: make(map[string]string),
+		done:         make(chan struct{}),
+	}
+}
+
+// subscribe records that a session wants updates for a resource.
+func (r *resourceSubscriptions) subscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sessions[uri] == nil {
+		r.sessions[uri] = make(map[string]bool)
+	}
+	r.sessions[uri][sessionID] = true
+}
+
+// unsubscribe removes a session's subscription to a resource.
+func (r *resourceSubscriptions) unsubscribe(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions[uri], sessionID)
+	if len(r.sessions[uri]) == 0 {
+		delete(r.sessions, uri)
+		delete(r.fingerprints, uri)
+	}
+}
+
+// removeSession drops every subscription held by a session.
+func (r *resourceSubscriptions) removeSession(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for uri, sessions := range r.sessions {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(r.sessions, uri)
+			delete(r.fingerprints, uri)
+		}
+	}
+}
+
+// uris returns the subscribed resource URIs in a stable order.
+func (r *resourceSubscriptions) uris() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uris := make([]string, 0, len(r.sessions))
+	for uri := range r.sessions {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// subscribers returns the sessions subscribed to a resource.
+func (r *resourceSubscriptions) subscribers(uri string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sessionIDs := make([]string, 0, len(r.sessions[uri]))
+	for sessionID := range r.sessions[uri] {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Strings(sessionIDs)
+	return sessionIDs
+}
+
+// changed records the latest fingerprint of a resource and reports whether it
+// differs from a previous observation. The first observation is a baseline.
+func (r *resourceSubscriptions) changed(uri, fingerprint string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, subscribed := r.sessions[uri]; !subscribed {
+		return false
+	}
+
+	previous, seen := r.fingerprints[uri]
+	r.fingerprints[uri] = fingerprint
+	return seen && previous != fingerprint
+}
+
+// close stops the subscription poller. It is safe to call more than once.
+func (r *resourceSubscriptions) close() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
+
+// pollSubscriptions refreshes subscribed resources until the server shuts down.
+func (s *Server) pollSubscriptions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.subscriptions.done:
+			return
+		case <-ticker.C:
+			s.refreshSubscriptions(context.Background())
+		}
+	}
+}
+
+// refreshSubscriptions fetches every subscribed resource and notifies its
+// subscribers when the underlying data has changed.
+func (s *Server) refreshSubscriptions(ctx context.Context) {
+	for _, uri := range s.subscriptions.uris() {
+		_, fingerprint, err := s.fetchLiveResource(ctx, uri)
+		if err != nil {
+			log.Printf("Error refreshing subscribed resource %s: %v", uri, err)
+			continue
+		}
+
+		if !s.subscriptions.changed(uri, fingerprint) {
+			continue
+		}
+
+		for _, sessionID := range s.subscriptions.subscribers(uri) {
+			err := s.mcpServer.SendNotificationToSpecificClient(
+				sessionID,
+				string(mcp.MethodNotificationResourceUpdated),
+				map[string]any{"uri": uri},
+			)
+			if err != nil {
+				log.Printf("Error notifying session %s about %s: %v", sessionID, uri, err)
+			}
+		}
+	}
+}
+
+// interceptSubscription handles resources/subscribe and resources/unsubscribe
+// requests, which mcp-go advertises but does not route. Known resources are
+// recorded and the request is rewritten as a ping so the client receives the
+// empty result the protocol expects; unknown resources are rewritten as a read
+// so the client receives the usual resource-not-found error. Other messages
+// pass through unchanged.
+func (s *Server) interceptSubscription(sessionID string, message []byte) []byte {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || len(request.ID) == 0 {
+		return message
+	}
+
+	switch request.Method {
+	case "resources/subscribe", "resources/unsubscribe":
+	default:
+		return message
+	}
+
+	rewritten := map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      request.ID,
+		"method":  string(mcp.MethodPing),
+	}
+
+	if _, _, ok := parseLiveResourceURI(request.Params.URI); !ok {
+		rewritten["method"] = string(mcp.MethodResourcesRead)
+		rewritten["params"] = map[string]any{"uri": request.Params.URI}
+	} else if request.Method == "resources/subscribe" {
+		log.Printf("Session %s subscribed to %s", sessionID, request.Params.URI)
+		s.subscriptions.subscribe(sessionID, request.Params.URI)
+	} else {
+		log.Printf("Session %s unsubscribed from %s", sessionID, request.Params.URI)
+		s.subscriptions.unsubscribe(sessionID, request.Params.URI)
+	}
+
+	rewrittenBytes, err := json.Marshal(rewritten)
+	if err != nil {
+		return message
+	}
+	return rewrittenBytes
+}
+
+// subscriptionMiddleware intercepts subscription requests posted to an HTTP
+// transport, identifying the session with the given function.
+func (s *Server) subscriptionMiddleware(next http.Handler, sessionID func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			_ = r.Body.Close()
+			if err != nil {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+
+			body = s.interceptSubscription(sessionID(r), body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// subscriptionReader intercepts subscription requests read from the stdio
+// transport, which carries one JSON-RPC message per line.
+type subscriptionReader struct {
+	reader    *bufio.Reader
+	intercept func([]byte) []byte
+	pending   []byte
+}
+
+// newSubscriptionReader wraps the stdio input for the given server.
+func newSubscriptionReader(s *Server, input io.Reader) *subscriptionReader {
+	return &subscriptionReader{
+		reader: bufio.NewReader(input),
+		intercept: func(message []byte) []byte {
+			return s.interceptSubscription(stdioSessionID, message)
+		},
+	}
+}
+
+// Read implements io.Reader, rewriting input a line at a time.
+func (r *subscriptionReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		line, err := r.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			message := r.intercept(bytes.TrimRight(line, "\r\n"))
+			r.pending = append(message, '\n')
+		}
+		if err != nil {
+			if len(r.pending) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
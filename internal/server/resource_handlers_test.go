@@ -0,0 +1,261 @@
+// ABOUTME: This file contains tests for the live data MCP resources.
+// ABOUTME: It verifies resource reads, subscription handling, and update notifications.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// testSession is a minimal client session that captures notifications
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return s.id }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func TestParseLiveResourceURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		kind string
+		id   string
+		ok   bool
+	}{
+		{"mbta://stops/place-pktrm/predictions", "predictions", "place-pktrm", true},
+		{"mbta://routes/Green-B/vehicles", "vehicles", "Green-B", true},
+		{"mbta://routes/Red%20Line/vehicles", "vehicles", "Red Line", true},
+		{"mbta://alerts/active", "alerts", "", true},
+		{"mbta://stops//predictions", "", "", false},
+		{"mbta://stops/place-pktrm/vehicles", "", "", false},
+		{"https://stops/place-pktrm/predictions", "", "", false},
+	}
+
+	for _, tt := range tests {
+		kind, id, ok := parseLiveResourceURI(tt.uri)
+		if kind != tt.kind || id != tt.id || ok != tt.ok {
+			t.Errorf("parseLiveResourceURI(%q) = (%q, %q, %v), expected (%q, %q, %v)",
+				tt.uri, kind, id, ok, tt.kind, tt.id, tt.ok)
+		}
+	}
+}
+
+func TestReadLiveResource(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+
+		// The station is resolved to its platforms first
+		if r.URL.Path == "/stops/place-pktrm" {
+			_, _ = w.Write([]byte(`{
+				"data": {"id": "place-pktrm", "type": "stop", "attributes": {"location_type": 1},
+					"relationships": {"child_stops": {"data": [{"id": "70075", "type": "stop"}]}}},
+				"included": [{"id": "70075", "type": "stop", "attributes": {"location_type": 0},
+					"relationships": {"parent_station": {"data": {"id": "place-pktrm", "type": "stop"}}}}]
+			}`))
+			return
+		}
+
+		if r.URL.Path != "/predictions" {
+			t.Errorf("Expected URL path '/predictions', got '%s'", r.URL.Path)
+		}
+		if r.URL.Query().Get("filter[stop]") != "place-pktrm,70075" {
+			t.Errorf("Expected filter[stop]=place-pktrm,70075, got '%s'", r.URL.Query().Get("filter[stop]"))
+		}
+		_, _ = w.Write([]byte(`{"data": [{"id": "prediction-1", "type": "prediction", "attributes": {"status": "Approaching"}}]}`))
+	}))
+	defer apiServer.Close()
+
+	server, err := New(&config.Config{APIKey: "test-api-key", APIBaseURL: apiServer.URL})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.registerLiveResources()
+
+	response := server.mcpServer.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": "mbta://stops/place-pktrm/predictions"}}`,
+	))
+
+	raw, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+
+	var result struct {
+		Result struct {
+			Contents []mcp.TextResourceContents `json:"contents"`
+		} `json:"result"`
+		Error any `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if result.Error != nil {
+		t.Fatalf("Expected no error, got %v", result.Error)
+	}
+	if len(result.Result.Contents) != 1 {
+		t.Fatalf("Expected 1 content item, got %d", len(result.Result.Contents))
+	}
+	content := result.Result.Contents[0]
+	if content.URI != "mbta://stops/place-pktrm/predictions" || content.MIMEType != "application/json" {
+		t.Errorf("Unexpected content metadata: %+v", content)
+	}
+	if !strings.Contains(content.Text, "prediction-1") {
+		t.Errorf("Expected contents to include prediction-1, got %s", content.Text)
+	}
+}
+
+func TestInterceptSubscription(t *testing.T) {
+	server, err := New(&config.Config{APIKey: "test-api-key", APIBaseURL: "http://127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.registerLiveResources()
+
+	t.Run("Subscribe is recorded and answered", func(t *testing.T) {
+		message := server.interceptSubscription("session-1",
+			[]byte(`{"jsonrpc": "2.0", "id": 7, "method": "resources/subscribe", "params": {"uri": "mbta://alerts/active"}}`))
+
+		if subscribers := server.subscriptions.subscribers(activeAlertsURI); len(subscribers) != 1 || subscribers[0] != "session-1" {
+			t.Errorf("Expected session-1 to be subscribed, got %v", subscribers)
+		}
+
+		response, err := json.Marshal(server.mcpServer.HandleMessage(context.Background(), message))
+		if err != nil {
+			t.Fatalf("Failed to marshal response: %v", err)
+		}
+		if !strings.Contains(string(response), `"id":7`) || !strings.Contains(string(response), `"result":{}`) {
+			t.Errorf("Expected empty result for request 7, got %s", response)
+		}
+	})
+
+	t.Run("Unsubscribe is recorded", func(t *testing.T) {
+		server.interceptSubscription("session-1",
+			[]byte(`{"jsonrpc": "2.0", "id": 8, "method": "resources/unsubscribe", "params": {"uri": "mbta://alerts/active"}}`))
+
+		if uris := server.subscriptions.uris(); len(uris) != 0 {
+			t.Errorf("Expected no subscriptions, got %v", uris)
+		}
+	})
+
+	t.Run("Unknown resource returns an error", func(t *testing.T) {
+		message := server.interceptSubscription("session-1",
+			[]byte(`{"jsonrpc": "2.0", "id": 9, "method": "resources/subscribe", "params": {"uri": "mbta://nowhere"}}`))
+
+		if uris := server.subscriptions.uris(); len(uris) != 0 {
+			t.Errorf("Expected unknown resource not to be subscribed, got %v", uris)
+		}
+
+		response, err := json.Marshal(server.mcpServer.HandleMessage(context.Background(), message))
+		if err != nil {
+			t.Fatalf("Failed to marshal response: %v", err)
+		}
+		if !strings.Contains(string(response), `"error"`) {
+			t.Errorf("Expected error response, got %s", response)
+		}
+	})
+
+	t.Run("Other messages pass through", func(t *testing.T) {
+		original := `{"jsonrpc": "2.0", "id": 10, "method": "tools/list"}`
+		if message := server.interceptSubscription("session-1", []byte(original)); string(message) != original {
+			t.Errorf("Expected message to be unchanged, got %s", message)
+		}
+	})
+}
+
+func TestSubscriptionReader(t *testing.T) {
+	server, err := New(&config.Config{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "resources/subscribe", "params": {"uri": "mbta://routes/Red/vehicles"}}`,
+	}, "\n") + "\n"
+
+	output, err := io.ReadAll(newSubscriptionReader(server, strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("Failed to read input: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), output)
+	}
+	if !strings.Contains(lines[0], "tools/list") {
+		t.Errorf("Expected first line to pass through, got %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"method":"ping"`) {
+		t.Errorf("Expected subscribe to be rewritten as ping, got %s", lines[1])
+	}
+	if subscribers := server.subscriptions.subscribers("mbta://routes/Red/vehicles"); len(subscribers) != 1 || subscribers[0] != stdioSessionID {
+		t.Errorf("Expected stdio session to be subscribed, got %v", subscribers)
+	}
+}
+
+func TestRefreshSubscriptions(t *testing.T) {
+	var version atomic.Int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = fmt.Fprintf(w, `{"data": [{"id": "y1234", "type": "vehicle", "attributes": {"label": "v%d"}}]}`, version.Load())
+	}))
+	defer apiServer.Close()
+
+	server, err := New(&config.Config{APIKey: "test-api-key", APIBaseURL: apiServer.URL})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	session := &testSession{id: "session-1", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	if err := server.mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	uri := "mbta://routes/Red/vehicles"
+	server.subscriptions.subscribe(session.id, uri)
+
+	// The first refresh only records a baseline
+	server.refreshSubscriptions(context.Background())
+	server.refreshSubscriptions(context.Background())
+	if len(session.notifications) != 0 {
+		t.Fatalf("Expected no notifications for unchanged data, got %d", len(session.notifications))
+	}
+
+	version.Store(1)
+	server.refreshSubscriptions(context.Background())
+
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != string(mcp.MethodNotificationResourceUpdated) {
+			t.Errorf("Expected resources/updated notification, got %s", notification.Method)
+		}
+		if notification.Params.AdditionalFields["uri"] != uri {
+			t.Errorf("Expected notification for %s, got %v", uri, notification.Params.AdditionalFields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a notification after data changed")
+	}
+
+	// Disconnecting drops the session's subscriptions
+	server.mcpServer.UnregisterSession(context.Background(), session.id)
+	if uris := server.subscriptions.uris(); len(uris) != 0 {
+		t.Errorf("Expected subscriptions to be removed with the session, got %v", uris)
+	}
+}
@@ -0,0 +1,177 @@
+// ABOUTME: This file implements the stop search handlers for the MCP server.
+// ABOUTME: It finds stations and stops by name so riders needn't know stop IDs.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Stop search defaults and limits
+const (
+	defaultStopSearchResults = 5
+	maxStopSearchResults     = 25
+)
+
+// registerStopSearchTools registers the stop search tools and handlers
+func (s *Server) registerStopSearchTools() {
+	// Tool: SearchStops - finds stations and stops by name
+	searchStopsTool := mcp.Tool{
+		Name:        "search_stops",
+		Description: "Search MBTA stations and stops by name, description, town or common nickname (such as DTX or Gov Center), tolerating typos. Returns ranked candidates with their stop IDs for use with other tools.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Name to search for, such as South Station or Harvard",
+				},
+				"limit": map[string]any{
+					"type":        "number",
+					"description": fmt.Sprintf("Maximum number of candidates to return (default: %d, max: %d)", defaultStopSearchResults, maxStopSearchResults),
+				},
+			},
+			Required: []string{"query"},
+		},
+	}
+
+	// Register the stop search tool with its handler, wrapped with middleware
+	s.mcpServer.AddTool(searchStopsTool, s.wrapWithMiddleware(s.searchStopsHandler))
+}
+
+// searchStopsHandler handles requests for finding stops by name
+func (s *Server) searchStopsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Printf("Received request for stop search: %s", request.Params.Name)
+
+	// Extract required parameters
+	args := request.GetArguments()
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return createErrorResponse("Missing or invalid query parameter"), nil
+	}
+
+	// Extract optional parameters
+	limit := defaultStopSearchResults
+	if limitVal, ok := args["limit"].(float64); ok {
+		if limitVal < 1 || limitVal > maxStopSearchResults {
+			return createErrorResponse(fmt.Sprintf("Invalid limit parameter, must be between 1 and %d", maxStopSearchResults)), nil
+		}
+		limit = int(limitVal)
+	}
+
+	log.Printf("Searching for stops matching %q", query)
+
+	matches, err := mbta.SearchStops(ctx, s.api, query, limit)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to search stops: %v", err)), nil
+	}
+
+	return formatStopSearchResponse(query, matches)
+}
+
+// resolveStop finds the stop a tool parameter names, whether by ID or by name,
+// noting in resolved when it isn't the stop with that ID
+func (s *Server) resolveStop(ctx context.Context, resolved resolvedStops, param, query string) (*models.Stop, error) {
+	stop, err := mbta.ResolveStop(ctx, s.api, query)
+	if err != nil {
+		return nil, err
+	}
+	if stop.ID != query {
+		log.Printf("Resolved %q to stop %s (%s)", query, stop.ID, stop.Attributes.Name)
+		resolved[param] = stopResolution{Query: query, ID: stop.ID, Name: stop.Attributes.Name}
+	}
+	return stop, nil
+}
+
+// stopResolution is a stop a tool parameter was resolved to by name
+type stopResolution struct {
+	Query string `json:"query"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+}
+
+// resolvedStops collects the stops tool parameters were resolved to by name,
+// keyed by parameter, so responses can show riders which stops were used
+type resolvedStops map[string]stopResolution
+
+// annotate adds the resolved stops to a tool result: as a resolved_stops
+// field of a JSON response, or as sentences after a text one
+func (r resolvedStops) annotate(result *mcp.CallToolResult) *mcp.CallToolResult {
+	if len(r) == 0 || result == nil || len(result.Content) == 0 {
+		return result
+	}
+	content, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+
+	// Keep numbers as they were written
+	var data map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(content.Text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err == nil {
+		data["resolved_stops"] = r
+		if jsonBytes, err := json.MarshalIndent(data, "", "  "); err == nil {
+			content.Text = string(jsonBytes)
+		}
+	} else {
+		params := make([]string, 0, len(r))
+		for param := range r {
+			params = append(params, param)
+		}
+		sort.Strings(params)
+
+		var notes strings.Builder
+		notes.WriteString(content.Text)
+		for _, param := range params {
+			fmt.Fprintf(&notes, "\n%s %q was taken to mean %s (%s).", param, r[param].Query, r[param].Name, r[param].ID)
+		}
+		content.Text = notes.String()
+	}
+	result.Content[0] = content
+	return result
+}
+
+// formatStopSearchResponse converts stop search matches to a proper MCP response
+func formatStopSearchResponse(query string, matches []models.StopMatch) (*mcp.CallToolResult, error) {
+	matchesData := make([]map[string]interface{}, 0, len(matches))
+	for i := range matches {
+		matchMap := formatStop(&matches[i].Stop)
+		matchMap["score"] = math.Round(matches[i].Score*100) / 100
+		matchMap["matched_on"] = matches[i].MatchedOn
+		matchMap["matched_text"] = matches[i].MatchedText
+		matchesData = append(matchesData, matchMap)
+	}
+
+	searchData := map[string]interface{}{
+		"query":       query,
+		"match_count": len(matches),
+		"matches":     matchesData,
+	}
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(searchData, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize stop search data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
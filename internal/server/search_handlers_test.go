@@ -0,0 +1,204 @@
+// ABOUTME: This file contains tests for the stop search handlers for the MCP server.
+// ABOUTME: It verifies ranked name searches and that trip tools accept stop names.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// namedStops is a stop backend that knows a few downtown stations by name
+type namedStops struct {
+	mbta.StopDataSource
+	stops []models.Stop
+}
+
+// newNamedStops returns a stop backend for South Station, Downtown Crossing and Harvard
+func newNamedStops() *namedStops {
+	station := func(id, name string, lat, lon float64) models.Stop {
+		return models.Stop{ID: id, Type: "stop", Attributes: models.StopAttributes{
+			Name:         name,
+			Municipality: "Boston",
+			Latitude:     lat,
+			Longitude:    lon,
+			LocationType: models.LocationTypeStation,
+		}}
+	}
+	return &namedStops{stops: []models.Stop{
+		station("place-sstat", "South Station", 42.352271, -71.055242),
+		station("place-dwnxg", "Downtown Crossing", 42.355518, -71.060225),
+		station("place-harsq", "Harvard", 42.373362, -71.118956),
+	}}
+}
+
+func (f *namedStops) GetStops(ctx context.Context) ([]models.Stop, error) {
+	return f.stops, nil
+}
+
+func (f *namedStops) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	for i := range f.stops {
+		if f.stops[i].ID == stopID {
+			return &f.stops[i], nil
+		}
+	}
+	return nil, fmt.Errorf("stop %s %w", stopID, mbta.ErrNotFound)
+}
+
+// plannedTrips records the stops, time and options trips are planned with
+type plannedTrips struct {
+	mbta.TripDataSource
+	origin, destination string
+	at                  time.Time
+	options             map[string]interface{}
+}
+
+func (p *plannedTrips) PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error) {
+	p.origin, p.destination, p.at, p.options = originStopID, destinationStopID, at, options
+	return nil, nil
+}
+
+// callTool calls a handler with arguments and returns its text and whether it failed
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) (string, bool) {
+	t.Helper()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	return result.Content[0].(mcp.TextContent).Text, result.IsError
+}
+
+func TestSearchStopsHandler(t *testing.T) {
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops()}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("Validates parameters", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{},
+			{"query": ""},
+			{"query": "Harvard", "limit": 0.0},
+			{"query": "Harvard", "limit": 100.0},
+		} {
+			if text, isError := callTool(t, server.searchStopsHandler, args); !isError {
+				t.Errorf("Expected an error for %v, got %s", args, text)
+			}
+		}
+	})
+
+	t.Run("Finds stations by nickname", func(t *testing.T) {
+		text, isError := callTool(t, server.searchStopsHandler, map[string]any{"query": "DTX"})
+		if isError {
+			t.Fatalf("Expected success, got %s", text)
+		}
+
+		var data struct {
+			Query      string                   `json:"query"`
+			MatchCount int                      `json:"match_count"`
+			Matches    []map[string]interface{} `json:"matches"`
+		}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if data.MatchCount != 1 || data.Matches[0]["id"] != "place-dwnxg" {
+			t.Fatalf("Expected Downtown Crossing, got %s", text)
+		}
+		if data.Matches[0]["matched_on"] != "alias" || data.Matches[0]["score"] != float64(1) {
+			t.Errorf("Expected an exact alias match, got %v", data.Matches[0])
+		}
+	})
+
+	t.Run("Tolerates typos", func(t *testing.T) {
+		text, _ := callTool(t, server.searchStopsHandler, map[string]any{"query": "sotuh staton"})
+		if !strings.Contains(text, `"id": "place-sstat"`) {
+			t.Errorf("Expected South Station, got %s", text)
+		}
+	})
+}
+
+func TestTripToolsAcceptStopNames(t *testing.T) {
+	trips := &plannedTrips{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("plan_trip", func(t *testing.T) {
+		text, _ := callTool(t, server.planTripHandler, map[string]any{
+			"origin_stop_id":      "South Station",
+			"destination_stop_id": "place-harsq",
+		})
+		if trips.origin != "place-sstat" || trips.destination != "place-harsq" {
+			t.Errorf("Expected a trip from place-sstat to place-harsq, got %s to %s", trips.origin, trips.destination)
+		}
+
+		// Riders are told which stop a name was taken to mean, even when
+		// no trip is found
+		var failed struct {
+			ResolvedStops map[string]map[string]string `json:"resolved_stops"`
+		}
+		if err := json.Unmarshal([]byte(text), &failed); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		if origin := failed.ResolvedStops["origin_stop_id"]; origin["query"] != "South Station" || origin["id"] != "place-sstat" {
+			t.Errorf("Expected the response to name the resolved origin, got %s", text)
+		}
+		if _, ok := failed.ResolvedStops["destination_stop_id"]; ok {
+			t.Errorf("Expected a stop ID not to be reported as resolved, got %s", text)
+		}
+
+		text, isError := callTool(t, server.planTripHandler, map[string]any{
+			"origin_stop_id":      "Nowhere Junction",
+			"destination_stop_id": "Harvard",
+		})
+		if !isError || !strings.Contains(text, "origin stop") {
+			t.Errorf("Expected an error naming the origin, got %s", text)
+		}
+	})
+
+	t.Run("estimate_travel_time", func(t *testing.T) {
+		text, isError := callTool(t, server.estimateTravelTimeHandler, map[string]any{
+			"origin_stop_id":      "downtown crossing",
+			"destination_stop_id": "Harverd",
+		})
+		if isError {
+			t.Fatalf("Expected success, got %s", text)
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		origin := data["origin"].(map[string]interface{})
+		destination := data["destination"].(map[string]interface{})
+		if origin["id"] != "place-dwnxg" || destination["id"] != "place-harsq" {
+			t.Errorf("Expected place-dwnxg to place-harsq, got %v to %v", origin, destination)
+		}
+
+		resolved, _ := data["resolved_stops"].(map[string]interface{})
+		misspelled, _ := resolved["destination_stop_id"].(map[string]interface{})
+		if len(resolved) != 2 || misspelled["query"] != "Harverd" || misspelled["id"] != "place-harsq" || misspelled["name"] != "Harvard" {
+			t.Errorf("Expected both names to be listed as resolved, got %v", data["resolved_stops"])
+		}
+	})
+}
@@ -1,32 +1,141 @@
 // ABOUTME: This file implements the MCP server for the MBTA application.
-// ABOUTME: It provides server initialization and configuration handling.
+// ABOUTME: It provides server initialization, configuration handling, and transport selection.
 
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/gtfs"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
 // Server represents the MBTA MCP server.
 type Server struct {
-	mcpServer *mcpserver.MCPServer
-	config    *config.Config
+	mcpServer  *mcpserver.MCPServer
+	config     *config.Config
+	httpServer *http.Server
+	sseServer  *mcpserver.SSEServer
+
+	subscriptions *resourceSubscriptions
+	cache         *mbta.ResponseCache
+	limiter       *mbta.RateLimiter
+	feed          *gtfs.Feed
+	stopIndex     *mbta.StopIndex
+	geocoder      mbta.Geocoder
+
+	// api answers every handler's transit data requests, and metrics counts
+	// the calls made to it
+	api     mbta.TransitAPI
+	metrics *mbta.Metrics
+
+	// done stops background work such as stop index refreshes
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// Option customizes a Server created by New.
+type Option func(*Server)
+
+// WithTransitAPI makes the server's handlers use the given backend instead of
+// the MBTA API client or GTFS feed selected by the configuration.
+func WithTransitAPI(api mbta.TransitAPI) Option {
+	return func(s *Server) {
+		s.api = api
+	}
+}
+
+// WithGeocoder makes trip planning look up addresses with the given geocoder
+// instead of the offline gazetteer.
+func WithGeocoder(geocoder mbta.Geocoder) Option {
+	return func(s *Server) {
+		s.geocoder = geocoder
+	}
 }
 
 // New creates a new MBTA MCP server with the provided configuration.
 // It initializes the MCP server and sets up basic configuration.
-func New(cfg *config.Config) (*Server, error) {
+func New(cfg *config.Config, opts ...Option) (*Server, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("configuration cannot be nil")
 	}
 
+	// Validate the transport before building anything around it
+	switch cfg.Transport {
+	case "", config.TransportStdio, config.TransportSSE, config.TransportStreamableHTTP:
+	default:
+		return nil, fmt.Errorf("unsupported transport %q (expected %s, %s, or %s)",
+			cfg.Transport, config.TransportStdio, config.TransportSSE, config.TransportStreamableHTTP)
+	}
+
+	// Load the static feed up front so a bad path fails at startup
+	var feed *gtfs.Feed
+	switch cfg.DataSource {
+	case "", config.DataSourceAPI:
+	case config.DataSourceGTFS:
+		if cfg.GTFSPath == "" {
+			return nil, fmt.Errorf("MBTA_GTFS_PATH is required when the data source is %s", config.DataSourceGTFS)
+		}
+		loaded, err := gtfs.Load(cfg.GTFSPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load GTFS feed: %w", err)
+		}
+		feed = loaded
+	default:
+		return nil, fmt.Errorf("unsupported data source %q (expected %s or %s)",
+			cfg.DataSource, config.DataSourceAPI, config.DataSourceGTFS)
+	}
+
+	// Addresses are looked up offline, in well-known places and any the
+	// configuration adds
+	gazetteer := mbta.DefaultGazetteer()
+	if cfg.GazetteerPath != "" {
+		places, err := loadGazetteer(cfg.GazetteerPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gazetteer: %w", err)
+		}
+		gazetteer.Add(places...)
+	}
+
+	// Share one response cache between all handlers
+	cache, err := mbta.NewCacheFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create response cache: %w", err)
+	}
+
+	// Share one rate limiter between all handlers, since they use the same key
+	rateLimit := cfg.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = mbta.DefaultAnonymousRateLimit
+		if cfg.APIKey != "" {
+			rateLimit = mbta.DefaultRateLimit
+		}
+	}
+
+	subscriptions := newResourceSubscriptions()
+
+	// Forget a session's resource subscriptions when it disconnects
+	hooks := &mcpserver.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session mcpserver.ClientSession) {
+		subscriptions.removeSession(session.SessionID())
+	})
+
 	// Create options for MCP server
-	var serverOpts []mcpserver.ServerOption
+	serverOpts := []mcpserver.ServerOption{
+		mcpserver.WithResourceCapabilities(true, false),
+		mcpserver.WithHooks(hooks),
+	}
 
 	// Enable logging if debug is enabled
 	if cfg.Debug {
@@ -42,13 +151,136 @@ func New(cfg *config.Config) (*Server, error) {
 
 	// Create and return server
 	server := &Server{
-		mcpServer: mcpServer,
-		config:    cfg,
+		mcpServer:     mcpServer,
+		config:        cfg,
+		subscriptions: subscriptions,
+		cache:         cache,
+		limiter:       mbta.NewRateLimiter(rateLimit),
+		feed:          feed,
+		geocoder:      gazetteer,
+		metrics:       mbta.NewMetrics(),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	// Nearby searches use a shared stop index, refreshed in the background.
+	// A GTFS feed indexes its own stops.
+	if cfg.StopIndexRefresh > 0 && feed == nil {
+		server.stopIndex = mbta.NewStopIndex()
+	}
+
+	// Count every call handlers make, whichever backend answers it. The
+	// client already caches and retries its own requests.
+	if server.api == nil {
+		server.api = server.defaultAPI()
+	}
+	server.api = mbta.Decorate(server.api, server.metrics.Decorator())
+
+	// Network transports share a single HTTP server so that Shutdown works
+	// regardless of whether Start has been called yet
+	if cfg.IsNetworkTransport() {
+		server.httpServer = &http.Server{
+			Addr:     cfg.ListenAddr,
+			ErrorLog: log.New(os.Stderr, "MBTA-MCP-ERROR: ", log.LstdFlags),
+		}
+
+		if cfg.Transport == config.TransportSSE {
+			server.sseServer = mcpserver.NewSSEServer(
+				mcpServer,
+				mcpserver.WithStaticBasePath(cfg.BasePath),
+				mcpserver.WithHTTPServer(server.httpServer),
+			)
+			server.httpServer.Handler = server.subscriptionMiddleware(server.sseServer, func(r *http.Request) string {
+				return r.URL.Query().Get("sessionId")
+			})
+		} else {
+			mux := http.NewServeMux()
+			mux.Handle(cfg.BasePath, server.subscriptionMiddleware(
+				mcpserver.NewStreamableHTTPServer(
+					mcpServer,
+					mcpserver.WithEndpointPath(cfg.BasePath),
+				),
+				func(r *http.Request) string {
+					return r.Header.Get("Mcp-Session-Id")
+				},
+			))
+			server.httpServer.Handler = mux
+		}
 	}
 
 	return server, nil
 }
 
+// loadGazetteer reads the places in a gazetteer CSV file
+func loadGazetteer(path string) ([]mbta.Place, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return mbta.LoadGazetteer(file)
+}
+
+// newClient creates an MBTA API client that shares the server's response cache
+// and rate limiter, and retries failed requests as configured. With a GTFS data
+// source, static data and walks between stations come from the feed and only
+// real-time requests reach the API, so only they are rate limited, retried and
+// cached.
+func (s *Server) newClient() *mbta.Client {
+	retry := mbta.DefaultRetryPolicy
+	retry.MaxAttempts = s.config.RetryAttempts
+
+	opts := []mbta.ClientOption{
+		mbta.WithCache(s.cache),
+		mbta.WithRetry(retry),
+		mbta.WithRateLimiter(s.limiter),
+	}
+	if s.feed != nil {
+		opts = append(opts,
+			mbta.WithTransport(s.feed.Transport(http.DefaultTransport)),
+			mbta.WithFootpaths(s.feed.Footpaths()),
+		)
+	}
+	if s.stopIndex != nil {
+		opts = append(opts, mbta.WithStopIndex(s.stopIndex))
+	}
+
+	return mbta.NewClient(s.config, opts...)
+}
+
+// defaultAPI returns the backend selected by the configuration: the MBTA API
+// client, with routes, stops and schedules taken from the GTFS feed if one is
+// loaded.
+func (s *Server) defaultAPI() mbta.TransitAPI {
+	client := s.newClient()
+	if s.feed == nil {
+		return client
+	}
+
+	return mbta.CompositeAPI{
+		RouteDataSource:      s.feed,
+		StopDataSource:       s.feed,
+		ScheduleDataSource:   s.feed,
+		PredictionDataSource: client,
+		VehicleDataSource:    client,
+		AlertDataSource:      client,
+		FacilityDataSource:   client,
+		TripDataSource:       client,
+	}
+}
+
+// CacheStats returns the response cache statistics, or false if caching is disabled.
+func (s *Server) CacheStats() (mbta.CacheStats, bool) {
+	if s.cache == nil {
+		return mbta.CacheStats{}, false
+	}
+	return s.cache.Stats(), true
+}
+
 // SetMetadata sets additional metadata for the MCP server.
 // This is a placeholder as the current API doesn't directly support metadata.
 func (s *Server) SetMetadata(key string, value interface{}) {
@@ -56,14 +288,102 @@ func (s *Server) SetMetadata(key string, value interface{}) {
 	log.Printf("Setting metadata %s = %v (note: not supported in current MCP API)", key, value)
 }
 
-// Start starts the MCP server using stdio protocol.
+// Start starts the MCP server using the configured transport.
 // It blocks until the server is stopped or encounters an error.
 func (s *Server) Start() error {
-	log.Println("Starting MBTA MCP Server with stdio protocol")
-
 	// Apply middleware before starting
 	s.ApplyMiddleware()
 
+	// Report how well the response cache did once the server stops
+	defer s.logCacheStats()
+
+	// Push updates for subscribed resources while the server runs
+	defer s.subscriptions.close()
+	if s.config.ResourcePollInterval > 0 {
+		go s.pollSubscriptions(s.config.ResourcePollInterval)
+	}
+
+	// Keep the stop index fresh while the server runs
+	defer s.stopBackground()
+	if s.stopIndex != nil {
+		go s.refreshStopIndex(s.config.StopIndexRefresh)
+	}
+
+	if s.httpServer != nil {
+		return s.startHTTP()
+	}
+
+	return s.startStdio()
+}
+
+// Shutdown gracefully stops a network transport, closing open sessions and
+// waiting for in-flight requests until the context expires.
+// It is a no-op for the stdio transport, which exits when its input closes.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.subscriptions.close()
+	s.stopBackground()
+
+	if s.sseServer != nil {
+		return s.sseServer.Shutdown(ctx)
+	}
+	if s.httpServer != nil {
+		return s.httpServer.Shutdown(ctx)
+	}
+	return nil
+}
+
+// stopBackground stops background work. It is safe to call more than once.
+func (s *Server) stopBackground() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// refreshStopIndex loads the stop index right away and then rebuilds it at
+// the given interval until the server shuts down, retrying failed loads within
+// a minute. Nearby searches fetch stops directly until the first load succeeds.
+func (s *Server) refreshStopIndex(interval time.Duration) {
+	for {
+		next := interval
+		if err := s.newClient().RefreshStopIndex(context.Background(), s.stopIndex); err != nil {
+			log.Printf("Error refreshing stop index: %v", err)
+			next = min(interval, time.Minute)
+		} else {
+			log.Printf("Stop index loaded with %d stops", s.stopIndex.Len())
+		}
+
+		timer := time.NewTimer(next)
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// logCacheStats logs the response cache hit and miss counts.
+func (s *Server) logCacheStats() {
+	if stats, ok := s.CacheStats(); ok {
+		log.Printf("Response cache: %d hits, %d revalidations, %d misses (%.0f%% hit rate)",
+			stats.Hits, stats.Revalidations, stats.Misses, stats.HitRate()*100)
+	}
+}
+
+// startHTTP serves the MCP server over SSE or streamable HTTP.
+func (s *Server) startHTTP() error {
+	log.Printf("Starting MBTA MCP Server with %s transport on %s%s",
+		s.config.Transport, s.config.ListenAddr, s.config.BasePath)
+
+	err := s.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// startStdio serves the MCP server over stdin and stdout.
+func (s *Server) startStdio() error {
+	log.Println("Starting MBTA MCP Server with stdio protocol")
+
 	// Configure stdio options
 	var stdioOpts []mcpserver.StdioOption
 
@@ -71,8 +391,17 @@ func (s *Server) Start() error {
 	errorLogger := log.New(os.Stderr, "MBTA-MCP-ERROR: ", log.LstdFlags)
 	stdioOpts = append(stdioOpts, mcpserver.WithErrorLogger(errorLogger))
 
-	// Start the server with stdio transport and options
-	return mcpserver.ServeStdio(s.mcpServer, stdioOpts...)
+	stdioServer := mcpserver.NewStdioServer(s.mcpServer)
+	for _, opt := range stdioOpts {
+		opt(stdioServer)
+	}
+
+	// Stop on SIGTERM or SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// Start the server, intercepting resource subscriptions on the way in
+	return stdioServer.Listen(ctx, newSubscriptionReader(s, os.Stdin), os.Stdout)
 }
 
 // This implementation is in middleware.go
@@ -1,12 +1,22 @@
 // ABOUTME: This file contains tests for the MCP server initialization.
-// ABOUTME: It verifies server creation, configuration, and error handling.
+// ABOUTME: It verifies server creation, configuration, transports, and error handling.
 
 package server
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestNewServer(t *testing.T) {
@@ -98,3 +108,236 @@ func TestStartServer(t *testing.T) {
 		_ = startMethod
 	})
 }
+
+func TestServerTransports(t *testing.T) {
+	newConfig := func(transport string) *config.Config {
+		return &config.Config{
+			APIKey:          "test-api-key",
+			Timeout:         30,
+			APIBaseURL:      "https://api-test.mbta.com",
+			Environment:     "test",
+			Transport:       transport,
+			ListenAddr:      "127.0.0.1:0",
+			BasePath:        "/mcp",
+			ShutdownTimeout: time.Second,
+		}
+	}
+
+	t.Run("Unsupported transport", func(t *testing.T) {
+		server, err := New(newConfig("carrier-pigeon"))
+		if err == nil {
+			t.Error("Expected error for unsupported transport, got nil")
+		}
+		if server != nil {
+			t.Errorf("Expected nil server for unsupported transport, got %v", server)
+		}
+	})
+
+	t.Run("Stdio transport has no HTTP server", func(t *testing.T) {
+		server, err := New(newConfig(config.TransportStdio))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		if server.httpServer != nil {
+			t.Error("Expected no HTTP server for stdio transport")
+		}
+		if err := server.Shutdown(context.Background()); err != nil {
+			t.Errorf("Expected no error shutting down stdio server, got %v", err)
+		}
+	})
+
+	for _, transport := range []string{config.TransportSSE, config.TransportStreamableHTTP} {
+		t.Run("Shutdown stops "+transport+" transport", func(t *testing.T) {
+			server, err := New(newConfig(transport))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			if server.httpServer == nil {
+				t.Fatal("Expected HTTP server for network transport")
+			}
+
+			// Shutting down before Start must still make Start return cleanly
+			if err := server.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Expected no error on shutdown, got %v", err)
+			}
+
+			done := make(chan error, 1)
+			go func() { done <- server.Start() }()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Expected nil error after shutdown, got %v", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Start did not return after Shutdown")
+			}
+		})
+	}
+
+	t.Run("Streamable HTTP serves tools at base path", func(t *testing.T) {
+		server, err := New(newConfig(config.TransportStreamableHTTP))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		server.RegisterDefaultHandlers()
+
+		ts := httptest.NewServer(server.httpServer.Handler)
+		defer ts.Close()
+
+		body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+		resp, err := http.Post(ts.URL+"/mcp", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to POST initialize: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("Mcp-Session-Id") == "" {
+			t.Error("Expected Mcp-Session-Id header in initialize response")
+		}
+	})
+}
+
+func TestServerDataSource(t *testing.T) {
+	newConfig := func(source, path string) *config.Config {
+		return &config.Config{
+			Timeout:    30 * time.Second,
+			APIBaseURL: "https://api-test.mbta.com",
+			DataSource: source,
+			GTFSPath:   path,
+		}
+	}
+
+	t.Run("Unsupported data source", func(t *testing.T) {
+		if _, err := New(newConfig("carrier-pigeon", "")); err == nil {
+			t.Error("Expected error for unsupported data source, got nil")
+		}
+	})
+
+	t.Run("GTFS without a path", func(t *testing.T) {
+		if _, err := New(newConfig(config.DataSourceGTFS, "")); err == nil {
+			t.Error("Expected error for GTFS data source without a path, got nil")
+		}
+	})
+
+	t.Run("GTFS with a missing feed", func(t *testing.T) {
+		if _, err := New(newConfig(config.DataSourceGTFS, "testdata/missing.zip")); err == nil {
+			t.Error("Expected error for a missing GTFS feed, got nil")
+		}
+	})
+
+	t.Run("GTFS serves static data", func(t *testing.T) {
+		server, err := New(newConfig(config.DataSourceGTFS, "../../pkg/gtfs/testdata/feed"))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		if server.feed == nil {
+			t.Fatal("Expected server to load the GTFS feed")
+		}
+
+		stop, err := server.newClient().GetStop(context.Background(), "place-harsq")
+		if err != nil {
+			t.Fatalf("Expected stop from the feed, got error %v", err)
+		}
+		if stop.Attributes.Name != "Harvard" {
+			t.Errorf("Expected Harvard, got %s", stop.Attributes.Name)
+		}
+
+		// Handlers search the feed's own stop index
+		if server.stopIndex != nil {
+			t.Error("Expected no API stop index with a GTFS feed")
+		}
+		nearby, err := server.api.FindNearbyStops(context.Background(), 42.3734, -71.1189, 0.5, 1, mbta.StopFilter{
+			LocationTypes: []int{models.LocationTypeStation},
+		})
+		if err != nil {
+			t.Fatalf("Expected nearby stops from the feed, got error %v", err)
+		}
+		if len(nearby) != 1 || nearby[0].Stop.ID != "place-harsq" {
+			t.Errorf("Expected place-harsq nearby, got %v", nearby)
+		}
+	})
+}
+
+// fakeStops is a stop backend that knows a single stop
+type fakeStops struct {
+	mbta.StopDataSource
+	requested []string
+}
+
+func (f *fakeStops) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	f.requested = append(f.requested, stopID)
+	return &models.Stop{ID: stopID, Type: "stop", Attributes: models.StopAttributes{Name: "Fake Station"}}, nil
+}
+
+func TestServerWithTransitAPI(t *testing.T) {
+	stops := &fakeStops{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: stops}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_stops"
+	request.Params.Arguments = map[string]any{"stop_id": "fake-1"}
+
+	result, err := server.getStopsHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got %v", result.Content)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "Fake Station") {
+		t.Errorf("Expected the injected backend's stop, got %s", text)
+	}
+	if len(stops.requested) != 1 || stops.requested[0] != "fake-1" {
+		t.Errorf("Expected one request for fake-1, got %v", stops.requested)
+	}
+
+	// Calls to the injected backend are still counted
+	if calls := server.metrics.Snapshot()["GetStop"].Calls; calls != 1 {
+		t.Errorf("Expected 1 GetStop call, got %d", calls)
+	}
+}
+
+func TestServerGazetteer(t *testing.T) {
+	newConfig := func(path string) *config.Config {
+		return &config.Config{
+			Timeout:       30 * time.Second,
+			APIBaseURL:    "https://api-test.mbta.com",
+			GazetteerPath: path,
+		}
+	}
+
+	t.Run("Missing gazetteer", func(t *testing.T) {
+		if _, err := New(newConfig("testdata/missing.csv")); err == nil {
+			t.Error("Expected error for a missing gazetteer, got nil")
+		}
+	})
+
+	t.Run("Places added to the built-in gazetteer", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "places.csv")
+		if err := os.WriteFile(path, []byte("name,latitude,longitude\n10 Park Plaza,42.3515,-71.0671\n"), 0o600); err != nil {
+			t.Fatalf("Failed to write gazetteer: %v", err)
+		}
+
+		server, err := New(newConfig(path))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		for _, query := range []string{"10 Park Plaza", "Fenway Park"} {
+			if _, err := server.geocoder.Geocode(context.Background(), query); err != nil {
+				t.Errorf("Expected to find %s, got error %v", query, err)
+			}
+		}
+	})
+}
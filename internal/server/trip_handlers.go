@@ -6,9 +6,11 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta"
@@ -16,33 +18,83 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// Trip planning defaults and limits
+const (
+	defaultTripDepartures = 3
+	maxTripDepartures     = 5
+	maxTripItineraries    = 6
+)
+
 // registerTripPlanningTools registers the trip planning tools and handlers
 func (s *Server) registerTripPlanningTools() {
-	// Tool: PlanTrip - creates a trip plan between two stops
+	// Tool: PlanTrip - creates a trip plan between two stops or locations
 	planTripTool := mcp.Tool{
 		Name:        "plan_trip",
-		Description: "Plan a trip between two MBTA stops or stations",
+		Description: "Plan a trip between two MBTA stops or stations, or between any two locations given by coordinates or address, walking to and from nearby stops. Returns a ranked list of itineraries across the next few departures, best first by optimize_for, with a compact comparison of them.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]any{
 				"origin_stop_id": map[string]any{
 					"type":        "string",
-					"description": "The ID of the origin stop or station",
+					"description": "The ID or name of the origin stop or station, such as place-sstat or South Station. A name is matched to the closest stop, reported under resolved_stops",
+				},
+				"origin_latitude": map[string]any{
+					"type":        "number",
+					"description": "Latitude to start from instead of a stop, with origin_longitude",
+				},
+				"origin_longitude": map[string]any{
+					"type":        "number",
+					"description": "Longitude to start from instead of a stop, with origin_latitude",
+				},
+				"origin_address": map[string]any{
+					"type":        "string",
+					"description": "Address or place to start from instead of a stop, such as Fenway Park",
 				},
 				"destination_stop_id": map[string]any{
 					"type":        "string",
-					"description": "The ID of the destination stop or station",
+					"description": "The ID or name of the destination stop or station, such as place-harsq or Harvard. A name is matched to the closest stop, reported under resolved_stops",
+				},
+				"destination_latitude": map[string]any{
+					"type":        "number",
+					"description": "Latitude to travel to instead of a stop, with destination_longitude",
+				},
+				"destination_longitude": map[string]any{
+					"type":        "number",
+					"description": "Longitude to travel to instead of a stop, with destination_latitude",
+				},
+				"destination_address": map[string]any{
+					"type":        "string",
+					"description": "Address or place to travel to instead of a stop, such as Museum of Fine Arts",
 				},
 				"departure_time": map[string]any{
 					"type":        "string",
 					"description": "The desired departure time (ISO 8601 format, e.g. '2023-05-23T14:30:00Z'). If not provided, current time is used.",
 				},
+				"arrive_by": map[string]any{
+					"type":        "string",
+					"description": "The latest time to arrive (ISO 8601 format), instead of departure_time. Finds the latest departure that still arrives on time, such as when to leave for a 9am meeting.",
+				},
 				"wheelchair_accessible": map[string]any{
 					"type":        "boolean",
-					"description": "Whether the trip must be wheelchair accessible",
+					"description": "Whether the trip must be wheelchair accessible, avoiding platforms with elevator outages, or whole stations when an outage doesn't say which platforms it affects",
+				},
+				"max_transfers": map[string]any{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of transfers allowed (default: %d)", mbta.DefaultMaxTransfers),
+					"minimum":     0,
+				},
+				"optimize_for": map[string]any{
+					"type":        "string",
+					"description": "How to rank itineraries: fastest, fewest_transfers, least_walking, or accessible to only include wheelchair accessible trips (default: fastest)",
+					"enum":        mbta.OptimizeCriteria,
+				},
+				"departures": map[string]any{
+					"type":        "integer",
+					"description": fmt.Sprintf("How many departures to consider, such as the next 3 (default: %d, maximum: %d)", defaultTripDepartures, maxTripDepartures),
+					"minimum":     1,
+					"maximum":     maxTripDepartures,
 				},
 			},
-			Required: []string{"origin_stop_id", "destination_stop_id"},
 		},
 	}
 
@@ -52,7 +104,7 @@ func (s *Server) registerTripPlanningTools() {
 	// Tool: FindTransfers - finds transfer points between routes
 	findTransfersTool := mcp.Tool{
 		Name:        "find_transfers",
-		Description: "Find transfer points between MBTA routes",
+		Description: "Find transfer points between MBTA routes: stops both routes serve, and nearby stops riders can walk between, with the walking distance and time",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]any{
@@ -81,11 +133,11 @@ func (s *Server) registerTripPlanningTools() {
 			Properties: map[string]any{
 				"origin_stop_id": map[string]any{
 					"type":        "string",
-					"description": "The ID of the origin stop or station",
+					"description": "The ID or name of the origin stop or station, such as place-sstat or South Station. A name is matched to the closest stop, reported under resolved_stops",
 				},
 				"destination_stop_id": map[string]any{
 					"type":        "string",
-					"description": "The ID of the destination stop or station",
+					"description": "The ID or name of the destination stop or station, such as place-harsq or Harvard. A name is matched to the closest stop, reported under resolved_stops",
 				},
 				"route_id": map[string]any{
 					"type":        "string",
@@ -101,24 +153,13 @@ func (s *Server) registerTripPlanningTools() {
 }
 
 // planTripHandler handles requests for planning trips between stops
-func (s *Server) planTripHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	log.Printf("Received request for trip planning: %s", request.Params.Name)
-
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
-	// Extract required parameters
-	args := request.Params.Arguments
-	originStopID, ok := args["origin_stop_id"].(string)
-	if !ok {
-		return createErrorResponse("Missing or invalid origin_stop_id parameter"), nil
-	}
+func (s *Server) planTripHandler(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+	resolved := resolvedStops{}
+	defer func() { result = resolved.annotate(result) }()
 
-	destinationStopID, ok := args["destination_stop_id"].(string)
-	if !ok {
-		return createErrorResponse("Missing or invalid destination_stop_id parameter"), nil
-	}
+	log.Printf("Received request for trip planning: %s", request.Params.Name)
 
+	args := request.GetArguments()
 	// Extract optional parameters
 	var departureTime time.Time
 	if departureTStr, ok := args["departure_time"].(string); ok && departureTStr != "" {
@@ -128,41 +169,158 @@ func (s *Server) planTripHandler(ctx context.Context, request mcp.CallToolReques
 			return createErrorResponse(fmt.Sprintf("Invalid departure_time format: %v", err)), nil
 		}
 	} else {
-		departureTime = time.Now()
+		departureTime = time.Now().In(mbta.ServiceLocation())
+	}
+
+	// Searching back from a deadline finds the latest riders can leave
+	var arriveBy bool
+	if arriveByStr, ok := args["arrive_by"].(string); ok && arriveByStr != "" {
+		if departureTStr, ok := args["departure_time"].(string); ok && departureTStr != "" {
+			return createErrorResponse("Specify either departure_time or arrive_by, not both"), nil
+		}
+		deadline, err := time.Parse(time.RFC3339, arriveByStr)
+		if err != nil {
+			return createErrorResponse(fmt.Sprintf("Invalid arrive_by format: %v", err)), nil
+		}
+		departureTime, arriveBy = deadline, true
+	}
+
+	// Rank itineraries by what riders care about most
+	optimizeFor := mbta.OptimizeFastest
+	if optimizeForVal, ok := args["optimize_for"].(string); ok && optimizeForVal != "" {
+		optimizeFor = optimizeForVal
+	}
+	if !mbta.IsOptimizeCriterion(optimizeFor) {
+		return createErrorResponse(fmt.Sprintf("Invalid optimize_for parameter, must be one of %s", strings.Join(mbta.OptimizeCriteria, ", "))), nil
+	}
+
+	departures := defaultTripDepartures
+	if departuresVal, ok := args["departures"].(float64); ok {
+		if departuresVal < 1 || departuresVal > maxTripDepartures {
+			return createErrorResponse(fmt.Sprintf("Invalid departures parameter, must be between 1 and %d", maxTripDepartures)), nil
+		}
+		departures = int(departuresVal)
 	}
 
-	// Check for wheelchair accessible requirement
+	// Check for wheelchair accessible requirement, which ranking for
+	// accessibility implies
 	var wheelchairAccessible bool
 	if wheelchairAccessibleVal, ok := args["wheelchair_accessible"].(bool); ok {
 		wheelchairAccessible = wheelchairAccessibleVal
 	}
+	if optimizeFor == mbta.OptimizeAccessible {
+		wheelchairAccessible = true
+	}
 
 	// Create options map
 	options := map[string]interface{}{
 		"wheelchair_accessible": wheelchairAccessible,
+		"arrive_by":             arriveBy,
+		"departures":            departures,
+	}
+
+	if maxTransfers, ok := args["max_transfers"].(float64); ok {
+		if maxTransfers < 0 {
+			return createErrorResponse("max_transfers must not be negative"), nil
+		}
+		options["max_transfers"] = int(maxTransfers)
+	}
+
+	// Either end may be a stop, given by name as well as by ID, or a location
+	originStop, err := s.resolveTripEndpoint(ctx, resolved, args, "origin", options)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to find %v", err)), nil
+	}
+	destinationStop, err := s.resolveTripEndpoint(ctx, resolved, args, "destination", options)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to find %v", err)), nil
 	}
+	originStopID, destinationStopID := originStop.ID, destinationStop.ID
 
-	log.Printf("Planning trip from %s to %s at %s", originStopID, destinationStopID, departureTime.Format(time.RFC3339))
+	if arriveBy {
+		log.Printf("Planning trip from %s to %s arriving by %s", originStop.Attributes.Name, destinationStop.Attributes.Name, departureTime.Format(time.RFC3339))
+	} else {
+		log.Printf("Planning trip from %s to %s at %s", originStop.Attributes.Name, destinationStop.Attributes.Name, departureTime.Format(time.RFC3339))
+	}
 
 	// Plan the trip
-	tripPlan, err := client.PlanTrip(ctx, originStopID, destinationStopID, departureTime, options)
+	tripPlans, err := s.api.PlanTrips(ctx, originStopID, destinationStopID, departureTime, options)
 	if err != nil {
+		// Explain why accessible trips were ruled out and where riders could go instead
+		var inaccessible *mbta.InaccessibleTripError
+		if errors.As(err, &inaccessible) {
+			return formatInaccessibleTripResponse(originStop, destinationStop, inaccessible)
+		}
 		return createErrorResponse(fmt.Sprintf("Failed to plan trip: %v", err)), nil
 	}
 
-	// Format the trip plan for response
-	return formatTripPlanResponse(tripPlan)
+	// Rank the plans, keeping the best few
+	tripPlans, err = mbta.RankTripPlans(tripPlans, optimizeFor)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to rank trip plans: %v", err)), nil
+	}
+	if len(tripPlans) > maxTripItineraries {
+		tripPlans = tripPlans[:maxTripItineraries]
+	}
+
+	// Format the trip plans for response
+	return formatTripPlansResponse(tripPlans, optimizeFor)
+}
+
+// resolveTripEndpoint finds where a trip starts or ends from the arguments
+// named after the given end ("origin" or "destination"): a stop ID or name,
+// coordinates, or an address looked up with the server's geocoder. A location
+// is added to the planning options and returned as the stop standing in for it,
+// and a stop found by name is noted in resolved.
+func (s *Server) resolveTripEndpoint(ctx context.Context, resolved resolvedStops, args map[string]any, end string, options map[string]interface{}) (*models.Stop, error) {
+	if query, ok := args[end+"_stop_id"].(string); ok && query != "" {
+		stop, err := s.resolveStop(ctx, resolved, end+"_stop_id", query)
+		if err != nil {
+			return nil, fmt.Errorf("%s stop: %w", end, err)
+		}
+		return stop, nil
+	}
+
+	var location *mbta.Location
+	latitude, hasLatitude := args[end+"_latitude"].(float64)
+	longitude, hasLongitude := args[end+"_longitude"].(float64)
+	address, hasAddress := args[end+"_address"].(string)
+	switch {
+	case hasLatitude && hasLongitude:
+		if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+			return nil, fmt.Errorf("%s coordinates: %.5f, %.5f are out of range", end, latitude, longitude)
+		}
+		location = &mbta.Location{Latitude: latitude, Longitude: longitude}
+	case hasLatitude || hasLongitude:
+		return nil, fmt.Errorf("%s coordinates: both %s_latitude and %s_longitude are required", end, end, end)
+	case hasAddress && address != "":
+		if s.geocoder == nil {
+			return nil, fmt.Errorf("%s address: addresses can't be looked up", end)
+		}
+		found, err := s.geocoder.Geocode(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("%s address: %w", end, err)
+		}
+		location = found
+		log.Printf("Geocoded %q to %s (%.5f, %.5f)", address, location.Name, location.Latitude, location.Longitude)
+	default:
+		return nil, fmt.Errorf("%s: provide %s_stop_id, %s_latitude and %s_longitude, or %s_address", end, end, end, end, end)
+	}
+
+	options[end+"_location"] = *location
+	locationID := mbta.OriginLocationID
+	if end == "destination" {
+		locationID = mbta.DestinationLocationID
+	}
+	return mbta.LocationStop(locationID, *location), nil
 }
 
 // findTransfersHandler handles requests for finding transfer points between routes
 func (s *Server) findTransfersHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for transfer points: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract required parameters
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	fromRouteID, ok := args["from_route_id"].(string)
 	if !ok {
 		return createErrorResponse("Missing or invalid from_route_id parameter"), nil
@@ -176,7 +334,7 @@ func (s *Server) findTransfersHandler(ctx context.Context, request mcp.CallToolR
 	log.Printf("Finding transfer points from route %s to route %s", fromRouteID, toRouteID)
 
 	// Find transfer points between routes
-	transferPoints, err := client.FindTransferPoints(ctx, []string{fromRouteID}, []string{toRouteID})
+	transferPoints, err := s.api.FindTransferPoints(ctx, []string{fromRouteID}, []string{toRouteID})
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to find transfer points: %v", err)), nil
 	}
@@ -198,14 +356,14 @@ func (s *Server) findTransfersHandler(ctx context.Context, request mcp.CallToolR
 }
 
 // estimateTravelTimeHandler handles requests for estimating travel time between stops
-func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	log.Printf("Received request for travel time estimation: %s", request.Params.Name)
+func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+	resolved := resolvedStops{}
+	defer func() { result = resolved.annotate(result) }()
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
+	log.Printf("Received request for travel time estimation: %s", request.Params.Name)
 
 	// Extract required parameters
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	originStopID, ok := args["origin_stop_id"].(string)
 	if !ok {
 		return createErrorResponse("Missing or invalid origin_stop_id parameter"), nil
@@ -224,16 +382,17 @@ func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.Call
 
 	log.Printf("Estimating travel time from %s to %s", originStopID, destinationStopID)
 
-	// Get origin and destination stops
-	originStop, err := client.GetStop(ctx, originStopID)
+	// Get origin and destination stops, which may be given by name as well as by ID
+	originStop, err := s.resolveStop(ctx, resolved, "origin_stop_id", originStopID)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve origin stop: %v", err)), nil
 	}
 
-	destStop, err := client.GetStop(ctx, destinationStopID)
+	destStop, err := s.resolveStop(ctx, resolved, "destination_stop_id", destinationStopID)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve destination stop: %v", err)), nil
 	}
+	originStopID, destinationStopID = originStop.ID, destStop.ID
 
 	// Calculate approximate distance
 	distance := calculateApproximateDistance(
@@ -248,7 +407,10 @@ func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.Call
 
 	if routeID != "" {
 		// Try to get schedule-based estimate
-		travelTimeMinutes, scheduleBasedEstimate, err = estimateScheduleBasedTravelTime(ctx, client, originStopID, destinationStopID, routeID)
+		// Stations are matched through their platforms, where trips stop
+		originIDs := s.expandStopID(ctx, originStopID)
+		destinationIDs := s.expandStopID(ctx, destinationStopID)
+		travelTimeMinutes, scheduleBasedEstimate, err = estimateScheduleBasedTravelTime(ctx, s.api, originIDs, destinationIDs, routeID)
 		if err == nil && scheduleBasedEstimate {
 			travelTimeSource = "Based on recent schedules"
 		}
@@ -258,7 +420,7 @@ func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.Call
 	if !scheduleBasedEstimate {
 		// Estimate travel time based on distance and mode of transit
 		if routeID != "" {
-			route, err := client.GetRoute(ctx, routeID)
+			route, err := s.api.GetRoute(ctx, routeID)
 			if err == nil {
 				// Use route type to determine average speed
 				travelTimeMinutes = estimateTimeByRouteType(distance, route.Attributes.Type)
@@ -279,22 +441,22 @@ func (s *Server) estimateTravelTimeHandler(ctx context.Context, request mcp.Call
 }
 
 // estimateScheduleBasedTravelTime tries to estimate travel time using real schedule data
-func estimateScheduleBasedTravelTime(ctx context.Context, client *mbta.Client, originID, destinationID, routeID string) (float64, bool, error) {
-	// Get schedules for today that include both stops on the specified route
-	now := time.Now()
+// between any of the origin stops and any of the destination stops
+func estimateScheduleBasedTravelTime(ctx context.Context, source mbta.ScheduleDataSource, originIDs, destinationIDs []string, routeID string) (float64, bool, error) {
+	// Get schedules for today's service that include both stops on the specified route
 	params := map[string]string{
 		"filter[route]":     routeID,
-		"filter[stop]":      originID + "," + destinationID,
-		"filter[date]":      now.Format("2006-01-02"),
+		"filter[stop]":      strings.Join(append(append([]string{}, originIDs...), destinationIDs...), ","),
+		"filter[date]":      mbta.ServiceDayOf(time.Now()).String(),
 		"filter[direction]": "0,1", // Consider both directions
 		"include":           "trip",
 		"sort":              "departure_time",
 		"fields[schedule]":  "departure_time,arrival_time,stop_sequence,pickup_type,drop_off_type,direction_id",
 		"fields[trip]":      "headsign,direction_id",
-		"page[limit]":       "100", // Get a reasonable sample
 	}
 
-	schedules, _, err := client.GetSchedules(ctx, params)
+	// Get a reasonable sample rather than the whole day
+	schedules, _, err := source.GetSchedules(mbta.WithPageOptions(ctx, mbta.PageOptions{MaxItems: 100}), params)
 	if err != nil {
 		return 0, false, err
 	}
@@ -304,25 +466,9 @@ func estimateScheduleBasedTravelTime(ctx context.Context, client *mbta.Client, o
 
 	// Process schedules to find trip legs
 	for _, schedule := range schedules {
-		// Extract trip ID from relationships
-		var tripID string
-		if tripRel, ok := schedule.Relationships["trip"]; ok {
-			if tripData, ok := tripRel.(map[string]interface{})["data"].(map[string]interface{}); ok {
-				if id, ok := tripData["id"].(string); ok {
-					tripID = id
-				}
-			}
-		}
-
-		// Extract stop ID from relationships
-		var stopID string
-		if stopRel, ok := schedule.Relationships["stop"]; ok {
-			if stopData, ok := stopRel.(map[string]interface{})["data"].(map[string]interface{}); ok {
-				if id, ok := stopData["id"].(string); ok {
-					stopID = id
-				}
-			}
-		}
+		// Extract trip and stop IDs from relationships
+		tripID := schedule.GetTripID()
+		stopID := schedule.GetStopID()
 
 		// Skip if missing key data
 		if tripID == "" || stopID == "" {
@@ -349,8 +495,8 @@ func estimateScheduleBasedTravelTime(ctx context.Context, client *mbta.Client, o
 	var validTrips int
 
 	for _, stopTimes := range tripStopTimes {
-		originTime, hasOrigin := stopTimes[originID]
-		destTime, hasDest := stopTimes[destinationID]
+		originTime, hasOrigin := firstStopTime(stopTimes, originIDs)
+		destTime, hasDest := firstStopTime(stopTimes, destinationIDs)
 
 		if hasOrigin && hasDest && destTime.After(originTime) {
 			// Calculate travel time in minutes
@@ -368,6 +514,16 @@ func estimateScheduleBasedTravelTime(ctx context.Context, client *mbta.Client, o
 	return 0, false, fmt.Errorf("no valid trips found between stops")
 }
 
+// firstStopTime returns a trip's time at the first of the stops it serves
+func firstStopTime(stopTimes map[string]time.Time, stopIDs []string) (time.Time, bool) {
+	for _, stopID := range stopIDs {
+		if t, ok := stopTimes[stopID]; ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // estimateTimeByRouteType estimates travel time based on route type and distance
 func estimateTimeByRouteType(distanceKm float64, routeType int) float64 {
 	// Approximate speeds based on route types
@@ -394,7 +550,205 @@ func estimateTimeByRouteType(distanceKm float64, routeType int) float64 {
 
 // formatTripPlanResponse converts a trip plan to a proper MCP response
 func formatTripPlanResponse(tripPlan *models.TripPlan) (*mcp.CallToolResult, error) {
-	// Convert the trip plan to a simplified format for the response
+	plan := tripPlanToMap(tripPlan)
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize trip plan data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// formatTripPlansResponse converts alternative trip plans, ranked by a
+// criterion, to a proper MCP response
+func formatTripPlansResponse(tripPlans []models.TripPlan, optimizeFor string) (*mcp.CallToolResult, error) {
+	if len(tripPlans) == 0 {
+		return createErrorResponse("No trip plans found"), nil
+	}
+
+	response := tripPlansToMap(tripPlans, optimizeFor)
+
+	// Create JSON string response
+	jsonBytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Failed to serialize trip plan data: %v", err)), nil
+	}
+
+	// Return data as a text content item
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonBytes),
+			},
+		},
+	}, nil
+}
+
+// tripPlansToMap converts alternative trip plans to a simplified format for
+// responses, labelling the fastest, the one with fewest transfers and the one
+// with least walking, and comparing them at a glance. Plans are recommended
+// by the given criterion, or the fastest if it's empty.
+func tripPlansToMap(tripPlans []models.TripPlan, optimizeFor string) map[string]interface{} {
+	if optimizeFor == "" {
+		optimizeFor = mbta.OptimizeFastest
+	}
+
+	// Find the plans that stand out, the first of any that tie. Arriving by
+	// a deadline, the fastest plan is the one leaving latest.
+	fewestTransfers, fastest, leastWalking := 0, 0, 0
+	walkingVaries := false
+	for i := range tripPlans {
+		if tripPlans[i].Transfers < tripPlans[fewestTransfers].Transfers {
+			fewestTransfers = i
+		}
+		if mbta.BetterTripPlan(&tripPlans[i], &tripPlans[fastest], mbta.OptimizeFastest) {
+			fastest = i
+		}
+		if tripPlans[i].WalkingTime() < tripPlans[leastWalking].WalkingTime() {
+			leastWalking = i
+		}
+		walkingVaries = walkingVaries || tripPlans[i].WalkingTime() != tripPlans[0].WalkingTime()
+	}
+
+	// Recommend the best plan clear of disruptions and missed connections
+	// when others run into them
+	recommended, troubled := -1, false
+	for i := range tripPlans {
+		if tripPlans[i].Disrupted || tripPlans[i].MissedConnection || tripPlans[i].ArrivesLate {
+			troubled = true
+		} else if recommended < 0 || mbta.BetterTripPlan(&tripPlans[i], &tripPlans[recommended], optimizeFor) {
+			recommended = i
+		}
+	}
+	if !troubled {
+		recommended = -1
+	}
+
+	itineraries := make([]map[string]interface{}, 0, len(tripPlans))
+	comparison := make([]map[string]interface{}, 0, len(tripPlans))
+	for i := range tripPlans {
+		plan := tripPlanToMap(&tripPlans[i])
+		plan["option"] = i + 1
+
+		// Every plan shares the same issues and detours, so they're listed once below
+		delete(plan, "accessibility_issues")
+		delete(plan, "detours")
+
+		labels := make([]string, 0, 2)
+		if i == fewestTransfers {
+			labels = append(labels, "fewest_transfers")
+		}
+		if i == fastest {
+			if tripPlans[i].ArriveBy != nil {
+				labels = append(labels, "latest_departure")
+			} else {
+				labels = append(labels, "fastest")
+			}
+		}
+		if i == leastWalking && walkingVaries {
+			labels = append(labels, "least_walking")
+		}
+		if i == recommended {
+			labels = append(labels, "recommended")
+		}
+		if tripPlans[i].Disrupted {
+			labels = append(labels, "disrupted")
+		}
+		if tripPlans[i].MissedConnection {
+			labels = append(labels, "missed_connection")
+		}
+		if tripPlans[i].ArrivesLate {
+			labels = append(labels, "arrives_late")
+		}
+		plan["labels"] = labels
+
+		itineraries = append(itineraries, plan)
+		comparison = append(comparison, tripPlanComparison(&tripPlans[i], i+1, labels))
+	}
+
+	response := map[string]interface{}{
+		"origin":          itineraries[0]["origin"],
+		"destination":     itineraries[0]["destination"],
+		"optimize_for":    optimizeFor,
+		"itinerary_count": len(itineraries),
+		"comparison":      comparison,
+		"itineraries":     itineraries,
+	}
+	// Tell riders arriving by a deadline when to leave: on the latest plan
+	// that still gets them there on time
+	if deadline := tripPlans[0].ArriveBy; deadline != nil {
+		response["arrive_by"] = deadline.Format(time.RFC3339)
+		leaving := fastest
+		if recommended >= 0 {
+			leaving = recommended
+		}
+		response["leave_by"] = tripPlans[leaving].DepartureTime.Format(time.RFC3339)
+	}
+	if issues := tripPlans[0].AccessibilityIssues; len(issues) > 0 {
+		response["accessibility_issues"] = formatAccessibilityIssues(issues)
+	}
+	if detours := tripPlans[0].Detours; len(detours) > 0 {
+		response["detours"] = formatDetours(detours)
+	}
+	return response
+}
+
+// tripPlanComparison summarizes a trip plan in a line for comparing it with
+// the others, such as "Leave 8:00 AM, arrive 8:25 AM (25 minutes), 1
+// transfer, 5 minutes walking: Red Line → Orange Line"
+func tripPlanComparison(tripPlan *models.TripPlan, option int, labels []string) map[string]interface{} {
+	var routes []string
+	for _, leg := range tripPlan.Legs {
+		if leg.IsWalk() {
+			continue
+		}
+		route := leg.RouteName
+		if route == "" {
+			route = leg.RouteID
+		}
+		routes = append(routes, route)
+	}
+	if len(routes) == 0 {
+		routes = []string{"Walk"}
+	}
+
+	duration := tripPlan.ArrivalTime.Sub(tripPlan.DepartureTime).Minutes()
+	walking := tripPlan.WalkingTime().Minutes()
+	summary := fmt.Sprintf("Leave %s, arrive %s (%s), %d transfer%s",
+		tripPlan.DepartureTime.Format("3:04 PM"), tripPlan.ArrivalTime.Format("3:04 PM"),
+		formatDuration(duration), tripPlan.Transfers, pluralize(tripPlan.Transfers))
+	if walking > 0 {
+		summary += fmt.Sprintf(", %s walking", formatDuration(walking))
+	}
+	summary += ": " + strings.Join(routes, " → ")
+
+	return map[string]interface{}{
+		"option":           option,
+		"labels":           labels,
+		"leave":            tripPlan.DepartureTime.Format("3:04 PM"),
+		"arrive":           tripPlan.ArrivalTime.Format("3:04 PM"),
+		"duration_minutes": duration,
+		"transfers":        tripPlan.Transfers,
+		"walking_minutes":  walking,
+		"routes":           routes,
+		"accessible":       tripPlan.AccessibleTrip,
+		"summary":          summary,
+	}
+}
+
+// tripPlanToMap converts a trip plan to a simplified format for responses
+func tripPlanToMap(tripPlan *models.TripPlan) map[string]interface{} {
 	plan := map[string]interface{}{
 		"origin": map[string]string{
 			"id":   tripPlan.Origin.ID,
@@ -409,13 +763,25 @@ func formatTripPlanResponse(tripPlan *models.TripPlan) (*mcp.CallToolResult, err
 		"duration_minutes":  tripPlan.Duration.Minutes(),
 		"total_distance_km": tripPlan.TotalDistance,
 		"accessible":        tripPlan.AccessibleTrip,
+		"disrupted":         tripPlan.Disrupted,
+		"missed_connection": tripPlan.MissedConnection,
+		"transfers":         tripPlan.Transfers,
 		"legs":              make([]interface{}, 0, len(tripPlan.Legs)),
 	}
 
+	// Plans arriving by a deadline say when to leave and how much time is left over
+	if tripPlan.ArriveBy != nil {
+		plan["arrive_by"] = tripPlan.ArriveBy.Format(time.RFC3339)
+		plan["leave_by"] = tripPlan.DepartureTime.Format(time.RFC3339)
+		plan["minutes_to_spare"] = tripPlan.ArriveBy.Sub(tripPlan.ArrivalTime).Minutes()
+		plan["arrives_late"] = tripPlan.ArrivesLate
+	}
+
 	// Convert each leg
 	for i, leg := range tripPlan.Legs {
 		legMap := map[string]interface{}{
 			"leg_number": i + 1,
+			"mode":       leg.Mode,
 			"origin": map[string]string{
 				"id":   leg.Origin.ID,
 				"name": leg.Origin.Attributes.Name,
@@ -449,11 +815,62 @@ func formatTripPlanResponse(tripPlan *models.TripPlan) (*mcp.CallToolResult, err
 			legMap["formatted_predicted_arrival"] = leg.PredictedTimes.PredictedArrival.Format("3:04 PM")
 		}
 
+		// Flag legs riders can't count on
+		if leg.Cancelled {
+			legMap["cancelled"] = true
+		}
+		if leg.MissedConnection {
+			legMap["missed_connection"] = true
+		}
+
+		// Add alerts affecting the leg
+		if len(leg.Alerts) > 0 {
+			alerts := make([]map[string]interface{}, 0, len(leg.Alerts))
+			for _, alert := range leg.Alerts {
+				alerts = append(alerts, map[string]interface{}{
+					"id":         alert.ID,
+					"effect":     alert.Attributes.Effect,
+					"header":     alert.Attributes.Header,
+					"severity":   alert.Attributes.Severity,
+					"disruption": alert.IsDisruption(),
+				})
+			}
+			legMap["alerts"] = alerts
+		}
+
 		plan["legs"] = append(plan["legs"].([]interface{}), legMap)
 	}
 
+	if len(tripPlan.AccessibilityIssues) > 0 {
+		plan["accessibility_issues"] = formatAccessibilityIssues(tripPlan.AccessibilityIssues)
+	}
+	if len(tripPlan.Detours) > 0 {
+		plan["detours"] = formatDetours(tripPlan.Detours)
+	}
+
+	return plan
+}
+
+// formatInaccessibleTripResponse explains why no accessible trip was found,
+// suggesting accessible stations to travel from or to instead
+func formatInaccessibleTripResponse(origin, destination *models.Stop, inaccessible *mbta.InaccessibleTripError) (*mcp.CallToolResult, error) {
+	response := map[string]interface{}{
+		"origin": map[string]string{
+			"id":   origin.ID,
+			"name": origin.Attributes.Name,
+		},
+		"destination": map[string]string{
+			"id":   destination.ID,
+			"name": destination.Attributes.Name,
+		},
+		"itinerary_count":      0,
+		"itineraries":          []interface{}{},
+		"message":              "No wheelchair accessible trip found. Trips are possible, but only through stations with elevator outages.",
+		"accessibility_issues": formatAccessibilityIssues(inaccessible.Issues),
+	}
+
 	// Create JSON string response
-	jsonBytes, err := json.MarshalIndent(plan, "", "  ")
+	jsonBytes, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to serialize trip plan data: %v", err)), nil
 	}
@@ -469,6 +886,35 @@ func formatTripPlanResponse(tripPlan *models.TripPlan) (*mcp.CallToolResult, err
 	}, nil
 }
 
+// formatAccessibilityIssues converts the outages that ruled out itineraries
+// to a simplified format, with the accessible station to use instead
+func formatAccessibilityIssues(issues []models.AccessibilityIssue) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(issues))
+	for _, issue := range issues {
+		issueMap := map[string]interface{}{
+			"stop": map[string]string{
+				"id":   issue.Stop.ID,
+				"name": issue.Stop.Attributes.Name,
+			},
+			"role":     issue.Role,
+			"route_id": issue.RouteID,
+			"reason":   issue.Reason,
+		}
+		if issue.Alert != nil {
+			issueMap["alert_id"] = issue.Alert.ID
+		}
+		if issue.Alternative != nil {
+			issueMap["alternative"] = map[string]interface{}{
+				"id":          issue.Alternative.Stop.ID,
+				"name":        issue.Alternative.Stop.Attributes.Name,
+				"distance_km": math.Round(issue.Alternative.DistanceKm*100) / 100,
+			}
+		}
+		result = append(result, issueMap)
+	}
+	return result
+}
+
 // formatTransferPointsResponse converts transfer points to a proper MCP response
 func formatTransferPointsResponse(transferPoints []models.TransferPoint) (*mcp.CallToolResult, error) {
 	// Convert the transfer points to a simplified format for the response
@@ -492,6 +938,15 @@ func formatTransferPointsResponse(transferPoints []models.TransferPoint) (*mcp.C
 			transferMap["suggested_wait_time"] = transfer.SuggestedWaitTime.Minutes()
 		}
 
+		// Walking transfers name the stop to walk to
+		transferMap["walking"] = transfer.IsWalking()
+		if transfer.IsWalking() {
+			transferMap["walk_to_stop_id"] = transfer.ToStop.ID
+			transferMap["walk_to_stop_name"] = transfer.ToStop.Attributes.Name
+			transferMap["walk_distance_km"] = transfer.WalkDistance
+			transferMap["walking_minutes"] = transfer.MinTransferTime.Minutes()
+		}
+
 		transferData = append(transferData, transferMap)
 	}
 
@@ -514,6 +969,9 @@ func formatTransferPointsResponse(transferPoints []models.TransferPoint) (*mcp.C
 
 // formatTravelTimeResponse converts travel time estimate to a proper MCP response
 func formatTravelTimeResponse(origin, destination *models.Stop, distanceKm, timeMinutes float64, source string) (*mcp.CallToolResult, error) {
+	// Arrival times are given in Boston time, wherever the server runs
+	arrival := time.Now().In(mbta.ServiceLocation()).Add(time.Duration(timeMinutes) * time.Minute)
+
 	// Create response data
 	estimateData := map[string]interface{}{
 		"origin": map[string]string{
@@ -528,8 +986,8 @@ func formatTravelTimeResponse(origin, destination *models.Stop, distanceKm, time
 		"estimated_minutes":      timeMinutes,
 		"formatted_time":         formatDuration(timeMinutes),
 		"estimation_source":      source,
-		"estimated_arrival":      time.Now().Add(time.Duration(timeMinutes) * time.Minute).Format(time.RFC3339),
-		"formatted_arrival":      time.Now().Add(time.Duration(timeMinutes) * time.Minute).Format("3:04 PM"),
+		"estimated_arrival":      arrival.Format(time.RFC3339),
+		"formatted_arrival":      arrival.Format("3:04 PM"),
 		"origin_municipality":    origin.Attributes.Municipality,
 		"dest_municipality":      destination.Attributes.Municipality,
 		"origin_location_type":   models.GetLocationTypeDescription(origin.Attributes.LocationType),
@@ -1,10 +1,15 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -135,6 +140,575 @@ func TestFormatTripPlanResponse(t *testing.T) {
 	}
 }
 
+func TestFormatTripPlansResponse(t *testing.T) {
+	now := time.Now()
+	origin := &models.Stop{ID: "place-alfcl", Attributes: models.StopAttributes{Name: "Alewife"}}
+	destination := &models.Stop{ID: "place-bbsta", Attributes: models.StopAttributes{Name: "Back Bay"}}
+
+	leg := func(routeID string, departure, arrival time.Time) models.TripLeg {
+		return models.TripLeg{
+			Origin:        origin,
+			Destination:   destination,
+			RouteID:       routeID,
+			DepartureTime: departure,
+			ArrivalTime:   arrival,
+			Duration:      arrival.Sub(departure),
+		}
+	}
+
+	tripPlans := []models.TripPlan{
+		{
+			Origin:        origin,
+			Destination:   destination,
+			DepartureTime: now,
+			ArrivalTime:   now.Add(50 * time.Minute),
+			Duration:      50 * time.Minute,
+			Legs:          []models.TripLeg{leg("Red", now, now.Add(50*time.Minute))},
+		},
+		{
+			Origin:        origin,
+			Destination:   destination,
+			DepartureTime: now,
+			ArrivalTime:   now.Add(35 * time.Minute),
+			Duration:      35 * time.Minute,
+			Transfers:     1,
+			Legs: []models.TripLeg{
+				leg("Red", now, now.Add(15*time.Minute)),
+				leg("Orange", now.Add(20*time.Minute), now.Add(35*time.Minute)),
+			},
+		},
+	}
+
+	response, err := formatTripPlansResponse(tripPlans, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.IsError {
+		t.Fatalf("Response indicates an error: %v", response)
+	}
+
+	textContent, ok := response.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("Expected TextContent, got %T", response.Content[0])
+	}
+
+	var responseData struct {
+		Origin         map[string]string        `json:"origin"`
+		ItineraryCount int                      `json:"itinerary_count"`
+		Itineraries    []map[string]interface{} `json:"itineraries"`
+	}
+	if err := json.Unmarshal([]byte(textContent.Text), &responseData); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+
+	if responseData.Origin["id"] != "place-alfcl" {
+		t.Errorf("Expected origin ID 'place-alfcl', got '%v'", responseData.Origin["id"])
+	}
+	if responseData.ItineraryCount != 2 || len(responseData.Itineraries) != 2 {
+		t.Fatalf("Expected 2 itineraries, got %d", len(responseData.Itineraries))
+	}
+
+	first, second := responseData.Itineraries[0], responseData.Itineraries[1]
+	if labels, _ := first["labels"].([]interface{}); len(labels) != 1 || labels[0] != "fewest_transfers" {
+		t.Errorf("Expected first itinerary labelled fewest_transfers, got %v", first["labels"])
+	}
+	if labels, _ := second["labels"].([]interface{}); len(labels) != 1 || labels[0] != "fastest" {
+		t.Errorf("Expected second itinerary labelled fastest, got %v", second["labels"])
+	}
+	if transfers, _ := second["transfers"].(float64); transfers != 1 {
+		t.Errorf("Expected 1 transfer, got %v", second["transfers"])
+	}
+	if legs, _ := second["legs"].([]interface{}); len(legs) != 2 {
+		t.Errorf("Expected 2 legs, got %v", second["legs"])
+	}
+
+	// A single plan is both the fastest and has the fewest transfers
+	response, err = formatTripPlansResponse(tripPlans[:1], "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := response.Content[0].(mcp.TextContent).Text; !strings.Contains(text, `"fewest_transfers"`) || !strings.Contains(text, `"fastest"`) {
+		t.Errorf("Expected a single itinerary to carry both labels, got %s", text)
+	}
+
+	// No plans is an error
+	if response, _ := formatTripPlansResponse(nil, ""); !response.IsError {
+		t.Error("Expected an error response for no plans")
+	}
+}
+
+func TestFormatTripPlansResponseDisruptions(t *testing.T) {
+	now := time.Now()
+	origin := &models.Stop{ID: "place-alfcl", Attributes: models.StopAttributes{Name: "Alewife"}}
+	destination := &models.Stop{ID: "place-sstat", Attributes: models.StopAttributes{Name: "South Station"}}
+	plan := func(routeID string, minutes int, alerts ...models.Alert) models.TripPlan {
+		arrival := now.Add(time.Duration(minutes) * time.Minute)
+		p := models.TripPlan{
+			Origin:        origin,
+			Destination:   destination,
+			DepartureTime: now,
+			ArrivalTime:   arrival,
+			Legs:          []models.TripLeg{{Origin: origin, Destination: destination, RouteID: routeID, DepartureTime: now, ArrivalTime: arrival, Alerts: alerts}},
+		}
+		for _, alert := range alerts {
+			p.Disrupted = p.Disrupted || alert.IsDisruption()
+		}
+		return p
+	}
+	delays := models.Alert{ID: "delays-red", Attributes: models.AlertAttributes{Effect: models.AlertEffectSignificantDelays, Header: "Red Line delays", Severity: 7}}
+
+	response, err := formatTripPlansResponse([]models.TripPlan{plan("Orange", 45), plan("Red", 30, delays)}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var responseData struct {
+		Itineraries []struct {
+			Labels    []string `json:"labels"`
+			Disrupted bool     `json:"disrupted"`
+			Legs      []struct {
+				Alerts []map[string]interface{} `json:"alerts"`
+			} `json:"legs"`
+		} `json:"itineraries"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].(mcp.TextContent).Text), &responseData); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+
+	first, second := responseData.Itineraries[0], responseData.Itineraries[1]
+	if strings.Join(first.Labels, ",") != "fewest_transfers,recommended" || first.Disrupted {
+		t.Errorf("Expected the undisrupted plan to be recommended, got %v", first.Labels)
+	}
+	if strings.Join(second.Labels, ",") != "fastest,disrupted" || !second.Disrupted {
+		t.Errorf("Expected the delayed plan to be flagged, got %v", second.Labels)
+	}
+	if alerts := second.Legs[0].Alerts; len(alerts) != 1 || alerts[0]["id"] != "delays-red" || alerts[0]["disruption"] != true {
+		t.Errorf("Expected the leg to carry delays-red, got %v", alerts)
+	}
+
+	// A missed connection counts against a plan too
+	late := plan("Red", 30)
+	late.MissedConnection = true
+	late.Legs[0].MissedConnection = true
+	response, _ = formatTripPlansResponse([]models.TripPlan{plan("Orange", 45), late}, "")
+	if text := response.Content[0].(mcp.TextContent).Text; !strings.Contains(text, `"recommended"`) || !strings.Contains(text, `"missed_connection": true`) {
+		t.Errorf("Expected the missed connection to be flagged, got %s", text)
+	}
+
+	// Without disruptions nothing is recommended over the others
+	response, _ = formatTripPlansResponse([]models.TripPlan{plan("Orange", 45)}, "")
+	if text := response.Content[0].(mcp.TextContent).Text; strings.Contains(text, "recommended") || strings.Contains(text, `"alerts"`) {
+		t.Errorf("Expected no recommendation or alerts, got %s", text)
+	}
+}
+
+// outageTrips answers trip plans with fixed results
+type outageTrips struct {
+	mbta.TripDataSource
+	plans []models.TripPlan
+	err   error
+}
+
+func (o *outageTrips) PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error) {
+	return o.plans, o.err
+}
+
+func TestPlanTripAccessibilityIssues(t *testing.T) {
+	downtown := &models.Stop{ID: "70020", Attributes: models.StopAttributes{Name: "Downtown Crossing"}}
+	issue := models.AccessibilityIssue{
+		Stop:    downtown,
+		Role:    models.StopRoleTransfer,
+		RouteID: "Red",
+		Reason:  "Elevator outage at Downtown Crossing prevents transferring",
+		Alert:   &models.Alert{ID: "elevator-dwnxg"},
+		Alternative: &models.NearbyStation{
+			Stop:       models.Stop{ID: "place-pktrm", Attributes: models.StopAttributes{Name: "Park Street"}},
+			DistanceKm: 0.214,
+		},
+	}
+
+	trips := &outageTrips{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	args := map[string]any{
+		"origin_stop_id":        "place-sstat",
+		"destination_stop_id":   "place-harsq",
+		"wheelchair_accessible": true,
+	}
+
+	t.Run("No accessible trip", func(t *testing.T) {
+		trips.err = &mbta.InaccessibleTripError{Origin: "place-sstat", Destination: "place-harsq", Issues: []models.AccessibilityIssue{issue}}
+
+		text, isError := callTool(t, server.planTripHandler, args)
+		if isError {
+			t.Fatalf("Expected an explanation, got error %s", text)
+		}
+
+		var response struct {
+			ItineraryCount int                      `json:"itinerary_count"`
+			Issues         []map[string]interface{} `json:"accessibility_issues"`
+		}
+		if err := json.Unmarshal([]byte(text), &response); err != nil {
+			t.Fatalf("Failed to parse response JSON: %v", err)
+		}
+		if response.ItineraryCount != 0 || len(response.Issues) != 1 {
+			t.Fatalf("Expected no itineraries and 1 issue, got %s", text)
+		}
+		if response.Issues[0]["alert_id"] != "elevator-dwnxg" || response.Issues[0]["role"] != "transfer" {
+			t.Errorf("Expected the transfer outage, got %v", response.Issues[0])
+		}
+		alternative, _ := response.Issues[0]["alternative"].(map[string]interface{})
+		if alternative["id"] != "place-pktrm" || alternative["distance_km"] != 0.21 {
+			t.Errorf("Expected Park Street 0.21 km away, got %v", alternative)
+		}
+	})
+
+	t.Run("Plans around outages", func(t *testing.T) {
+		now := time.Now()
+		plan := models.TripPlan{
+			Origin:              downtown,
+			Destination:         downtown,
+			DepartureTime:       now,
+			ArrivalTime:         now.Add(20 * time.Minute),
+			Legs:                []models.TripLeg{{Origin: downtown, Destination: downtown, DepartureTime: now, ArrivalTime: now.Add(20 * time.Minute)}},
+			AccessibilityIssues: []models.AccessibilityIssue{issue},
+		}
+		trips.plans, trips.err = []models.TripPlan{plan, plan}, nil
+
+		text, isError := callTool(t, server.planTripHandler, args)
+		if isError {
+			t.Fatalf("Unexpected error %s", text)
+		}
+		if count := strings.Count(text, `"elevator-dwnxg"`); count != 1 {
+			t.Errorf("Expected the issue listed once, got %d times in %s", count, text)
+		}
+	})
+}
+
+func TestPlanTripArriveBy(t *testing.T) {
+	trips := &plannedTrips{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	callTool(t, server.planTripHandler, map[string]any{
+		"origin_stop_id":      "South Station",
+		"destination_stop_id": "Harvard",
+		"arrive_by":           "2025-05-20T09:00:00-04:00",
+	})
+	if arriveBy, _ := trips.options["arrive_by"].(bool); !arriveBy {
+		t.Errorf("Expected an arrive-by search, got options %v", trips.options)
+	}
+	if deadline, _ := time.Parse(time.RFC3339, "2025-05-20T09:00:00-04:00"); !trips.at.Equal(deadline) {
+		t.Errorf("Expected to search back from 09:00, got %s", trips.at)
+	}
+
+	callTool(t, server.planTripHandler, map[string]any{
+		"origin_stop_id":      "South Station",
+		"destination_stop_id": "Harvard",
+		"departure_time":      "2025-05-20T08:00:00-04:00",
+	})
+	if arriveBy, _ := trips.options["arrive_by"].(bool); arriveBy {
+		t.Error("Expected a departure search without arrive_by")
+	}
+
+	errorTests := []struct {
+		name     string
+		args     map[string]any
+		expected string
+	}{
+		{"both times", map[string]any{"arrive_by": "2025-05-20T09:00:00-04:00", "departure_time": "2025-05-20T08:00:00-04:00"}, "either departure_time or arrive_by"},
+		{"invalid time", map[string]any{"arrive_by": "9am"}, "Invalid arrive_by format"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["origin_stop_id"], tt.args["destination_stop_id"] = "South Station", "Harvard"
+			text, isError := callTool(t, server.planTripHandler, tt.args)
+			if !isError || !strings.Contains(text, tt.expected) {
+				t.Errorf("Expected an error containing %q, got %s", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestFormatTripPlansResponseArriveBy(t *testing.T) {
+	deadline := time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC)
+	stop := &models.Stop{ID: "place-sstat", Attributes: models.StopAttributes{Name: "South Station"}}
+	plan := func(leave, arrive string, late bool) models.TripPlan {
+		departure, _ := time.Parse(time.RFC3339, "2025-05-20T"+leave+":00Z")
+		arrival, _ := time.Parse(time.RFC3339, "2025-05-20T"+arrive+":00Z")
+		return models.TripPlan{
+			Origin:        stop,
+			Destination:   stop,
+			DepartureTime: departure,
+			ArrivalTime:   arrival,
+			ArriveBy:      &deadline,
+			ArrivesLate:   late,
+			Legs:          []models.TripLeg{{Origin: stop, Destination: stop, DepartureTime: departure, ArrivalTime: arrival}},
+		}
+	}
+
+	// The plan leaving latest is running late, so riders should leave earlier
+	response, err := formatTripPlansResponse([]models.TripPlan{plan("08:20", "08:50", false), plan("08:30", "08:58", true)}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Content[0].(mcp.TextContent).Text), &data); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if data["arrive_by"] != "2025-05-20T09:00:00Z" || data["leave_by"] != "2025-05-20T08:20:00Z" {
+		t.Errorf("Expected to leave by 08:20 to arrive by 09:00, got %v and %v", data["leave_by"], data["arrive_by"])
+	}
+
+	itineraries := data["itineraries"].([]interface{})
+	first := itineraries[0].(map[string]interface{})
+	if first["leave_by"] != "2025-05-20T08:20:00Z" || first["minutes_to_spare"] != 10.0 {
+		t.Errorf("Expected to leave by 08:20 with 10 minutes to spare, got %v and %v", first["leave_by"], first["minutes_to_spare"])
+	}
+	last := itineraries[1].(map[string]interface{})
+	labels := fmt.Sprint(last["labels"])
+	if !strings.Contains(labels, "latest_departure") || !strings.Contains(labels, "arrives_late") || strings.Contains(labels, "fastest") {
+		t.Errorf("Expected the last plan to leave latest and arrive late, got %s", labels)
+	}
+}
+
+func TestPlanTripOptimizeFor(t *testing.T) {
+	now := time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC)
+	stop := &models.Stop{ID: "place-sstat", Attributes: models.StopAttributes{Name: "South Station"}}
+	plan := func(routeName string, leave, arrive, transfers, walking int, accessible bool) models.TripPlan {
+		departure, arrival := now.Add(time.Duration(leave)*time.Minute), now.Add(time.Duration(arrive)*time.Minute)
+		p := models.TripPlan{
+			Origin:         stop,
+			Destination:    stop,
+			DepartureTime:  departure,
+			ArrivalTime:    arrival,
+			Transfers:      transfers,
+			AccessibleTrip: accessible,
+			Legs:           []models.TripLeg{{Mode: models.LegModeTransit, Origin: stop, Destination: stop, RouteName: routeName, DepartureTime: departure, ArrivalTime: arrival}},
+		}
+		if walking > 0 {
+			p.Legs = append(p.Legs, models.TripLeg{Mode: models.LegModeWalk, Origin: stop, Destination: stop, Duration: time.Duration(walking) * time.Minute})
+		}
+		return p
+	}
+
+	trips := &outageTrips{plans: []models.TripPlan{
+		plan("Red Line", 5, 40, 0, 0, false),
+		plan("Orange Line", 0, 25, 1, 5, true),
+		plan("Green Line", 12, 40, 1, 2, true),
+	}}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	type comparison struct {
+		Labels  []string `json:"labels"`
+		Routes  []string `json:"routes"`
+		Summary string   `json:"summary"`
+	}
+	tests := []struct {
+		optimizeFor string
+		expected    string
+	}{
+		{"", "Orange Line,Green Line,Red Line"},
+		{"fewest_transfers", "Red Line,Orange Line,Green Line"},
+		{"least_walking", "Red Line,Green Line,Orange Line"},
+		{"accessible", "Orange Line,Green Line"},
+	}
+	for _, tt := range tests {
+		t.Run("optimize for "+tt.optimizeFor, func(t *testing.T) {
+			args := map[string]any{"origin_stop_id": "South Station", "destination_stop_id": "Harvard"}
+			if tt.optimizeFor != "" {
+				args["optimize_for"] = tt.optimizeFor
+			}
+			text, isError := callTool(t, server.planTripHandler, args)
+			if isError {
+				t.Fatalf("Unexpected error %s", text)
+			}
+
+			var response struct {
+				OptimizeFor string       `json:"optimize_for"`
+				Comparison  []comparison `json:"comparison"`
+			}
+			if err := json.Unmarshal([]byte(text), &response); err != nil {
+				t.Fatalf("Failed to parse response JSON: %v", err)
+			}
+			routes := make([]string, 0, len(response.Comparison))
+			for _, option := range response.Comparison {
+				routes = append(routes, option.Routes[0])
+			}
+			if got := strings.Join(routes, ","); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+			if tt.optimizeFor != "" && response.OptimizeFor != tt.optimizeFor {
+				t.Errorf("Expected optimize_for %s, got %s", tt.optimizeFor, response.OptimizeFor)
+			}
+		})
+	}
+
+	// Each option is summarized in a line and labelled for what it's best at
+	text, _ := callTool(t, server.planTripHandler, map[string]any{"origin_stop_id": "South Station", "destination_stop_id": "Harvard"})
+	var response struct {
+		Comparison []comparison `json:"comparison"`
+	}
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+	fastest := response.Comparison[0]
+	if fastest.Summary != "Leave 8:00 AM, arrive 8:25 AM (25 minutes), 1 transfer, 5 minutes walking: Orange Line" {
+		t.Errorf("Unexpected summary %q", fastest.Summary)
+	}
+	if labels := strings.Join(fastest.Labels, ","); labels != "fastest" {
+		t.Errorf("Expected the first option labelled fastest, got %s", labels)
+	}
+	if labels := strings.Join(response.Comparison[2].Labels, ","); labels != "fewest_transfers,least_walking" {
+		t.Errorf("Expected the last option to have fewest transfers and least walking, got %s", labels)
+	}
+
+	// Ranking for accessibility only plans accessible trips
+	planned := &plannedTrips{}
+	server, err = New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: planned}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	callTool(t, server.planTripHandler, map[string]any{"origin_stop_id": "South Station", "destination_stop_id": "Harvard", "optimize_for": "accessible", "departures": float64(2)})
+	if accessible, _ := planned.options["wheelchair_accessible"].(bool); !accessible {
+		t.Errorf("Expected an accessible search, got options %v", planned.options)
+	}
+	if departures, _ := planned.options["departures"].(int); departures != 2 {
+		t.Errorf("Expected 2 departures, got %v", planned.options["departures"])
+	}
+	callTool(t, server.planTripHandler, map[string]any{"origin_stop_id": "South Station", "destination_stop_id": "Harvard"})
+	if departures, _ := planned.options["departures"].(int); departures != 3 {
+		t.Errorf("Expected 3 departures by default, got %v", planned.options["departures"])
+	}
+
+	errorTests := []struct {
+		name     string
+		args     map[string]any
+		expected string
+	}{
+		{"unknown criterion", map[string]any{"optimize_for": "scenic"}, "Invalid optimize_for"},
+		{"too many departures", map[string]any{"departures": float64(10)}, "Invalid departures"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["origin_stop_id"], tt.args["destination_stop_id"] = "South Station", "Harvard"
+			text, isError := callTool(t, server.planTripHandler, tt.args)
+			if !isError || !strings.Contains(text, tt.expected) {
+				t.Errorf("Expected an error containing %q, got %s", tt.expected, text)
+			}
+		})
+	}
+}
+
+// fixedGeocoder finds every address at the same place
+type fixedGeocoder struct {
+	location mbta.Location
+	queries  []string
+}
+
+func (g *fixedGeocoder) Geocode(ctx context.Context, query string) (*mbta.Location, error) {
+	g.queries = append(g.queries, query)
+	location := g.location
+	return &location, nil
+}
+
+func TestPlanTripLocations(t *testing.T) {
+	trips := &plannedTrips{}
+	server, err := New(&config.Config{
+		Timeout:    30 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}, WithTransitAPI(mbta.CompositeAPI{StopDataSource: newNamedStops(), TripDataSource: trips}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	t.Run("Coordinates and address", func(t *testing.T) {
+		callTool(t, server.planTripHandler, map[string]any{
+			"origin_latitude":     42.3505,
+			"origin_longitude":    -71.0580,
+			"destination_address": "MFA",
+		})
+		if trips.origin != mbta.OriginLocationID || trips.destination != mbta.DestinationLocationID {
+			t.Errorf("Expected a trip between locations, got %s to %s", trips.origin, trips.destination)
+		}
+		if origin, ok := trips.options["origin_location"].(mbta.Location); !ok || origin.Latitude != 42.3505 || origin.Longitude != -71.0580 {
+			t.Errorf("Expected the origin coordinates, got %v", trips.options["origin_location"])
+		}
+		if destination, ok := trips.options["destination_location"].(mbta.Location); !ok || destination.Name != "Museum of Fine Arts" {
+			t.Errorf("Expected the geocoded destination, got %v", trips.options["destination_location"])
+		}
+	})
+
+	t.Run("Stop and address", func(t *testing.T) {
+		callTool(t, server.planTripHandler, map[string]any{
+			"origin_stop_id":      "South Station",
+			"destination_address": "Fenway Park",
+		})
+		if trips.origin != "place-sstat" || trips.destination != mbta.DestinationLocationID {
+			t.Errorf("Expected a trip from place-sstat to a location, got %s to %s", trips.origin, trips.destination)
+		}
+		if _, ok := trips.options["origin_location"]; ok {
+			t.Error("Expected no origin location when starting at a stop")
+		}
+	})
+
+	t.Run("Custom geocoder", func(t *testing.T) {
+		geocoder := &fixedGeocoder{location: mbta.Location{Name: "1 Main St", Latitude: 42.36, Longitude: -71.08}}
+		WithGeocoder(geocoder)(server)
+		defer WithGeocoder(mbta.DefaultGazetteer())(server)
+
+		callTool(t, server.planTripHandler, map[string]any{
+			"origin_address":      "1 Main Street, Cambridge",
+			"destination_stop_id": "Harvard",
+		})
+		if len(geocoder.queries) != 1 || geocoder.queries[0] != "1 Main Street, Cambridge" {
+			t.Errorf("Expected the address to be geocoded, got %v", geocoder.queries)
+		}
+		if origin, ok := trips.options["origin_location"].(mbta.Location); !ok || origin.Name != "1 Main St" {
+			t.Errorf("Expected the geocoded origin, got %v", trips.options["origin_location"])
+		}
+	})
+
+	errorTests := []struct {
+		name     string
+		args     map[string]any
+		expected string
+	}{
+		{"missing origin", map[string]any{"destination_stop_id": "Harvard"}, "origin: provide origin_stop_id"},
+		{"half coordinates", map[string]any{"origin_latitude": 42.35, "destination_stop_id": "Harvard"}, "both origin_latitude and origin_longitude"},
+		{"out of range", map[string]any{"origin_stop_id": "Harvard", "destination_latitude": 142.0, "destination_longitude": -71.0}, "out of range"},
+		{"unknown address", map[string]any{"origin_address": "123 Nowhere Road", "destination_stop_id": "Harvard"}, "origin address: no place found"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := callTool(t, server.planTripHandler, tt.args)
+			if !isError || !strings.Contains(text, tt.expected) {
+				t.Errorf("Expected an error containing %q, got %s", tt.expected, text)
+			}
+		})
+	}
+}
+
 func TestFormatTransferPointsResponse(t *testing.T) {
 	// Create sample transfer points
 	transferPoints := []models.TransferPoint{
@@ -215,6 +789,47 @@ func TestFormatTransferPointsResponse(t *testing.T) {
 	}
 }
 
+func TestFormatTransferPointsResponseWalking(t *testing.T) {
+	transferPoints := []models.TransferPoint{
+		{
+			Stop:            &models.Stop{ID: "70077", Attributes: models.StopAttributes{Name: "Downtown Crossing"}},
+			ToStop:          &models.Stop{ID: "70196", Attributes: models.StopAttributes{Name: "Park Street"}},
+			FromRoute:       "Orange",
+			ToRoute:         "Green-D",
+			TransferType:    models.TransferTypeMinimum,
+			MinTransferTime: 4 * time.Minute,
+			WalkDistance:    0.2,
+		},
+	}
+
+	response, err := formatTransferPointsResponse(transferPoints)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var transferData []map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Content[0].(mcp.TextContent).Text), &transferData); err != nil {
+		t.Fatalf("Failed to parse response JSON: %v", err)
+	}
+	if len(transferData) != 1 {
+		t.Fatalf("Expected 1 transfer point, got %d", len(transferData))
+	}
+
+	transfer := transferData[0]
+	if walking, ok := transfer["walking"].(bool); !ok || !walking {
+		t.Errorf("Expected walking true, got %v", transfer["walking"])
+	}
+	if transfer["walk_to_stop_id"] != "70196" || transfer["walk_to_stop_name"] != "Park Street" {
+		t.Errorf("Expected a walk to Park Street, got %v (%v)", transfer["walk_to_stop_name"], transfer["walk_to_stop_id"])
+	}
+	if transfer["walk_distance_km"] != 0.2 {
+		t.Errorf("Expected walk_distance_km 0.2, got %v", transfer["walk_distance_km"])
+	}
+	if transfer["walking_minutes"] != 4.0 {
+		t.Errorf("Expected walking_minutes 4, got %v", transfer["walking_minutes"])
+	}
+}
+
 func TestFormatTravelTimeResponse(t *testing.T) {
 	// Create sample stops
 	origin := &models.Stop{
@@ -309,6 +924,15 @@ func TestFormatTravelTimeResponse(t *testing.T) {
 	if formatted, ok := responseData["formatted_time"].(string); !ok || formatted != "15 minutes" {
 		t.Errorf("Expected formatted_time '15 minutes', got '%v'", responseData["formatted_time"])
 	}
+
+	// Arrival is in Boston time wherever the server runs
+	arrival, err := time.Parse(time.RFC3339, fmt.Sprint(responseData["estimated_arrival"]))
+	if err != nil {
+		t.Fatalf("Failed to parse estimated_arrival: %v", err)
+	}
+	if _, offset := arrival.Zone(); offset != -4*60*60 && offset != -5*60*60 {
+		t.Errorf("Expected a Boston offset, got %s", arrival)
+	}
 }
 
 func TestFormatDuration(t *testing.T) {
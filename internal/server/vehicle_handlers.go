@@ -10,7 +10,6 @@ import (
 	"log"
 	"strconv"
 
-	"github.com/crdant/mbta-mcp-server/pkg/mbta"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -121,11 +120,8 @@ func (s *Server) registerVehicleTrackingTools() {
 func (s *Server) getVehiclesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for vehicles: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	params := make(map[string]string)
 
 	// Process route_id filter
@@ -213,7 +209,7 @@ func (s *Server) getVehiclesHandler(ctx context.Context, request mcp.CallToolReq
 	}
 
 	// Request vehicles with the specified filters
-	vehicles, err := client.GetVehicles(ctx, params)
+	vehicles, err := s.api.GetVehicles(ctx, params)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve vehicles: %v", err)), nil
 	}
@@ -228,11 +224,8 @@ func (s *Server) getVehiclesHandler(ctx context.Context, request mcp.CallToolReq
 func (s *Server) getVehicleHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for vehicle: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract vehicle ID parameter
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	vehicleID, ok := args["vehicle_id"]
 	if !ok {
 		return createErrorResponse("Missing required parameter: vehicle_id"), nil
@@ -246,7 +239,7 @@ func (s *Server) getVehicleHandler(ctx context.Context, request mcp.CallToolRequ
 	log.Printf("Retrieving vehicle with ID: %s", vehicleIDStr)
 
 	// Get the specified vehicle
-	vehicle, err := client.GetVehicle(ctx, vehicleIDStr)
+	vehicle, err := s.api.GetVehicle(ctx, vehicleIDStr)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve vehicle %s: %v", vehicleIDStr, err)), nil
 	}
@@ -259,11 +252,8 @@ func (s *Server) getVehicleHandler(ctx context.Context, request mcp.CallToolRequ
 func (s *Server) getVehiclePredictionsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for vehicle predictions: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract vehicle ID parameter
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	vehicleID, ok := args["vehicle_id"]
 	if !ok {
 		return createErrorResponse("Missing required parameter: vehicle_id"), nil
@@ -277,7 +267,7 @@ func (s *Server) getVehiclePredictionsHandler(ctx context.Context, request mcp.C
 	log.Printf("Retrieving predictions for vehicle ID: %s", vehicleIDStr)
 
 	// Get predictions for the specified vehicle
-	predictions, err := client.GetPredictionsByVehicle(ctx, vehicleIDStr)
+	predictions, err := s.api.GetPredictions(ctx, map[string]string{"filter[vehicle]": vehicleIDStr})
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve predictions for vehicle %s: %v", vehicleIDStr, err)), nil
 	}
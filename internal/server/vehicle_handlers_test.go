@@ -42,11 +42,9 @@ func TestGetVehiclesHandler(t *testing.T) {
 		// Create a request for the vehicles handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_vehicles",
 				Arguments: map[string]any{},
@@ -103,11 +101,9 @@ func TestGetVehiclesHandler(t *testing.T) {
 		// Create a request with route filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicles",
 				Arguments: map[string]any{
@@ -134,11 +130,9 @@ func TestGetVehiclesHandler(t *testing.T) {
 		// Create a request with trip filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicles",
 				Arguments: map[string]any{
@@ -165,11 +159,9 @@ func TestGetVehiclesHandler(t *testing.T) {
 		// Create a request with location filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicles",
 				Arguments: map[string]any{
@@ -219,11 +211,9 @@ func TestGetVehicleHandler(t *testing.T) {
 		// Create a request for the vehicle handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle",
 				Arguments: map[string]any{
@@ -275,11 +265,9 @@ func TestGetVehicleHandler(t *testing.T) {
 		// Create a request with an invalid vehicle ID
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle",
 				Arguments: map[string]any{
@@ -311,11 +299,9 @@ func TestGetVehicleHandler(t *testing.T) {
 		// Create a request without a vehicle ID
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_vehicle",
 				Arguments: map[string]any{},
@@ -366,11 +352,9 @@ func TestGetVehiclePredictionsHandler(t *testing.T) {
 		// Create a request for the predictions handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_predictions",
 				Arguments: map[string]any{
@@ -426,11 +410,9 @@ func TestGetVehiclePredictionsHandler(t *testing.T) {
 		// Create a request with an invalid vehicle ID
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_predictions",
 				Arguments: map[string]any{
@@ -457,11 +439,9 @@ func TestGetVehiclePredictionsHandler(t *testing.T) {
 		// Create a request without a vehicle ID
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_vehicle_predictions",
 				Arguments: map[string]any{},
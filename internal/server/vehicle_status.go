@@ -10,7 +10,6 @@ import (
 	"log"
 	"strconv"
 
-	"github.com/crdant/mbta-mcp-server/pkg/mbta"
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -19,11 +18,8 @@ import (
 func (s *Server) getVehicleStatusHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Printf("Received request for vehicle status updates: %s", request.Params.Name)
 
-	// Create MBTA client
-	client := mbta.NewClient(s.config)
-
 	// Extract parameters for filtering
-	args := request.Params.Arguments
+	args := request.GetArguments()
 	params := make(map[string]string)
 
 	// Process route_id filter
@@ -92,7 +88,7 @@ func (s *Server) getVehicleStatusHandler(ctx context.Context, request mcp.CallTo
 	}
 
 	// Get vehicles with the specified filters
-	vehicles, err := client.GetVehicles(ctx, params)
+	vehicles, err := s.api.GetVehicles(ctx, params)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Failed to retrieve vehicle status updates: %v", err)), nil
 	}
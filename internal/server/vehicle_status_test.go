@@ -37,11 +37,9 @@ func TestGetVehicleStatusHandler(t *testing.T) {
 		// Create a request for the status handler
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name:      "get_vehicle_status",
 				Arguments: map[string]any{},
@@ -98,11 +96,9 @@ func TestGetVehicleStatusHandler(t *testing.T) {
 		// Create a request with route filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_status",
 				Arguments: map[string]any{
@@ -129,11 +125,9 @@ func TestGetVehicleStatusHandler(t *testing.T) {
 		// Create a request with status type filter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_status",
 				Arguments: map[string]any{
@@ -160,11 +154,9 @@ func TestGetVehicleStatusHandler(t *testing.T) {
 		// Create a request with limit parameter
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_status",
 				Arguments: map[string]any{
@@ -196,11 +188,9 @@ func TestGetVehicleStatusHandler(t *testing.T) {
 		// Create a request with invalid status type
 		request := mcp.CallToolRequest{
 			Params: struct {
-				Name      string         `json:"name"`
-				Arguments map[string]any `json:"arguments,omitempty"`
-				Meta      *struct {
-					ProgressToken mcp.ProgressToken `json:"progressToken,omitempty"`
-				} `json:"_meta,omitempty"`
+				Name      string    `json:"name"`
+				Arguments any       `json:"arguments,omitempty"`
+				Meta      *mcp.Meta `json:"_meta,omitempty"`
 			}{
 				Name: "get_vehicle_status",
 				Arguments: map[string]any{
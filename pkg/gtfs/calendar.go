@@ -0,0 +1,60 @@
+package gtfs
+
+import (
+	"time"
+)
+
+// dateLayout is the GTFS YYYYMMDD date format
+const dateLayout = "20060102"
+
+// ServiceActive returns whether trips of a service run on the given service
+// date, applying calendar_dates.txt exceptions over the weekly calendar
+func (f *Feed) ServiceActive(serviceID string, date time.Time) bool {
+	day := date.In(f.location).Format(dateLayout)
+
+	switch f.exceptions[serviceID][day] {
+	case ServiceAdded:
+		return true
+	case ServiceRemoved:
+		return false
+	}
+
+	service, ok := f.services[serviceID]
+	if !ok {
+		return false
+	}
+	if day < service.StartDate || day > service.EndDate {
+		return false
+	}
+	return service.Weekdays[date.In(f.location).Weekday()]
+}
+
+// ActiveServices returns the IDs of every service running on the given service date
+func (f *Feed) ActiveServices(date time.Time) map[string]bool {
+	active := make(map[string]bool)
+	for id := range f.services {
+		if f.ServiceActive(id, date) {
+			active[id] = true
+		}
+	}
+	for id := range f.exceptions {
+		if f.ServiceActive(id, date) {
+			active[id] = true
+		}
+	}
+	return active
+}
+
+// ServiceDate returns the start of the service day containing t's date in the
+// feed's timezone. GTFS measures stop times from noon minus 12 hours, which is
+// midnight except on days when daylight saving time begins or ends.
+func (f *Feed) ServiceDate(t time.Time) time.Time {
+	year, month, day := t.In(f.location).Date()
+	noon := time.Date(year, month, day, 12, 0, 0, 0, f.location)
+	return noon.Add(-12 * time.Hour)
+}
+
+// ScheduledTime returns the time of a stop time offset on a service date
+func (f *Feed) ScheduledTime(serviceDate time.Time, offset time.Duration) time.Time {
+	return f.ServiceDate(serviceDate).Add(offset).In(f.location)
+}
@@ -0,0 +1,80 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServiceActive(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	tests := []struct {
+		name     string
+		service  string
+		date     string
+		expected bool
+	}{
+		{name: "Weekday", service: "weekday", date: "2025-05-20", expected: true},
+		{name: "WeekdayOnSaturday", service: "weekday", date: "2025-05-24", expected: false},
+		{name: "Saturday", service: "saturday", date: "2025-05-24", expected: true},
+		{name: "RemovedOnHoliday", service: "weekday", date: "2025-05-26", expected: false},
+		{name: "AddedOnHoliday", service: "saturday", date: "2025-05-26", expected: true},
+		{name: "BeforeStart", service: "weekday", date: "2024-12-31", expected: false},
+		{name: "AfterEnd", service: "weekday", date: "2026-01-02", expected: false},
+		{name: "UnknownService", service: "sunday", date: "2025-05-25", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := feed.parseDate(tt.date)
+			if err != nil {
+				t.Fatalf("Failed to parse date: %v", err)
+			}
+			if got := feed.ServiceActive(tt.service, date); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestActiveServices(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	date, _ := feed.parseDate("2025-05-26")
+	active := feed.ActiveServices(date)
+	if len(active) != 1 || !active["saturday"] {
+		t.Errorf("Expected only saturday service on Memorial Day, got %v", active)
+	}
+}
+
+func TestScheduledTime(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	tests := []struct {
+		name     string
+		date     string
+		offset   time.Duration
+		expected string
+	}{
+		{name: "Morning", date: "2025-05-20", offset: 8 * time.Hour, expected: "2025-05-20T08:00:00-04:00"},
+		{name: "PastMidnight", date: "2025-05-20", offset: 24*time.Hour + 30*time.Minute, expected: "2025-05-21T00:30:00-04:00"},
+		{name: "DaylightSavingStarts", date: "2025-03-09", offset: 8 * time.Hour, expected: "2025-03-09T08:00:00-04:00"},
+		{name: "DaylightSavingEnds", date: "2025-11-02", offset: 8 * time.Hour, expected: "2025-11-02T08:00:00-05:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, _ := feed.parseDate(tt.date)
+			got := feed.ScheduledTime(date, tt.offset).Format(time.RFC3339)
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	// A time late in the evening belongs to the service day it falls on
+	late := time.Date(2025, 5, 20, 23, 30, 0, 0, feed.Location())
+	if got := feed.ServiceDate(late).Format(time.RFC3339); got != "2025-05-20T00:00:00-04:00" {
+		t.Errorf("Expected service date 2025-05-20T00:00:00-04:00, got %s", got)
+	}
+}
@@ -0,0 +1,397 @@
+// Package gtfs loads a static GTFS feed, such as the one the MBTA publishes at
+// https://cdn.mbta.com/MBTA_GTFS.zip, into indexed in-memory structures that use
+// the same models as the MBTA API client. A loaded Feed answers stop, route,
+// trip and schedule queries without a network connection.
+package gtfs
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// DefaultTimezone is used when the feed's agency.txt doesn't name a timezone
+const DefaultTimezone = "America/New_York"
+
+// Feed is a parsed GTFS feed indexed for lookups
+type Feed struct {
+	location *time.Location
+
+	stops     map[string]*models.Stop
+	stopIDs   []string
+	children  map[string][]string // Parent station -> child stop IDs
+	routes    map[string]*models.Route
+	routeIDs  []string
+	trips     map[string]*models.Trip
+	tripIDs   []string
+	stopTimes map[string][]StopTime // Trip ID -> stop times in stop sequence order
+
+	stopTrips  map[string][]string // Stop ID -> trips that call there
+	routeTrips map[string][]string // Route ID -> trips on the route
+	routeStops map[string][]string // Route ID -> stops served by the route
+	stopRoutes map[string][]string // Stop ID -> routes serving the stop
+
+	services   map[string]Service
+	exceptions map[string]map[string]int // Service ID -> YYYYMMDD -> exception type
+	transfers  []Transfer
+	pathways   []Pathway
+	shapes     map[string]*Shape
+
+	stopIndex *mbta.StopIndex
+	footpaths *mbta.FootpathGraph
+}
+
+// StopTime is a scheduled arrival and departure of a trip at a stop. Times are
+// offsets from noon minus 12 hours on the service date, as GTFS defines them,
+// so they may exceed 24 hours for trips running past midnight.
+type StopTime struct {
+	TripID       string
+	StopID       string
+	StopSequence int
+	Arrival      time.Duration
+	Departure    time.Duration
+	StopHeadsign string
+	PickupType   int
+	DropOffType  int
+	Timepoint    bool
+}
+
+// Service is a set of dates on which trips run, from calendar.txt
+type Service struct {
+	ID        string
+	Weekdays  [7]bool // Indexed by time.Weekday
+	StartDate string  // YYYYMMDD
+	EndDate   string  // YYYYMMDD
+}
+
+// Calendar exception types from calendar_dates.txt
+const (
+	ServiceAdded   = 1
+	ServiceRemoved = 2
+)
+
+// Transfer is a rule for changing vehicles from transfers.txt
+type Transfer struct {
+	FromStopID      string
+	ToStopID        string
+	FromRouteID     string
+	ToRouteID       string
+	FromTripID      string
+	ToTripID        string
+	TransferType    int
+	MinTransferTime time.Duration
+}
+
+// Pathway is a walkway, stairway, elevator or other link between locations
+// in and around a station, from pathways.txt
+type Pathway struct {
+	ID            string
+	FromStopID    string
+	ToStopID      string
+	Mode          int // One of the PathwayMode constants
+	Bidirectional bool
+	Length        float64       // Meters; 0 if unknown
+	TraversalTime time.Duration // 0 if unknown
+}
+
+// Shape is the path a vehicle travels, from shapes.txt
+type Shape struct {
+	ID     string
+	Points []ShapePoint
+}
+
+// ShapePoint is a single point along a shape
+type ShapePoint struct {
+	Latitude         float64
+	Longitude        float64
+	Sequence         int
+	DistanceTraveled float64
+}
+
+// Load reads a GTFS feed from a zip file or a directory of unpacked .txt files
+func Load(path string) (*Feed, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening GTFS feed: %w", err)
+	}
+
+	if info.IsDir() {
+		return Parse(os.DirFS(path))
+	}
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening GTFS zip: %w", err)
+	}
+	defer func() { _ = archive.Close() }()
+
+	return Parse(archive)
+}
+
+// Parse reads a GTFS feed from a file system such as an opened zip archive.
+// stops.txt, routes.txt, trips.txt and stop_times.txt are required; calendars,
+// transfers, pathways and shapes are loaded when present.
+func Parse(fsys fs.FS) (*Feed, error) {
+	f := &Feed{
+		stops:      make(map[string]*models.Stop),
+		children:   make(map[string][]string),
+		routes:     make(map[string]*models.Route),
+		trips:      make(map[string]*models.Trip),
+		stopTimes:  make(map[string][]StopTime),
+		stopTrips:  make(map[string][]string),
+		routeTrips: make(map[string][]string),
+		routeStops: make(map[string][]string),
+		stopRoutes: make(map[string][]string),
+		services:   make(map[string]Service),
+		exceptions: make(map[string]map[string]int),
+		shapes:     make(map[string]*Shape),
+	}
+
+	timezone, err := parseAgencyTimezone(fsys)
+	if err != nil {
+		return nil, err
+	}
+	f.location, err = time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("error loading feed timezone %q: %w", timezone, err)
+	}
+
+	steps := []struct {
+		file     string
+		required bool
+		parse    func(*table) error
+	}{
+		{"stops.txt", true, f.parseStops},
+		{"routes.txt", true, f.parseRoutes},
+		{"directions.txt", false, f.parseDirections},
+		{"trips.txt", true, f.parseTrips},
+		{"stop_times.txt", true, f.parseStopTimes},
+		{"calendar.txt", false, f.parseCalendar},
+		{"calendar_dates.txt", false, f.parseCalendarDates},
+		{"transfers.txt", false, f.parseTransfers},
+		{"pathways.txt", false, f.parsePathways},
+		{"shapes.txt", false, f.parseShapes},
+	}
+
+	for _, step := range steps {
+		if err := readTable(fsys, step.file, step.required, step.parse); err != nil {
+			return nil, err
+		}
+	}
+
+	f.buildIndexes()
+
+	return f, nil
+}
+
+// Location returns the timezone the feed's schedules are expressed in
+func (f *Feed) Location() *time.Location {
+	return f.location
+}
+
+// Stop returns the stop with the given ID
+func (f *Feed) Stop(id string) (*models.Stop, bool) {
+	stop, ok := f.stops[id]
+	return stop, ok
+}
+
+// Route returns the route with the given ID
+func (f *Feed) Route(id string) (*models.Route, bool) {
+	route, ok := f.routes[id]
+	return route, ok
+}
+
+// Trip returns the trip with the given ID
+func (f *Feed) Trip(id string) (*models.Trip, bool) {
+	trip, ok := f.trips[id]
+	return trip, ok
+}
+
+// StopTimes returns a trip's stop times in stop sequence order
+func (f *Feed) StopTimes(tripID string) []StopTime {
+	return f.stopTimes[tripID]
+}
+
+// ChildStops returns the IDs of the platforms and entrances of a parent station
+func (f *Feed) ChildStops(stationID string) []string {
+	return f.children[stationID]
+}
+
+// RoutesForStop returns the IDs of the routes that serve a stop
+func (f *Feed) RoutesForStop(stopID string) []string {
+	return f.stopRoutes[stopID]
+}
+
+// StopsForRoute returns the IDs of the stops a route serves
+func (f *Feed) StopsForRoute(routeID string) []string {
+	return f.routeStops[routeID]
+}
+
+// Shape returns the shape with the given ID
+func (f *Feed) Shape(id string) (*Shape, bool) {
+	shape, ok := f.shapes[id]
+	return shape, ok
+}
+
+// Transfers returns the feed's transfer rules
+func (f *Feed) Transfers() []Transfer {
+	return f.transfers
+}
+
+// Pathways returns the feed's pathways
+func (f *Feed) Pathways() []Pathway {
+	return f.pathways
+}
+
+// Footpaths returns the walks between stops of different stations that the
+// feed's transfers and pathways describe
+func (f *Feed) Footpaths() *mbta.FootpathGraph {
+	return f.footpaths
+}
+
+// TransferPoints converts transfer rules between platforms of the same station
+// into transfer points for trip planning
+func (f *Feed) TransferPoints() []models.TransferPoint {
+	points := make([]models.TransferPoint, 0)
+	for _, transfer := range f.transfers {
+		if f.station(transfer.FromStopID) != f.station(transfer.ToStopID) {
+			continue
+		}
+		stop, ok := f.stops[transfer.FromStopID]
+		if !ok {
+			continue
+		}
+		points = append(points, models.TransferPoint{
+			Stop:            stop,
+			FromRoute:       transfer.FromRouteID,
+			ToRoute:         transfer.ToRouteID,
+			TransferType:    transfer.TransferType,
+			MinTransferTime: transfer.MinTransferTime,
+		})
+	}
+	return points
+}
+
+// station returns the parent station of a stop, or the stop itself
+func (f *Feed) station(stopID string) string {
+	if stop, ok := f.stops[stopID]; ok {
+		if parent := models.RelationshipID(stop.Relationships, "parent_station"); parent != "" {
+			return parent
+		}
+	}
+	return stopID
+}
+
+// buildIndexes derives the lookup tables used by queries once all files are read
+func (f *Feed) buildIndexes() {
+	for id, stop := range f.stops {
+		f.stopIDs = append(f.stopIDs, id)
+		if parent := models.RelationshipID(stop.Relationships, "parent_station"); parent != "" {
+			f.children[parent] = append(f.children[parent], id)
+		}
+	}
+	for id := range f.routes {
+		f.routeIDs = append(f.routeIDs, id)
+	}
+	for id := range f.trips {
+		f.tripIDs = append(f.tripIDs, id)
+	}
+	sort.Strings(f.stopIDs)
+	sort.Strings(f.tripIDs)
+	for _, ids := range f.children {
+		sort.Strings(ids)
+	}
+
+	// Routes keep the feed's sort order, like the API
+	sort.Slice(f.routeIDs, func(i, j int) bool {
+		a, b := f.routes[f.routeIDs[i]], f.routes[f.routeIDs[j]]
+		if a.Attributes.SortOrder != b.Attributes.SortOrder {
+			return a.Attributes.SortOrder < b.Attributes.SortOrder
+		}
+		return a.ID < b.ID
+	})
+
+	routeStops := make(map[string]map[string]bool)
+	stopRoutes := make(map[string]map[string]bool)
+	for _, tripID := range f.tripIDs {
+		times := f.stopTimes[tripID]
+		sort.Slice(times, func(i, j int) bool { return times[i].StopSequence < times[j].StopSequence })
+
+		routeID := f.trips[tripID].GetRouteID()
+		f.routeTrips[routeID] = append(f.routeTrips[routeID], tripID)
+		if routeStops[routeID] == nil {
+			routeStops[routeID] = make(map[string]bool)
+		}
+
+		for _, st := range times {
+			f.stopTrips[st.StopID] = append(f.stopTrips[st.StopID], tripID)
+			routeStops[routeID][st.StopID] = true
+			if stopRoutes[st.StopID] == nil {
+				stopRoutes[st.StopID] = make(map[string]bool)
+			}
+			stopRoutes[st.StopID][routeID] = true
+		}
+	}
+
+	f.routeStops = sortedKeys(routeStops)
+	f.stopRoutes = sortedKeys(stopRoutes)
+
+	for _, shape := range f.shapes {
+		sort.Slice(shape.Points, func(i, j int) bool { return shape.Points[i].Sequence < shape.Points[j].Sequence })
+	}
+
+	f.buildStopIndex()
+	f.buildFootpaths()
+}
+
+// buildStopIndex indexes every stop for nearby searches. Stations are served
+// by the route types of their platforms.
+func (f *Feed) buildStopIndex() {
+	routeTypes := make(map[string][]int)
+	add := func(stopID string, routeType int) {
+		for _, existing := range routeTypes[stopID] {
+			if existing == routeType {
+				return
+			}
+		}
+		routeTypes[stopID] = append(routeTypes[stopID], routeType)
+	}
+
+	for stopID, routeIDs := range f.stopRoutes {
+		for _, routeID := range routeIDs {
+			routeType := f.routes[routeID].Attributes.Type
+			add(stopID, routeType)
+			if station := f.station(stopID); station != stopID {
+				add(station, routeType)
+			}
+		}
+	}
+
+	stops := make([]models.Stop, 0, len(f.stopIDs))
+	for _, id := range f.stopIDs {
+		stops = append(stops, *f.stops[id])
+	}
+
+	f.stopIndex = mbta.NewStopIndex()
+	f.stopIndex.Build(stops, routeTypes)
+}
+
+// sortedKeys converts sets keyed by ID into sorted slices
+func sortedKeys(sets map[string]map[string]bool) map[string][]string {
+	result := make(map[string][]string, len(sets))
+	for id, set := range sets {
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result[id] = keys
+	}
+	return result
+}
@@ -0,0 +1,268 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// testFeedDir holds a small extract of the MBTA feed: Red Line trips from
+// Alewife through Harvard to Park Street, a Green Line C trip from Park Street
+// and a route 1 bus
+const testFeedDir = "testdata/feed"
+
+// loadTestFeed loads the test feed or fails the test
+func loadTestFeed(t *testing.T) *Feed {
+	t.Helper()
+	feed, err := Load(testFeedDir)
+	if err != nil {
+		t.Fatalf("Failed to load test feed: %v", err)
+	}
+	return feed
+}
+
+// zipTestFeed packs the test feed directory into a zip file and returns its path
+func zipTestFeed(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "MBTA_GTFS.zip")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	archive := zip.NewWriter(out)
+	entries, err := os.ReadDir(testFeedDir)
+	if err != nil {
+		t.Fatalf("Failed to read test feed: %v", err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(testFeedDir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", entry.Name(), err)
+		}
+		w, err := archive.Create(entry.Name())
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", entry.Name(), err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Failed to write %s: %v", entry.Name(), err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Directory", func(t *testing.T) {
+		feed := loadTestFeed(t)
+		if len(feed.stops) != 11 {
+			t.Errorf("Expected 11 stops, got %d", len(feed.stops))
+		}
+		if len(feed.trips) != 6 {
+			t.Errorf("Expected 6 trips, got %d", len(feed.trips))
+		}
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		feed, err := Load(zipTestFeed(t))
+		if err != nil {
+			t.Fatalf("Failed to load zipped feed: %v", err)
+		}
+		if len(feed.routes) != 3 {
+			t.Errorf("Expected 3 routes, got %d", len(feed.routes))
+		}
+		if len(feed.StopTimes("red-1")) != 3 {
+			t.Errorf("Expected 3 stop times for red-1, got %d", len(feed.StopTimes("red-1")))
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing.zip")); err == nil {
+			t.Error("Expected error loading a missing feed, got nil")
+		}
+	})
+
+	t.Run("NotZip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "feed.zip")
+		if err := os.WriteFile(path, []byte("not a zip"), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Expected error loading an invalid zip, got nil")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Run("MissingRequiredFile", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"stops.txt": {Data: []byte("stop_id,stop_name\n1,One\n")},
+		}
+		_, err := Parse(fsys)
+		if err == nil || !strings.Contains(err.Error(), "routes.txt") {
+			t.Errorf("Expected error about routes.txt, got %v", err)
+		}
+	})
+
+	t.Run("DefaultTimezone", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"stops.txt":      {Data: []byte("stop_id,stop_name\n1,One\n")},
+			"routes.txt":     {Data: []byte("route_id,route_type\nR,3\n")},
+			"trips.txt":      {Data: []byte("route_id,service_id,trip_id\nR,S,T\n")},
+			"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\nT,08:00:00,08:00:00,1,1\n")},
+		}
+		feed, err := Parse(fsys)
+		if err != nil {
+			t.Fatalf("Failed to parse feed: %v", err)
+		}
+		if feed.Location().String() != DefaultTimezone {
+			t.Errorf("Expected timezone %s, got %s", DefaultTimezone, feed.Location())
+		}
+	})
+
+	t.Run("InvalidTimezone", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"agency.txt": {Data: []byte("agency_id,agency_timezone\n1,Mars/Olympus_Mons\n")},
+		}
+		if _, err := Parse(fsys); err == nil {
+			t.Error("Expected error for an unknown timezone, got nil")
+		}
+	})
+}
+
+func TestFeedStops(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	stop, ok := feed.Stop("70067")
+	if !ok {
+		t.Fatal("Expected stop 70067")
+	}
+	if stop.Attributes.Name != "Harvard" {
+		t.Errorf("Expected name Harvard, got %s", stop.Attributes.Name)
+	}
+	if stop.Attributes.PlatformName != "Ashmont/Braintree" {
+		t.Errorf("Expected platform name Ashmont/Braintree, got %s", stop.Attributes.PlatformName)
+	}
+	if stop.Attributes.VehicleType == nil || *stop.Attributes.VehicleType != 1 {
+		t.Errorf("Expected vehicle type 1, got %v", stop.Attributes.VehicleType)
+	}
+	if parent := models.RelationshipID(stop.Relationships, "parent_station"); parent != "place-harsq" {
+		t.Errorf("Expected parent station place-harsq, got %q", parent)
+	}
+
+	station, _ := feed.Stop("place-harsq")
+	if station.Attributes.LocationType != 1 {
+		t.Errorf("Expected location type 1, got %d", station.Attributes.LocationType)
+	}
+	if parent := models.RelationshipID(station.Relationships, "parent_station"); parent != "" {
+		t.Errorf("Expected no parent station, got %q", parent)
+	}
+
+	bus, _ := feed.Stop("2168")
+	if bus.Attributes.OnStreet == nil || *bus.Attributes.OnStreet != "Massachusetts Avenue" {
+		t.Errorf("Expected on street Massachusetts Avenue, got %v", bus.Attributes.OnStreet)
+	}
+
+	if children := feed.ChildStops("place-harsq"); !reflect.DeepEqual(children, []string{"70067", "70068"}) {
+		t.Errorf("Expected children [70067 70068], got %v", children)
+	}
+	if routes := feed.RoutesForStop("70075"); !reflect.DeepEqual(routes, []string{"Red"}) {
+		t.Errorf("Expected routes [Red], got %v", routes)
+	}
+	if stops := feed.StopsForRoute("Red"); !reflect.DeepEqual(stops, []string{"70061", "70067", "70075"}) {
+		t.Errorf("Expected Red stops [70061 70067 70075], got %v", stops)
+	}
+}
+
+func TestFeedRoutesAndTrips(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	route, ok := feed.Route("Red")
+	if !ok {
+		t.Fatal("Expected route Red")
+	}
+	if route.Attributes.LongName != "Red Line" || route.Attributes.Type != 1 {
+		t.Errorf("Expected Red Line of type 1, got %s of type %d", route.Attributes.LongName, route.Attributes.Type)
+	}
+	if !reflect.DeepEqual(route.Attributes.DirectionDestinations, []string{"Ashmont/Braintree", "Alewife"}) {
+		t.Errorf("Expected direction destinations from directions.txt, got %v", route.Attributes.DirectionDestinations)
+	}
+	if line := models.RelationshipID(route.Relationships, "line"); line != "line-Red" {
+		t.Errorf("Expected line line-Red, got %q", line)
+	}
+
+	trip, ok := feed.Trip("bus-1")
+	if !ok {
+		t.Fatal("Expected trip bus-1")
+	}
+	if trip.GetRouteID() != "1" || trip.GetServiceID() != "weekday" {
+		t.Errorf("Expected route 1 and service weekday, got %s and %s", trip.GetRouteID(), trip.GetServiceID())
+	}
+	if trip.Attributes.WheelchairEnabled {
+		t.Error("Expected bus-1 to not be wheelchair accessible")
+	}
+	if !trip.Attributes.BikeAllowed {
+		t.Error("Expected bus-1 to allow bikes")
+	}
+
+	times := feed.StopTimes("red-1")
+	if len(times) != 3 || times[0].StopID != "70061" || times[2].StopID != "70075" {
+		t.Fatalf("Expected red-1 stop times in sequence order, got %+v", times)
+	}
+	if times[1].Arrival != 8*time.Hour+7*time.Minute || times[1].Departure != 8*time.Hour+8*time.Minute {
+		t.Errorf("Expected Harvard at 08:07-08:08, got %v-%v", times[1].Arrival, times[1].Departure)
+	}
+
+	late := feed.StopTimes("red-late")
+	if late[0].Departure != 24*time.Hour+30*time.Minute {
+		t.Errorf("Expected departure at 24:30:00, got %v", late[0].Departure)
+	}
+
+	bus := feed.StopTimes("bus-1")
+	if bus[0].StopHeadsign != "Nubian via Central" {
+		t.Errorf("Expected stop headsign, got %q", bus[0].StopHeadsign)
+	}
+	if bus[1].Timepoint {
+		t.Error("Expected second bus stop time to not be a timepoint")
+	}
+}
+
+func TestFeedShapesAndTransfers(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	shape, ok := feed.Shape("931_0009")
+	if !ok {
+		t.Fatal("Expected shape 931_0009")
+	}
+	for i, point := range shape.Points {
+		if point.Sequence != i+1 {
+			t.Errorf("Expected point %d to have sequence %d, got %d", i, i+1, point.Sequence)
+		}
+	}
+
+	if len(feed.Transfers()) != 2 {
+		t.Errorf("Expected 2 transfers, got %d", len(feed.Transfers()))
+	}
+
+	points := feed.TransferPoints()
+	if len(points) != 1 {
+		t.Fatalf("Expected 1 transfer point within a station, got %d", len(points))
+	}
+	if points[0].Stop.ID != "70075" || points[0].FromRoute != "Red" || points[0].ToRoute != "Green-C" {
+		t.Errorf("Expected Red to Green-C at 70075, got %s to %s at %s", points[0].FromRoute, points[0].ToRoute, points[0].Stop.ID)
+	}
+	if points[0].MinTransferTime != 3*time.Minute {
+		t.Errorf("Expected minimum transfer time 3m, got %v", points[0].MinTransferTime)
+	}
+}
@@ -0,0 +1,192 @@
+package gtfs
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Pathway modes from pathways.txt
+const (
+	PathwayModeWalkway        = 1
+	PathwayModeStairs         = 2
+	PathwayModeMovingSidewalk = 3
+	PathwayModeEscalator      = 4
+	PathwayModeElevator       = 5
+	PathwayModeFareGate       = 6
+	PathwayModeExitGate       = 7
+)
+
+// unknownPathwayTime is assumed for a pathway without a traversal time,
+// length or located ends
+const unknownPathwayTime = time.Minute
+
+// maxPathwayWalk is the longest walk through pathways turned into a footpath
+var maxPathwayWalk = mbta.WalkingTime(mbta.DefaultMaxWalkDistance)
+
+// buildFootpaths connects stops of different stations that riders can walk
+// between: those with a transfer rule, and those linked through pathways,
+// such as concourses between neighboring stations. Each walk is also added
+// between the stations themselves.
+func (f *Feed) buildFootpaths() {
+	f.footpaths = mbta.NewFootpathGraph()
+
+	add := func(path mbta.Footpath) {
+		f.footpaths.Add(path)
+		station := path
+		station.FromStopID, station.ToStopID = f.station(path.FromStopID), f.station(path.ToStopID)
+		f.footpaths.Add(station)
+	}
+
+	for _, transfer := range f.transfers {
+		if transfer.TransferType == models.TransferTypeNotPossible || transfer.FromTripID != "" || transfer.ToTripID != "" {
+			continue
+		}
+		if f.station(transfer.FromStopID) == f.station(transfer.ToStopID) {
+			continue
+		}
+		from, fromOK := f.stops[transfer.FromStopID]
+		to, toOK := f.stops[transfer.ToStopID]
+		if !fromOK || !toOK {
+			continue
+		}
+
+		path, located := mbta.EstimateFootpath(from, to)
+		if transfer.MinTransferTime > 0 {
+			path.Duration = transfer.MinTransferTime
+		} else if !located {
+			continue
+		}
+		path.FromStopID, path.ToStopID = from.ID, to.ID
+		path.Source = mbta.FootpathSourceTransfer
+		add(path)
+	}
+
+	for _, path := range f.pathwayFootpaths() {
+		add(path)
+	}
+}
+
+// pathwayFootpaths finds the quickest walk through pathways from each served
+// stop to the served stops of other stations within maxPathwayWalk
+func (f *Feed) pathwayFootpaths() []mbta.Footpath {
+	edges := make(map[string][]pathwayEdge)
+	for _, pathway := range f.pathways {
+		edge := pathwayEdge{to: pathway.ToStopID, time: f.pathwayTime(pathway), length: pathway.Length}
+		edges[pathway.FromStopID] = append(edges[pathway.FromStopID], edge)
+		if pathway.Bidirectional {
+			edge.to = pathway.FromStopID
+			edges[pathway.ToStopID] = append(edges[pathway.ToStopID], edge)
+		}
+	}
+
+	var paths []mbta.Footpath
+	for _, from := range f.stopIDs {
+		if len(f.stopRoutes[from]) == 0 || len(edges[from]) == 0 {
+			continue
+		}
+		station := f.station(from)
+
+		for to, reached := range shortestPathways(edges, from, maxPathwayWalk) {
+			if len(f.stopRoutes[to]) == 0 || f.station(to) == station {
+				continue
+			}
+			paths = append(paths, mbta.Footpath{
+				FromStopID: from,
+				ToStopID:   to,
+				Distance:   reached.length / 1000,
+				Duration:   reached.time,
+				Source:     mbta.FootpathSourcePathway,
+			})
+		}
+	}
+	return paths
+}
+
+// pathwayTime returns how long a pathway takes to traverse: its traversal
+// time, or an estimate from its length or the distance between its ends
+func (f *Feed) pathwayTime(pathway Pathway) time.Duration {
+	if pathway.TraversalTime > 0 {
+		return pathway.TraversalTime
+	}
+	if pathway.Length > 0 {
+		return (time.Duration(pathway.Length/mbta.DefaultWalkingSpeed) * time.Second).Round(time.Second)
+	}
+	from, fromOK := f.stops[pathway.FromStopID]
+	to, toOK := f.stops[pathway.ToStopID]
+	if fromOK && toOK {
+		if path, ok := mbta.EstimateFootpath(from, to); ok && path.Duration > 0 {
+			return path.Duration
+		}
+	}
+	return unknownPathwayTime
+}
+
+// pathwayEdge is a pathway leaving a location
+type pathwayEdge struct {
+	to     string
+	time   time.Duration
+	length float64 // Meters
+}
+
+// pathwayReach is the quickest way found to a location through pathways
+type pathwayReach struct {
+	time   time.Duration
+	length float64 // Meters
+}
+
+// shortestPathways finds the quickest walk from a location to every other
+// reachable within limit (Dijkstra's algorithm)
+func shortestPathways(edges map[string][]pathwayEdge, from string, limit time.Duration) map[string]pathwayReach {
+	reached := map[string]pathwayReach{from: {}}
+	done := make(map[string]bool)
+	queue := &pathwayQueue{{stop: from}}
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(pathwayQueueItem)
+		if done[current.stop] {
+			continue
+		}
+		done[current.stop] = true
+
+		for _, edge := range edges[current.stop] {
+			next := pathwayReach{
+				time:   reached[current.stop].time + edge.time,
+				length: reached[current.stop].length + edge.length,
+			}
+			if next.time > limit || done[edge.to] {
+				continue
+			}
+			if existing, ok := reached[edge.to]; ok && existing.time <= next.time {
+				continue
+			}
+			reached[edge.to] = next
+			heap.Push(queue, pathwayQueueItem{stop: edge.to, time: next.time})
+		}
+	}
+
+	delete(reached, from)
+	return reached
+}
+
+// pathwayQueueItem is a location waiting to be visited by shortestPathways
+type pathwayQueueItem struct {
+	stop string
+	time time.Duration
+}
+
+// pathwayQueue is a min-heap of locations by walking time
+type pathwayQueue []pathwayQueueItem
+
+func (q pathwayQueue) Len() int           { return len(q) }
+func (q pathwayQueue) Less(i, j int) bool { return q[i].time < q[j].time }
+func (q pathwayQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *pathwayQueue) Push(x any)        { *q = append(*q, x.(pathwayQueueItem)) }
+func (q *pathwayQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,60 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+)
+
+func TestFeedFootpaths(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	if len(feed.Pathways()) != 3 {
+		t.Errorf("Expected 3 pathways, got %d", len(feed.Pathways()))
+	}
+	footpaths := feed.Footpaths()
+
+	// 150 meters from the platform to the bus stop is quicker than the
+	// transfer rule allows
+	path, ok := footpaths.Between("70067", "2168")
+	if !ok {
+		t.Fatal("Expected a footpath from Harvard to the bus stop")
+	}
+	if path.Duration != 125*time.Second || path.Distance != 0.15 || path.Source != mbta.FootpathSourcePathway {
+		t.Errorf("Expected a 125s pathway walk of 0.15 km, got %+v", path)
+	}
+	if path, ok := footpaths.Between("2168", "70067"); !ok || path.Duration != 125*time.Second {
+		t.Errorf("Expected bidirectional pathways to be walked both ways, got %+v", path)
+	}
+
+	// Stations are connected by their quickest walk
+	if path, ok := footpaths.Between("place-harsq", "2168"); !ok || path.Duration != 125*time.Second {
+		t.Errorf("Expected a 125s walk from the station, got %+v", path)
+	}
+
+	// Changes within a station, platforms no trip serves and walks too long
+	// aren't footpaths
+	for _, pair := range [][2]string{{"70075", "70200"}, {"70067", "70068"}, {"70068", "2168"}, {"70151", "110"}} {
+		if path, ok := footpaths.Between(pair[0], pair[1]); ok {
+			t.Errorf("Expected no footpath from %s to %s, got %+v", pair[0], pair[1], path)
+		}
+	}
+}
+
+func TestFeedFootpathsFromTransfers(t *testing.T) {
+	feed := loadTestFeed(t)
+	feed.pathways = nil
+	feed.buildFootpaths()
+
+	path, ok := feed.Footpaths().Between("70067", "2168")
+	if !ok || path.Duration != 5*time.Minute || path.Source != mbta.FootpathSourceTransfer {
+		t.Errorf("Expected the 5 minute transfer, got %+v", path)
+	}
+	if path.Distance <= 0 {
+		t.Errorf("Expected the distance between the stops, got %.3f", path.Distance)
+	}
+	if _, ok := feed.Footpaths().Between("2168", "70067"); ok {
+		t.Error("Expected transfers to be one way")
+	}
+}
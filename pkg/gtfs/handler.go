@@ -0,0 +1,258 @@
+package gtfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Handler serves the feed's routes, stops, trips and schedules in the MBTA
+// API's JSON:API format, so the API client can use the feed as its backend.
+// Collections honor page[offset] and page[limit] and link to the next page.
+func (f *Feed) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /routes", func(w http.ResponseWriter, r *http.Request) {
+		writeCollection(w, r, f.findRoutes(queryParams(r)), nil)
+	})
+	mux.HandleFunc("GET /routes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		route, err := f.GetRoute(r.Context(), r.PathValue("id"))
+		writeResource(w, route, err)
+	})
+	mux.HandleFunc("GET /stops", func(w http.ResponseWriter, r *http.Request) {
+		writeCollection(w, r, f.findStops(queryParams(r)), nil)
+	})
+	mux.HandleFunc("GET /stops/{id}", func(w http.ResponseWriter, r *http.Request) {
+		stop, err := f.GetStop(r.Context(), r.PathValue("id"))
+		if err != nil || !strings.Contains(r.URL.Query().Get("include"), "child_stops") {
+			writeResource(w, stop, err)
+			return
+		}
+
+		// Link and include the station's platforms and entrances, and with
+		// child_stops.child_stops their boarding areas too
+		depth := 1
+		if strings.Contains(r.URL.Query().Get("include"), "child_stops.child_stops") {
+			depth = 2
+		}
+		included := make([]models.Included, 0)
+		withChildren := *stop
+		withChildren.Relationships = f.linkChildStops(stop, depth, &included)
+
+		writeJSON(w, http.StatusOK, struct {
+			Data     *models.Stop      `json:"data"`
+			Included []models.Included `json:"included"`
+		}{Data: &withChildren, Included: included})
+	})
+	mux.HandleFunc("GET /trips", func(w http.ResponseWriter, r *http.Request) {
+		trips, err := f.GetTrips(r.Context(), queryParams(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCollection(w, r, trips, nil)
+	})
+	mux.HandleFunc("GET /trips/{id}", func(w http.ResponseWriter, r *http.Request) {
+		trip, err := f.GetTrip(r.Context(), r.PathValue("id"))
+		writeResource(w, trip, err)
+	})
+	mux.HandleFunc("GET /schedules", func(w http.ResponseWriter, r *http.Request) {
+		schedules, included, err := f.GetSchedules(r.Context(), queryParams(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeCollection(w, r, schedules, included)
+	})
+
+	return mux
+}
+
+// linkChildStops returns a stop's relationships with its child stops linked,
+// adding the children to included and linking theirs in turn, depth levels
+// down
+func (f *Feed) linkChildStops(stop *models.Stop, depth int, included *[]models.Included) map[string]interface{} {
+	relationships := make(map[string]interface{}, len(stop.Relationships)+1)
+	for key, value := range stop.Relationships {
+		relationships[key] = value
+	}
+
+	identifiers := make([]interface{}, 0)
+	for _, id := range f.children[stop.ID] {
+		child := f.stops[id]
+		identifiers = append(identifiers, map[string]interface{}{"id": id, "type": "stop"})
+		childRelationships := child.Relationships
+		if depth > 1 {
+			childRelationships = f.linkChildStops(child, depth-1, included)
+		}
+		*included = append(*included, models.Included{ID: id, Type: "stop", Attributes: child.Attributes, Relationships: childRelationships})
+	}
+	relationships["child_stops"] = map[string]interface{}{"data": identifiers}
+	return relationships
+}
+
+// Transport returns an HTTP transport that answers requests the feed can serve
+// from memory and passes everything else, such as predictions and vehicles, to
+// fallback. With a nil fallback those requests get a 404 Not Found response.
+func (f *Feed) Transport(fallback http.RoundTripper) mbta.LocalTransport {
+	return &feedTransport{
+		mux:      f.Handler().(*http.ServeMux),
+		fallback: fallback,
+	}
+}
+
+// feedTransport routes requests to the feed's handler or a fallback transport
+type feedTransport struct {
+	mux      *http.ServeMux
+	fallback http.RoundTripper
+}
+
+// Serves returns whether the feed answers a request itself, rather than
+// sending it to the fallback. It implements mbta.LocalTransport.
+func (t *feedTransport) Serves(req *http.Request) bool {
+	_, pattern := t.mux.Handler(req)
+	return pattern != "" || t.fallback == nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *feedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.Serves(req) {
+		return t.fallback.RoundTrip(req)
+	}
+
+	w := &responseWriter{header: make(http.Header)}
+	t.mux.ServeHTTP(w, req)
+	return w.response(req), nil
+}
+
+// responseWriter collects a handler's response in memory
+type responseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter, keeping the first status written
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// response converts what the handler wrote into a response to req
+func (w *responseWriter) response(req *http.Request) *http.Response {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		ContentLength: int64(w.body.Len()),
+		Request:       req,
+	}
+}
+
+// queryParams flattens a request's query string into the map used by feed queries
+func queryParams(r *http.Request) map[string]string {
+	params := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+	return params
+}
+
+// writeCollection writes one page of a JSON:API collection
+func writeCollection[T any](w http.ResponseWriter, r *http.Request, items []T, included []models.Included) {
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("page[offset]"))
+	limit, _ := strconv.Atoi(query.Get("page[limit]"))
+	offset = min(max(offset, 0), len(items))
+
+	end := len(items)
+	if limit > 0 && offset+limit < len(items) {
+		end = offset + limit
+	}
+
+	body := struct {
+		Data     []T               `json:"data"`
+		Included []models.Included `json:"included,omitempty"`
+		Links    map[string]string `json:"links,omitempty"`
+	}{
+		Data:     items[offset:end],
+		Included: included,
+	}
+
+	if end < len(items) {
+		next := url.Values{}
+		for key, values := range query {
+			next[key] = values
+		}
+		next.Set("page[offset]", strconv.Itoa(end))
+		next.Set("page[limit]", strconv.Itoa(limit))
+		body.Links = map[string]string{"next": r.URL.Path + "?" + next.Encode()}
+	}
+
+	writeJSON(w, http.StatusOK, body)
+}
+
+// writeResource writes a single JSON:API resource or the error that prevented finding it
+func writeResource[T any](w http.ResponseWriter, resource *T, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Data *T `json:"data"`
+	}{Data: resource})
+}
+
+// writeError writes a JSON:API error response
+func writeError(w http.ResponseWriter, err error) {
+	status, code := http.StatusBadRequest, "bad_request"
+	if errors.Is(err, ErrNotFound) {
+		status, code = http.StatusNotFound, "not_found"
+	}
+
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]string{{
+			"status": strconv.Itoa(status),
+			"code":   code,
+			"title":  http.StatusText(status),
+			"detail": err.Error(),
+		}},
+	})
+}
+
+// writeJSON writes a JSON:API response body
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
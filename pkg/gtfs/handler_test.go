@@ -0,0 +1,225 @@
+package gtfs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// newFeedClient returns an API client whose requests are answered by the feed,
+// falling back to the given transport
+func newFeedClient(feed *Feed, fallback http.RoundTripper) *mbta.Client {
+	cfg := &config.Config{
+		APIBaseURL: "https://api-v3.mbta.com",
+		Timeout:    5 * time.Second,
+	}
+	return mbta.NewClient(cfg, mbta.WithTransport(feed.Transport(fallback)))
+}
+
+func TestHandlerPagination(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/stops?filter[location_type]=1&page[limit]=2", nil)
+	feed.Handler().ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/vnd.api+json" {
+		t.Errorf("Expected JSON:API content type, got %s", contentType)
+	}
+
+	var body struct {
+		Data  []json.RawMessage `json:"data"`
+		Links map[string]string `json:"links"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Data) != 2 {
+		t.Errorf("Expected 2 stops on the first page, got %d", len(body.Data))
+	}
+	if next := body.Links["next"]; !strings.HasPrefix(next, "/stops?") || !strings.Contains(next, "page%5Boffset%5D=2") {
+		t.Errorf("Expected next link to offset 2, got %q", next)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	t.Run("ServesFeed", func(t *testing.T) {
+		client := newFeedClient(feed, nil)
+
+		stop, err := client.GetStop(ctx, "70067")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stop.Attributes.Name != "Harvard" {
+			t.Errorf("Expected Harvard, got %s", stop.Attributes.Name)
+		}
+
+		stops, err := client.GetStops(mbta.WithPageOptions(ctx, mbta.PageOptions{Limit: 4}))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(stops) != 11 {
+			t.Errorf("Expected all 11 stops across pages, got %d", len(stops))
+		}
+
+		schedules, included, err := client.GetSchedules(ctx, map[string]string{
+			"filter[stop]": "place-harsq",
+			"filter[date]": "2025-05-20",
+			"include":      "trip",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(schedules) != 3 {
+			t.Errorf("Expected 3 weekday schedules at Harvard, got %d", len(schedules))
+		}
+		if len(included) != 3 {
+			t.Errorf("Expected 3 included trips, got %d", len(included))
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		client := newFeedClient(feed, nil)
+		if _, err := client.GetStop(ctx, "missing"); err == nil {
+			t.Error("Expected error for a missing stop, got nil")
+		}
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		client := newFeedClient(feed, nil)
+		if _, _, err := client.GetSchedules(ctx, map[string]string{"filter[date]": "2025-05-20"}); err == nil {
+			t.Error("Expected error for schedules without a filter, got nil")
+		}
+	})
+
+	t.Run("FallsBack", func(t *testing.T) {
+		var fallbackPath string
+		fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fallbackPath = req.URL.Path
+			recorder := httptest.NewRecorder()
+			recorder.Header().Set("Content-Type", "application/vnd.api+json")
+			_, _ = recorder.WriteString(`{"data": []}`)
+			return recorder.Result(), nil
+		})
+		client := newFeedClient(feed, fallback)
+
+		if _, err := client.GetPredictions(ctx, map[string]string{"filter[stop]": "70067"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fallbackPath != "/predictions" {
+			t.Errorf("Expected predictions to fall back to the API, got %q", fallbackPath)
+		}
+
+		fallbackPath = ""
+		if _, err := client.GetRoutes(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fallbackPath != "" {
+			t.Errorf("Expected routes to be served from the feed, got fallback to %q", fallbackPath)
+		}
+	})
+
+	t.Run("NoFallback", func(t *testing.T) {
+		client := newFeedClient(feed, nil)
+		if _, err := client.GetPredictions(ctx, map[string]string{"filter[stop]": "70067"}); err == nil {
+			t.Error("Expected error for predictions without a fallback, got nil")
+		}
+	})
+}
+
+func TestTransportSkipsRateLimit(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		recorder.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = recorder.WriteString(`{"data": []}`)
+		return recorder.Result(), nil
+	})
+	limiter := mbta.NewRateLimiter(mbta.DefaultAnonymousRateLimit)
+	cache := mbta.NewResponseCache(mbta.NewMemoryCache(100), nil)
+	client := mbta.NewClient(
+		&config.Config{APIBaseURL: "https://api-v3.mbta.com", Timeout: 5 * time.Second},
+		mbta.WithCache(cache),
+		mbta.WithRetry(mbta.DefaultRetryPolicy),
+		mbta.WithRateLimiter(limiter),
+		mbta.WithTransport(feed.Transport(fallback)),
+	)
+
+	// Requests the feed answers cost no API quota and aren't cached
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetStop(ctx, "70067"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, _, err := client.GetSchedules(ctx, map[string]string{"filter[stop]": "place-harsq", "filter[date]": "2025-05-20"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if tokens := limiter.Quota().AvailableTokens; tokens != mbta.DefaultAnonymousRateLimit {
+		t.Errorf("Expected feed requests to leave %d tokens, got %d", mbta.DefaultAnonymousRateLimit, tokens)
+	}
+	if stats := cache.Stats(); stats.Hits+stats.Misses != 0 {
+		t.Errorf("Expected feed requests to bypass the cache, got %+v", stats)
+	}
+
+	// Requests that fall back to the API still take a token
+	if _, err := client.GetPredictions(ctx, map[string]string{"filter[stop]": "70067"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tokens := limiter.Quota().AvailableTokens; tokens != mbta.DefaultAnonymousRateLimit-1 {
+		t.Errorf("Expected a prediction request to take a token, got %d left", tokens)
+	}
+	if stats := cache.Stats(); stats.Misses != 1 {
+		t.Errorf("Expected the prediction request to be cached, got %+v", stats)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTransportStationHierarchy(t *testing.T) {
+	feed := loadTestFeed(t)
+	client := newFeedClient(feed, nil)
+
+	// A boarding area on a platform is a grandchild of the station
+	feed.stops["70200-front"] = &models.Stop{
+		ID:            "70200-front",
+		Type:          "stop",
+		Attributes:    models.StopAttributes{Name: "Park Street", LocationType: models.LocationTypeBoardingArea},
+		Relationships: map[string]interface{}{"parent_station": map[string]interface{}{"data": map[string]interface{}{"id": "70200", "type": "stop"}}},
+	}
+	feed.children["70200"] = append(feed.children["70200"], "70200-front")
+
+	hierarchy, err := client.GetStationHierarchy(context.Background(), "70200")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hierarchy.Station.ID != "place-pktrm" {
+		t.Errorf("Expected station place-pktrm, got %s", hierarchy.Station.ID)
+	}
+	if ids := hierarchy.PlatformIDs(); len(ids) != 2 || ids[0] != "70075" || ids[1] != "70200" {
+		t.Errorf("Expected platforms 70075 and 70200, got %v", ids)
+	}
+	if len(hierarchy.BoardingAreas) != 1 || !hierarchy.Contains("70200-front") {
+		t.Errorf("Expected the boarding area on 70200, got %v", hierarchy.BoardingAreas)
+	}
+}
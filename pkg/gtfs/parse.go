@@ -0,0 +1,395 @@
+package gtfs
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// table is a GTFS file being read one record at a time
+type table struct {
+	file    string
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+// get returns the value of a column in the current record, or "" if the file has no such column
+func (t *table) get(column string) string {
+	index, ok := t.columns[column]
+	if !ok || index >= len(t.record) {
+		return ""
+	}
+	return strings.TrimSpace(t.record[index])
+}
+
+// getInt returns an integer column, or 0 if it is empty or invalid
+func (t *table) getInt(column string) int {
+	value, _ := strconv.Atoi(t.get(column))
+	return value
+}
+
+// getFloat returns a floating point column, or 0 if it is empty or invalid
+func (t *table) getFloat(column string) float64 {
+	value, _ := strconv.ParseFloat(t.get(column), 64)
+	return value
+}
+
+// optional returns a pointer to a column's value, or nil if it is empty
+func (t *table) optional(column string) *string {
+	value := t.get(column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// errorf reports a problem with the current record
+func (t *table) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s line %d: %s", t.file, t.line, fmt.Sprintf(format, args...))
+}
+
+// readTable calls parse for every record of a GTFS file
+func readTable(fsys fs.FS, file string, required bool, parse func(*table) error) error {
+	r, err := fsys.Open(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("error opening %s: %w", file, err)
+	}
+	defer func() { _ = r.Close() }()
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("error reading %s header: %w", file, err)
+	}
+
+	t := &table{file: file, line: 1, columns: make(map[string]int, len(header))}
+	for i, column := range header {
+		// Some producers start files with a byte order mark
+		column = strings.TrimPrefix(column, "\ufeff")
+		t.columns[strings.TrimSpace(column)] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", file, err)
+		}
+		t.line++
+		t.record = record
+
+		if err := parse(t); err != nil {
+			return err
+		}
+	}
+}
+
+// parseAgencyTimezone returns the timezone named in agency.txt
+func parseAgencyTimezone(fsys fs.FS) (string, error) {
+	timezone := ""
+	err := readTable(fsys, "agency.txt", false, func(t *table) error {
+		if timezone == "" {
+			timezone = t.get("agency_timezone")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	return timezone, nil
+}
+
+// parseStops reads stops.txt
+func (f *Feed) parseStops(t *table) error {
+	id := t.get("stop_id")
+	if id == "" {
+		return t.errorf("missing stop_id")
+	}
+
+	stop := &models.Stop{
+		ID:   id,
+		Type: "stop",
+		Attributes: models.StopAttributes{
+			Address:            t.get("stop_address"),
+			AtStreet:           t.optional("at_street"),
+			Description:        t.get("stop_desc"),
+			Latitude:           t.getFloat("stop_lat"),
+			LocationType:       t.getInt("location_type"),
+			Longitude:          t.getFloat("stop_lon"),
+			Municipality:       t.get("municipality"),
+			Name:               t.get("stop_name"),
+			OnStreet:           t.optional("on_street"),
+			PlatformCode:       t.get("platform_code"),
+			PlatformName:       t.get("platform_name"),
+			WheelchairBoarding: t.getInt("wheelchair_boarding"),
+		},
+		Relationships: map[string]interface{}{
+			"parent_station": relationship("stop", t.get("parent_station")),
+		},
+	}
+
+	if vehicleType := t.get("vehicle_type"); vehicleType != "" {
+		if value, err := strconv.Atoi(vehicleType); err == nil {
+			stop.Attributes.VehicleType = &value
+		}
+	}
+
+	f.stops[id] = stop
+	return nil
+}
+
+// parseRoutes reads routes.txt
+func (f *Feed) parseRoutes(t *table) error {
+	id := t.get("route_id")
+	if id == "" {
+		return t.errorf("missing route_id")
+	}
+
+	f.routes[id] = &models.Route{
+		ID:   id,
+		Type: "route",
+		Attributes: models.RouteAttributes{
+			Color:       t.get("route_color"),
+			Description: t.get("route_desc"),
+			FareClass:   t.get("route_fare_class"),
+			LongName:    t.get("route_long_name"),
+			ShortName:   t.get("route_short_name"),
+			SortOrder:   t.getInt("route_sort_order"),
+			TextColor:   t.get("route_text_color"),
+			Type:        t.getInt("route_type"),
+		},
+		Relationships: map[string]interface{}{
+			"line": relationship("line", t.get("line_id")),
+		},
+	}
+	return nil
+}
+
+// parseDirections reads the MBTA's directions.txt extension, which names each
+// direction of a route
+func (f *Feed) parseDirections(t *table) error {
+	route, ok := f.routes[t.get("route_id")]
+	if !ok {
+		return nil
+	}
+
+	direction := t.getInt("direction_id")
+	if direction < 0 || direction > 1 {
+		return nil
+	}
+
+	for len(route.Attributes.DirectionNames) < 2 {
+		route.Attributes.DirectionNames = append(route.Attributes.DirectionNames, "")
+		route.Attributes.DirectionDestinations = append(route.Attributes.DirectionDestinations, "")
+	}
+	route.Attributes.DirectionNames[direction] = t.get("direction")
+	route.Attributes.DirectionDestinations[direction] = t.get("direction_destination")
+	return nil
+}
+
+// parseTrips reads trips.txt
+func (f *Feed) parseTrips(t *table) error {
+	id := t.get("trip_id")
+	if id == "" {
+		return t.errorf("missing trip_id")
+	}
+
+	f.trips[id] = &models.Trip{
+		ID:   id,
+		Type: "trip",
+		Attributes: models.TripAttributes{
+			Name:              t.get("trip_short_name"),
+			Headsign:          t.get("trip_headsign"),
+			Direction:         t.getInt("direction_id"),
+			BlockID:           t.optional("block_id"),
+			ServiceID:         t.get("service_id"),
+			ShapeID:           t.optional("shape_id"),
+			WheelchairEnabled: t.getInt("wheelchair_accessible") == 1,
+			BikeAllowed:       t.getInt("bikes_allowed") == 1,
+		},
+		Relationships: map[string]interface{}{
+			"route":   relationship("route", t.get("route_id")),
+			"service": relationship("service", t.get("service_id")),
+			"shape":   relationship("shape", t.get("shape_id")),
+		},
+	}
+	return nil
+}
+
+// parseStopTimes reads stop_times.txt
+func (f *Feed) parseStopTimes(t *table) error {
+	tripID := t.get("trip_id")
+	if _, ok := f.trips[tripID]; !ok {
+		return nil
+	}
+
+	arrival, arrivalErr := parseTime(t.get("arrival_time"))
+	departure, departureErr := parseTime(t.get("departure_time"))
+	switch {
+	case arrivalErr != nil && departureErr != nil:
+		// Stops between timepoints may leave times to be interpolated
+		return nil
+	case arrivalErr != nil:
+		arrival = departure
+	case departureErr != nil:
+		departure = arrival
+	}
+
+	// Strings repeated on millions of rows share storage with the stop's ID
+	stopID := t.get("stop_id")
+	if stop, ok := f.stops[stopID]; ok {
+		stopID = stop.ID
+	}
+
+	f.stopTimes[tripID] = append(f.stopTimes[tripID], StopTime{
+		TripID:       f.trips[tripID].ID,
+		StopID:       stopID,
+		StopSequence: t.getInt("stop_sequence"),
+		Arrival:      arrival,
+		Departure:    departure,
+		StopHeadsign: strings.Clone(t.get("stop_headsign")),
+		PickupType:   t.getInt("pickup_type"),
+		DropOffType:  t.getInt("drop_off_type"),
+		Timepoint:    t.get("timepoint") != "0",
+	})
+	return nil
+}
+
+// parseCalendar reads calendar.txt
+func (f *Feed) parseCalendar(t *table) error {
+	id := t.get("service_id")
+	if id == "" {
+		return t.errorf("missing service_id")
+	}
+
+	service := Service{
+		ID:        id,
+		StartDate: t.get("start_date"),
+		EndDate:   t.get("end_date"),
+	}
+	for day, column := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+		service.Weekdays[day] = t.get(column) == "1"
+	}
+
+	f.services[id] = service
+	return nil
+}
+
+// parseCalendarDates reads calendar_dates.txt
+func (f *Feed) parseCalendarDates(t *table) error {
+	id := t.get("service_id")
+	date := t.get("date")
+	if id == "" || date == "" {
+		return t.errorf("missing service_id or date")
+	}
+
+	if f.exceptions[id] == nil {
+		f.exceptions[id] = make(map[string]int)
+	}
+	f.exceptions[id][date] = t.getInt("exception_type")
+	return nil
+}
+
+// parseTransfers reads transfers.txt
+func (f *Feed) parseTransfers(t *table) error {
+	f.transfers = append(f.transfers, Transfer{
+		FromStopID:      t.get("from_stop_id"),
+		ToStopID:        t.get("to_stop_id"),
+		FromRouteID:     t.get("from_route_id"),
+		ToRouteID:       t.get("to_route_id"),
+		FromTripID:      t.get("from_trip_id"),
+		ToTripID:        t.get("to_trip_id"),
+		TransferType:    t.getInt("transfer_type"),
+		MinTransferTime: time.Duration(t.getInt("min_transfer_time")) * time.Second,
+	})
+	return nil
+}
+
+// parsePathways reads pathways.txt
+func (f *Feed) parsePathways(t *table) error {
+	from, to := t.get("from_stop_id"), t.get("to_stop_id")
+	if from == "" || to == "" {
+		return t.errorf("missing from_stop_id or to_stop_id")
+	}
+
+	f.pathways = append(f.pathways, Pathway{
+		ID:            t.get("pathway_id"),
+		FromStopID:    from,
+		ToStopID:      to,
+		Mode:          t.getInt("pathway_mode"),
+		Bidirectional: t.getInt("is_bidirectional") == 1,
+		Length:        t.getFloat("length"),
+		TraversalTime: time.Duration(t.getInt("traversal_time")) * time.Second,
+	})
+	return nil
+}
+
+// parseShapes reads shapes.txt
+func (f *Feed) parseShapes(t *table) error {
+	id := t.get("shape_id")
+	if id == "" {
+		return t.errorf("missing shape_id")
+	}
+
+	shape, ok := f.shapes[id]
+	if !ok {
+		shape = &Shape{ID: id}
+		f.shapes[id] = shape
+	}
+	shape.Points = append(shape.Points, ShapePoint{
+		Latitude:         t.getFloat("shape_pt_lat"),
+		Longitude:        t.getFloat("shape_pt_lon"),
+		Sequence:         t.getInt("shape_pt_sequence"),
+		DistanceTraveled: t.getFloat("shape_dist_traveled"),
+	})
+	return nil
+}
+
+// parseTime parses a GTFS HH:MM:SS time, which may be past 24:00:00
+func parseTime(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+
+	var fields [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid time %q", value)
+		}
+		fields[i] = n
+	}
+
+	return time.Duration(fields[0])*time.Hour + time.Duration(fields[1])*time.Minute + time.Duration(fields[2])*time.Second, nil
+}
+
+// relationship builds a to-one JSON:API relationship; an empty ID gives null data
+func relationship(resourceType, id string) map[string]interface{} {
+	if id == "" {
+		return map[string]interface{}{"data": nil}
+	}
+	return map[string]interface{}{"data": map[string]interface{}{"id": id, "type": resourceType}}
+}
@@ -0,0 +1,112 @@
+package gtfs
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "08:07:30", expected: 8*time.Hour + 7*time.Minute + 30*time.Second},
+		{value: "00:00:00", expected: 0},
+		{value: "25:15:00", expected: 25*time.Hour + 15*time.Minute},
+		{value: "8:05:00", expected: 8*time.Hour + 5*time.Minute},
+		{value: "", wantErr: true},
+		{value: "08:00", wantErr: true},
+		{value: "aa:00:00", wantErr: true},
+		{value: "-1:00:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTime(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got nil", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestReadTable(t *testing.T) {
+	t.Run("ByteOrderMarkAndQuoting", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"stops.txt": {Data: []byte("\ufeffstop_id, stop_name\n1,\"Park St, Boston\"\n")},
+		}
+
+		var names []string
+		err := readTable(fsys, "stops.txt", true, func(tb *table) error {
+			names = append(names, tb.get("stop_id")+"="+tb.get("stop_name"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(names) != 1 || names[0] != "1=Park St, Boston" {
+			t.Errorf("Expected [1=Park St, Boston], got %v", names)
+		}
+	})
+
+	t.Run("MissingOptionalFile", func(t *testing.T) {
+		err := readTable(fstest.MapFS{}, "shapes.txt", false, func(*table) error {
+			t.Error("Expected no records")
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Expected no error for a missing optional file, got %v", err)
+		}
+	})
+
+	t.Run("RecordError", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"stops.txt": {Data: []byte("stop_id,stop_name\n1,One\n,Two\n")},
+		}
+		feed := &Feed{stops: make(map[string]*models.Stop)}
+		err := readTable(fsys, "stops.txt", true, feed.parseStops)
+		if err == nil || !strings.Contains(err.Error(), "stops.txt line 3") {
+			t.Errorf("Expected error on stops.txt line 3, got %v", err)
+		}
+	})
+}
+
+func TestParseStopTimesMissingTimes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"stops.txt":  {Data: []byte("stop_id,stop_name\n1,One\n2,Two\n3,Three\n")},
+		"routes.txt": {Data: []byte("route_id,route_type\nR,3\n")},
+		"trips.txt":  {Data: []byte("route_id,service_id,trip_id\nR,S,T\n")},
+		"stop_times.txt": {Data: []byte("trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+			"T,,08:00:00,1,1\nT,,,2,2\nT,08:20:00,,3,3\n")},
+	}
+
+	feed, err := Parse(fsys)
+	if err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+
+	times := feed.StopTimes("T")
+	if len(times) != 2 {
+		t.Fatalf("Expected untimed stop to be skipped, got %d stop times", len(times))
+	}
+	if times[0].Arrival != 8*time.Hour {
+		t.Errorf("Expected arrival to default to departure, got %v", times[0].Arrival)
+	}
+	if times[1].Departure != 8*time.Hour+20*time.Minute {
+		t.Errorf("Expected departure to default to arrival, got %v", times[1].Departure)
+	}
+}
@@ -0,0 +1,488 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Feed serves static data directly to the server
+var (
+	_ mbta.RouteDataSource    = (*Feed)(nil)
+	_ mbta.StopDataSource     = (*Feed)(nil)
+	_ mbta.ScheduleDataSource = (*Feed)(nil)
+)
+
+// ErrNotFound is returned when a requested resource isn't in the feed. It
+// matches mbta.ErrNotFound, like a 404 response from the API.
+var ErrNotFound = fmt.Errorf("%w in GTFS feed", mbta.ErrNotFound)
+
+// ErrMissingFilter is returned for schedule queries without a route, stop or trip filter
+var ErrMissingFilter = errors.New("schedules require a filter[route], filter[stop] or filter[trip] parameter")
+
+// GetRoutes returns every route in the feed in sort order
+func (f *Feed) GetRoutes(ctx context.Context) ([]models.Route, error) {
+	return f.findRoutes(nil), nil
+}
+
+// GetRoute returns a route by ID
+func (f *Feed) GetRoute(ctx context.Context, routeID string) (*models.Route, error) {
+	route, ok := f.routes[routeID]
+	if !ok {
+		return nil, fmt.Errorf("route %s %w", routeID, ErrNotFound)
+	}
+	return route, nil
+}
+
+// GetStops returns every stop in the feed
+func (f *Feed) GetStops(ctx context.Context) ([]models.Stop, error) {
+	return f.findStops(nil), nil
+}
+
+// GetStop returns a stop by ID
+func (f *Feed) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	stop, ok := f.stops[stopID]
+	if !ok {
+		return nil, fmt.Errorf("stop %s %w", stopID, ErrNotFound)
+	}
+	return stop, nil
+}
+
+// GetStopsForRoute returns the IDs of the stations and stops a route serves,
+// like the API's filter[route] stop query
+func (f *Feed) GetStopsForRoute(ctx context.Context, routeID string) ([]string, error) {
+	stops := f.findStops(map[string]string{"filter[route]": routeID})
+	ids := make([]string, len(stops))
+	for i, stop := range stops {
+		ids[i] = stop.ID
+	}
+	return ids, nil
+}
+
+// GetStationHierarchy resolves a station, platform, entrance or boarding area
+// to the station it belongs to, with all of that station's descendants
+func (f *Feed) GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error) {
+	stop, ok := f.stops[stopID]
+	if !ok {
+		return nil, fmt.Errorf("stop %s %w", stopID, ErrNotFound)
+	}
+	for depth := 0; depth < 3; depth++ {
+		parent, ok := f.stops[stop.GetParentStationID()]
+		if !ok {
+			break
+		}
+		stop = parent
+	}
+
+	var descendants []models.Stop
+	pending := append([]string(nil), f.children[stop.ID]...)
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = append(pending[1:], f.children[id]...)
+		descendants = append(descendants, *f.stops[id])
+	}
+
+	return models.NewStationHierarchy(*stop, descendants), nil
+}
+
+// FindNearbyStops returns up to maxResults stops matching the filter within
+// radius kilometers of a point, closest first. A radius of 0 doesn't limit
+// the distance.
+func (f *Feed) FindNearbyStops(ctx context.Context, lat, lon, radius float64, maxResults int, filter mbta.StopFilter) ([]models.NearbyStation, error) {
+	return f.stopIndex.Nearest(lat, lon, max(maxResults, 0), radius, filter), nil
+}
+
+// GetTrips returns trips matching the same filters as the MBTA API:
+// filter[id], filter[route], filter[direction_id] and filter[date]
+func (f *Feed) GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error) {
+	ids := filterSet(params, "filter[id]")
+	routes := filterSet(params, "filter[route]")
+	directions := filterSet(params, "filter[direction_id]")
+
+	var services map[string]bool
+	if value := params["filter[date]"]; value != "" {
+		date, err := f.parseDate(value)
+		if err != nil {
+			return nil, err
+		}
+		services = f.ActiveServices(date)
+	}
+
+	trips := make([]models.Trip, 0)
+	for _, id := range f.tripIDs {
+		trip := f.trips[id]
+		if ids != nil && !ids[id] {
+			continue
+		}
+		if routes != nil && !routes[trip.GetRouteID()] {
+			continue
+		}
+		if directions != nil && !directions[strconv.Itoa(trip.Attributes.Direction)] {
+			continue
+		}
+		if services != nil && !services[trip.GetServiceID()] {
+			continue
+		}
+		trips = append(trips, *trip)
+	}
+	return trips, nil
+}
+
+// GetTrip returns a trip by ID
+func (f *Feed) GetTrip(ctx context.Context, tripID string) (*models.Trip, error) {
+	trip, ok := f.trips[tripID]
+	if !ok {
+		return nil, fmt.Errorf("trip %s %w", tripID, ErrNotFound)
+	}
+	return trip, nil
+}
+
+// GetSchedules returns scheduled stop times matching the same filters as the
+// MBTA API: filter[route], filter[stop], filter[trip], filter[direction_id],
+// filter[date], filter[min_time] and filter[max_time]. A parent station in
+// filter[stop] matches its platforms. Use include=trip,route,stop to return
+// the related resources, and sort=departure_time or arrival_time (prefixed
+// with - for descending) to order them.
+func (f *Feed) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	routes := filterSet(params, "filter[route]")
+	stops := f.expandStations(filterSet(params, "filter[stop]"))
+	trips := filterSet(params, "filter[trip]")
+	directions := filterSet(params, "filter[direction_id]")
+	if routes == nil && stops == nil && trips == nil {
+		return nil, nil, ErrMissingFilter
+	}
+
+	// Without a date, use the service day running now, which continues past
+	// midnight
+	value := params["filter[date]"]
+	if value == "" {
+		value = mbta.ServiceDayOf(time.Now()).String()
+	}
+	date, err := f.parseDate(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	services := f.ActiveServices(date)
+
+	minTime, hasMin, err := parseFilterTime(params["filter[min_time]"])
+	if err != nil {
+		return nil, nil, err
+	}
+	maxTime, hasMax, err := parseFilterTime(params["filter[max_time]"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schedules := make([]models.Schedule, 0)
+	for _, tripID := range f.candidateTrips(routes, stops, trips) {
+		trip := f.trips[tripID]
+		if !services[trip.GetServiceID()] {
+			continue
+		}
+		if routes != nil && !routes[trip.GetRouteID()] {
+			continue
+		}
+		if directions != nil && !directions[strconv.Itoa(trip.Attributes.Direction)] {
+			continue
+		}
+
+		times := f.stopTimes[tripID]
+		for i, st := range times {
+			if stops != nil && !stops[st.StopID] {
+				continue
+			}
+			if (hasMin && st.Departure < minTime) || (hasMax && st.Departure > maxTime) {
+				continue
+			}
+			schedules = append(schedules, f.schedule(trip, st, date, i == 0, i == len(times)-1))
+		}
+	}
+
+	sortSchedules(schedules, params["sort"])
+
+	return schedules, f.included(schedules, params["include"]), nil
+}
+
+// findRoutes returns routes matching filter[id], filter[type] and filter[stop]
+func (f *Feed) findRoutes(params map[string]string) []models.Route {
+	ids := filterSet(params, "filter[id]")
+	types := filterSet(params, "filter[type]")
+
+	var served map[string]bool
+	if stops := f.expandStations(filterSet(params, "filter[stop]")); stops != nil {
+		served = make(map[string]bool)
+		for stopID := range stops {
+			for _, routeID := range f.stopRoutes[stopID] {
+				served[routeID] = true
+			}
+		}
+	}
+
+	routes := make([]models.Route, 0)
+	for _, id := range f.routeIDs {
+		route := f.routes[id]
+		if ids != nil && !ids[id] {
+			continue
+		}
+		if types != nil && !types[strconv.Itoa(route.Attributes.Type)] {
+			continue
+		}
+		if served != nil && !served[id] {
+			continue
+		}
+		routes = append(routes, *route)
+	}
+	return routes
+}
+
+// findStops returns stops matching filter[id], filter[location_type],
+// filter[route] and filter[route_type]. Like the API, route filters return the
+// parent station of each platform served.
+func (f *Feed) findStops(params map[string]string) []models.Stop {
+	ids := filterSet(params, "filter[id]")
+	locationTypes := filterSet(params, "filter[location_type]")
+
+	var served map[string]bool
+	routes := filterSet(params, "filter[route]")
+	if routeTypes := filterSet(params, "filter[route_type]"); routeTypes != nil {
+		if routes == nil {
+			routes = make(map[string]bool)
+		}
+		for id, route := range f.routes {
+			if routeTypes[strconv.Itoa(route.Attributes.Type)] {
+				routes[id] = true
+			}
+		}
+	}
+	if routes != nil {
+		served = make(map[string]bool)
+		for routeID := range routes {
+			for _, stopID := range f.routeStops[routeID] {
+				served[f.station(stopID)] = true
+			}
+		}
+	}
+
+	stops := make([]models.Stop, 0)
+	for _, id := range f.stopIDs {
+		stop := f.stops[id]
+		if ids != nil && !ids[id] {
+			continue
+		}
+		if locationTypes != nil && !locationTypes[strconv.Itoa(stop.Attributes.LocationType)] {
+			continue
+		}
+		if served != nil && !served[id] {
+			continue
+		}
+		stops = append(stops, *stop)
+	}
+	return stops
+}
+
+// candidateTrips returns the trips that could match a schedule query, using
+// the most selective index available
+func (f *Feed) candidateTrips(routes, stops, trips map[string]bool) []string {
+	var candidates []string
+	switch {
+	case trips != nil:
+		for id := range trips {
+			if _, ok := f.trips[id]; ok {
+				candidates = append(candidates, id)
+			}
+		}
+	case stops != nil:
+		seen := make(map[string]bool)
+		for stopID := range stops {
+			for _, id := range f.stopTrips[stopID] {
+				if !seen[id] {
+					seen[id] = true
+					candidates = append(candidates, id)
+				}
+			}
+		}
+	default:
+		for routeID := range routes {
+			candidates = append(candidates, f.routeTrips[routeID]...)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// expandStations adds the child platforms of any parent stations in a stop filter
+func (f *Feed) expandStations(stops map[string]bool) map[string]bool {
+	if stops == nil {
+		return nil
+	}
+	expanded := make(map[string]bool, len(stops))
+	for id := range stops {
+		expanded[id] = true
+		for _, child := range f.children[id] {
+			expanded[child] = true
+		}
+	}
+	return expanded
+}
+
+// schedule converts a stop time on a service date into an API schedule. Like
+// the API, the first stop has no arrival time and the last no departure time.
+func (f *Feed) schedule(trip *models.Trip, st StopTime, date time.Time, first, last bool) models.Schedule {
+	schedule := models.Schedule{
+		ID:   fmt.Sprintf("schedule-%s-%s-%d", trip.ID, st.StopID, st.StopSequence),
+		Type: "schedule",
+		Attributes: models.ScheduleAttributes{
+			DropOffType:  st.DropOffType,
+			PickupType:   st.PickupType,
+			StopHeadsign: st.StopHeadsign,
+			StopSequence: st.StopSequence,
+			Timepoint:    st.Timepoint,
+		},
+		Relationships: map[string]interface{}{
+			"route":      relationship("route", trip.GetRouteID()),
+			"stop":       relationship("stop", st.StopID),
+			"trip":       relationship("trip", trip.ID),
+			"prediction": relationship("prediction", ""),
+		},
+	}
+
+	if !first {
+		schedule.Attributes.ArrivalTime = f.ScheduledTime(date, st.Arrival).Format(time.RFC3339)
+	}
+	if !last {
+		schedule.Attributes.DepartureTime = f.ScheduledTime(date, st.Departure).Format(time.RFC3339)
+	}
+
+	return schedule
+}
+
+// included returns the trips, routes and stops of schedules requested by an include parameter
+func (f *Feed) included(schedules []models.Schedule, include string) []models.Included {
+	if include == "" {
+		return nil
+	}
+
+	includeTypes := make(map[string]bool)
+	for _, name := range strings.Split(include, ",") {
+		includeTypes[strings.TrimSpace(name)] = true
+	}
+
+	included := make([]models.Included, 0)
+	seen := make(map[models.ResourceIdentifier]bool)
+	add := func(resourceType, id string, attributes, relationships interface{}) {
+		key := models.ResourceIdentifier{ID: id, Type: resourceType}
+		if id == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		included = append(included, models.Included{ID: id, Type: resourceType, Attributes: attributes, Relationships: relationships})
+	}
+
+	for _, schedule := range schedules {
+		if includeTypes["trip"] {
+			if trip, ok := f.trips[schedule.GetTripID()]; ok {
+				add("trip", trip.ID, trip.Attributes, trip.Relationships)
+			}
+		}
+		if includeTypes["route"] {
+			if route, ok := f.routes[schedule.GetRouteID()]; ok {
+				add("route", route.ID, route.Attributes, route.Relationships)
+			}
+		}
+		if includeTypes["stop"] {
+			if stop, ok := f.stops[schedule.GetStopID()]; ok {
+				add("stop", stop.ID, stop.Attributes, stop.Relationships)
+			}
+		}
+	}
+
+	return included
+}
+
+// parseDate parses a YYYY-MM-DD filter date in the feed's timezone
+func (f *Feed) parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, f.location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", value, err)
+	}
+	return date, nil
+}
+
+// parseFilterTime parses an HH:MM filter time, which may be past 24:00
+func parseFilterTime(value string) (time.Duration, bool, error) {
+	if value == "" {
+		return 0, false, nil
+	}
+	offset, err := parseTime(value + ":00")
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid time filter %q", value)
+	}
+	return offset, true, nil
+}
+
+// sortSchedules orders schedules by a sort parameter, keeping trip order for ties
+func sortSchedules(schedules []models.Schedule, sortBy string) {
+	descending := strings.HasPrefix(sortBy, "-")
+	field := strings.TrimPrefix(sortBy, "-")
+
+	var key func(models.Schedule) string
+	switch field {
+	case "departure_time":
+		key = func(s models.Schedule) string {
+			return firstNonEmpty(s.Attributes.DepartureTime, s.Attributes.ArrivalTime)
+		}
+	case "arrival_time":
+		key = func(s models.Schedule) string {
+			return firstNonEmpty(s.Attributes.ArrivalTime, s.Attributes.DepartureTime)
+		}
+	default:
+		return
+	}
+
+	sort.SliceStable(schedules, func(i, j int) bool {
+		a, b := parseScheduleTime(key(schedules[i])), parseScheduleTime(key(schedules[j]))
+		if descending {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+}
+
+// parseScheduleTime parses an RFC 3339 schedule time, returning the zero time if it is invalid
+func parseScheduleTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// filterSet splits a comma-separated filter parameter into a set, or returns
+// nil when the parameter is absent
+func filterSet(params map[string]string, key string) map[string]bool {
+	value, ok := params[key]
+	if !ok || value == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,330 @@
+package gtfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// scheduleTripIDs returns the trip IDs of schedules in order
+func scheduleTripIDs(schedules []models.Schedule) []string {
+	ids := make([]string, len(schedules))
+	for i, schedule := range schedules {
+		ids[i] = schedule.GetTripID()
+	}
+	return ids
+}
+
+func TestGetRoutesAndStops(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	routes, err := feed.GetRoutes(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(routes) != 3 || routes[0].ID != "Red" || routes[2].ID != "1" {
+		t.Errorf("Expected routes in sort order Red, Green-C, 1, got %v", routes)
+	}
+
+	if _, err := feed.GetRoute(ctx, "Blue"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	stops, err := feed.GetStops(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stops) != 11 {
+		t.Errorf("Expected 11 stops, got %d", len(stops))
+	}
+
+	stop, err := feed.GetStop(ctx, "place-pktrm")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stop.Attributes.Name != "Park Street" {
+		t.Errorf("Expected Park Street, got %s", stop.Attributes.Name)
+	}
+}
+
+func TestFindRoutes(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected []string
+	}{
+		{name: "Type", params: map[string]string{"filter[type]": "0,1"}, expected: []string{"Red", "Green-C"}},
+		{name: "Station", params: map[string]string{"filter[stop]": "place-pktrm"}, expected: []string{"Red", "Green-C"}},
+		{name: "BusStop", params: map[string]string{"filter[stop]": "2168"}, expected: []string{"1"}},
+		{name: "ID", params: map[string]string{"filter[id]": "Green-C"}, expected: []string{"Green-C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := feed.findRoutes(tt.params)
+			if len(routes) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, routes)
+			}
+			for i, route := range routes {
+				if route.ID != tt.expected[i] {
+					t.Errorf("Expected route %s at %d, got %s", tt.expected[i], i, route.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFindStops(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		expected []string
+	}{
+		{name: "RouteReturnsStations", params: map[string]string{"filter[route]": "Red"}, expected: []string{"place-alfcl", "place-harsq", "place-pktrm"}},
+		{name: "RouteType", params: map[string]string{"filter[route_type]": "3"}, expected: []string{"110", "2168"}},
+		{name: "LocationType", params: map[string]string{"filter[location_type]": "1"}, expected: []string{"place-alfcl", "place-harsq", "place-pktrm"}},
+		{name: "ID", params: map[string]string{"filter[id]": "70061,70151"}, expected: []string{"70061", "70151"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stops := feed.findStops(tt.params)
+			if len(stops) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %d stops", tt.expected, len(stops))
+			}
+			for i, stop := range stops {
+				if stop.ID != tt.expected[i] {
+					t.Errorf("Expected stop %s at %d, got %s", tt.expected[i], i, stop.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetStopsForRoute(t *testing.T) {
+	feed := loadTestFeed(t)
+
+	ids, err := feed.GetStopsForRoute(context.Background(), "Red")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"place-alfcl", "place-harsq", "place-pktrm"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, ids)
+			break
+		}
+	}
+}
+
+func TestGetStationHierarchy(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	for _, stopID := range []string{"place-harsq", "70067", "70068"} {
+		hierarchy, err := feed.GetStationHierarchy(ctx, stopID)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", stopID, err)
+		}
+		if hierarchy.Station.ID != "place-harsq" {
+			t.Errorf("Expected %s to resolve to place-harsq, got %s", stopID, hierarchy.Station.ID)
+		}
+		if ids := hierarchy.PlatformIDs(); len(ids) != 2 || ids[0] != "70067" || ids[1] != "70068" {
+			t.Errorf("Expected platforms 70067 and 70068, got %v", ids)
+		}
+	}
+
+	// A stop without a station is its own hierarchy
+	hierarchy, err := feed.GetStationHierarchy(ctx, "2168")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if hierarchy.Station.ID != "2168" || len(hierarchy.Platforms) != 0 {
+		t.Errorf("Expected 2168 alone, got %+v", hierarchy)
+	}
+
+	if _, err := feed.GetStationHierarchy(ctx, "place-nowhere"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFindNearbyStops(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		k        int
+		radius   float64
+		filter   mbta.StopFilter
+		expected []string
+	}{
+		{name: "NearestBusStop", k: 1, filter: mbta.StopFilter{RouteTypes: []int{models.RouteTypeBus}}, expected: []string{"2168"}},
+		{name: "StationsWithinRadius", radius: 4, filter: mbta.StopFilter{LocationTypes: []int{models.LocationTypeStation}}, expected: []string{"place-harsq", "place-alfcl"}},
+		{name: "StationByPlatformRouteType", k: 1, filter: mbta.StopFilter{LocationTypes: []int{models.LocationTypeStation}, RouteTypes: []int{models.RouteTypeLightRail}}, expected: []string{"place-pktrm"}},
+		{name: "Wheelchair", k: 2, filter: mbta.StopFilter{RouteTypes: []int{models.RouteTypeBus}, WheelchairAccessible: true}, expected: []string{"2168"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := feed.FindNearbyStops(ctx, 42.3734, -71.1189, tt.radius, tt.k, tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %d stops", tt.expected, len(results))
+			}
+			for i, result := range results {
+				if result.Stop.ID != tt.expected[i] {
+					t.Errorf("Expected stop %s at %d, got %s", tt.expected[i], i, result.Stop.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTrips(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	trips, err := feed.GetTrips(ctx, map[string]string{"filter[route]": "Red", "filter[date]": "2025-05-24"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(trips) != 1 || trips[0].ID != "red-sat" {
+		t.Errorf("Expected only red-sat on a Saturday, got %v", trips)
+	}
+
+	if _, err := feed.GetTrips(ctx, map[string]string{"filter[date]": "May 24"}); err == nil {
+		t.Error("Expected error for an invalid date, got nil")
+	}
+
+	if _, err := feed.GetTrip(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetSchedules(t *testing.T) {
+	feed := loadTestFeed(t)
+	ctx := context.Background()
+
+	t.Run("StationExpandsToPlatforms", func(t *testing.T) {
+		schedules, _, err := feed.GetSchedules(ctx, map[string]string{
+			"filter[stop]": "place-pktrm",
+			"filter[date]": "2025-05-20",
+			"sort":         "departure_time",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// red-1 arrives 08:20, green-1 leaves 08:25, red-2 09:20, red-late 00:50
+		expected := []string{"red-1", "green-1", "red-2", "red-late"}
+		got := scheduleTripIDs(schedules)
+		if len(got) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("Expected %v, got %v", expected, got)
+				break
+			}
+		}
+	})
+
+	t.Run("FirstAndLastStops", func(t *testing.T) {
+		schedules, _, err := feed.GetSchedules(ctx, map[string]string{
+			"filter[trip]": "red-late",
+			"filter[date]": "2025-05-20",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(schedules) != 3 {
+			t.Fatalf("Expected 3 schedules, got %d", len(schedules))
+		}
+		first, last := schedules[0].Attributes, schedules[2].Attributes
+		if first.ArrivalTime != "" || first.DepartureTime != "2025-05-21T00:30:00-04:00" {
+			t.Errorf("Expected first stop to depart only at 2025-05-21T00:30:00-04:00, got %q/%q", first.ArrivalTime, first.DepartureTime)
+		}
+		if last.DepartureTime != "" || last.ArrivalTime != "2025-05-21T00:50:00-04:00" {
+			t.Errorf("Expected last stop to arrive only at 2025-05-21T00:50:00-04:00, got %q/%q", last.ArrivalTime, last.DepartureTime)
+		}
+		if schedules[1].ID != "schedule-red-late-70067-20" {
+			t.Errorf("Expected ID schedule-red-late-70067-20, got %s", schedules[1].ID)
+		}
+	})
+
+	t.Run("TimeWindow", func(t *testing.T) {
+		schedules, _, err := feed.GetSchedules(ctx, map[string]string{
+			"filter[route]":    "Red",
+			"filter[stop]":     "70067",
+			"filter[date]":     "2025-05-20",
+			"filter[min_time]": "08:30",
+			"filter[max_time]": "24:45",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got := scheduleTripIDs(schedules)
+		if len(got) != 2 || got[0] != "red-2" || got[1] != "red-late" {
+			t.Errorf("Expected [red-2 red-late], got %v", got)
+		}
+	})
+
+	t.Run("Holiday", func(t *testing.T) {
+		schedules, _, err := feed.GetSchedules(ctx, map[string]string{
+			"filter[route]": "Red",
+			"filter[date]":  "2025-05-26",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, schedule := range schedules {
+			if schedule.GetTripID() != "red-sat" {
+				t.Errorf("Expected only Saturday service on Memorial Day, got %s", schedule.GetTripID())
+			}
+		}
+	})
+
+	t.Run("Include", func(t *testing.T) {
+		_, included, err := feed.GetSchedules(ctx, map[string]string{
+			"filter[trip]": "green-1",
+			"filter[date]": "2025-05-20",
+			"include":      "trip,route,stop",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		counts := make(map[string]int)
+		for _, item := range included {
+			counts[item.Type]++
+		}
+		if counts["trip"] != 1 || counts["route"] != 1 || counts["stop"] != 2 {
+			t.Errorf("Expected 1 trip, 1 route and 2 stops, got %v", counts)
+		}
+	})
+
+	t.Run("MissingFilter", func(t *testing.T) {
+		if _, _, err := feed.GetSchedules(ctx, map[string]string{"filter[date]": "2025-05-20"}); !errors.Is(err, ErrMissingFilter) {
+			t.Errorf("Expected ErrMissingFilter, got %v", err)
+		}
+	})
+
+	t.Run("InvalidTime", func(t *testing.T) {
+		_, _, err := feed.GetSchedules(ctx, map[string]string{"filter[route]": "Red", "filter[min_time]": "8am"})
+		if err == nil {
+			t.Error("Expected error for an invalid time, got nil")
+		}
+	})
+}
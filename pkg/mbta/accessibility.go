@@ -0,0 +1,160 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// AccessibilityDataSource provides what station accessibility reports need
+type AccessibilityDataSource interface {
+	StopDataSource
+	FacilityDataSource
+	AlertDataSource
+}
+
+// StationAccessibility reports every elevator, escalator, ramp and other
+// accessibility feature at the station a stop belongs to. A facility named
+// by an accessibility alert active at the given time is out of service.
+func StationAccessibility(ctx context.Context, source AccessibilityDataSource, stopID string, at time.Time) (*models.StationAccessibility, error) {
+	hierarchy, err := source.GetStationHierarchy(ctx, stopID)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving station for %s: %w", stopID, err)
+	}
+
+	facilities, err := source.GetFacilities(ctx, map[string]string{
+		"filter[stop]": strings.Join(hierarchy.StopIDs(), ","),
+		"filter[type]": strings.Join(models.AccessibilityFacilityTypes, ","),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting facilities for %s: %w", hierarchy.Station.ID, err)
+	}
+
+	live := make(map[string]*models.LiveFacility)
+	if len(facilities) > 0 {
+		facilityIDs := make([]string, len(facilities))
+		for i, facility := range facilities {
+			facilityIDs[i] = facility.ID
+		}
+		liveFacilities, err := source.GetLiveFacilities(ctx, map[string]string{"filter[id]": strings.Join(facilityIDs, ",")})
+		if err != nil {
+			return nil, fmt.Errorf("error getting live facilities for %s: %w", hierarchy.Station.ID, err)
+		}
+		for i := range liveFacilities {
+			live[liveFacilities[i].GetFacilityID()] = &liveFacilities[i]
+		}
+	}
+
+	alerts, err := AccessibilityAlerts(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("error getting accessibility alerts: %w", err)
+	}
+
+	report := &models.StationAccessibility{
+		Station:    hierarchy.Station,
+		Facilities: make([]models.FacilityStatus, 0, len(facilities)),
+		AsOf:       at,
+	}
+
+	atStation := make(map[string]bool, len(facilities))
+	for _, facility := range facilities {
+		atStation[facility.ID] = true
+	}
+
+	// Outages are reported against facilities, and sometimes only their stops
+	facilityAlerts := make(map[string][]models.Alert)
+	for _, alert := range alerts {
+		if !alert.IsActive(at) {
+			continue
+		}
+
+		relevant := false
+		for _, facilityID := range alert.GetAffectedFacilities() {
+			if atStation[facilityID] {
+				facilityAlerts[facilityID] = append(facilityAlerts[facilityID], alert)
+				relevant = true
+			}
+		}
+		for _, affected := range alert.GetAffectedStops() {
+			if hierarchy.Contains(affected) {
+				relevant = true
+			}
+		}
+		if relevant {
+			report.Alerts = append(report.Alerts, alert)
+		}
+	}
+
+	for _, facility := range facilities {
+		status := models.FacilityStatus{
+			Facility: facility,
+			Live:     live[facility.ID],
+			Status:   models.FacilityStatusInService,
+			Alerts:   facilityAlerts[facility.ID],
+		}
+		if len(status.Alerts) > 0 {
+			status.Status = models.FacilityStatusOutOfService
+		}
+		report.Facilities = append(report.Facilities, status)
+	}
+
+	sort.SliceStable(report.Facilities, func(i, j int) bool {
+		a, b := &report.Facilities[i].Facility, &report.Facilities[j].Facility
+		if a.Attributes.Type != b.Attributes.Type {
+			return a.Attributes.Type < b.Attributes.Type
+		}
+		return a.GetName() < b.GetName()
+	})
+
+	return report, nil
+}
+
+// ElevatorOutages returns the elevator outage alerts active at the given time,
+// keyed by each stop or station they name
+func ElevatorOutages(ctx context.Context, alerts AlertDataSource, at time.Time) (map[string]*models.Alert, error) {
+	accessibility, err := AccessibilityAlerts(ctx, alerts)
+	if err != nil {
+		return nil, fmt.Errorf("error getting accessibility alerts: %w", err)
+	}
+
+	outages := make(map[string]*models.Alert)
+	for i := range accessibility {
+		alert := &accessibility[i]
+		if alert.Attributes.Effect != models.AlertEffectElevatorOutage || !alert.IsActive(at) {
+			continue
+		}
+		for _, stopID := range alert.GetAffectedStops() {
+			if _, ok := outages[stopID]; !ok {
+				outages[stopID] = alert
+			}
+		}
+	}
+	return outages, nil
+}
+
+// inaccessibleStops returns the platforms and stations in the timetable that
+// riders who need step-free access can't use because of elevator outages. An
+// outage naming platforms as well as their station only affects those
+// platforms, since the station is named so riders find the alert. An outage
+// naming only a station affects all of it, as which platforms the elevator
+// serves isn't known.
+func (t *Timetable) inaccessibleStops(outages map[string]*models.Alert) map[string]bool {
+	scoped := make(map[string]*models.Alert)
+	for stopID, alert := range outages {
+		if station := t.node(stopID); station != stopID && outages[station] == alert {
+			scoped[station] = alert
+		}
+	}
+
+	inaccessible := make(map[string]bool, len(outages))
+	for stopID := range outages {
+		if _, ok := scoped[stopID]; !ok {
+			inaccessible[stopID] = true
+		}
+	}
+	return inaccessible
+}
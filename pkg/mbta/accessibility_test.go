@@ -0,0 +1,171 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// accessibilityTestSource is Park Street with two elevators and an escalator
+type accessibilityTestSource struct {
+	StopDataSource
+	facilities     []models.Facility
+	live           []models.LiveFacility
+	alerts         []models.Alert
+	facilityParams map[string]string
+	facilityErr    error
+}
+
+func (s *accessibilityTestSource) GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error) {
+	hierarchy := models.NewStationHierarchy(stationTestStops["place-pktrm"], []models.Stop{
+		stationTestStops["70075"],
+		stationTestStops["70200"],
+	})
+	if !hierarchy.Contains(stopID) {
+		return nil, errors.New("not found")
+	}
+	return hierarchy, nil
+}
+
+func (s *accessibilityTestSource) GetFacilities(ctx context.Context, params map[string]string) ([]models.Facility, error) {
+	s.facilityParams = params
+	return s.facilities, s.facilityErr
+}
+
+func (s *accessibilityTestSource) GetLiveFacilities(ctx context.Context, params map[string]string) ([]models.LiveFacility, error) {
+	return s.live, nil
+}
+
+func (s *accessibilityTestSource) GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+	return s.alerts, nil
+}
+
+func (s *accessibilityTestSource) GetAlert(ctx context.Context, alertID string) (*models.Alert, error) {
+	return nil, errors.New("not found")
+}
+
+// accessibilityTestAlert builds an elevator outage alert active around now
+func accessibilityTestAlert(id string, start, end time.Time, entities ...models.AlertEntity) models.Alert {
+	return models.Alert{
+		ID: id,
+		Attributes: models.AlertAttributes{
+			Effect:         models.AlertEffectElevatorOutage,
+			ActivePeriod:   []models.AlertPeriod{{Start: start, End: end}},
+			InformedEntity: entities,
+		},
+	}
+}
+
+func TestStationAccessibility(t *testing.T) {
+	now := time.Date(2025, 5, 23, 10, 0, 0, 0, time.UTC)
+	facility := func(id, name, facilityType string) models.Facility {
+		return models.Facility{ID: id, Type: "facility", Attributes: models.FacilityAttributes{LongName: name, Type: facilityType}}
+	}
+
+	source := &accessibilityTestSource{
+		facilities: []models.Facility{
+			facility("830", "Escalator 830", models.FacilityTypeEscalator),
+			facility("823", "Elevator 823", models.FacilityTypeElevator),
+			facility("804", "Elevator 804", models.FacilityTypeElevator),
+		},
+		live: []models.LiveFacility{{ID: "823", Type: "live_facility"}},
+		alerts: []models.Alert{
+			accessibilityTestAlert("outage-804", now.Add(-time.Hour), now.Add(time.Hour), models.AlertEntity{Stop: "place-pktrm", FacilityID: "804"}),
+			accessibilityTestAlert("ended-823", now.Add(-2*time.Hour), now.Add(-time.Hour), models.AlertEntity{Stop: "place-pktrm", FacilityID: "823"}),
+			accessibilityTestAlert("elsewhere", now.Add(-time.Hour), time.Time{}, models.AlertEntity{Stop: "place-dwnxg", FacilityID: "901"}),
+			accessibilityTestAlert("platform", now.Add(-time.Hour), time.Time{}, models.AlertEntity{Stop: "70075"}),
+		},
+	}
+
+	report, err := StationAccessibility(context.Background(), source, "70200", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Station.ID != "place-pktrm" {
+		t.Errorf("Expected the platform's station, got %s", report.Station.ID)
+	}
+	if source.facilityParams["filter[stop]"] != "place-pktrm,70075,70200" {
+		t.Errorf("Expected facilities for the station and its platforms, got %v", source.facilityParams)
+	}
+
+	// Elevators come first, in order of name
+	if len(report.Facilities) != 3 {
+		t.Fatalf("Expected 3 facilities, got %d", len(report.Facilities))
+	}
+	order := []string{"804", "823", "830"}
+	for i, status := range report.Facilities {
+		if status.Facility.ID != order[i] {
+			t.Errorf("Expected facility %s at %d, got %s", order[i], i, status.Facility.ID)
+		}
+	}
+
+	if elevator := report.Facilities[0]; elevator.InService() || len(elevator.Alerts) != 1 || elevator.Alerts[0].ID != "outage-804" {
+		t.Errorf("Expected elevator 804 out of service for outage-804, got %+v", elevator)
+	}
+	if elevator := report.Facilities[1]; !elevator.InService() || elevator.Live == nil {
+		t.Errorf("Expected elevator 823 in service with live state, got %+v", elevator)
+	}
+
+	// Only active alerts about this station are reported
+	if len(report.Alerts) != 2 || report.Alerts[0].ID != "outage-804" || report.Alerts[1].ID != "platform" {
+		t.Errorf("Expected outage-804 and platform alerts, got %v", report.Alerts)
+	}
+
+	source.facilityErr = errors.New("unavailable")
+	if _, err := StationAccessibility(context.Background(), source, "place-pktrm", now); err == nil {
+		t.Error("Expected an error when facilities are unavailable")
+	}
+	if _, err := StationAccessibility(context.Background(), source, "place-nowhere", now); err == nil {
+		t.Error("Expected an error for an unknown stop")
+	}
+}
+
+func TestElevatorOutages(t *testing.T) {
+	now := time.Now()
+	issue := accessibilityTestAlert("issue", now.Add(-time.Hour), time.Time{}, models.AlertEntity{Stop: "place-dwnxg"})
+	issue.Attributes.Effect = models.AlertEffectAccessibilityIssue
+
+	source := &accessibilityTestSource{alerts: []models.Alert{
+		accessibilityTestAlert("pktrm", now.Add(-time.Hour), time.Time{}, models.AlertEntity{Stop: "place-pktrm"}, models.AlertEntity{Stop: "70075"}),
+		accessibilityTestAlert("later", now.Add(time.Hour), time.Time{}, models.AlertEntity{Stop: "place-gover"}),
+		issue,
+	}}
+
+	outages, err := ElevatorOutages(context.Background(), source, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(outages) != 2 || outages["place-pktrm"].ID != "pktrm" || outages["70075"].ID != "pktrm" {
+		t.Errorf("Expected Park Street and its platform, got %v", outages)
+	}
+}
+
+func TestInaccessibleStops(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	platform := &models.Alert{ID: "platform"}
+	station := &models.Alert{ID: "station"}
+
+	// An outage naming a platform and its station only closes the platform,
+	// but one naming just a station closes all of it
+	inaccessible := timetable.inaccessibleStops(map[string]*models.Alert{
+		"place-b": platform,
+		"b1":      platform,
+		"place-x": station,
+	})
+	if len(inaccessible) != 2 || !inaccessible["b1"] || !inaccessible["place-x"] {
+		t.Errorf("Expected b1 and place-x to be inaccessible, got %v", inaccessible)
+	}
+
+	// Platforms the timetable doesn't know can't be told apart from the
+	// station, which stays closed
+	inaccessible = timetable.inaccessibleStops(map[string]*models.Alert{"place-b": platform, "b3": platform})
+	if !inaccessible["place-b"] {
+		t.Errorf("Expected place-b to be inaccessible, got %v", inaccessible)
+	}
+}
@@ -0,0 +1,133 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// RouteDataSource provides MBTA routes
+type RouteDataSource interface {
+	GetRoutes(ctx context.Context) ([]models.Route, error)
+	GetRoute(ctx context.Context, routeID string) (*models.Route, error)
+}
+
+// StopDataSource provides MBTA stops and stop searches
+type StopDataSource interface {
+	GetStops(ctx context.Context) ([]models.Stop, error)
+	GetStop(ctx context.Context, stopID string) (*models.Stop, error)
+	GetStopsForRoute(ctx context.Context, routeID string) ([]string, error)
+	FindNearbyStops(ctx context.Context, lat, lon, radius float64, maxResults int, filter StopFilter) ([]models.NearbyStation, error)
+	GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error)
+}
+
+// ScheduleDataSource provides scheduled arrivals and departures
+type ScheduleDataSource interface {
+	GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error)
+}
+
+// PredictionDataSource provides real-time arrival and departure predictions
+type PredictionDataSource interface {
+	GetPredictions(ctx context.Context, params map[string]string) ([]models.Prediction, error)
+}
+
+// VehicleDataSource provides real-time vehicle positions
+type VehicleDataSource interface {
+	GetVehicles(ctx context.Context, params map[string]string) ([]models.Vehicle, error)
+	GetVehicle(ctx context.Context, vehicleID string) (*models.Vehicle, error)
+}
+
+// AlertDataSource provides service alerts
+type AlertDataSource interface {
+	GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error)
+	GetAlert(ctx context.Context, alertID string) (*models.Alert, error)
+}
+
+// FacilityDataSource provides station facilities such as elevators and
+// escalators, and their live state
+type FacilityDataSource interface {
+	GetFacilities(ctx context.Context, params map[string]string) ([]models.Facility, error)
+	GetLiveFacilities(ctx context.Context, params map[string]string) ([]models.LiveFacility, error)
+}
+
+// TripDataSource provides trips and plans journeys between stops
+type TripDataSource interface {
+	GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error)
+	GetTrip(ctx context.Context, tripID string) (*models.Trip, error)
+	PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error)
+	FindTransferPoints(ctx context.Context, routesA, routesB []string) ([]models.TransferPoint, error)
+}
+
+// TransitAPI is everything the server needs from a transit data backend.
+// Client implements it against the MBTA API; CompositeAPI assembles one from
+// separate backends, and Decorate wraps one with caching, metrics or retries.
+type TransitAPI interface {
+	RouteDataSource
+	StopDataSource
+	ScheduleDataSource
+	PredictionDataSource
+	VehicleDataSource
+	AlertDataSource
+	FacilityDataSource
+	TripDataSource
+}
+
+// CompositeAPI is a TransitAPI that takes each kind of data from its own
+// backend, such as static data from a GTFS feed and real-time data from the API
+type CompositeAPI struct {
+	RouteDataSource
+	StopDataSource
+	ScheduleDataSource
+	PredictionDataSource
+	VehicleDataSource
+	AlertDataSource
+	FacilityDataSource
+	TripDataSource
+}
+
+// Client implements every data source
+var _ TransitAPI = (*Client)(nil)
+
+// ServiceDisruptions returns alerts that represent significant service disruptions
+func ServiceDisruptions(ctx context.Context, alerts AlertDataSource) ([]models.Alert, error) {
+	return alerts.GetAlerts(ctx, serviceDisruptionParams())
+}
+
+// AccessibilityAlerts returns alerts related to accessibility issues
+func AccessibilityAlerts(ctx context.Context, alerts AlertDataSource) ([]models.Alert, error) {
+	return alerts.GetAlerts(ctx, accessibilityAlertParams())
+}
+
+// ActiveAlerts returns alerts affecting riders right now
+func ActiveAlerts(ctx context.Context, alerts AlertDataSource) ([]models.Alert, error) {
+	return alerts.GetAlerts(ctx, activeAlertParams())
+}
+
+// ExpandStopIDs replaces each station ID with the station and its platforms,
+// so queries for a station match vehicles, predictions and alerts recorded
+// against any of them. Other stop IDs are kept as they are.
+func ExpandStopIDs(ctx context.Context, stops StopDataSource, stopIDs ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var expanded []string
+	for _, stopID := range stopIDs {
+		ids := []string{stopID}
+
+		hierarchy, err := stops.GetStationHierarchy(ctx, stopID)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving station for %s: %w", stopID, err)
+		}
+		if hierarchy.Station.ID == stopID {
+			ids = hierarchy.StopIDs()
+		}
+
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				expanded = append(expanded, id)
+			}
+		}
+	}
+	return expanded, nil
+}
@@ -0,0 +1,77 @@
+package mbta
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// alertSourceFunc adapts a function to an AlertDataSource
+type alertSourceFunc func(ctx context.Context, params map[string]string) ([]models.Alert, error)
+
+func (f alertSourceFunc) GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+	return f(ctx, params)
+}
+
+func (f alertSourceFunc) GetAlert(ctx context.Context, alertID string) (*models.Alert, error) {
+	return nil, nil
+}
+
+func TestAlertQueries(t *testing.T) {
+	var got map[string]string
+	alerts := alertSourceFunc(func(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+		got = params
+		return nil, nil
+	})
+
+	tests := []struct {
+		name     string
+		query    func(context.Context, AlertDataSource) ([]models.Alert, error)
+		expected map[string]string
+	}{
+		{
+			name:     "Active",
+			query:    ActiveAlerts,
+			expected: activeAlertParams(),
+		},
+		{
+			name:  "Service disruptions",
+			query: ServiceDisruptions,
+			expected: map[string]string{
+				"filter[effect]": "NO_SERVICE,SUSPENSION,REDUCED_SERVICE,SIGNIFICANT_DELAYS,DETOUR,STATION_CLOSURE,STOP_CLOSED,SHUTTLE",
+			},
+		},
+		{
+			name:  "Accessibility",
+			query: AccessibilityAlerts,
+			expected: map[string]string{
+				"filter[effect]": "ACCESSIBILITY_ISSUE,ELEVATOR_OUTAGE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.query(context.Background(), alerts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected params %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompositeAPI(t *testing.T) {
+	routes := newFakeRoutes()
+	var api TransitAPI = CompositeAPI{RouteDataSource: routes}
+
+	if _, err := api.GetRoute(context.Background(), "Red"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if routes.calls["Red"] != 1 {
+		t.Errorf("Expected the route backend to answer, got %v", routes.calls)
+	}
+}
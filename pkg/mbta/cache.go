@@ -0,0 +1,347 @@
+package mbta
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+)
+
+// DefaultCacheTTLs are the cache lifetimes for each API resource. Reference data
+// changes rarely, while real-time data goes stale within seconds.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"routes":          24 * time.Hour,
+	"route_patterns":  24 * time.Hour,
+	"lines":           24 * time.Hour,
+	"stops":           24 * time.Hour,
+	"shapes":          24 * time.Hour,
+	"facilities":      time.Hour,
+	"trips":           time.Hour,
+	"schedules":       5 * time.Minute,
+	"alerts":          30 * time.Second,
+	"live_facilities": 30 * time.Second,
+	"predictions":     10 * time.Second,
+	"vehicles":        5 * time.Second,
+}
+
+// CacheEntry is a cached API response body
+type CacheEntry struct {
+	Body         []byte    `json:"body"`
+	ContentType  string    `json:"content_type,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// response builds an HTTP response that serves the cached body
+func (e *CacheEntry) response() *http.Response {
+	header := http.Header{}
+	if e.ContentType != "" {
+		header.Set("Content-Type", e.ContentType)
+	}
+	if e.LastModified != "" {
+		header.Set("Last-Modified", e.LastModified)
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+	}
+}
+
+// Fresh returns whether the entry can be served without contacting the API
+func (e *CacheEntry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Cache stores API responses keyed by request path
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheStats reports how often cached responses were used
+type CacheStats struct {
+	Hits          uint64 `json:"hits"`          // Served from cache without a request
+	Revalidations uint64 `json:"revalidations"` // Served from cache after a 304 Not Modified
+	Misses        uint64 `json:"misses"`        // Fetched from the API
+}
+
+// HitRate returns the fraction of requests answered from the cache
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Revalidations + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.Revalidations) / float64(total)
+}
+
+// WithCache answers the client's GET requests from the given response cache
+func WithCache(cache *ResponseCache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// CacheStats returns the client's cache statistics, or false if the client
+// has no cache
+func (c *Client) CacheStats() (CacheStats, bool) {
+	if c.cache == nil {
+		return CacheStats{}, false
+	}
+	return c.cache.Stats(), true
+}
+
+// makeCachedRequest performs a GET request through the response cache
+func (c *Client) makeCachedRequest(ctx context.Context, path string) (*http.Response, error) {
+	now := time.Now()
+	entry, found := c.cache.store.Get(path)
+	if found && entry.Fresh(now) {
+		c.cache.hits.Add(1)
+		return entry.response(), nil
+	}
+
+	// Ask the API whether an expired entry is still current
+	header := http.Header{}
+	if found && entry.LastModified != "" {
+		header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, header)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := c.cache.ttl(path)
+
+	if resp.StatusCode == http.StatusNotModified && found {
+		_ = resp.Body.Close()
+		c.cache.revalidations.Add(1)
+
+		refreshed := *entry
+		refreshed.ExpiresAt = now.Add(ttl)
+		c.cache.store.Set(path, &refreshed)
+		return refreshed.response(), nil
+	}
+
+	c.cache.misses.Add(1)
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, &NetworkError{Err: fmt.Errorf("error reading response body: %w", err)}
+	}
+
+	// Keep responses that are fresh for a while or can be revalidated later
+	lastModified := resp.Header.Get("Last-Modified")
+	if ttl > 0 || lastModified != "" {
+		c.cache.store.Set(path, &CacheEntry{
+			Body:         body,
+			ContentType:  resp.Header.Get("Content-Type"),
+			LastModified: lastModified,
+			ExpiresAt:    now.Add(ttl),
+		})
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// NewCacheFromConfig creates the response cache described by the configuration,
+// using the default TTLs. It returns nil when caching is disabled.
+func NewCacheFromConfig(cfg *config.Config) (*ResponseCache, error) {
+	if cfg.CacheDir != "" {
+		store, err := NewDiskCache(cfg.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		return NewResponseCache(store, nil), nil
+	}
+	if cfg.CacheSize > 0 {
+		return NewResponseCache(NewMemoryCache(cfg.CacheSize), nil), nil
+	}
+	return nil, nil
+}
+
+// MemoryCache is an in-memory cache that evicts the least recently used entry
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// memoryCacheItem is an element of the MemoryCache recency list
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates an LRU cache holding up to capacity entries
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the entry for a key and marks it as recently used
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return element.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores an entry, evicting the least recently used entry if full
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Len returns the number of cached entries
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DiskCache stores entries as files in a directory so they survive restarts
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a cache in the given directory, creating it if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get reads the entry for a key from disk
+func (d *DiskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set writes the entry for a key to disk, replacing it atomically
+func (d *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), d.path(key))
+}
+
+// path returns the file that holds the entry for a key
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// ResponseCache wraps a Cache with per-resource TTLs and hit statistics.
+// A single ResponseCache can be shared by many clients.
+type ResponseCache struct {
+	store Cache
+	ttls  map[string]time.Duration
+
+	hits          atomic.Uint64
+	revalidations atomic.Uint64
+	misses        atomic.Uint64
+}
+
+// NewResponseCache keeps each response in store for the TTL of its resource
+// type. A nil ttls map uses DefaultCacheTTLs. Responses with a Last-Modified
+// header are revalidated with If-Modified-Since once they expire.
+func NewResponseCache(store Cache, ttls map[string]time.Duration) *ResponseCache {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+	return &ResponseCache{store: store, ttls: ttls}
+}
+
+// Stats returns a snapshot of the cache statistics
+func (r *ResponseCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:          r.hits.Load(),
+		Revalidations: r.revalidations.Load(),
+		Misses:        r.misses.Load(),
+	}
+}
+
+// ttl returns the lifetime for responses from the given request path
+func (r *ResponseCache) ttl(path string) time.Duration {
+	return r.ttls[resourceFromPath(path)]
+}
+
+// resourceFromPath extracts the resource type from a request path such as
+// "/stops/place-pktrm?include=child_stops"
+func resourceFromPath(path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return resource
+}
@@ -0,0 +1,226 @@
+package mbta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+
+	cache.Set("a", &CacheEntry{Body: []byte("a")})
+	cache.Set("b", &CacheEntry{Body: []byte("b")})
+
+	// Touch "a" so "b" becomes the least recently used entry
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("Expected entry a to be cached")
+	}
+	cache.Set("c", &CacheEntry{Body: []byte("c")})
+
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used entry b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected entry %s to be cached", key)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	cache.Set("/routes?filter[type]=1", &CacheEntry{
+		Body:         []byte(`{"data": []}`),
+		LastModified: "Tue, 20 May 2025 12:00:00 GMT",
+		ExpiresAt:    expires,
+	})
+
+	// A new cache over the same directory sees the stored entry
+	reopened, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache returned error: %v", err)
+	}
+
+	entry, ok := reopened.Get("/routes?filter[type]=1")
+	if !ok {
+		t.Fatal("Expected entry to be read back from disk")
+	}
+	if string(entry.Body) != `{"data": []}` {
+		t.Errorf("Expected cached body, got %s", entry.Body)
+	}
+	if !entry.ExpiresAt.Equal(expires) {
+		t.Errorf("Expected expiry %v, got %v", expires, entry.ExpiresAt)
+	}
+
+	if _, ok := reopened.Get("/routes"); ok {
+		t.Error("Expected missing key not to be found")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	t.Run("Fresh responses are served from the cache", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			_, _ = w.Write([]byte(`{"data": [{"id": "Red", "type": "route", "attributes": {"long_name": "Red Line"}}]}`))
+		}))
+		defer server.Close()
+
+		cache := NewResponseCache(NewMemoryCache(10), nil)
+		cfg := &config.Config{APIKey: "test-key", APIBaseURL: server.URL}
+
+		// Separate clients share the cache
+		for i := 0; i < 3; i++ {
+			routes, err := NewClient(cfg, WithCache(cache)).GetRoutes(context.Background())
+			if err != nil {
+				t.Fatalf("GetRoutes returned error: %v", err)
+			}
+			if len(routes) != 1 || routes[0].Attributes.LongName != "Red Line" {
+				t.Fatalf("Unexpected routes: %+v", routes)
+			}
+		}
+
+		if requests != 1 {
+			t.Errorf("Expected 1 API request, got %d", requests)
+		}
+
+		stats := cache.Stats()
+		if stats.Hits != 2 || stats.Misses != 1 {
+			t.Errorf("Expected 2 hits and 1 miss, got %+v", stats)
+		}
+		if rate := stats.HitRate(); rate < 0.66 || rate > 0.67 {
+			t.Errorf("Expected hit rate of 2/3, got %f", rate)
+		}
+	})
+
+	t.Run("Expired responses are revalidated", func(t *testing.T) {
+		lastModified := "Tue, 20 May 2025 12:00:00 GMT"
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", lastModified)
+			_, _ = w.Write([]byte(`{"data": [{"id": "place-pktrm", "type": "stop", "attributes": {"name": "Park Street"}}]}`))
+		}))
+		defer server.Close()
+
+		// A zero TTL forces every request after the first to revalidate
+		cache := NewResponseCache(NewMemoryCache(10), map[string]time.Duration{"stops": 0})
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithCache(cache))
+
+		for i := 0; i < 2; i++ {
+			stops, err := client.GetStops(context.Background())
+			if err != nil {
+				t.Fatalf("GetStops returned error: %v", err)
+			}
+			if len(stops) != 1 || stops[0].Attributes.Name != "Park Street" {
+				t.Fatalf("Unexpected stops on request %d: %+v", i, stops)
+			}
+		}
+
+		if requests != 2 {
+			t.Errorf("Expected 2 API requests, got %d", requests)
+		}
+		if stats, _ := client.CacheStats(); stats.Revalidations != 1 || stats.Misses != 1 {
+			t.Errorf("Expected 1 revalidation and 1 miss, got %+v", stats)
+		}
+	})
+
+	t.Run("Uncacheable responses and errors are not stored", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path == "/vehicles/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[{"status":"404","title":"Not Found"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data": []}`))
+		}))
+		defer server.Close()
+
+		store := NewMemoryCache(10)
+		cache := NewResponseCache(store, map[string]time.Duration{"vehicles": time.Minute})
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithCache(cache))
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.GetVehicle(context.Background(), "missing"); err == nil {
+				t.Fatal("Expected error for missing vehicle")
+			}
+			// No TTL is configured for predictions
+			if _, err := client.GetPredictions(context.Background(), nil); err != nil {
+				t.Fatalf("GetPredictions returned error: %v", err)
+			}
+		}
+
+		if requests != 4 {
+			t.Errorf("Expected 4 API requests, got %d", requests)
+		}
+		if store.Len() != 0 {
+			t.Errorf("Expected nothing to be cached, got %d entries", store.Len())
+		}
+	})
+
+	t.Run("Clients without a cache report no stats", func(t *testing.T) {
+		client := NewClient(&config.Config{APIBaseURL: "http://127.0.0.1:0"})
+		if _, ok := client.CacheStats(); ok {
+			t.Error("Expected no cache stats for a client without a cache")
+		}
+	})
+}
+
+func TestNewCacheFromConfig(t *testing.T) {
+	if cache, err := NewCacheFromConfig(&config.Config{}); err != nil || cache != nil {
+		t.Errorf("Expected caching to be disabled, got %v, %v", cache, err)
+	}
+
+	cache, err := NewCacheFromConfig(&config.Config{CacheSize: 10})
+	if err != nil || cache == nil {
+		t.Fatalf("Expected memory cache, got %v, %v", cache, err)
+	}
+	if _, ok := cache.store.(*MemoryCache); !ok {
+		t.Errorf("Expected *MemoryCache store, got %T", cache.store)
+	}
+
+	cache, err = NewCacheFromConfig(&config.Config{CacheSize: 10, CacheDir: t.TempDir()})
+	if err != nil || cache == nil {
+		t.Fatalf("Expected disk cache, got %v, %v", cache, err)
+	}
+	if _, ok := cache.store.(*DiskCache); !ok {
+		t.Errorf("Expected *DiskCache store, got %T", cache.store)
+	}
+}
+
+func TestResourceFromPath(t *testing.T) {
+	tests := map[string]string{
+		"/routes":                         "routes",
+		"/stops/place-pktrm?include=stop": "stops",
+		"/predictions?filter[stop]=70061": "predictions",
+		"/live_facilities/123":            "live_facilities",
+	}
+
+	for path, expected := range tests {
+		if got := resourceFromPath(path); got != expected {
+			t.Errorf("resourceFromPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
@@ -20,6 +20,44 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	cache      *ResponseCache
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	stopIndex  *StopIndex
+	footpaths  *FootpathGraph
+}
+
+// ClientOption customizes a Client created by NewClient
+type ClientOption func(*Client)
+
+// WithTransport sends the client's requests through the given transport, such
+// as one that answers them from an offline GTFS feed
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// LocalTransport is a transport that answers some requests itself, such as
+// from an offline GTFS feed, and sends the rest to the API. Requests it answers
+// cost no API quota, so they skip the client's rate limiter, retry policy and
+// response cache.
+type LocalTransport interface {
+	http.RoundTripper
+
+	// Serves returns whether the transport answers a request itself
+	Serves(req *http.Request) bool
+}
+
+// servesLocally returns whether a request is answered by the client's
+// transport without reaching the API
+func (c *Client) servesLocally(method, path string) bool {
+	local, ok := c.httpClient.Transport.(LocalTransport)
+	if !ok {
+		return false
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, nil)
+	return err == nil && local.Serves(req)
 }
 
 // GetStopsForRoute returns all stops served by a specific route
@@ -31,21 +69,14 @@ func (c *Client) GetStopsForRoute(ctx context.Context, routeID string) ([]string
 
 	path := "/stops?" + query.Encode()
 
-	stopsResp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	stops, _, err := listAll[models.Stop](ctx, c, path, "stop")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = stopsResp.Body.Close() }()
-
-	// Parse response
-	var stopResponse models.StopResponse
-	if err := json.NewDecoder(stopsResp.Body).Decode(&stopResponse); err != nil {
-		return nil, fmt.Errorf("error decoding stop response: %w", err)
-	}
 
 	// Extract stop IDs
-	stopIDs := make([]string, len(stopResponse.Data))
-	for i, stop := range stopResponse.Data {
+	stopIDs := make([]string, len(stops))
+	for i, stop := range stops {
 		stopIDs[i] = stop.ID
 	}
 
@@ -53,7 +84,7 @@ func (c *Client) GetStopsForRoute(ctx context.Context, routeID string) ([]string
 }
 
 // NewClient creates a new MBTA API client with the provided configuration
-func NewClient(cfg *config.Config) *Client {
+func NewClient(cfg *config.Config, opts ...ClientOption) *Client {
 	// Create transport with sensible defaults
 	transport := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -74,15 +105,43 @@ func NewClient(cfg *config.Config) *Client {
 		Timeout:   cfg.Timeout,
 	}
 
-	return &Client{
+	client := &Client{
 		baseURL:    cfg.APIBaseURL,
 		apiKey:     cfg.APIKey,
 		httpClient: httpClient,
 	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
 }
 
-// makeRequest performs an HTTP request with proper headers and handles authentication
+// makeRequest performs an HTTP request with proper headers and handles authentication.
+// GET requests are answered from the response cache when one is configured.
 func (c *Client) makeRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	if method == http.MethodGet && c.cache != nil && !c.servesLocally(method, path) {
+		return c.makeCachedRequest(ctx, path)
+	}
+	return c.doRequest(ctx, method, path, body, nil)
+}
+
+// doRequest performs an HTTP request with the given extra headers, retrying
+// idempotent requests according to the client's retry policy
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	// Requests with a body can't be replayed, and local answers won't change
+	if c.retry == nil || body != nil || !isIdempotent(method) || c.servesLocally(method, path) {
+		return c.doAttempt(ctx, method, path, body, header)
+	}
+
+	return c.retry.do(ctx, func() (*http.Response, error) {
+		return c.doAttempt(ctx, method, path, nil, header)
+	})
+}
+
+// doAttempt performs a single HTTP request with the given extra headers
+func (c *Client) doAttempt(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
 	// Create request with context
@@ -92,6 +151,9 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, body io.R
 	}
 
 	// Set common headers
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	req.Header.Set("Accept", "application/vnd.api+json")
 
 	// Set API key if available
@@ -99,6 +161,19 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, body io.R
 		req.Header.Set("X-API-Key", c.apiKey)
 	}
 
+	// Stay under the API rate limit, unless the request won't reach the API
+	limiter := c.limiter
+	if limiter != nil {
+		if local, ok := c.httpClient.Transport.(LocalTransport); ok && local.Serves(req) {
+			limiter = nil
+		}
+	}
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	// Perform the request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -112,6 +187,10 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, body io.R
 		return nil, &NetworkError{Err: fmt.Errorf("error performing request: %w", err)}
 	}
 
+	if limiter != nil {
+		limiter.Observe(resp.Header)
+	}
+
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
 		// Read error response body
@@ -123,7 +202,7 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, body io.R
 		}
 
 		// Parse the API error
-		return nil, parseAPIError(resp.StatusCode, respBody)
+		return nil, parseAPIErrorResponse(resp.StatusCode, resp.Header, respBody)
 	}
 
 	// Successfully processed the request
@@ -140,19 +219,12 @@ func isTimeoutError(err error) bool {
 
 // GetRoutes retrieves all available MBTA routes
 func (c *Client) GetRoutes(ctx context.Context) ([]models.Route, error) {
-	resp, err := c.makeRequest(ctx, http.MethodGet, "/routes", nil)
+	routes, _, err := listAll[models.Route](ctx, c, "/routes", "route")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var routeResponse models.RouteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&routeResponse); err != nil {
-		return nil, fmt.Errorf("error decoding route response: %w", err)
-	}
 
-	return routeResponse.Data, nil
+	return routes, nil
 }
 
 // GetRoute retrieves a specific MBTA route by ID
@@ -176,19 +248,12 @@ func (c *Client) GetRoute(ctx context.Context, routeID string) (*models.Route, e
 
 // GetStops retrieves all available MBTA stops
 func (c *Client) GetStops(ctx context.Context) ([]models.Stop, error) {
-	resp, err := c.makeRequest(ctx, http.MethodGet, "/stops", nil)
+	stops, _, err := listAll[models.Stop](ctx, c, "/stops", "stop")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var stopResponse models.StopResponse
-	if err := json.NewDecoder(resp.Body).Decode(&stopResponse); err != nil {
-		return nil, fmt.Errorf("error decoding stop response: %w", err)
-	}
 
-	return stopResponse.Data, nil
+	return stops, nil
 }
 
 // GetStop retrieves a specific MBTA stop by ID
@@ -219,6 +284,7 @@ func (c *Client) GetStop(ctx context.Context, stopID string) (*models.Stop, erro
 // - filter[date]: Filter by service date (YYYY-MM-DD format)
 // - filter[min_time]: Filter by minimum departure time (HH:MM format)
 // - filter[max_time]: Filter by maximum departure time (HH:MM format)
+// Every page of results is returned; use WithPageOptions to cap them.
 func (c *Client) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
 	// Build query parameters
 	query := url.Values{}
@@ -226,9 +292,9 @@ func (c *Client) GetSchedules(ctx context.Context, params map[string]string) ([]
 		query.Add(key, value)
 	}
 
-	// If date filter isn't provided, use today's date
+	// If date filter isn't provided, use today's service day
 	if _, hasDate := params["filter[date]"]; !hasDate {
-		query.Add("filter[date]", time.Now().Format("2006-01-02"))
+		query.Add("filter[date]", ServiceDayOf(time.Now()).String())
 	}
 
 	path := "/schedules"
@@ -236,17 +302,5 @@ func (c *Client) GetSchedules(ctx context.Context, params map[string]string) ([]
 		path += "?" + queryString
 	}
 
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var scheduleResponse models.ScheduleResponse
-	if err := json.NewDecoder(resp.Body).Decode(&scheduleResponse); err != nil {
-		return nil, nil, fmt.Errorf("error decoding schedule response: %w", err)
-	}
-
-	return scheduleResponse.Data, scheduleResponse.Included, nil
+	return listAll[models.Schedule](ctx, c, path, "schedule")
 }
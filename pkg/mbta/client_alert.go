@@ -24,19 +24,12 @@ func (c *Client) GetAlerts(ctx context.Context, params map[string]string) ([]mod
 		path += "?" + queryString
 	}
 
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	alerts, _, err := listAll[models.Alert](ctx, c, path, "alert")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var alertResponse models.AlertResponse
-	if err := json.NewDecoder(resp.Body).Decode(&alertResponse); err != nil {
-		return nil, fmt.Errorf("error decoding alert response: %w", err)
-	}
 
-	return alertResponse.Data, nil
+	return alerts, nil
 }
 
 // GetAlert retrieves a specific MBTA alert by ID
@@ -60,10 +53,7 @@ func (c *Client) GetAlert(ctx context.Context, alertID string) (*models.Alert, e
 
 // GetActiveAlerts retrieves all currently active alerts
 func (c *Client) GetActiveAlerts(ctx context.Context) ([]models.Alert, error) {
-	params := map[string]string{
-		"filter[activity]": "USING_ACCESSORY,BOARD,EXIT,PARK_CAR,RIDE,STORE_BIKE,USING_ESCALATOR,USING_WHEELCHAIR",
-	}
-	return c.GetAlerts(ctx, params)
+	return ActiveAlerts(ctx, c)
 }
 
 // GetAlertsByRoute retrieves all alerts for a specific route
@@ -116,33 +106,37 @@ func (c *Client) GetAlertsByEffect(ctx context.Context, effect models.AlertEffec
 
 // GetServiceDisruptions retrieves all alerts that represent significant service disruptions
 func (c *Client) GetServiceDisruptions(ctx context.Context) ([]models.Alert, error) {
-	// These are the effects that represent significant service disruptions
-	disruptions := []models.AlertEffect{
-		models.AlertEffectNoService,
-		models.AlertEffectReducedService,
-		models.AlertEffectSignificantDelays,
-		models.AlertEffectDetour,
-		models.AlertEffectStationClosure,
-		models.AlertEffectShuttle,
+	return ServiceDisruptions(ctx, c)
+}
+
+// GetAccessibilityAlerts retrieves all alerts related to accessibility issues
+func (c *Client) GetAccessibilityAlerts(ctx context.Context) ([]models.Alert, error) {
+	return AccessibilityAlerts(ctx, c)
+}
+
+// activeAlertParams filters alerts to those affecting riders right now
+func activeAlertParams() map[string]string {
+	return map[string]string{
+		"filter[activity]": "USING_ACCESSORY,BOARD,EXIT,PARK_CAR,RIDE,STORE_BIKE,USING_ESCALATOR,USING_WHEELCHAIR",
 	}
+}
 
+// serviceDisruptionParams filters alerts to significant service disruptions
+func serviceDisruptionParams() map[string]string {
 	// Convert effects to comma-separated string
-	effectStrings := make([]string, 0, len(disruptions))
-	for _, effect := range disruptions {
+	effectStrings := make([]string, 0, len(models.DisruptionEffects))
+	for _, effect := range models.DisruptionEffects {
 		effectStrings = append(effectStrings, string(effect))
 	}
 
-	params := map[string]string{
+	return map[string]string{
 		"filter[effect]": strings.Join(effectStrings, ","),
 	}
-
-	return c.GetAlerts(ctx, params)
 }
 
-// GetAccessibilityAlerts retrieves all alerts related to accessibility issues
-func (c *Client) GetAccessibilityAlerts(ctx context.Context) ([]models.Alert, error) {
-	params := map[string]string{
+// accessibilityAlertParams filters alerts to accessibility issues
+func accessibilityAlertParams() map[string]string {
+	return map[string]string{
 		"filter[effect]": string(models.AlertEffectAccessibilityIssue) + "," + string(models.AlertEffectElevatorOutage),
 	}
-	return c.GetAlerts(ctx, params)
 }
@@ -0,0 +1,65 @@
+package mbta
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// GetFacilities retrieves MBTA facilities such as elevators and escalators
+// with optional filtering
+func (c *Client) GetFacilities(ctx context.Context, params map[string]string) ([]models.Facility, error) {
+	// Build query parameters
+	query := url.Values{}
+	for key, value := range params {
+		query.Add(key, value)
+	}
+
+	path := "/facilities"
+	if queryString := query.Encode(); queryString != "" {
+		path += "?" + queryString
+	}
+
+	facilities, _, err := listAll[models.Facility](ctx, c, path, "facility")
+	if err != nil {
+		return nil, err
+	}
+
+	return facilities, nil
+}
+
+// GetLiveFacilities retrieves the current state of MBTA facilities with
+// optional filtering
+func (c *Client) GetLiveFacilities(ctx context.Context, params map[string]string) ([]models.LiveFacility, error) {
+	// Build query parameters
+	query := url.Values{}
+	for key, value := range params {
+		query.Add(key, value)
+	}
+
+	path := "/live_facilities"
+	if queryString := query.Encode(); queryString != "" {
+		path += "?" + queryString
+	}
+
+	liveFacilities, _, err := listAll[models.LiveFacility](ctx, c, path, "live_facility")
+	if err != nil {
+		return nil, err
+	}
+
+	return liveFacilities, nil
+}
+
+// GetFacilitiesByStop retrieves the facilities at a stop, optionally only
+// those of the given types
+func (c *Client) GetFacilitiesByStop(ctx context.Context, stopID string, facilityTypes ...string) ([]models.Facility, error) {
+	params := map[string]string{
+		"filter[stop]": stopID,
+	}
+	if len(facilityTypes) > 0 {
+		params["filter[type]"] = strings.Join(facilityTypes, ",")
+	}
+	return c.GetFacilities(ctx, params)
+}
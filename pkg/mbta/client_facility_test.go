@@ -0,0 +1,67 @@
+package mbta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+)
+
+func TestGetFacilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+
+		switch r.URL.Path {
+		case "/facilities":
+			if r.URL.Query().Get("filter[stop]") != "place-pktrm" {
+				t.Errorf("Expected filter[stop]=place-pktrm, got '%s'", r.URL.Query().Get("filter[stop]"))
+			}
+			if r.URL.Query().Get("filter[type]") != "ELEVATOR,ESCALATOR" {
+				t.Errorf("Expected filter[type]=ELEVATOR,ESCALATOR, got '%s'", r.URL.Query().Get("filter[type]"))
+			}
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": "804", "type": "facility", "attributes": {"long_name": "Park Street Elevator 804", "type": "ELEVATOR", "properties": []},
+					"relationships": {"stop": {"data": {"id": "place-pktrm", "type": "stop"}}}},
+				{"id": "830", "type": "facility", "attributes": {"long_name": "Park Street Escalator 830", "type": "ESCALATOR", "properties": []}}
+			]}`))
+		case "/live_facilities":
+			if r.URL.Query().Get("filter[id]") != "804,830" {
+				t.Errorf("Expected filter[id]=804,830, got '%s'", r.URL.Query().Get("filter[id]"))
+			}
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": "804", "type": "live_facility", "attributes": {"properties": [{"name": "status", "value": "working"}], "updated_at": "2025-05-23T10:00:00-04:00"}}
+			]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL, Timeout: 5 * time.Second})
+
+	facilities, err := client.GetFacilitiesByStop(context.Background(), "place-pktrm", "ELEVATOR", "ESCALATOR")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(facilities) != 2 {
+		t.Fatalf("Expected 2 facilities, got %d", len(facilities))
+	}
+	if facilities[0].GetName() != "Park Street Elevator 804" || facilities[0].GetStopID() != "place-pktrm" {
+		t.Errorf("Expected elevator 804 at place-pktrm, got %+v", facilities[0])
+	}
+
+	live, err := client.GetLiveFacilities(context.Background(), map[string]string{"filter[id]": "804,830"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(live) != 1 || live[0].GetFacilityID() != "804" {
+		t.Fatalf("Expected live state for 804, got %+v", live)
+	}
+	if value, _ := live[0].Property("status"); value != "working" {
+		t.Errorf("Expected status working, got %v", value)
+	}
+}
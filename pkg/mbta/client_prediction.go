@@ -2,9 +2,7 @@ package mbta
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
@@ -23,19 +21,12 @@ func (c *Client) GetPredictions(ctx context.Context, params map[string]string) (
 		path += "?" + queryString
 	}
 
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	predictions, _, err := listAll[models.Prediction](ctx, c, path, "prediction")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	// Parse response
-	var predictionResponse models.PredictionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&predictionResponse); err != nil {
-		return nil, fmt.Errorf("error decoding prediction response: %w", err)
-	}
-
-	return predictionResponse.Data, nil
+	return predictions, nil
 }
 
 // GetPredictionsByVehicle retrieves predictions for a specific vehicle
@@ -2,12 +2,11 @@ package mbta
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 )
@@ -18,38 +17,55 @@ import (
 // maxResults: Maximum number of results to return
 // onlyStations: If true, only return full stations (not platforms or stops)
 func (c *Client) FindNearbyStations(ctx context.Context, lat, lon, radius float64, maxResults int, onlyStations bool) ([]models.NearbyStation, error) {
-	// Build query parameters
-	query := url.Values{}
+	var filter StopFilter
 
 	// If only interested in stations, filter by location_type=1
 	if onlyStations {
-		query.Add("filter[location_type]", strconv.Itoa(models.LocationTypeStation))
+		filter.LocationTypes = []int{models.LocationTypeStation}
+	}
+
+	return c.FindNearbyStops(ctx, lat, lon, radius, maxResults, filter)
+}
+
+// FindNearbyStops finds up to maxResults stops matching the filter within
+// radius kilometers of the specified coordinates, closest first. A radius of 0
+// finds the nearest stops at any distance. Searches use the client's stop index
+// once it has been built, and otherwise fetch the matching stops.
+func (c *Client) FindNearbyStops(ctx context.Context, lat, lon, radius float64, maxResults int, filter StopFilter) ([]models.NearbyStation, error) {
+	if c.stopIndex != nil && c.stopIndex.Len() > 0 {
+		return c.stopIndex.Nearest(lat, lon, max(maxResults, 0), radius, filter), nil
+	}
+
+	// Build query parameters
+	query := url.Values{}
+	if len(filter.LocationTypes) > 0 {
+		query.Add("filter[location_type]", joinInts(filter.LocationTypes))
+	}
+	if len(filter.RouteTypes) > 0 {
+		query.Add("filter[route_type]", joinInts(filter.RouteTypes))
 	}
 
 	// Make the request to get all stops
 	path := "/stops?" + query.Encode()
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	stops, _, err := listAll[models.Stop](ctx, c, path, "station")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stations: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var stopResponse models.StopResponse
-	if err := json.NewDecoder(resp.Body).Decode(&stopResponse); err != nil {
-		return nil, fmt.Errorf("error decoding station response: %w", err)
-	}
 
 	// Calculate distance to each stop and filter by radius
 	var nearbyStations []models.NearbyStation
-	for _, stop := range stopResponse.Data {
+	for _, stop := range stops {
+		if filter.WheelchairAccessible && !stop.IsAccessible() {
+			continue
+		}
+
 		distance := calculateApproximateDistance(
 			lat, lon,
 			stop.Attributes.Latitude, stop.Attributes.Longitude,
 		)
 
 		// Only include stops within the radius
-		if distance <= radius {
+		if radius <= 0 || distance <= radius {
 			nearbyStations = append(nearbyStations, models.NearbyStation{
 				Stop:       stop,
 				DistanceKm: distance,
@@ -69,3 +85,12 @@ func (c *Client) FindNearbyStations(ctx context.Context, lat, lon, radius float6
 
 	return nearbyStations, nil
 }
+
+// joinInts formats integers as a comma-separated filter value
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,81 @@
+package mbta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// maxStationDepth bounds how many parent_station links are followed, since
+// boarding areas belong to platforms which belong to stations
+const maxStationDepth = 3
+
+// stationDescendants includes a station's child stops and theirs, such as
+// the boarding areas of its platforms
+const stationDescendants = "child_stops.child_stops"
+
+// GetStationHierarchy resolves a station, platform, entrance or boarding area
+// to the station it belongs to, with all of that station's descendants
+func (c *Client) GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error) {
+	stop, included, err := c.getStopIncluding(ctx, stopID, stationDescendants)
+	if err != nil {
+		return nil, err
+	}
+
+	for depth := 0; depth < maxStationDepth; depth++ {
+		parent := stop.GetParentStationID()
+		if parent == "" {
+			break
+		}
+		if stop, included, err = c.getStopIncluding(ctx, parent, stationDescendants); err != nil {
+			return nil, fmt.Errorf("error retrieving parent station %s: %w", parent, err)
+		}
+	}
+
+	// Walk down from the station through the included child stops
+	index := models.NewIncludedIndex(included)
+	seen := map[string]bool{stop.ID: true}
+	var descendants []models.Stop
+	pending := stop.GetChildStopIDs()
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if child, ok := index.Stop(id); ok {
+			descendants = append(descendants, *child)
+			pending = append(pending, child.GetChildStopIDs()...)
+		}
+	}
+
+	return models.NewStationHierarchy(*stop, descendants), nil
+}
+
+// getStopIncluding retrieves a stop along with the related resources named by include
+func (c *Client) getStopIncluding(ctx context.Context, stopID, include string) (*models.Stop, []models.Included, error) {
+	query := url.Values{}
+	query.Add("include", include)
+
+	resp, err := c.makeRequest(ctx, http.MethodGet, fmt.Sprintf("/stops/%s?%s", stopID, query.Encode()), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Parse response
+	var stopData struct {
+		Data     models.Stop       `json:"data"`
+		Included []models.Included `json:"included"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stopData); err != nil {
+		return nil, nil, fmt.Errorf("error decoding stop response: %w", err)
+	}
+
+	return &stopData.Data, stopData.Included, nil
+}
@@ -0,0 +1,161 @@
+package mbta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// stationTestStops is Park Street with two platforms, an entrance and a
+// boarding area on one of the platforms
+var stationTestStops = map[string]models.Stop{
+	"place-pktrm":        indexTestStop("place-pktrm", 42.3564, -71.0624, models.LocationTypeStation, 1, ""),
+	"70075":              indexTestStop("70075", 42.3564, -71.0624, models.LocationTypePlatform, 1, "place-pktrm"),
+	"70200":              indexTestStop("70200", 42.3564, -71.0624, models.LocationTypePlatform, 1, "place-pktrm"),
+	"door-pktrm-tremont": indexTestStop("door-pktrm-tremont", 42.3564, -71.0624, models.LocationTypeEntrance, 1, "place-pktrm"),
+	"70200-front":        indexTestStop("70200-front", 42.3564, -71.0624, models.LocationTypeBoardingArea, 1, "70200"),
+	"8279":               indexTestStop("8279", 42.355, -71.0605, models.LocationTypePlatform, 1, ""),
+}
+
+// childStops returns the identifiers and included resources of a stop's
+// children, linking each to its own children when nested is set
+func childStops(id string, nested bool) ([]interface{}, []models.Included) {
+	var identifiers []interface{}
+	var included []models.Included
+	for _, child := range stationTestStops {
+		if child.GetParentStationID() != id {
+			continue
+		}
+		identifiers = append(identifiers, map[string]interface{}{"id": child.ID, "type": "stop"})
+		relationships := child.Relationships
+		if nested {
+			grandchildren, grandchildIncluded := childStops(child.ID, false)
+			relationships = map[string]interface{}{
+				"parent_station": child.Relationships["parent_station"],
+				"child_stops":    map[string]interface{}{"data": grandchildren},
+			}
+			included = append(included, grandchildIncluded...)
+		}
+		included = append(included, models.Included{ID: child.ID, Type: "stop", Attributes: child.Attributes, Relationships: relationships})
+	}
+	return identifiers, included
+}
+
+// newStationTestServer serves stops with their child stops, and theirs,
+// included on request
+func newStationTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/stops/"):]
+		*requests = append(*requests, id)
+
+		stop, ok := stationTestStops[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": [{"status": "404", "code": "not_found"}]}`))
+			return
+		}
+		if r.URL.Query().Get("include") != "child_stops.child_stops" {
+			t.Errorf("Expected child stops and theirs to be included, got %s", r.URL.RawQuery)
+		}
+
+		children, included := childStops(id, true)
+		stop.Relationships = map[string]interface{}{
+			"parent_station": stop.Relationships["parent_station"],
+			"child_stops":    map[string]interface{}{"data": children},
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": stop, "included": included})
+	}))
+}
+
+func TestGetStationHierarchy(t *testing.T) {
+	var requests []string
+	server := newStationTestServer(t, &requests)
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL, Timeout: 5 * time.Second})
+
+	tests := []struct {
+		name     string
+		stopID   string
+		requests []string
+	}{
+		{name: "Station", stopID: "place-pktrm", requests: []string{"place-pktrm"}},
+		{name: "Platform", stopID: "70075", requests: []string{"70075", "place-pktrm"}},
+		{name: "BoardingArea", stopID: "70200-front", requests: []string{"70200-front", "70200", "place-pktrm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			hierarchy, err := client.GetStationHierarchy(context.Background(), tt.stopID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if hierarchy.Station.ID != "place-pktrm" {
+				t.Errorf("Expected station place-pktrm, got %s", hierarchy.Station.ID)
+			}
+			platforms := hierarchy.PlatformIDs()
+			if len(platforms) != 2 || !hierarchy.Contains("70075") || !hierarchy.Contains("70200") {
+				t.Errorf("Expected platforms 70075 and 70200, got %v", platforms)
+			}
+			if len(hierarchy.Entrances) != 1 || hierarchy.Entrances[0].ID != "door-pktrm-tremont" {
+				t.Errorf("Expected the Tremont Street entrance, got %v", hierarchy.Entrances)
+			}
+			// Boarding areas belong to platforms rather than the station
+			if len(hierarchy.BoardingAreas) != 1 || !hierarchy.Contains("70200-front") {
+				t.Errorf("Expected the boarding area on 70200, got %v", hierarchy.BoardingAreas)
+			}
+			if !reflect.DeepEqual(requests, tt.requests) {
+				t.Errorf("Expected requests for %v, got %v", tt.requests, requests)
+			}
+		})
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := client.GetStationHierarchy(context.Background(), "place-nowhere"); err == nil {
+			t.Error("Expected an error for an unknown stop")
+		}
+	})
+}
+
+func TestExpandStopIDs(t *testing.T) {
+	var requests []string
+	server := newStationTestServer(t, &requests)
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL, Timeout: 5 * time.Second})
+
+	expanded, err := ExpandStopIDs(context.Background(), client, "place-pktrm", "70075", "8279")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Platforms aren't expanded, and each ID appears once
+	if len(expanded) != 4 || expanded[0] != "place-pktrm" || expanded[3] != "8279" {
+		t.Errorf("Expected place-pktrm, its platforms and 8279, got %v", expanded)
+	}
+	seen := make(map[string]bool)
+	for _, id := range expanded {
+		if seen[id] {
+			t.Errorf("Expected %s once, got %v", id, expanded)
+		}
+		seen[id] = true
+	}
+	if !seen["70075"] || !seen["70200"] {
+		t.Errorf("Expected both platforms, got %v", expanded)
+	}
+
+	if _, err := ExpandStopIDs(context.Background(), client, "place-nowhere"); err == nil {
+		t.Error("Expected an error for an unknown stop")
+	}
+}
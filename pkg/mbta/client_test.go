@@ -125,6 +125,40 @@ func TestClientHeaders(t *testing.T) {
 	_ = resp.Body.Close()
 }
 
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	var requested string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		recorder := httptest.NewRecorder()
+		_, _ = fmt.Fprintln(recorder, `{"data": []}`)
+		return recorder.Result(), nil
+	})
+
+	cfg := &config.Config{
+		Timeout:    5 * time.Second,
+		APIBaseURL: "https://api-test.mbta.com",
+	}
+	client := NewClient(cfg, WithTransport(transport))
+
+	routes, err := client.GetRoutes(context.Background())
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(routes) != 0 {
+		t.Errorf("Expected no routes, got %d", len(routes))
+	}
+	if requested != "https://api-test.mbta.com/routes" {
+		t.Errorf("Expected request to https://api-test.mbta.com/routes, got %s", requested)
+	}
+}
+
 func TestAuthentication(t *testing.T) {
 	t.Run("With API Key", func(t *testing.T) {
 		apiKey := "test-api-key"
@@ -7,11 +7,17 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 )
 
+// accessibleAlternativeRadius is how far, in kilometers, to look for an
+// accessible station to use instead of one with an elevator outage
+const accessibleAlternativeRadius = 1.5
+
 // GetTrips retrieves trip data with optional filtering
 func (c *Client) GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error) {
 	// Build query parameters
@@ -25,19 +31,12 @@ func (c *Client) GetTrips(ctx context.Context, params map[string]string) ([]mode
 		path += "?" + queryString
 	}
 
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	trips, _, err := listAll[models.Trip](ctx, c, path, "trip")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	// Parse response
-	var tripResponse models.TripResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tripResponse); err != nil {
-		return nil, fmt.Errorf("error decoding trip response: %w", err)
-	}
-
-	return tripResponse.Data, nil
+	return trips, nil
 }
 
 // GetTrip retrieves a specific trip by ID
@@ -67,460 +66,474 @@ func (c *Client) GetTripsByRoute(ctx context.Context, routeID string) ([]models.
 	return c.GetTrips(ctx, params)
 }
 
-// PlanTrip creates a trip plan between two stops
+// PlanTrip creates a trip plan between two stops. It returns the fastest of
+// the itineraries found by PlanTrips, or for arrive-by searches the one
+// leaving latest.
 func (c *Client) PlanTrip(ctx context.Context, originStopID, destinationStopID string, departureTime time.Time, options map[string]interface{}) (*models.TripPlan, error) {
-	// First, validate that both stops exist
-	originStop, err := c.GetStop(ctx, originStopID)
+	plans, err := c.PlanTrips(ctx, originStopID, destinationStopID, departureTime, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plans are ordered from fewest transfers to fastest (or latest leaving)
+	return &plans[len(plans)-1], nil
+}
+
+// PlanTrips finds itineraries between two stops with a round-based (RAPTOR)
+// search over scheduled trips. Each itinerary returned either needs fewer
+// transfers or arrives sooner than every other, ordered from fewest transfers
+// to fastest. Supported options:
+// - wheelchair_accessible (bool): only use wheelchair accessible trips
+// - max_transfers (int): transfers allowed (default: DefaultMaxTransfers)
+// - arrive_by (bool): treat the time as the latest arrival rather than the earliest departure
+// - avoid_routes ([]string): route IDs not to ride, such as one riders know
+// is disrupted
+// - avoid_disruptions (bool): plan around suspensions, shuttles and closed
+// stations in active alerts, explaining them as detours
+// - origin_location, destination_location (Location): start or end at a
+// location instead of a stop, walking to or from the stops nearby
+// - departures (int): also plan the next departures, up to this many
+// searches in all (default: 1), see Timetable.PlanDepartures
+func (c *Client) PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error) {
+	// First, validate that both ends of the trip exist
+	origin, err := c.tripEndpoint(ctx, originStopID, options["origin_location"], OriginLocationID)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving origin stop: %w", err)
 	}
 
-	destinationStop, err := c.GetStop(ctx, destinationStopID)
+	destination, err := c.tripEndpoint(ctx, destinationStopID, options["destination_location"], DestinationLocationID)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving destination stop: %w", err)
 	}
+	originStop, destinationStop := origin.stop, destination.stop
 
-	// Get routes that serve the origin stop
-	originRoutes, err := c.getRoutesForStop(ctx, originStopID)
+	query := JourneyQuery{
+		Origin:       originStop.ID,
+		Destination:  destinationStop.ID,
+		Time:         at,
+		MaxTransfers: DefaultMaxTransfers,
+	}
+	if val, ok := options["wheelchair_accessible"].(bool); ok {
+		query.RequireAccessible = val
+	}
+	if val, ok := options["arrive_by"].(bool); ok {
+		query.ArriveBy = val
+	}
+	switch val := options["max_transfers"].(type) {
+	case int:
+		query.MaxTransfers = val
+	case float64:
+		query.MaxTransfers = int(val)
+	}
+	switch val := options["avoid_routes"].(type) {
+	case []string:
+		for _, routeID := range val {
+			query.Closures = append(query.Closures, Closure{RouteID: routeID})
+		}
+	case []interface{}:
+		for _, routeID := range val {
+			if routeID, ok := routeID.(string); ok && routeID != "" {
+				query.Closures = append(query.Closures, Closure{RouteID: routeID})
+			}
+		}
+	}
+	avoidDisruptions, _ := options["avoid_disruptions"].(bool)
+	departures := 1
+	switch val := options["departures"].(type) {
+	case int:
+		departures = val
+	case float64:
+		departures = int(val)
+	}
+
+	// Get routes that serve the origin and destination stops
+	originRoutes, err := c.getRoutesForStop(ctx, origin.routeStopIDs(), at)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving routes for origin: %w", err)
 	}
 
-	// Get routes that serve the destination stop
-	destRoutes, err := c.getRoutesForStop(ctx, destinationStopID)
+	destRoutes, err := c.getRoutesForStop(ctx, destination.routeStopIDs(), at)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving routes for destination: %w", err)
 	}
 
-	// Find common routes (direct trips)
-	directRoutes := findCommonRoutes(originRoutes, destRoutes)
+	routeIDs := mergeRouteIDs(originRoutes, destRoutes)
 
-	// Initialize trip plan
-	tripPlan := &models.TripPlan{
-		Origin:      originStop,
-		Destination: destinationStop,
-		Legs:        make([]models.TripLeg, 0),
+	// Find where riders can change between origin and destination routes, with
+	// known transfer times and walks; other transfers fall back to
+	// DefaultMinTransferTime
+	var transferPoints []models.TransferPoint
+	if query.MaxTransfers > 0 {
+		transferPoints, _ = c.FindTransferPoints(ctx, originRoutes, destRoutes)
 	}
 
-	// Check if wheelchair accessible trip is required
-	requireAccessible := false
-	if val, ok := options["wheelchair_accessible"]; ok {
-		if boolVal, ok := val.(bool); ok {
-			requireAccessible = boolVal
+	// Journeys that can't be made on one route or with one change usually
+	// change through the rapid transit network, so load it as well. It's
+	// most of the schedules a plan loads, so it's left out when it isn't
+	// needed.
+	if query.MaxTransfers > 1 && len(transferPoints) == 0 && !sharesRoute(originRoutes, destRoutes) {
+		rapidTransit, err := c.getRapidTransitRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving rapid transit routes: %w", err)
 		}
+		routeIDs = mergeRouteIDs(routeIDs, rapidTransit)
 	}
 
-	// Check for direct routes first (simplest case)
-	if len(directRoutes) > 0 {
-		// Just use the first direct route for now - in the future we could compare and find the best
-		routeID := directRoutes[0]
-
-		// Find schedules for this route that include both stops
-		scheduleParams := map[string]string{
-			"filter[route]":     routeID,
-			"filter[stop]":      originStopID + "," + destinationStopID,
-			"filter[date]":      departureTime.Format("2006-01-02"),
-			"filter[direction]": "0,1", // Consider both directions
-			"filter[min_time]":  departureTime.Format("15:04"),
-			"include":           "trip",
-			"sort":              "departure_time",
-			"fields[schedule]":  "departure_time,arrival_time,stop_sequence,pickup_type,drop_off_type,direction_id",
-			"fields[trip]":      "headsign,direction_id,wheelchair_accessible",
-			"fields[stop]":      "name,location_type,wheelchair_boarding",
-			"page[limit]":       "10", // Limit to a reasonable number
-		}
+	if len(routeIDs) == 0 {
+		return nil, fmt.Errorf("no routes serve %s or %s", origin.name(), destination.name())
+	}
 
-		schedules, included, err := c.GetSchedules(ctx, scheduleParams)
-		if err != nil {
-			return nil, fmt.Errorf("error retrieving schedules: %w", err)
-		}
+	from, to := at, at.Add(DefaultSearchWindow)
+	if query.ArriveBy {
+		from, to = at.Add(-DefaultSearchWindow), at
+	}
 
-		// Find potential legs based on schedules
-		directLeg, found, err := c.findDirectLeg(schedules, included, originStopID, destinationStopID, requireAccessible)
-		if err != nil {
-			return nil, fmt.Errorf("error finding direct trip leg: %w", err)
-		}
+	timetable, err := c.LoadTimetable(ctx, routeIDs, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error loading schedules: %w", err)
+	}
+	origin.addTo(timetable)
+	destination.addTo(timetable)
 
-		if found {
-			tripPlan.Legs = append(tripPlan.Legs, directLeg)
-			tripPlan.DepartureTime = directLeg.DepartureTime
-			tripPlan.ArrivalTime = directLeg.ArrivalTime
-			tripPlan.Duration = directLeg.Duration
-			tripPlan.TotalDistance = directLeg.Distance
-			tripPlan.AccessibleTrip = directLeg.IsAccessible
+	// Riders can walk between nearby stations, and to a stop across the street
+	timetable.AddFootpaths(c.footpaths)
+	timetable.AddNearbyFootpaths(DefaultMaxWalkDistance)
 
-			return tripPlan, nil
-		}
+	// Riders starting or ending at a location walk to or from the stops nearby
+	if origin.location {
+		timetable.AddAccessWalks(originStop, origin.nearby, true)
+	}
+	if destination.location {
+		timetable.AddAccessWalks(destinationStop, destination.nearby, false)
 	}
 
-	// If no direct route, need to find transfers
-	// This would be a more complex implementation involving:
-	// 1. Finding routes that serve the origin
-	// 2. Finding routes that serve the destination
-	// 3. Finding potential transfer points between these routes
-	// 4. Computing possible trip legs
-	// 5. Selecting the best combination
+	for _, point := range transferPoints {
+		timetable.AddTransfer(point)
+	}
 
-	// For simplicity in this implementation, we'll attempt just one transfer
-	// by finding a good transfer station
-	transferPlan, err := c.findSingleTransferTrip(ctx, originStop, destinationStop, originRoutes, destRoutes, departureTime, requireAccessible)
-	if err != nil {
-		return nil, fmt.Errorf("error finding transfer trip: %w", err)
+	// Riders who need step-free access can't use stations with a broken elevator
+	var outages map[string]*models.Alert
+	if query.RequireAccessible {
+		outages, err = ElevatorOutages(ctx, c, at)
+		if err != nil {
+			return nil, fmt.Errorf("error checking elevator outages: %w", err)
+		}
+		query.InaccessibleStops = timetable.inaccessibleStops(outages)
 	}
 
-	if transferPlan != nil {
-		return transferPlan, nil
+	// Plan around suspended segments, shuttles and closed stations
+	var closures map[Closure]*models.Alert
+	requested := query.Closures
+	if avoidDisruptions {
+		disruptions, err := ServiceDisruptions(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("error getting service disruptions: %w", err)
+		}
+		closures = DisruptionClosures(disruptions, at)
+		for closure := range closures {
+			query.Closures = append(query.Closures, closure)
+		}
 	}
 
-	return nil, fmt.Errorf("no possible trip found between %s and %s at specified time", originStopID, destinationStopID)
-}
+	plans := timetable.PlanDepartures(query, departures)
 
-// getRoutesForStop returns all routes that serve a specific stop
-func (c *Client) getRoutesForStop(ctx context.Context, stopID string) ([]string, error) {
-	// Query schedules filtered by stop to find routes
-	params := map[string]string{
-		"filter[stop]":  stopID,
-		"fields[route]": "id",
-		"include":       "route",
-	}
+	// Explain which outages ruled out the trips riders could otherwise take
+	var issues []models.AccessibilityIssue
+	if len(outages) > 0 {
+		unrestricted := query
+		unrestricted.InaccessibleStops = nil
+		issues = c.outageIssues(ctx, timetable.Plan(unrestricted), outages, query.InaccessibleStops)
 
-	_, included, err := c.GetSchedules(ctx, params)
-	if err != nil {
-		return nil, err
+		if len(plans) == 0 && len(issues) > 0 {
+			return nil, &InaccessibleTripError{Origin: origin.name(), Destination: destination.name(), Issues: issues}
+		}
 	}
 
-	// Extract unique route IDs from included data
-	routeMap := make(map[string]bool)
-	for _, inc := range included {
-		if inc.Type == "route" {
-			routeMap[inc.ID] = true
+	// Explain which disruptions ruled out the usual trips
+	var detours []models.Detour
+	if len(closures) > 0 {
+		usual := query
+		usual.Closures = requested
+		detours = Detours(timetable.Plan(usual), closures)
+
+		if len(plans) == 0 && len(detours) > 0 {
+			return nil, &DisruptedTripError{Origin: origin.name(), Destination: destination.name(), Detours: detours}
 		}
 	}
 
-	// Convert map to slice
-	routes := make([]string, 0, len(routeMap))
-	for route := range routeMap {
-		routes = append(routes, route)
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("no possible trip found between %s and %s at specified time", origin.name(), destination.name())
 	}
 
-	return routes, nil
-}
-
-// findCommonRoutes identifies routes that serve both stops
-func findCommonRoutes(routesA, routesB []string) []string {
-	// Create map for faster lookup
-	routeMapB := make(map[string]bool)
-	for _, route := range routesB {
-		routeMapB[route] = true
+	for i := range plans {
+		plans[i].Origin = originStop
+		plans[i].Destination = destinationStop
+		plans[i].AccessibilityIssues = issues
+		plans[i].Detours = detours
 	}
 
-	// Check each route in A against map B
-	common := make([]string, 0)
-	for _, route := range routesA {
-		if routeMapB[route] {
-			common = append(common, route)
+	// Check plans against real-time predictions, and warn about alerts on the
+	// way, without failing a plan for want of either. Plans that only walk
+	// have no trips to predict, and an empty filter would fetch every
+	// prediction in the system.
+	if tripIDs := planTripIDs(plans); len(tripIDs) > 0 {
+		if predictions, err := c.GetPredictions(ctx, map[string]string{"filter[trip]": strings.Join(tripIDs, ",")}); err == nil {
+			timetable.ApplyPredictions(plans, predictions)
 		}
 	}
+	if alerts, err := ActiveAlerts(ctx, c); err == nil {
+		AnnotateAlerts(plans, alerts)
+	}
 
-	return common
+	return plans, nil
 }
 
-// findDirectLeg attempts to find a direct trip leg between origin and destination stops
-func (c *Client) findDirectLeg(schedules []models.Schedule, included []models.Included, originID, destinationID string, requireAccessible bool) (models.TripLeg, bool, error) {
-	// Maps to store relevant information
-	stopSequences := make(map[string]map[string]int) // tripID -> stopID -> sequence
-	stopTimes := make(map[string]map[string]string)  // tripID -> stopID -> time
-	trips := make(map[string]models.Trip)            // tripID -> Trip
-	routes := make(map[string]models.Route)          // routeID -> Route
-	stops := make(map[string]models.Stop)            // stopID -> Stop
-
-	// Parse included data
-	for _, inc := range included {
-		switch inc.Type {
-		case "trip":
-			var trip models.Trip
-			tripBytes, _ := json.Marshal(inc)
-			if err := json.Unmarshal(tripBytes, &trip); err == nil {
-				trips[inc.ID] = trip
-			}
-		case "route":
-			var route models.Route
-			routeBytes, _ := json.Marshal(inc)
-			if err := json.Unmarshal(routeBytes, &route); err == nil {
-				routes[inc.ID] = route
-			}
-		case "stop":
-			var stop models.Stop
-			stopBytes, _ := json.Marshal(inc)
-			if err := json.Unmarshal(stopBytes, &stop); err == nil {
-				stops[inc.ID] = stop
+// planTripIDs returns the trips the plans ride, each once
+func planTripIDs(plans []models.TripPlan) []string {
+	seen := make(map[string]bool)
+	var tripIDs []string
+	for _, plan := range plans {
+		for _, leg := range plan.Legs {
+			if !leg.IsWalk() && !seen[leg.TripID] {
+				seen[leg.TripID] = true
+				tripIDs = append(tripIDs, leg.TripID)
 			}
 		}
 	}
+	return tripIDs
+}
 
-	// Process schedules to build trip segments
-	for _, schedule := range schedules {
-		// Extract trip ID from relationships
-		var tripID string
-		if tripRel, ok := schedule.Relationships["trip"]; ok {
-			if tripData, ok := tripRel.(map[string]interface{})["data"].(map[string]interface{}); ok {
-				if id, ok := tripData["id"].(string); ok {
-					tripID = id
-				}
-			}
-		}
+// outageIssues explains where elevator outages make stops inaccessible on the
+// given plans, once for each station, suggesting the nearest accessible
+// station to use instead
+func (c *Client) outageIssues(ctx context.Context, plans []models.TripPlan, outages map[string]*models.Alert, inaccessible map[string]bool) []models.AccessibilityIssue {
+	var issues []models.AccessibilityIssue
+	seen := make(map[string]bool)
 
-		// Extract stop ID from relationships
-		var stopID string
-		if stopRel, ok := schedule.Relationships["stop"]; ok {
-			if stopData, ok := stopRel.(map[string]interface{})["data"].(map[string]interface{}); ok {
-				if id, ok := stopData["id"].(string); ok {
-					stopID = id
-				}
-			}
+	check := func(stop *models.Stop, role, routeID string) {
+		station := stop.GetParentStationID()
+		if station == "" {
+			station = stop.ID
 		}
+		if seen[station] || (!inaccessible[stop.ID] && !inaccessible[station]) {
+			return
+		}
+		seen[station] = true
 
-		// Skip if missing key data
-		if tripID == "" || stopID == "" {
-			continue
+		// Outages naming only the station close all of it
+		alert, wholeStation := outages[stop.ID], false
+		if !inaccessible[stop.ID] {
+			alert, wholeStation = outages[station], true
 		}
 
-		// Initialize maps if needed
-		if _, ok := stopSequences[tripID]; !ok {
-			stopSequences[tripID] = make(map[string]int)
+		activity := map[string]string{
+			models.StopRoleOrigin:      "boarding",
+			models.StopRoleTransfer:    "transferring",
+			models.StopRoleDestination: "getting off",
+		}[role]
+		reason := fmt.Sprintf("Elevator outage at %s prevents %s", stop.Attributes.Name, activity)
+		if alert.Attributes.Header != "" {
+			reason += ": " + alert.Attributes.Header
 		}
-		if _, ok := stopTimes[tripID]; !ok {
-			stopTimes[tripID] = make(map[string]string)
+		if wholeStation {
+			reason += ". The alert doesn't say which platforms the elevator serves, so the whole station is treated as unusable"
 		}
 
-		// Store stop sequence and departure time
-		stopSequences[tripID][stopID] = schedule.Attributes.StopSequence
-		stopTimes[tripID][stopID] = schedule.Attributes.DepartureTime
+		issues = append(issues, models.AccessibilityIssue{
+			Stop:        stop,
+			Role:        role,
+			RouteID:     routeID,
+			Reason:      reason,
+			Alert:       alert,
+			Alternative: c.accessibleAlternative(ctx, stop, station, outages),
+		})
 	}
 
-	// Find trips that include both origin and destination
-	var bestLeg models.TripLeg
-	var foundValidLeg bool
+	for _, plan := range plans {
+		for i, leg := range plan.Legs {
+			if leg.IsWalk() {
+				continue
+			}
+			boardRole, alightRole := models.StopRoleTransfer, models.StopRoleTransfer
+			if i == 0 || (i == 1 && plan.Legs[0].IsWalk()) {
+				boardRole = models.StopRoleOrigin
+			}
+			if i == len(plan.Legs)-1 || (i == len(plan.Legs)-2 && plan.Legs[i+1].IsWalk()) {
+				alightRole = models.StopRoleDestination
+			}
+			check(leg.Origin, boardRole, leg.RouteID)
+			check(leg.Destination, alightRole, leg.RouteID)
+		}
+	}
 
-	for tripID, stopSeq := range stopSequences {
-		originSeq, hasOrigin := stopSeq[originID]
-		destSeq, hasDest := stopSeq[destinationID]
+	return issues
+}
 
-		// Skip if trip doesn't include both stops or if destination comes before origin
-		if !hasOrigin || !hasDest || destSeq <= originSeq {
-			continue
-		}
+// accessibleAlternative returns the nearest wheelchair accessible station to
+// a stop that isn't affected by an outage, or nil if there isn't one nearby
+func (c *Client) accessibleAlternative(ctx context.Context, stop *models.Stop, station string, outages map[string]*models.Alert) *models.NearbyStation {
+	if stop.Attributes.Latitude == 0 && stop.Attributes.Longitude == 0 {
+		return nil
+	}
 
-		// Get trip data
-		trip, hasTrip := trips[tripID]
-		if !hasTrip {
-			continue
-		}
+	nearby, err := c.FindNearbyStops(ctx, stop.Attributes.Latitude, stop.Attributes.Longitude, accessibleAlternativeRadius, 0, StopFilter{
+		LocationTypes:        []int{models.LocationTypeStation},
+		WheelchairAccessible: true,
+	})
+	if err != nil {
+		return nil
+	}
 
-		// Check accessibility requirements
-		if requireAccessible && !trip.IsWheelchairAccessible() {
+	for i := range nearby {
+		if nearby[i].Stop.ID == station {
 			continue
 		}
-
-		// Get route data
-		routeID := trip.GetRouteID()
-		route, hasRoute := routes[routeID]
-		if !hasRoute {
+		if _, out := outages[nearby[i].Stop.ID]; out {
 			continue
 		}
+		return &nearby[i]
+	}
+	return nil
+}
 
-		// Parse departure and arrival times
-		originTime := stopTimes[tripID][originID]
-		destTime := stopTimes[tripID][destinationID]
-
-		departureTime, err := time.Parse(time.RFC3339, originTime)
-		if err != nil {
-			continue
-		}
+// LoadTimetable loads the scheduled trips of the given routes that run between
+// two times and indexes them for trip planning. Trips are loaded from the
+// service day running at from, so times past midnight are requested at hours
+// past 24, and from the next service day as well when the window reaches it.
+func (c *Client) LoadTimetable(ctx context.Context, routeIDs []string, from, to time.Time) (*Timetable, error) {
+	// Sort the routes so the same request is cached regardless of their order
+	routeIDs = append([]string(nil), routeIDs...)
+	sort.Strings(routeIDs)
 
-		arrivalTime, err := time.Parse(time.RFC3339, destTime)
-		if err != nil {
-			continue
-		}
+	params := map[string]string{
+		"filter[route]": strings.Join(routeIDs, ","),
+		"include":       "trip,route,stop",
+		"sort":          "departure_time",
+	}
 
-		// Get Stop pointers or create new ones if not in the map
-		var originStop, destStop *models.Stop
-
-		if originStopData, found := stops[originID]; found {
-			originStopCopy := originStopData // Create a copy to get a stable pointer
-			originStop = &originStopCopy
-		} else {
-			// Create a minimal stop if not found
-			originStop = &models.Stop{
-				ID: originID,
-				Attributes: models.StopAttributes{
-					Name: "Unknown Stop",
-				},
-			}
-		}
+	schedules, included, err := getSchedulesBetween(ctx, c, params, from, to)
+	if err != nil {
+		return nil, err
+	}
 
-		if destStopData, found := stops[destinationID]; found {
-			destStopCopy := destStopData // Create a copy to get a stable pointer
-			destStop = &destStopCopy
-		} else {
-			// Create a minimal stop if not found
-			destStop = &models.Stop{
-				ID: destinationID,
-				Attributes: models.StopAttributes{
-					Name: "Unknown Stop",
-				},
-			}
-		}
+	return NewTimetable(schedules, included), nil
+}
 
-		// Create the leg
-		leg := models.TripLeg{
-			Origin:        originStop,
-			Destination:   destStop,
-			RouteID:       routeID,
-			RouteName:     route.Attributes.LongName,
-			TripID:        tripID,
-			DepartureTime: departureTime,
-			ArrivalTime:   arrivalTime,
-			Duration:      arrivalTime.Sub(departureTime),
-			Headsign:      trip.Attributes.Headsign,
-			DirectionID:   trip.Attributes.Direction,
-			IsAccessible:  trip.IsWheelchairAccessible(),
-			Instructions:  fmt.Sprintf("Board the %s toward %s", route.Attributes.LongName, trip.Attributes.Headsign),
-		}
+// getRapidTransitRoutes returns the IDs of all subway and light rail routes
+func (c *Client) getRapidTransitRoutes(ctx context.Context) ([]string, error) {
+	query := url.Values{}
+	query.Add("filter[type]", fmt.Sprintf("%d,%d", models.RouteTypeLightRail, models.RouteTypeSubway))
 
-		// Calculate approximate distance
-		// For a real implementation, we would use the route shape data
-		leg.Distance = calculateApproximateDistance(
-			originStop.Attributes.Latitude, originStop.Attributes.Longitude,
-			destStop.Attributes.Latitude, destStop.Attributes.Longitude,
-		)
+	routes, _, err := listAll[models.Route](ctx, c, "/routes?"+query.Encode(), "route")
+	if err != nil {
+		return nil, err
+	}
 
-		// For now just take the first valid leg we find
-		// In a more sophisticated implementation, we'd compare options
-		bestLeg = leg
-		foundValidLeg = true
-		break
+	routeIDs := make([]string, len(routes))
+	for i, route := range routes {
+		routeIDs[i] = route.ID
 	}
 
-	return bestLeg, foundValidLeg, nil
+	return routeIDs, nil
 }
 
-// findSingleTransferTrip attempts to find a trip with one transfer between origin and destination
-func (c *Client) findSingleTransferTrip(
-	ctx context.Context,
-	origin, destination *models.Stop,
-	originRoutes, destRoutes []string,
-	departureTime time.Time,
-	requireAccessible bool,
-) (*models.TripPlan, error) {
-	// Get potential transfer points by looking for stops that are served by
-	// both origin routes and destination routes
-	transferPoints, err := c.FindTransferPoints(ctx, originRoutes, destRoutes)
-	if err != nil {
-		return nil, fmt.Errorf("error finding transfer points: %w", err)
-	}
-
-	if len(transferPoints) == 0 {
-		return nil, fmt.Errorf("no transfer points found between origin and destination")
-	}
-
-	// For each transfer point, try to create a complete trip
-	for _, transfer := range transferPoints {
-		// Find a leg from origin to transfer point
-		firstLegParams := map[string]string{
-			"filter[route]":     transfer.FromRoute,
-			"filter[stop]":      origin.ID + "," + transfer.Stop.ID,
-			"filter[date]":      departureTime.Format("2006-01-02"),
-			"filter[min_time]":  departureTime.Format("15:04"),
-			"filter[direction]": "0,1", // Consider both directions
-			"include":           "trip,route,stop",
-			"sort":              "departure_time",
-			"fields[schedule]":  "departure_time,arrival_time,stop_sequence,pickup_type,drop_off_type,direction_id",
-			"fields[trip]":      "headsign,direction_id,wheelchair_accessible",
-			"fields[stop]":      "name,location_type,wheelchair_boarding",
-			"page[limit]":       "5", // Limit to a reasonable number
+// sharesRoute returns whether any route is in both lists
+func sharesRoute(a, b []string) bool {
+	for _, routeA := range a {
+		for _, routeB := range b {
+			if routeA == routeB {
+				return true
+			}
 		}
+	}
+	return false
+}
 
-		firstLegSchedules, firstLegIncluded, err := c.GetSchedules(ctx, firstLegParams)
-		if err != nil {
-			continue // Try the next transfer point
+// mergeRouteIDs combines route ID lists without duplicates, keeping their order
+func mergeRouteIDs(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0)
+	for _, list := range lists {
+		for _, routeID := range list {
+			if !seen[routeID] {
+				seen[routeID] = true
+				merged = append(merged, routeID)
+			}
 		}
+	}
+	return merged
+}
 
-		firstLeg, foundFirstLeg, err := c.findDirectLeg(
-			firstLegSchedules,
-			firstLegIncluded,
-			origin.ID,
-			transfer.Stop.ID,
-			requireAccessible,
-		)
-
-		if err != nil || !foundFirstLeg {
-			continue // Try the next transfer point
-		}
+// getRoutesForStop returns all routes that serve a specific stop on the
+// service day running at a given time
+func (c *Client) getRoutesForStop(ctx context.Context, stopID string, at time.Time) ([]string, error) {
+	// Query schedules filtered by stop to find routes
+	params := map[string]string{
+		"filter[stop]":  stopID,
+		"filter[date]":  ServiceDayOf(at).String(),
+		"fields[route]": "id",
+		"include":       "route",
+	}
 
-		// Calculate min transfer time (use default if not specified)
-		minTransferTime := transfer.MinTransferTime
-		if minTransferTime < 2*time.Minute {
-			minTransferTime = 2 * time.Minute // Default minimum transfer time
-		}
+	_, included, err := c.GetSchedules(ctx, params)
+	if err != nil {
+		return nil, err
+	}
 
-		// Find a leg from transfer point to destination
-		// Set departure time for second leg to be after arrival at transfer point plus transfer time
-		secondLegDepartureTime := firstLeg.ArrivalTime.Add(minTransferTime)
-
-		secondLegParams := map[string]string{
-			"filter[route]":     transfer.ToRoute,
-			"filter[stop]":      transfer.Stop.ID + "," + destination.ID,
-			"filter[date]":      secondLegDepartureTime.Format("2006-01-02"),
-			"filter[min_time]":  secondLegDepartureTime.Format("15:04"),
-			"filter[direction]": "0,1", // Consider both directions
-			"include":           "trip,route,stop",
-			"sort":              "departure_time",
-			"fields[schedule]":  "departure_time,arrival_time,stop_sequence,pickup_type,drop_off_type,direction_id",
-			"fields[trip]":      "headsign,direction_id,wheelchair_accessible",
-			"fields[stop]":      "name,location_type,wheelchair_boarding",
-			"page[limit]":       "5", // Limit to a reasonable number
+	// Extract unique route IDs from included data
+	routeMap := make(map[string]bool)
+	for _, inc := range included {
+		if inc.Type == "route" {
+			routeMap[inc.ID] = true
 		}
+	}
 
-		secondLegSchedules, secondLegIncluded, err := c.GetSchedules(ctx, secondLegParams)
-		if err != nil {
-			continue // Try the next transfer point
-		}
+	// Convert map to slice
+	routes := make([]string, 0, len(routeMap))
+	for route := range routeMap {
+		routes = append(routes, route)
+	}
 
-		secondLeg, foundSecondLeg, err := c.findDirectLeg(
-			secondLegSchedules,
-			secondLegIncluded,
-			transfer.Stop.ID,
-			destination.ID,
-			requireAccessible,
-		)
+	return routes, nil
+}
 
-		if err != nil || !foundSecondLeg {
-			continue // Try the next transfer point
-		}
+// findCommonRoutes identifies routes that serve both stops
+func findCommonRoutes(routesA, routesB []string) []string {
+	// Create map for faster lookup
+	routeMapB := make(map[string]bool)
+	for _, route := range routesB {
+		routeMapB[route] = true
+	}
 
-		// If we found both legs, create a complete trip plan
-		tripPlan := &models.TripPlan{
-			Origin:         origin,
-			Destination:    destination,
-			DepartureTime:  firstLeg.DepartureTime,
-			ArrivalTime:    secondLeg.ArrivalTime,
-			Duration:       secondLeg.ArrivalTime.Sub(firstLeg.DepartureTime),
-			Legs:           []models.TripLeg{firstLeg, secondLeg},
-			TotalDistance:  firstLeg.Distance + secondLeg.Distance,
-			AccessibleTrip: firstLeg.IsAccessible && secondLeg.IsAccessible,
+	// Check each route in A against map B
+	common := make([]string, 0)
+	for _, route := range routesA {
+		if routeMapB[route] {
+			common = append(common, route)
 		}
-
-		return tripPlan, nil
 	}
 
-	return nil, fmt.Errorf("no valid trip with transfer found between %s and %s at specified time", origin.ID, destination.ID)
+	return common
 }
 
-// FindTransferPoints finds potential transfer points between two sets of routes
+// FindTransferPoints finds where riders can change between two sets of
+// routes: at stops both serve, or by walking from a stop of one route to a
+// nearby stop of the other. Walks follow the client's footpaths where they
+// connect the stops and are estimated from coordinates otherwise.
 func (c *Client) FindTransferPoints(ctx context.Context, routesA, routesB []string) ([]models.TransferPoint, error) {
 	transferPoints := make([]models.TransferPoint, 0)
 
+	// Load each route's stops once
+	routeStops := make(map[string][]models.Stop)
+	stopsFor := func(routeID string) ([]models.Stop, bool) {
+		if stops, ok := routeStops[routeID]; ok {
+			return stops, stops != nil
+		}
+		stops, err := c.getStopsForRoute(ctx, routeID)
+		routeStops[routeID] = stops
+		return stops, err == nil
+	}
+
 	// For each pair of routes
 	for _, routeA := range routesA {
 		for _, routeB := range routesB {
@@ -529,26 +542,23 @@ func (c *Client) FindTransferPoints(ctx context.Context, routesA, routesB []stri
 				continue
 			}
 
-			// Find common stops between routes
-			stopsA, err := c.getStopsForRoute(ctx, routeA)
-			if err != nil {
+			stopsA, ok := stopsFor(routeA)
+			if !ok {
 				continue
 			}
-
-			stopsB, err := c.getStopsForRoute(ctx, routeB)
-			if err != nil {
+			stopsB, ok := stopsFor(routeB)
+			if !ok {
 				continue
 			}
 
 			// Find common stops
-			commonStops := findCommonStops(stopsA, stopsB)
+			commonStops := findCommonStops(stopIDs(stopsA), stopIDs(stopsB))
+			shared := make(map[string]bool, len(commonStops))
 
 			// For each common stop, create a transfer point
 			for _, stopID := range commonStops {
-				stop, err := c.GetStop(ctx, stopID)
-				if err != nil {
-					continue
-				}
+				shared[stopID] = true
+				stop := findStop(stopsA, stopID)
 
 				// Create transfer point
 				transferPoints = append(transferPoints, models.TransferPoint{
@@ -559,40 +569,102 @@ func (c *Client) FindTransferPoints(ctx context.Context, routesA, routesB []stri
 					MinTransferTime: 3 * time.Minute, // Default transfer time
 				})
 			}
+
+			transferPoints = append(transferPoints, c.walkingTransfers(stopsA, stopsB, shared, routeA, routeB)...)
 		}
 	}
 
 	return transferPoints, nil
 }
 
+// walkingTransfers finds, for each stop of one route not shared with the
+// other, the quickest walk to a stop of the other route, quickest first
+func (c *Client) walkingTransfers(stopsA, stopsB []models.Stop, shared map[string]bool, routeA, routeB string) []models.TransferPoint {
+	var points []models.TransferPoint
+	for i := range stopsA {
+		from := &stopsA[i]
+		if shared[from.ID] {
+			continue
+		}
+
+		var quickest *models.TransferPoint
+		for j := range stopsB {
+			to := &stopsB[j]
+			if shared[to.ID] || stationID(from) == stationID(to) {
+				continue
+			}
+			path, ok := c.footpath(from, to)
+			if !ok || (quickest != nil && path.Duration >= quickest.MinTransferTime) {
+				continue
+			}
+			quickest = &models.TransferPoint{
+				Stop:            from,
+				ToStop:          to,
+				FromRoute:       routeA,
+				ToRoute:         routeB,
+				TransferType:    models.TransferTypeMinimum,
+				MinTransferTime: path.Duration,
+				WalkDistance:    path.Distance,
+			}
+		}
+		if quickest != nil {
+			points = append(points, *quickest)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool { return points[i].MinTransferTime < points[j].MinTransferTime })
+	return points
+}
+
+// footpath returns the walk between two stops: the client's footpath if there
+// is one, or an estimate if they're within DefaultMaxWalkDistance
+func (c *Client) footpath(from, to *models.Stop) (Footpath, bool) {
+	if c.footpaths != nil {
+		if path, ok := c.footpaths.Between(from.ID, to.ID); ok {
+			return path, true
+		}
+	}
+
+	path, ok := EstimateFootpath(from, to)
+	if !ok || path.Distance > DefaultMaxWalkDistance {
+		return Footpath{}, false
+	}
+	return path, true
+}
+
 // getStopsForRoute returns all stops served by a specific route
-func (c *Client) getStopsForRoute(ctx context.Context, routeID string) ([]string, error) {
+func (c *Client) getStopsForRoute(ctx context.Context, routeID string) ([]models.Stop, error) {
 	// Use query parameters to filter stops by route
 	query := url.Values{}
 	query.Add("filter[route]", routeID)
-	query.Add("fields[stop]", "id")
 
 	path := "/stops?" + query.Encode()
 
-	stopsResp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	stops, _, err := listAll[models.Stop](ctx, c, path, "stop")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = stopsResp.Body.Close() }()
 
-	// Parse response
-	var stopResponse models.StopResponse
-	if err := json.NewDecoder(stopsResp.Body).Decode(&stopResponse); err != nil {
-		return nil, fmt.Errorf("error decoding stop response: %w", err)
-	}
+	return stops, nil
+}
 
-	// Extract stop IDs
-	stopIDs := make([]string, len(stopResponse.Data))
-	for i, stop := range stopResponse.Data {
-		stopIDs[i] = stop.ID
+// stopIDs returns the IDs of stops
+func stopIDs(stops []models.Stop) []string {
+	ids := make([]string, len(stops))
+	for i, stop := range stops {
+		ids[i] = stop.ID
 	}
+	return ids
+}
 
-	return stopIDs, nil
+// findStop returns the stop with the given ID from a list
+func findStop(stops []models.Stop, stopID string) *models.Stop {
+	for i := range stops {
+		if stops[i].ID == stopID {
+			return &stops[i]
+		}
+	}
+	return nil
 }
 
 // findCommonStops identifies stops that are in both stop lists
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 )
 
 func TestGetTrips(t *testing.T) {
@@ -360,6 +361,74 @@ func TestFindCommonRoutes(t *testing.T) {
 	}
 }
 
+func TestFindTransferPoints(t *testing.T) {
+	routeStops := map[string]string{
+		"Orange": `[
+			{"id": "place-dwnxg", "type": "stop", "attributes": {"name": "Downtown Crossing", "latitude": 42.355518, "longitude": -71.060225}},
+			{"id": "place-haecl", "type": "stop", "attributes": {"name": "Haymarket", "latitude": 42.363021, "longitude": -71.05829}},
+			{"id": "place-rugg", "type": "stop", "attributes": {"name": "Ruggles", "latitude": 42.336377, "longitude": -71.08904}}
+		]`,
+		"Green-D": `[
+			{"id": "place-pktrm", "type": "stop", "attributes": {"name": "Park Street", "latitude": 42.356395, "longitude": -71.062424}},
+			{"id": "place-haecl", "type": "stop", "attributes": {"name": "Haymarket", "latitude": 42.363021, "longitude": -71.05829}},
+			{"id": "place-kencl", "type": "stop", "attributes": {"name": "Kenmore", "latitude": 42.348949, "longitude": -71.095169}}
+		]`,
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stops" {
+			t.Errorf("Expected path /stops, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = w.Write([]byte(`{"data": ` + routeStops[r.URL.Query().Get("filter[route]")] + `}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Run("Shared and nearby stops", func(t *testing.T) {
+		client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+		points, err := client.FindTransferPoints(context.Background(), []string{"Orange"}, []string{"Green-D"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(points) != 2 {
+			t.Fatalf("Expected 2 transfer points, got %d: %+v", len(points), points)
+		}
+
+		shared := points[0]
+		if shared.Stop.ID != "place-haecl" || shared.IsWalking() {
+			t.Errorf("Expected a transfer at Haymarket, got %+v", shared)
+		}
+
+		// Riders walk from Downtown Crossing to Park Street
+		walk := points[1]
+		if !walk.IsWalking() || walk.Stop.ID != "place-dwnxg" || walk.ToStop.ID != "place-pktrm" {
+			t.Fatalf("Expected a walk from Downtown Crossing to Park Street, got %+v", walk)
+		}
+		if walk.WalkDistance < 0.15 || walk.WalkDistance > 0.25 {
+			t.Errorf("Expected a walk of about 0.2 km, got %.3f", walk.WalkDistance)
+		}
+		if walk.MinTransferTime != WalkingTime(walk.WalkDistance) || walk.TransferType != models.TransferTypeMinimum {
+			t.Errorf("Expected a minimum transfer time of %s, got %s", WalkingTime(walk.WalkDistance), walk.MinTransferTime)
+		}
+	})
+
+	t.Run("Known footpaths", func(t *testing.T) {
+		graph := NewFootpathGraph()
+		graph.Add(Footpath{FromStopID: "place-dwnxg", ToStopID: "place-pktrm", Distance: 0.25, Duration: 5 * time.Minute, Source: FootpathSourcePathway})
+		client := NewClient(&config.Config{APIBaseURL: server.URL}, WithFootpaths(graph))
+
+		points, err := client.FindTransferPoints(context.Background(), []string{"Orange"}, []string{"Green-D"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(points) != 2 || points[1].MinTransferTime != 5*time.Minute || points[1].WalkDistance != 0.25 {
+			t.Errorf("Expected the known 5 minute walk, got %+v", points)
+		}
+	})
+}
+
 func TestFindCommonStops(t *testing.T) {
 	tests := []struct {
 		name     string
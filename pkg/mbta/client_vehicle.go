@@ -23,19 +23,12 @@ func (c *Client) GetVehicles(ctx context.Context, params map[string]string) ([]m
 		path += "?" + queryString
 	}
 
-	resp, err := c.makeRequest(ctx, http.MethodGet, path, nil)
+	vehicles, _, err := listAll[models.Vehicle](ctx, c, path, "vehicle")
 	if err != nil {
 		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }()
-
-	// Parse response
-	var vehicleResponse models.VehicleResponse
-	if err := json.NewDecoder(resp.Body).Decode(&vehicleResponse); err != nil {
-		return nil, fmt.Errorf("error decoding vehicle response: %w", err)
-	}
 
-	return vehicleResponse.Data, nil
+	return vehicles, nil
 }
 
 // GetVehicle retrieves a specific MBTA vehicle by ID
@@ -0,0 +1,310 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Call describes a single TransitAPI method invocation passed through decorators
+type Call struct {
+	Method string // TransitAPI method name, such as "GetStop"
+	Args   []any  // Arguments after the context
+
+	invoke func(ctx context.Context) (any, error)
+}
+
+// CallHandler performs a call, returning the method's result
+type CallHandler func(ctx context.Context, call Call) (any, error)
+
+// Decorator wraps a call handler with extra behavior such as caching,
+// metrics or retries
+type Decorator func(next CallHandler) CallHandler
+
+// Decorate wraps every method of api with the given decorators. The first
+// decorator is the outermost, so Decorate(api, metrics, cache) counts every
+// call, including those the cache answers.
+func Decorate(api TransitAPI, decorators ...Decorator) TransitAPI {
+	handler := func(ctx context.Context, call Call) (any, error) {
+		return call.invoke(ctx)
+	}
+	for i := len(decorators) - 1; i >= 0; i-- {
+		handler = decorators[i](handler)
+	}
+	return &decoratedAPI{api: api, handler: handler}
+}
+
+// RetryDecorator retries calls that fail with a network error, a timeout, a
+// rate limit or a server error, according to the policy
+func RetryDecorator(policy RetryPolicy) Decorator {
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call Call) (any, error) {
+			return retry(ctx, &policy, func() (any, error) {
+				return next(ctx, call)
+			})
+		}
+	}
+}
+
+// decoratedAPI implements TransitAPI by passing every method through a handler
+type decoratedAPI struct {
+	api     TransitAPI
+	handler CallHandler
+}
+
+// invoke passes a method call through the handler and converts the result back
+func invoke[T any](ctx context.Context, d *decoratedAPI, method string, args []any, fn func(context.Context) (T, error)) (T, error) {
+	result, err := d.handler(ctx, Call{
+		Method: method,
+		Args:   args,
+		invoke: func(ctx context.Context) (any, error) { return fn(ctx) },
+	})
+	value, _ := result.(T)
+	return value, err
+}
+
+// scheduleResult carries both results of GetSchedules through decorators
+type scheduleResult struct {
+	schedules []models.Schedule
+	included  []models.Included
+}
+
+// GetRoutes implements RouteDataSource
+func (d *decoratedAPI) GetRoutes(ctx context.Context) ([]models.Route, error) {
+	return invoke(ctx, d, "GetRoutes", nil, d.api.GetRoutes)
+}
+
+// GetRoute implements RouteDataSource
+func (d *decoratedAPI) GetRoute(ctx context.Context, routeID string) (*models.Route, error) {
+	return invoke(ctx, d, "GetRoute", []any{routeID}, func(ctx context.Context) (*models.Route, error) {
+		return d.api.GetRoute(ctx, routeID)
+	})
+}
+
+// GetStops implements StopDataSource
+func (d *decoratedAPI) GetStops(ctx context.Context) ([]models.Stop, error) {
+	return invoke(ctx, d, "GetStops", nil, d.api.GetStops)
+}
+
+// GetStop implements StopDataSource
+func (d *decoratedAPI) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	return invoke(ctx, d, "GetStop", []any{stopID}, func(ctx context.Context) (*models.Stop, error) {
+		return d.api.GetStop(ctx, stopID)
+	})
+}
+
+// GetStopsForRoute implements StopDataSource
+func (d *decoratedAPI) GetStopsForRoute(ctx context.Context, routeID string) ([]string, error) {
+	return invoke(ctx, d, "GetStopsForRoute", []any{routeID}, func(ctx context.Context) ([]string, error) {
+		return d.api.GetStopsForRoute(ctx, routeID)
+	})
+}
+
+// FindNearbyStops implements StopDataSource
+func (d *decoratedAPI) FindNearbyStops(ctx context.Context, lat, lon, radius float64, maxResults int, filter StopFilter) ([]models.NearbyStation, error) {
+	args := []any{lat, lon, radius, maxResults, filter}
+	return invoke(ctx, d, "FindNearbyStops", args, func(ctx context.Context) ([]models.NearbyStation, error) {
+		return d.api.FindNearbyStops(ctx, lat, lon, radius, maxResults, filter)
+	})
+}
+
+// GetStationHierarchy implements StopDataSource
+func (d *decoratedAPI) GetStationHierarchy(ctx context.Context, stopID string) (*models.StationHierarchy, error) {
+	return invoke(ctx, d, "GetStationHierarchy", []any{stopID}, func(ctx context.Context) (*models.StationHierarchy, error) {
+		return d.api.GetStationHierarchy(ctx, stopID)
+	})
+}
+
+// GetSchedules implements ScheduleDataSource
+func (d *decoratedAPI) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	result, err := invoke(ctx, d, "GetSchedules", []any{params}, func(ctx context.Context) (scheduleResult, error) {
+		schedules, included, err := d.api.GetSchedules(ctx, params)
+		return scheduleResult{schedules: schedules, included: included}, err
+	})
+	return result.schedules, result.included, err
+}
+
+// GetPredictions implements PredictionDataSource
+func (d *decoratedAPI) GetPredictions(ctx context.Context, params map[string]string) ([]models.Prediction, error) {
+	return invoke(ctx, d, "GetPredictions", []any{params}, func(ctx context.Context) ([]models.Prediction, error) {
+		return d.api.GetPredictions(ctx, params)
+	})
+}
+
+// GetVehicles implements VehicleDataSource
+func (d *decoratedAPI) GetVehicles(ctx context.Context, params map[string]string) ([]models.Vehicle, error) {
+	return invoke(ctx, d, "GetVehicles", []any{params}, func(ctx context.Context) ([]models.Vehicle, error) {
+		return d.api.GetVehicles(ctx, params)
+	})
+}
+
+// GetVehicle implements VehicleDataSource
+func (d *decoratedAPI) GetVehicle(ctx context.Context, vehicleID string) (*models.Vehicle, error) {
+	return invoke(ctx, d, "GetVehicle", []any{vehicleID}, func(ctx context.Context) (*models.Vehicle, error) {
+		return d.api.GetVehicle(ctx, vehicleID)
+	})
+}
+
+// GetAlerts implements AlertDataSource
+func (d *decoratedAPI) GetAlerts(ctx context.Context, params map[string]string) ([]models.Alert, error) {
+	return invoke(ctx, d, "GetAlerts", []any{params}, func(ctx context.Context) ([]models.Alert, error) {
+		return d.api.GetAlerts(ctx, params)
+	})
+}
+
+// GetAlert implements AlertDataSource
+func (d *decoratedAPI) GetAlert(ctx context.Context, alertID string) (*models.Alert, error) {
+	return invoke(ctx, d, "GetAlert", []any{alertID}, func(ctx context.Context) (*models.Alert, error) {
+		return d.api.GetAlert(ctx, alertID)
+	})
+}
+
+// GetFacilities implements FacilityDataSource
+func (d *decoratedAPI) GetFacilities(ctx context.Context, params map[string]string) ([]models.Facility, error) {
+	return invoke(ctx, d, "GetFacilities", []any{params}, func(ctx context.Context) ([]models.Facility, error) {
+		return d.api.GetFacilities(ctx, params)
+	})
+}
+
+// GetLiveFacilities implements FacilityDataSource
+func (d *decoratedAPI) GetLiveFacilities(ctx context.Context, params map[string]string) ([]models.LiveFacility, error) {
+	return invoke(ctx, d, "GetLiveFacilities", []any{params}, func(ctx context.Context) ([]models.LiveFacility, error) {
+		return d.api.GetLiveFacilities(ctx, params)
+	})
+}
+
+// GetTrips implements TripDataSource
+func (d *decoratedAPI) GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error) {
+	return invoke(ctx, d, "GetTrips", []any{params}, func(ctx context.Context) ([]models.Trip, error) {
+		return d.api.GetTrips(ctx, params)
+	})
+}
+
+// GetTrip implements TripDataSource
+func (d *decoratedAPI) GetTrip(ctx context.Context, tripID string) (*models.Trip, error) {
+	return invoke(ctx, d, "GetTrip", []any{tripID}, func(ctx context.Context) (*models.Trip, error) {
+		return d.api.GetTrip(ctx, tripID)
+	})
+}
+
+// PlanTrips implements TripDataSource
+func (d *decoratedAPI) PlanTrips(ctx context.Context, originStopID, destinationStopID string, at time.Time, options map[string]interface{}) ([]models.TripPlan, error) {
+	args := []any{originStopID, destinationStopID, at, options}
+	return invoke(ctx, d, "PlanTrips", args, func(ctx context.Context) ([]models.TripPlan, error) {
+		return d.api.PlanTrips(ctx, originStopID, destinationStopID, at, options)
+	})
+}
+
+// FindTransferPoints implements TripDataSource
+func (d *decoratedAPI) FindTransferPoints(ctx context.Context, routesA, routesB []string) ([]models.TransferPoint, error) {
+	return invoke(ctx, d, "FindTransferPoints", []any{routesA, routesB}, func(ctx context.Context) ([]models.TransferPoint, error) {
+		return d.api.FindTransferPoints(ctx, routesA, routesB)
+	})
+}
+
+// DefaultCallCacheTTLs are cache lifetimes for the results of calls for data
+// that rarely changes during a day
+var DefaultCallCacheTTLs = map[string]time.Duration{
+	"GetRoutes":           time.Hour,
+	"GetRoute":            time.Hour,
+	"GetStops":            time.Hour,
+	"GetStop":             time.Hour,
+	"GetStopsForRoute":    time.Hour,
+	"GetStationHierarchy": time.Hour,
+	"GetFacilities":       time.Hour,
+}
+
+// CacheDecorator keeps the results of successful calls in memory for the
+// lifetime given for their method, holding at most maxEntries results. Calls
+// to methods without a lifetime pass through. Cached results are shared
+// between callers, who must not modify them.
+func CacheDecorator(maxEntries int, ttls map[string]time.Duration) Decorator {
+	cache := &callCache{
+		maxEntries: maxEntries,
+		ttls:       ttls,
+		entries:    make(map[string]callCacheEntry),
+	}
+
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call Call) (any, error) {
+			ttl := cache.ttls[call.Method]
+			if ttl <= 0 || cache.maxEntries <= 0 {
+				return next(ctx, call)
+			}
+
+			key := fmt.Sprintf("%s%v", call.Method, call.Args)
+			if result, ok := cache.get(key); ok {
+				return result, nil
+			}
+
+			result, err := next(ctx, call)
+			if err == nil {
+				cache.put(key, result, ttl)
+			}
+			return result, err
+		}
+	}
+}
+
+// callCache holds cached call results
+type callCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttls       map[string]time.Duration
+	entries    map[string]callCacheEntry
+}
+
+// callCacheEntry is a cached call result
+type callCacheEntry struct {
+	result  any
+	expires time.Time
+}
+
+// get returns an unexpired cached result
+func (c *callCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// put caches a result, making room by dropping expired entries and then
+// those closest to expiring
+func (c *callCache) put(key string, result any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for len(c.entries) >= c.maxEntries {
+			var soonest string
+			for k, entry := range c.entries {
+				if soonest == "" || entry.expires.Before(c.entries[soonest].expires) {
+					soonest = k
+				}
+			}
+			delete(c.entries, soonest)
+		}
+	}
+
+	c.entries[key] = callCacheEntry{result: result, expires: now.Add(ttl)}
+}
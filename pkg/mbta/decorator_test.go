@@ -0,0 +1,196 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// fakeRoutes is a route backend that counts calls and fails on demand
+type fakeRoutes struct {
+	calls    map[string]int
+	failures []error // Returned by successive GetRoute calls before succeeding
+}
+
+func newFakeRoutes(failures ...error) *fakeRoutes {
+	return &fakeRoutes{calls: make(map[string]int), failures: failures}
+}
+
+func (f *fakeRoutes) GetRoutes(ctx context.Context) ([]models.Route, error) {
+	f.calls["GetRoutes"]++
+	return []models.Route{{ID: "Red"}, {ID: "Orange"}}, nil
+}
+
+func (f *fakeRoutes) GetRoute(ctx context.Context, routeID string) (*models.Route, error) {
+	f.calls[routeID]++
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		return nil, err
+	}
+	return &models.Route{ID: routeID}, nil
+}
+
+func TestDecorateOrder(t *testing.T) {
+	var order []string
+	trace := func(name string) Decorator {
+		return func(next CallHandler) CallHandler {
+			return func(ctx context.Context, call Call) (any, error) {
+				order = append(order, name+" "+call.Method)
+				return next(ctx, call)
+			}
+		}
+	}
+
+	api := Decorate(CompositeAPI{RouteDataSource: newFakeRoutes()}, trace("outer"), trace("inner"))
+
+	route, err := api.GetRoute(context.Background(), "Red")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if route.ID != "Red" {
+		t.Errorf("Expected route Red, got %s", route.ID)
+	}
+
+	expected := []string{"outer GetRoute", "inner GetRoute"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+}
+
+func TestDecorateMultipleResults(t *testing.T) {
+	schedules := scheduleSourceFunc(func(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+		return []models.Schedule{{ID: "s1"}}, []models.Included{{ID: "t1", Type: "trip"}}, nil
+	})
+
+	api := Decorate(CompositeAPI{ScheduleDataSource: schedules})
+	got, included, err := api.GetSchedules(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != "s1" || len(included) != 1 || included[0].ID != "t1" {
+		t.Errorf("Expected schedule s1 with trip t1, got %v and %v", got, included)
+	}
+}
+
+// scheduleSourceFunc adapts a function to a ScheduleDataSource
+type scheduleSourceFunc func(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error)
+
+func (f scheduleSourceFunc) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	return f(ctx, params)
+}
+
+func TestCacheDecorator(t *testing.T) {
+	t.Run("Caches results per arguments", func(t *testing.T) {
+		routes := newFakeRoutes()
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, CacheDecorator(10, DefaultCallCacheTTLs))
+
+		for i := 0; i < 3; i++ {
+			if _, err := api.GetRoute(context.Background(), "Red"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if _, err := api.GetRoute(context.Background(), "Orange"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if routes.calls["Red"] != 1 || routes.calls["Orange"] != 1 {
+			t.Errorf("Expected one call per route, got %v", routes.calls)
+		}
+	})
+
+	t.Run("Skips methods without a lifetime", func(t *testing.T) {
+		routes := newFakeRoutes()
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, CacheDecorator(10, map[string]time.Duration{"GetRoute": time.Hour}))
+
+		for i := 0; i < 2; i++ {
+			if _, err := api.GetRoutes(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if routes.calls["GetRoutes"] != 2 {
+			t.Errorf("Expected 2 uncached calls, got %d", routes.calls["GetRoutes"])
+		}
+	})
+
+	t.Run("Expires results", func(t *testing.T) {
+		routes := newFakeRoutes()
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, CacheDecorator(10, map[string]time.Duration{"GetRoute": time.Millisecond}))
+
+		_, _ = api.GetRoute(context.Background(), "Red")
+		time.Sleep(5 * time.Millisecond)
+		_, _ = api.GetRoute(context.Background(), "Red")
+
+		if routes.calls["Red"] != 2 {
+			t.Errorf("Expected the expired result to be fetched again, got %d calls", routes.calls["Red"])
+		}
+	})
+
+	t.Run("Evicts the soonest to expire", func(t *testing.T) {
+		routes := newFakeRoutes()
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, CacheDecorator(2, DefaultCallCacheTTLs))
+
+		for _, id := range []string{"Red", "Orange", "Blue", "Blue", "Orange", "Red"} {
+			if _, err := api.GetRoute(context.Background(), id); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		// Red was cached first, so it made room for Blue and had to be fetched again
+		expected := map[string]int{"Red": 2, "Orange": 1, "Blue": 1}
+		if !reflect.DeepEqual(routes.calls, expected) {
+			t.Errorf("Expected calls %v, got %v", expected, routes.calls)
+		}
+	})
+
+	t.Run("Doesn't cache errors", func(t *testing.T) {
+		routes := newFakeRoutes(errors.New("boom"))
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, CacheDecorator(10, DefaultCallCacheTTLs))
+
+		if _, err := api.GetRoute(context.Background(), "Red"); err == nil {
+			t.Fatal("Expected the first call to fail")
+		}
+		route, err := api.GetRoute(context.Background(), "Red")
+		if err != nil || route.ID != "Red" {
+			t.Fatalf("Expected the second call to succeed, got %v, %v", route, err)
+		}
+		if routes.calls["Red"] != 2 {
+			t.Errorf("Expected 2 calls, got %d", routes.calls["Red"])
+		}
+	})
+}
+
+func TestRetryDecorator(t *testing.T) {
+	t.Run("Retries transient errors", func(t *testing.T) {
+		routes := newFakeRoutes(
+			&NetworkError{Err: errors.New("connection reset")},
+			fmt.Errorf("wrapped: %w", &APIError{StatusCode: 503}),
+		)
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, RetryDecorator(fastRetry))
+
+		route, err := api.GetRoute(context.Background(), "Red")
+		if err != nil {
+			t.Fatalf("Expected success after retries, got %v", err)
+		}
+		if route.ID != "Red" || routes.calls["Red"] != 3 {
+			t.Errorf("Expected Red after 3 calls, got %v after %d", route, routes.calls["Red"])
+		}
+	})
+
+	t.Run("Doesn't retry permanent errors", func(t *testing.T) {
+		routes := newFakeRoutes(&APIError{StatusCode: 404})
+		api := Decorate(CompositeAPI{RouteDataSource: routes}, RetryDecorator(fastRetry))
+
+		if _, err := api.GetRoute(context.Background(), "Red"); err == nil {
+			t.Fatal("Expected the error to be returned")
+		}
+		if routes.calls["Red"] != 1 {
+			t.Errorf("Expected 1 call, got %d", routes.calls["Red"])
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// departureLateness is how long after its scheduled time a trip is still
+// looked for, so late trips predicted to leave within the window are matched
+// to their schedules
+const departureLateness = 30 * time.Minute
+
+// DepartureDataSource provides what a departure board needs
+type DepartureDataSource interface {
+	ScheduleDataSource
+	PredictionDataSource
+	TripDataSource
+}
+
+// Departures returns the departures from a stop between from and from+window,
+// soonest first. A parent station includes its child platforms. Real-time
+// predictions replace scheduled times where they exist, and cancelled or
+// skipped trips are kept with their status so riders know not to wait for
+// them. Extra filters such as filter[route] or filter[direction_id] narrow
+// both the schedule and prediction queries.
+func Departures(ctx context.Context, source DepartureDataSource, stopID string, from time.Time, window time.Duration, filters map[string]string) ([]models.Departure, error) {
+	end := from.Add(window)
+
+	// Include trips scheduled a little earlier that may be running late
+	earliest := from.Add(-departureLateness)
+
+	scheduleParams := map[string]string{
+		"filter[stop]": stopID,
+		"include":      "trip,route,stop",
+		"sort":         "departure_time",
+	}
+	predictionParams := map[string]string{
+		"filter[stop]": stopID,
+	}
+	for key, value := range filters {
+		scheduleParams[key] = value
+		predictionParams[key] = value
+	}
+
+	schedules, included, err := getSchedulesBetween(ctx, source, scheduleParams, earliest, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedules for %s: %w", stopID, err)
+	}
+	predictions, err := source.GetPredictions(ctx, predictionParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get predictions for %s: %w", stopID, err)
+	}
+
+	index := models.NewIncludedIndex(included)
+
+	// Predictions match schedules by trip and stop sequence
+	predicted := make(map[string]*models.Prediction, len(predictions))
+	for i := range predictions {
+		prediction := &predictions[i]
+		predicted[departureKey(prediction.GetTripID(), prediction.Attributes.StopSequence)] = prediction
+	}
+
+	departures := make([]models.Departure, 0, len(schedules)+len(predictions))
+	for _, schedule := range schedules {
+		key := departureKey(schedule.GetTripID(), schedule.Attributes.StopSequence)
+		prediction := predicted[key]
+		delete(predicted, key)
+
+		// Riders can't board at the end of a trip or where pickup isn't offered
+		if schedule.Attributes.DepartureTime == "" || !schedule.IsPickupAvailable() {
+			continue
+		}
+		scheduledTime, err := time.Parse(time.RFC3339, schedule.Attributes.DepartureTime)
+		if err != nil {
+			continue
+		}
+
+		departure := models.Departure{
+			RouteID:       schedule.GetRouteID(),
+			TripID:        schedule.GetTripID(),
+			StopID:        schedule.GetStopID(),
+			Headsign:      schedule.Attributes.StopHeadsign,
+			ScheduledTime: &scheduledTime,
+			Status:        models.DepartureStatusScheduled,
+		}
+		if trip, ok := index.Trip(departure.TripID); ok {
+			departure.DirectionID = trip.Attributes.Direction
+			if departure.Headsign == "" {
+				departure.Headsign = trip.Attributes.Headsign
+			}
+		}
+		if stop, ok := index.Stop(departure.StopID); ok {
+			departure.Track = stop.Attributes.PlatformCode
+		}
+		if prediction != nil {
+			applyPrediction(&departure, prediction)
+		}
+
+		departures = append(departures, departure)
+	}
+
+	// The rest are added trips, or trips whose schedules fell outside the query
+	for _, prediction := range predictions {
+		if predicted[departureKey(prediction.GetTripID(), prediction.Attributes.StopSequence)] == nil {
+			continue
+		}
+		if prediction.Attributes.DepartureTime == nil {
+			continue
+		}
+
+		departure := models.Departure{
+			RouteID:     prediction.GetRouteID(),
+			TripID:      prediction.GetTripID(),
+			StopID:      prediction.GetStopID(),
+			DirectionID: prediction.Attributes.Direction,
+			Status:      models.DepartureStatusPredicted,
+		}
+		applyPrediction(&departure, &prediction)
+		if departure.PredictedTime == nil {
+			continue
+		}
+
+		departures = append(departures, departure)
+	}
+
+	// Keep what falls in the window
+	inWindow := departures[:0]
+	for _, departure := range departures {
+		t := departure.Time()
+		if !t.Before(from) && !t.After(end) {
+			inWindow = append(inWindow, departure)
+		}
+	}
+	departures = inWindow
+
+	if err := fillDepartureHeadsigns(ctx, source, departures); err != nil {
+		return nil, err
+	}
+
+	// Name the routes the schedules told us about
+	for i := range departures {
+		if route, ok := index.Route(departures[i].RouteID); ok {
+			departures[i].RouteName = route.Attributes.LongName
+			if departures[i].RouteName == "" {
+				departures[i].RouteName = route.Attributes.ShortName
+			}
+		}
+	}
+
+	sort.SliceStable(departures, func(i, j int) bool {
+		return departures[i].Time().Before(departures[j].Time())
+	})
+
+	return departures, nil
+}
+
+// applyPrediction updates a departure with a real-time prediction
+func applyPrediction(departure *models.Departure, prediction *models.Prediction) {
+	departure.VehicleID = prediction.GetVehicleID()
+	if prediction.Attributes.Track != nil && *prediction.Attributes.Track != "" {
+		departure.Track = *prediction.Attributes.Track
+	}
+	if prediction.Attributes.Status != nil {
+		departure.StatusText = *prediction.Attributes.Status
+	}
+
+	switch prediction.Attributes.Schedule {
+	case models.ScheduleRelationshipCancelled:
+		departure.Status = models.DepartureStatusCancelled
+		return
+	case models.ScheduleRelationshipSkipped:
+		departure.Status = models.DepartureStatusSkipped
+		return
+	case models.ScheduleRelationshipNoData:
+		return
+	case models.ScheduleRelationshipAdded, models.ScheduleRelationshipUnscheduled:
+		departure.Status = models.DepartureStatusAdded
+	default:
+		departure.Status = models.DepartureStatusPredicted
+	}
+
+	if departureTime, err := prediction.GetDepartureTime(); err == nil && departureTime != nil {
+		departure.PredictedTime = departureTime
+	} else if departure.Status == models.DepartureStatusPredicted && departure.ScheduledTime != nil {
+		// Without a predicted departure, fall back to the scheduled time
+		departure.Status = models.DepartureStatusScheduled
+	}
+}
+
+// fillDepartureHeadsigns looks up the headsigns of departures whose trips
+// weren't returned with the schedules
+func fillDepartureHeadsigns(ctx context.Context, source TripDataSource, departures []models.Departure) error {
+	var tripIDs []string
+	for _, departure := range departures {
+		if departure.Headsign == "" {
+			tripIDs = append(tripIDs, departure.TripID)
+		}
+	}
+	if len(tripIDs) == 0 {
+		return nil
+	}
+
+	trips, err := source.GetTrips(ctx, map[string]string{"filter[id]": strings.Join(tripIDs, ",")})
+	if err != nil {
+		return fmt.Errorf("failed to get trips for departures: %w", err)
+	}
+
+	headsigns := make(map[string]string, len(trips))
+	for _, trip := range trips {
+		headsigns[trip.ID] = trip.Attributes.Headsign
+	}
+	for i := range departures {
+		if departures[i].Headsign == "" {
+			departures[i].Headsign = headsigns[departures[i].TripID]
+		}
+	}
+	return nil
+}
+
+// departureKey identifies a trip's visit to a stop
+func departureKey(tripID string, stopSequence int) string {
+	return tripID + "/" + strconv.Itoa(stopSequence)
+}
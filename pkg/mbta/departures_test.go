@@ -0,0 +1,228 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// departureTestSource answers departure board queries from fixed data
+type departureTestSource struct {
+	CompositeAPI
+	schedules   []models.Schedule
+	included    []models.Included
+	predictions []models.Prediction
+	trips       []models.Trip
+
+	scheduleParams   map[string]string
+	scheduleRequests []map[string]string
+	predictionParams map[string]string
+	tripParams       map[string]string
+}
+
+func (s *departureTestSource) GetSchedules(ctx context.Context, params map[string]string) ([]models.Schedule, []models.Included, error) {
+	s.scheduleParams = params
+	s.scheduleRequests = append(s.scheduleRequests, params)
+	return s.schedules, s.included, nil
+}
+
+func (s *departureTestSource) GetPredictions(ctx context.Context, params map[string]string) ([]models.Prediction, error) {
+	s.predictionParams = params
+	return s.predictions, nil
+}
+
+func (s *departureTestSource) GetTrips(ctx context.Context, params map[string]string) ([]models.Trip, error) {
+	s.tripParams = params
+	return s.trips, nil
+}
+
+// departureSchedule builds a schedule at a Park Street platform
+func departureSchedule(tripID, stopID string, sequence int, departure string) models.Schedule {
+	schedule := models.Schedule{
+		ID:   "schedule-" + tripID,
+		Type: "schedule",
+		Attributes: models.ScheduleAttributes{
+			StopSequence: sequence,
+		},
+		Relationships: map[string]interface{}{
+			"trip": relationship("trip", tripID),
+			"stop": relationship("stop", stopID),
+		},
+	}
+	if departure != "" {
+		schedule.Attributes.DepartureTime = "2025-05-20T" + departure + ":00-04:00"
+	}
+	if tripID[0] == 'g' {
+		schedule.Relationships["route"] = relationship("route", "Green-C")
+	} else {
+		schedule.Relationships["route"] = relationship("route", "Red")
+	}
+	return schedule
+}
+
+// departurePrediction builds a prediction at a Park Street platform
+func departurePrediction(tripID, stopID string, sequence int, departure, scheduleRelationship string) models.Prediction {
+	prediction := models.Prediction{
+		ID:   "prediction-" + tripID,
+		Type: "prediction",
+		Attributes: models.PredictionAttributes{
+			StopSequence: sequence,
+			Schedule:     scheduleRelationship,
+		},
+		Relationships: map[string]interface{}{
+			"route":   relationship("route", "Red"),
+			"trip":    relationship("trip", tripID),
+			"stop":    relationship("stop", stopID),
+			"vehicle": relationship("vehicle", "v-"+tripID),
+		},
+	}
+	if departure != "" {
+		departureTime := "2025-05-20T" + departure + ":00-04:00"
+		prediction.Attributes.DepartureTime = &departureTime
+	}
+	return prediction
+}
+
+func TestDepartures(t *testing.T) {
+	track := "2"
+	added := departurePrediction("added-1", "70076", 1, "08:20", models.ScheduleRelationshipAdded)
+	added.Attributes.Track = &track
+	added.Attributes.Direction = 1
+
+	source := &departureTestSource{
+		schedules: []models.Schedule{
+			departureSchedule("red-early", "70075", 10, "07:40"),
+			departureSchedule("red-late", "70075", 10, "07:50"),
+			departureSchedule("red-1", "70075", 10, "08:05"),
+			departureSchedule("green-1", "70200", 5, "08:10"),
+			departureSchedule("red-2", "70075", 10, "08:12"),
+			departureSchedule("red-end", "70075", 22, ""),
+			departureSchedule("green-2", "70200", 5, "08:45"),
+		},
+		included: []models.Included{
+			{ID: "red-late", Type: "trip", Attributes: map[string]interface{}{"headsign": "Ashmont", "direction_id": 0}},
+			{ID: "red-1", Type: "trip", Attributes: map[string]interface{}{"headsign": "Braintree", "direction_id": 0}},
+			{ID: "red-2", Type: "trip", Attributes: map[string]interface{}{"headsign": "Ashmont", "direction_id": 0}},
+			{ID: "green-1", Type: "trip", Attributes: map[string]interface{}{"headsign": "Cleveland Circle", "direction_id": 0}},
+			{ID: "Red", Type: "route", Attributes: map[string]interface{}{"long_name": "Red Line"}},
+			{ID: "Green-C", Type: "route", Attributes: map[string]interface{}{"long_name": "Green Line C"}},
+			{ID: "70200", Type: "stop", Attributes: map[string]interface{}{"platform_code": "C"}},
+		},
+		predictions: []models.Prediction{
+			departurePrediction("red-late", "70075", 10, "08:02", ""),
+			departurePrediction("red-1", "70075", 10, "08:08", ""),
+			departurePrediction("red-2", "70075", 10, "", models.ScheduleRelationshipCancelled),
+			departurePrediction("arriving", "70076", 30, "", ""),
+			added,
+		},
+		trips: []models.Trip{{ID: "added-1", Attributes: models.TripAttributes{Headsign: "Alewife"}}},
+	}
+
+	from := time.Date(2025, 5, 20, 8, 0, 0, 0, time.FixedZone("EDT", -4*60*60))
+	departures, err := Departures(context.Background(), source, "place-pktrm", from, 30*time.Minute, map[string]string{
+		"filter[route]": "Red,Green-C",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both queries cover the station, with late trips' schedules included
+	for key, expected := range map[string]string{
+		"filter[stop]":     "place-pktrm",
+		"filter[date]":     "2025-05-20",
+		"filter[min_time]": "07:30",
+		"filter[max_time]": "08:30",
+		"filter[route]":    "Red,Green-C",
+	} {
+		if source.scheduleParams[key] != expected {
+			t.Errorf("Expected schedule %s %s, got %s", key, expected, source.scheduleParams[key])
+		}
+	}
+	if source.predictionParams["filter[stop]"] != "place-pktrm" || source.predictionParams["filter[route]"] != "Red,Green-C" {
+		t.Errorf("Expected predictions for place-pktrm on the filtered routes, got %v", source.predictionParams)
+	}
+	if source.tripParams["filter[id]"] != "added-1" {
+		t.Errorf("Expected a headsign lookup for added-1 only, got %v", source.tripParams)
+	}
+
+	type summary struct {
+		trip     string
+		status   models.DepartureStatus
+		minutes  int
+		delay    int
+		headsign string
+		track    string
+	}
+	expected := []summary{
+		{"red-late", models.DepartureStatusPredicted, 2, 12, "Ashmont", ""},
+		{"red-1", models.DepartureStatusPredicted, 8, 3, "Braintree", ""},
+		{"green-1", models.DepartureStatusScheduled, 10, 0, "Cleveland Circle", "C"},
+		{"red-2", models.DepartureStatusCancelled, 12, 0, "Ashmont", ""},
+		{"added-1", models.DepartureStatusAdded, 20, 0, "Alewife", "2"},
+	}
+
+	got := make([]summary, len(departures))
+	for i, departure := range departures {
+		got[i] = summary{departure.TripID, departure.Status, departure.MinutesUntil(from), departure.DelayMinutes(), departure.Headsign, departure.Track}
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected departures\n%v\ngot\n%v", expected, got)
+	}
+
+	if departures[2].RouteName != "Green Line C" || departures[0].RouteName != "Red Line" {
+		t.Errorf("Expected route names from the schedules, got %q and %q", departures[2].RouteName, departures[0].RouteName)
+	}
+	if departures[4].DirectionID != 1 || departures[4].VehicleID != "v-added-1" {
+		t.Errorf("Expected the added trip's direction and vehicle from its prediction, got %+v", departures[4])
+	}
+}
+
+func TestDeparturesServiceDay(t *testing.T) {
+	source := &departureTestSource{}
+
+	// Ten past midnight in Boston, asked from a host in UTC, is still the
+	// previous day's service
+	from := time.Date(2025, 5, 21, 4, 10, 0, 0, time.UTC)
+	if _, err := Departures(context.Background(), source, "place-pktrm", from, 30*time.Minute, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for key, expected := range map[string]string{
+		"filter[date]":     "2025-05-20",
+		"filter[min_time]": "23:40",
+		"filter[max_time]": "24:40",
+	} {
+		if source.scheduleParams[key] != expected {
+			t.Errorf("Expected schedule %s %s, got %s", key, expected, source.scheduleParams[key])
+		}
+	}
+}
+
+func TestDeparturesAcrossServiceDays(t *testing.T) {
+	source := &departureTestSource{}
+
+	// Ten to three, the last trips of one service day and the first of the
+	// next are both due
+	from := time.Date(2025, 5, 21, 2, 50, 0, 0, ServiceLocation())
+	if _, err := Departures(context.Background(), source, "place-pktrm", from, 30*time.Minute, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"2025-05-20 26:20-27:20", "2025-05-21 00:00-03:20"}
+	if len(source.scheduleRequests) != len(expected) {
+		t.Fatalf("Expected %d schedule requests, got %d", len(expected), len(source.scheduleRequests))
+	}
+	for i, params := range source.scheduleRequests {
+		got := fmt.Sprintf("%s %s-%s", params["filter[date]"], params["filter[min_time]"], params["filter[max_time]"])
+		if got != expected[i] {
+			t.Errorf("Expected schedule request %s, got %s", expected[i], got)
+		}
+		if params["filter[stop]"] != "place-pktrm" {
+			t.Errorf("Expected both requests filtered by stop, got %v", params)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package mbta
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// closingEffects are the alert effects that take service out of use, rather
+// than only slowing it down
+var closingEffects = map[models.AlertEffect]bool{
+	models.AlertEffectNoService:      true,
+	models.AlertEffectSuspension:     true,
+	models.AlertEffectShuttle:        true,
+	models.AlertEffectStationClosure: true,
+	models.AlertEffectStopClosed:     true,
+}
+
+// stationClosingEffects close stops to riders while trains still run
+// through them
+var stationClosingEffects = map[models.AlertEffect]bool{
+	models.AlertEffectStationClosure: true,
+	models.AlertEffectStopClosed:     true,
+}
+
+// DisruptionClosures returns the service closed by alerts active at the given
+// time, with the alert that closed it: suspended segments, stops replaced by
+// shuttle buses and closed stations. An alert naming a route without any of
+// its stops closes the whole route. Stops only close to riding through when
+// their entity includes the RIDE activity, such as between the ends of a
+// shuttle; otherwise, as at closed stations, riders just can't get on or off.
+// Entities naming a direction only close service in that direction.
+func DisruptionClosures(alerts []models.Alert, at time.Time) map[Closure]*models.Alert {
+	closures := make(map[Closure]*models.Alert)
+	for i := range alerts {
+		alert := &alerts[i]
+		if !closingEffects[alert.Attributes.Effect] || !alert.IsActive(at) {
+			continue
+		}
+
+		// Routes listed alongside their stops are only closed at those stops
+		routesWithStops := make(map[string]bool)
+		for _, entity := range alert.Attributes.InformedEntity {
+			if entity.Stop != "" {
+				routesWithStops[entity.Route] = true
+			}
+		}
+
+		for _, entity := range alert.Attributes.InformedEntity {
+			if entity.Trip != "" || (entity.Route == "" && entity.Stop == "") {
+				continue
+			}
+			if entity.Stop == "" && routesWithStops[entity.Route] {
+				continue
+			}
+			closure := Closure{RouteID: entity.Route, StopID: entity.Stop}
+			if entity.DirectionID != nil {
+				closure.DirectionID, closure.OneWay = *entity.DirectionID, true
+			}
+			if entity.Stop != "" {
+				closure.Ride = !stationClosingEffects[alert.Attributes.Effect] && entity.HasActivity(models.AlertActivityRide)
+			}
+			if _, ok := closures[closure]; !ok {
+				closures[closure] = alert
+			}
+		}
+	}
+	return closures
+}
+
+// AnnotateAlerts attaches to each leg of the plans the alerts that affect it
+// while it runs, and flags plans with a leg under a significant disruption
+func AnnotateAlerts(plans []models.TripPlan, alerts []models.Alert) {
+	for i := range plans {
+		plan := &plans[i]
+		for j := range plan.Legs {
+			leg := &plan.Legs[j]
+			if leg.IsWalk() {
+				continue
+			}
+			for _, alert := range alerts {
+				if !alert.IsActive(leg.DepartureTime) && !alert.IsActive(leg.ArrivalTime) {
+					continue
+				}
+				if alert.AffectsLeg(leg) {
+					leg.Alerts = append(leg.Alerts, alert)
+					plan.Disrupted = plan.Disrupted || alert.IsDisruption()
+				}
+			}
+		}
+	}
+}
+
+// Detours explains which closures the given plans run into, once for each
+// alert, listing the closed stops each plan would have used
+func Detours(plans []models.TripPlan, closures map[Closure]*models.Alert) []models.Detour {
+	var detours []models.Detour
+	byAlert := make(map[string]int)
+
+	for _, plan := range plans {
+		for _, leg := range plan.Legs {
+			if leg.IsWalk() {
+				continue
+			}
+			stops := make([]models.Stop, 0, len(leg.Stops)+2)
+			stops = append(stops, *leg.Origin)
+			stops = append(stops, leg.Stops...)
+			stops = append(stops, *leg.Destination)
+
+			for j, stop := range stops {
+				riding := j > 0 && j < len(stops)-1
+				alert, wholeRoute := closingAlert(closures, leg.RouteID, leg.DirectionID, &stop, riding)
+				if alert == nil {
+					continue
+				}
+
+				i, ok := byAlert[alert.ID]
+				if !ok {
+					i = len(detours)
+					byAlert[alert.ID] = i
+					detours = append(detours, models.Detour{Alert: alert, RouteID: leg.RouteID, RouteName: leg.RouteName})
+				}
+				if !wholeRoute && !containsStop(detours[i].Stops, stop.ID) {
+					detours[i].Stops = append(detours[i].Stops, stop)
+				}
+			}
+		}
+	}
+
+	for i := range detours {
+		detours[i].Reason = detourReason(&detours[i])
+	}
+	return detours
+}
+
+// closingAlert returns the alert closing a stop to a route in a direction, and
+// whether it closes the whole route. Riding through a stop is only closed by
+// closures that close it to riding.
+func closingAlert(closures map[Closure]*models.Alert, routeID string, directionID int, stop *models.Stop, riding bool) (*models.Alert, bool) {
+	for _, key := range (Closure{RouteID: routeID}).directions(directionID) {
+		if alert, ok := closures[key]; ok {
+			return alert, true
+		}
+	}
+	for _, stopID := range []string{stop.ID, stop.GetParentStationID()} {
+		if stopID == "" {
+			continue
+		}
+		for _, closure := range []Closure{{RouteID: routeID, StopID: stopID}, {StopID: stopID}} {
+			for _, key := range closure.directions(directionID) {
+				for _, ride := range []bool{true, false} {
+					key.Ride = ride
+					if alert, ok := closures[key]; ok && (ride || !riding) {
+						return alert, false
+					}
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// detourReason describes a detour, such as "Shuttle Bus Service on Red Line at
+// JFK/UMass and Savin Hill: Shuttle buses replace trains"
+func detourReason(detour *models.Detour) string {
+	route := detour.RouteName
+	if route == "" {
+		route = detour.RouteID
+	}
+
+	reason := fmt.Sprintf("%s on %s", models.GetAlertEffectDescription(detour.Alert.Attributes.Effect), route)
+	if len(detour.Stops) > 0 {
+		names := make([]string, len(detour.Stops))
+		for i, stop := range detour.Stops {
+			names[i] = stop.Attributes.Name
+		}
+		reason += " at " + joinNames(names)
+	}
+	if detour.Alert.Attributes.Header != "" {
+		reason += ": " + detour.Alert.Attributes.Header
+	}
+	return reason
+}
+
+// joinNames lists names in prose: "A", "A and B" or "A, B and C"
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// containsStop returns whether a stop is in a list
+func containsStop(stops []models.Stop, stopID string) bool {
+	for _, stop := range stops {
+		if stop.ID == stopID {
+			return true
+		}
+	}
+	return false
+}
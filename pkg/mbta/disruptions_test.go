@@ -0,0 +1,144 @@
+package mbta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// disruptionTestAlert builds an alert active around now
+func disruptionTestAlert(id string, effect models.AlertEffect, entities ...models.AlertEntity) models.Alert {
+	return models.Alert{
+		ID: id,
+		Attributes: models.AlertAttributes{
+			Effect:         effect,
+			ActivePeriod:   []models.AlertPeriod{{Start: time.Now().Add(-time.Hour)}},
+			InformedEntity: entities,
+		},
+	}
+}
+
+func TestDisruptionClosures(t *testing.T) {
+	inbound := 1
+	later := disruptionTestAlert("later", models.AlertEffectSuspension, models.AlertEntity{Route: "Orange"})
+	later.Attributes.ActivePeriod[0].Start = time.Now().Add(time.Hour)
+
+	alerts := []models.Alert{
+		disruptionTestAlert("shuttle", models.AlertEffectShuttle,
+			models.AlertEntity{Route: "Red"},
+			models.AlertEntity{Route: "Red", Stop: "place-jfk", Activities: []string{models.AlertActivityBoard, models.AlertActivityExit}},
+			models.AlertEntity{Route: "Red", Stop: "place-shmnl", Activities: []string{models.AlertActivityBoard, models.AlertActivityExit, models.AlertActivityRide}},
+		),
+		disruptionTestAlert("suspension", models.AlertEffectSuspension, models.AlertEntity{Route: "Mattapan"}),
+		disruptionTestAlert("inbound", models.AlertEffectSuspension, models.AlertEntity{Route: "Orange", DirectionID: &inbound}),
+		disruptionTestAlert("closure", models.AlertEffectStationClosure, models.AlertEntity{Stop: "place-boyls", Activities: []string{models.AlertActivityBoard, models.AlertActivityExit, models.AlertActivityRide}}),
+		disruptionTestAlert("delays", models.AlertEffectSignificantDelays, models.AlertEntity{Route: "Green-B"}),
+		disruptionTestAlert("trip", models.AlertEffectNoService, models.AlertEntity{Route: "CR-Fitchburg", Trip: "CR-123"}),
+		later,
+	}
+
+	closures := DisruptionClosures(alerts, time.Now())
+
+	// Trains run through the end of the shuttle and the closed station, but
+	// not the stops between
+	expected := map[Closure]string{
+		{RouteID: "Red", StopID: "place-jfk"}:               "shuttle",
+		{RouteID: "Red", StopID: "place-shmnl", Ride: true}: "shuttle",
+		{RouteID: "Mattapan"}:                               "suspension",
+		{RouteID: "Orange", DirectionID: 1, OneWay: true}:   "inbound",
+		{StopID: "place-boyls"}:                             "closure",
+	}
+	if len(closures) != len(expected) {
+		t.Errorf("Expected %d closures, got %v", len(expected), closures)
+	}
+	for closure, alertID := range expected {
+		if alert, ok := closures[closure]; !ok || alert.ID != alertID {
+			t.Errorf("Expected %+v to be closed by %s, got %v", closure, alertID, alert)
+		}
+	}
+}
+
+func TestDetours(t *testing.T) {
+	stop := func(id, name, parent string) models.Stop {
+		s := models.Stop{ID: id, Attributes: models.StopAttributes{Name: name}}
+		if parent != "" {
+			s.Relationships = map[string]interface{}{"parent_station": relationship("stop", parent)}
+		}
+		return s
+	}
+	alewife, jfk, savin, ashmont := stop("70061", "Alewife", ""), stop("70086", "JFK/UMass", "place-jfk"), stop("70088", "Savin Hill", "place-shmnl"), stop("70094", "Ashmont", "")
+
+	shuttle := disruptionTestAlert("shuttle", models.AlertEffectShuttle)
+	shuttle.Attributes.Header = "Shuttle buses replace Red Line trains"
+	closures := map[Closure]*models.Alert{
+		{RouteID: "Red", StopID: "place-jfk", Ride: true}:   &shuttle,
+		{RouteID: "Red", StopID: "place-shmnl", Ride: true}: &shuttle,
+	}
+
+	leg := models.TripLeg{Origin: &alewife, Destination: &ashmont, RouteID: "Red", RouteName: "Red Line", Stops: []models.Stop{jfk, savin}}
+	plans := []models.TripPlan{{Legs: []models.TripLeg{leg}}, {Legs: []models.TripLeg{leg}}}
+
+	detours := Detours(plans, closures)
+	if len(detours) != 1 {
+		t.Fatalf("Expected 1 detour, got %d", len(detours))
+	}
+	if len(detours[0].Stops) != 2 {
+		t.Errorf("Expected 2 closed stops, got %d", len(detours[0].Stops))
+	}
+	if expected := "Shuttle Bus Service on Red Line at JFK/UMass and Savin Hill: Shuttle buses replace Red Line trains"; detours[0].Reason != expected {
+		t.Errorf("Expected reason %q, got %q", expected, detours[0].Reason)
+	}
+
+	if detours := Detours(plans, map[Closure]*models.Alert{{RouteID: "Orange"}: &shuttle}); len(detours) != 0 {
+		t.Errorf("Expected no detours for another route, got %+v", detours)
+	}
+
+	// A closure in one direction only detours trips going that way
+	oneWay := map[Closure]*models.Alert{{RouteID: "Red", StopID: "place-jfk", DirectionID: 1, OneWay: true, Ride: true}: &shuttle}
+	if detours := Detours(plans, oneWay); len(detours) != 0 {
+		t.Errorf("Expected no detours for the other direction, got %+v", detours)
+	}
+	inbound := []models.TripPlan{{Legs: []models.TripLeg{leg}}}
+	inbound[0].Legs[0].DirectionID = 1
+	if detours := Detours(inbound, oneWay); len(detours) != 1 {
+		t.Errorf("Expected a detour in the closed direction, got %+v", detours)
+	}
+
+	// Riding through a closed station isn't a detour, but getting off there is
+	closed := disruptionTestAlert("closure", models.AlertEffectStationClosure)
+	if detours := Detours(plans, map[Closure]*models.Alert{{StopID: "place-jfk"}: &closed}); len(detours) != 0 {
+		t.Errorf("Expected no detour riding through a closed station, got %+v", detours)
+	}
+	toJFK := models.TripLeg{Origin: &alewife, Destination: &jfk, RouteID: "Red", RouteName: "Red Line"}
+	if detours := Detours([]models.TripPlan{{Legs: []models.TripLeg{toJFK}}}, map[Closure]*models.Alert{{StopID: "place-jfk"}: &closed}); len(detours) != 1 {
+		t.Errorf("Expected a detour getting off at a closed station, got %+v", detours)
+	}
+}
+
+func TestAnnotateAlerts(t *testing.T) {
+	now := time.Now()
+	stop := func(id string) *models.Stop { return &models.Stop{ID: id} }
+	plans := []models.TripPlan{
+		{Legs: []models.TripLeg{{Origin: stop("place-alfcl"), Destination: stop("place-sstat"), RouteID: "Red", DepartureTime: now, ArrivalTime: now.Add(30 * time.Minute)}}},
+		{Legs: []models.TripLeg{{Origin: stop("place-alfcl"), Destination: stop("place-sstat"), RouteID: "Orange", DepartureTime: now, ArrivalTime: now.Add(30 * time.Minute)}}},
+		{Legs: []models.TripLeg{{Origin: stop("place-alfcl"), Destination: stop("place-sstat"), RouteID: "Red", DepartureTime: now.Add(3 * time.Hour), ArrivalTime: now.Add(4 * time.Hour)}}},
+	}
+
+	delays := disruptionTestAlert("delays", models.AlertEffectSignificantDelays, models.AlertEntity{Route: "Red"})
+	delays.Attributes.ActivePeriod[0].End = now.Add(time.Hour)
+	AnnotateAlerts(plans, []models.Alert{
+		delays,
+		disruptionTestAlert("tracks", models.AlertEffectTrackChange, models.AlertEntity{Route: "Orange"}),
+	})
+
+	if alerts := plans[0].Legs[0].Alerts; !plans[0].Disrupted || len(alerts) != 1 || alerts[0].ID != "delays" {
+		t.Errorf("Expected the Red Line plan to be disrupted by delays, got %+v", alerts)
+	}
+	if alerts := plans[1].Legs[0].Alerts; plans[1].Disrupted || len(alerts) != 1 || alerts[0].ID != "tracks" {
+		t.Errorf("Expected the Orange Line plan to carry an undisruptive alert, got %+v", alerts)
+	}
+	if plans[2].Disrupted || len(plans[2].Legs[0].Alerts) != 0 {
+		t.Errorf("Expected a later plan to miss the delays, got %+v", plans[2].Legs[0].Alerts)
+	}
+}
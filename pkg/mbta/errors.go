@@ -2,9 +2,15 @@ package mbta
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
 )
 
 // APIError represents an error returned by the MBTA API
@@ -22,11 +28,21 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("MBTA API Error (%s): %s - %s", e.Status, e.Title, e.Detail)
 }
 
+// ErrNotFound matches errors for resources that don't exist, such as API
+// responses with status 404, with errors.Is
+var ErrNotFound = errors.New("not found")
+
 // IsNotFoundError checks if the error is a not found error
 func (e *APIError) IsNotFoundError() bool {
 	return e.StatusCode == http.StatusNotFound
 }
 
+// Is reports whether the error matches target, so that errors.Is finds
+// ErrNotFound in 404 responses
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.IsNotFoundError()
+}
+
 // IsAuthError checks if the error is an authentication error
 func (e *APIError) IsAuthError() bool {
 	return e.StatusCode == http.StatusUnauthorized
@@ -69,6 +85,40 @@ func (e *TimeoutError) Error() string {
 	return fmt.Sprintf("Request timed out after %v: %v", e.Timeout, e.Err)
 }
 
+// InaccessibleTripError reports that trips exist between two stops, but all
+// of them use stations riders who need step-free access can't use
+type InaccessibleTripError struct {
+	Origin      string
+	Destination string
+	Issues      []models.AccessibilityIssue
+}
+
+// Error implements the error interface for InaccessibleTripError
+func (e *InaccessibleTripError) Error() string {
+	reasons := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		reasons[i] = issue.Reason
+	}
+	return fmt.Sprintf("no accessible trip found between %s and %s: %s", e.Origin, e.Destination, strings.Join(reasons, "; "))
+}
+
+// DisruptedTripError reports that trips between two stops are only possible
+// on service that isn't running
+type DisruptedTripError struct {
+	Origin      string
+	Destination string
+	Detours     []models.Detour
+}
+
+// Error implements the error interface for DisruptedTripError
+func (e *DisruptedTripError) Error() string {
+	reasons := make([]string, len(e.Detours))
+	for i, detour := range e.Detours {
+		reasons[i] = detour.Reason
+	}
+	return fmt.Sprintf("no trip found between %s and %s that avoids service disruptions: %s", e.Origin, e.Destination, strings.Join(reasons, "; "))
+}
+
 // parseAPIError parses an error response from the MBTA API
 func parseAPIError(statusCode int, responseBody []byte) error {
 	var errorResponse struct {
@@ -116,7 +166,7 @@ func parseAPIError(statusCode int, responseBody []byte) error {
 
 	// Check for rate limit errors
 	if statusCode == http.StatusTooManyRequests {
-		// For now, default to 60 seconds if no retry-after header
+		// Default to 60 seconds when the response headers don't say otherwise
 		retryAfter := 60
 		return &RateLimitError{
 			APIError:   err,
@@ -126,3 +176,53 @@ func parseAPIError(statusCode int, responseBody []byte) error {
 
 	return err
 }
+
+// parseAPIErrorResponse parses an error response, using the Retry-After and
+// x-ratelimit-reset headers to determine how long a rate-limited client must wait
+func parseAPIErrorResponse(statusCode int, header http.Header, responseBody []byte) error {
+	err := parseAPIError(statusCode, responseBody)
+
+	// Rate limits are reported as such even when the body isn't a JSON:API error
+	if apiErr, ok := err.(*APIError); ok && apiErr.IsRateLimitError() {
+		err = &RateLimitError{APIError: apiErr, RetryAfter: 60}
+	}
+
+	if rateLimitErr, ok := err.(*RateLimitError); ok {
+		if retryAfter, ok := retryAfterFromHeaders(header, time.Now()); ok {
+			rateLimitErr.RetryAfter = retryAfter
+		}
+	}
+
+	return err
+}
+
+// retryAfterFromHeaders returns the number of seconds to wait before retrying,
+// read from a Retry-After header (seconds or HTTP date) or, failing that, the
+// Unix timestamp in x-ratelimit-reset
+func retryAfterFromHeaders(header http.Header, now time.Time) (int, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return seconds, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return secondsUntil(date, now), true
+		}
+	}
+
+	if value := header.Get("X-Ratelimit-Reset"); value != "" {
+		if reset, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return secondsUntil(time.Unix(reset, 0), now), true
+		}
+	}
+
+	return 0, false
+}
+
+// secondsUntil returns the whole seconds from now until t, rounded up and never negative
+func secondsUntil(t, now time.Time) int {
+	seconds := math.Ceil(t.Sub(now).Seconds())
+	if seconds < 0 {
+		return 0
+	}
+	return int(seconds)
+}
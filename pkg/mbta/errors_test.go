@@ -3,6 +3,7 @@ package mbta
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -189,3 +190,56 @@ func TestParseAPIError(t *testing.T) {
 		t.Errorf("Expected StatusCode %d, got %d", http.StatusBadRequest, apiErr.StatusCode)
 	}
 }
+
+func TestParseAPIErrorResponseRetryAfter(t *testing.T) {
+	body := []byte(`{"errors": [{"status": "429", "title": "Rate Limit Exceeded"}]}`)
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected int
+	}{
+		{"No headers", http.Header{}, 60},
+		{"Retry-After seconds", http.Header{"Retry-After": []string{"7"}}, 7},
+		{"Rate limit reset", http.Header{"X-Ratelimit-Reset": []string{strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)}}, 30},
+		{"Reset in the past", http.Header{"X-Ratelimit-Reset": []string{"1"}}, 0},
+		{"Unparseable headers", http.Header{"Retry-After": []string{"soon"}}, 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseAPIErrorResponse(http.StatusTooManyRequests, tt.header, body)
+
+			rateLimitErr, ok := err.(*RateLimitError)
+			if !ok {
+				t.Fatalf("Expected *RateLimitError, got %T", err)
+			}
+			// Allow a second of slack for timestamps computed from the clock
+			if rateLimitErr.RetryAfter < tt.expected-1 || rateLimitErr.RetryAfter > tt.expected {
+				t.Errorf("Expected RetryAfter %d, got %d", tt.expected, rateLimitErr.RetryAfter)
+			}
+		})
+	}
+
+	t.Run("Retry-After date", func(t *testing.T) {
+		now := time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC)
+		header := http.Header{"Retry-After": []string{now.Add(90 * time.Second).Format(http.TimeFormat)}}
+
+		seconds, ok := retryAfterFromHeaders(header, now)
+		if !ok || seconds != 90 {
+			t.Errorf("Expected 90 seconds, got %d (ok=%v)", seconds, ok)
+		}
+	})
+
+	t.Run("Plain text rate limit body", func(t *testing.T) {
+		err := parseAPIErrorResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"3"}}, []byte("Too Many Requests"))
+
+		rateLimitErr, ok := err.(*RateLimitError)
+		if !ok {
+			t.Fatalf("Expected *RateLimitError, got %T", err)
+		}
+		if rateLimitErr.RetryAfter != 3 {
+			t.Errorf("Expected RetryAfter 3, got %d", rateLimitErr.RetryAfter)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package mbta
+
+import (
+	"sort"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Walking defaults
+const (
+	DefaultWalkingSpeed    = 1.2 // Meters per second, allowing for crossings and stairs
+	DefaultMaxWalkDistance = 0.5 // Kilometers riders are asked to walk between stops
+)
+
+// walkingDetourFactor is how much longer a walk is than the straight line
+// between two stops, since streets rarely run directly between them
+const walkingDetourFactor = 1.3
+
+// Where a footpath comes from
+const (
+	FootpathSourceCoordinates = "coordinates" // Estimated from the distance between stops
+	FootpathSourceTransfer    = "transfer"    // A GTFS transfer between stops
+	FootpathSourcePathway     = "pathway"     // GTFS pathways through stations
+)
+
+// Footpath is a walk between two stops, such as between nearby stations or to
+// a bus stop across the street
+type Footpath struct {
+	FromStopID string
+	ToStopID   string
+	Distance   float64       // Kilometers; 0 if unknown
+	Duration   time.Duration // Time needed to walk it
+	Source     string        // FootpathSourceCoordinates, FootpathSourceTransfer or FootpathSourcePathway
+}
+
+// FootpathGraph holds the walks between stops that riders can use to change
+// between routes that don't share a stop. It isn't safe to modify once shared.
+type FootpathGraph struct {
+	paths map[string]map[string]Footpath // From stop ID -> to stop ID -> path
+	count int
+}
+
+// NewFootpathGraph creates an empty footpath graph
+func NewFootpathGraph() *FootpathGraph {
+	return &FootpathGraph{paths: make(map[string]map[string]Footpath)}
+}
+
+// WithFootpaths lets the trip planner and transfer searches use the given
+// footpaths, such as those from a GTFS feed's pathways and transfers, in
+// addition to those estimated from stop coordinates
+func WithFootpaths(graph *FootpathGraph) ClientOption {
+	return func(c *Client) {
+		c.footpaths = graph
+	}
+}
+
+// WalkingTime estimates how long it takes to walk between two points the
+// given distance in kilometers apart
+func WalkingTime(distanceKm float64) time.Duration {
+	seconds := distanceKm * 1000 * walkingDetourFactor / DefaultWalkingSpeed
+	return (time.Duration(seconds) * time.Second).Round(time.Second)
+}
+
+// EstimateFootpath estimates the walk between two stops from the distance
+// between them, or returns false if either stop's location is unknown
+func EstimateFootpath(from, to *models.Stop) (Footpath, bool) {
+	if !hasLocation(from) || !hasLocation(to) {
+		return Footpath{}, false
+	}
+
+	distance := calculateApproximateDistance(from.Attributes.Latitude, from.Attributes.Longitude, to.Attributes.Latitude, to.Attributes.Longitude)
+	return Footpath{
+		FromStopID: from.ID,
+		ToStopID:   to.ID,
+		Distance:   distance,
+		Duration:   WalkingTime(distance),
+		Source:     FootpathSourceCoordinates,
+	}, true
+}
+
+// Add adds a footpath, keeping whichever is quicker if the stops are
+// already connected
+func (g *FootpathGraph) Add(path Footpath) {
+	if path.FromStopID == "" || path.ToStopID == "" || path.FromStopID == path.ToStopID {
+		return
+	}
+
+	to, ok := g.paths[path.FromStopID]
+	if !ok {
+		to = make(map[string]Footpath)
+		g.paths[path.FromStopID] = to
+	}
+	existing, ok := to[path.ToStopID]
+	if ok && existing.Duration <= path.Duration {
+		return
+	}
+	if !ok {
+		g.count++
+	}
+	to[path.ToStopID] = path
+}
+
+// AddNearbyStops connects each pair of stops with coordinates within
+// maxDistanceKm of each other in both directions, estimating the walk from
+// the distance between them. Stops of the same station, and stops already
+// connected, are left alone.
+func (g *FootpathGraph) AddNearbyStops(stops []models.Stop, maxDistanceKm float64) {
+	located := make([]models.Stop, 0, len(stops))
+	for _, stop := range stops {
+		if hasLocation(&stop) {
+			located = append(located, stop)
+		}
+	}
+
+	index := NewStopIndex()
+	index.Build(located, nil)
+
+	for _, stop := range located {
+		station := stationID(&stop)
+		for _, nearby := range index.Within(stop.Attributes.Latitude, stop.Attributes.Longitude, maxDistanceKm, StopFilter{}) {
+			if nearby.Stop.ID == stop.ID || stationID(&nearby.Stop) == station {
+				continue
+			}
+			if _, ok := g.Between(stop.ID, nearby.Stop.ID); ok {
+				continue
+			}
+			g.Add(Footpath{
+				FromStopID: stop.ID,
+				ToStopID:   nearby.Stop.ID,
+				Distance:   nearby.DistanceKm,
+				Duration:   WalkingTime(nearby.DistanceKm),
+				Source:     FootpathSourceCoordinates,
+			})
+		}
+	}
+}
+
+// Between returns the footpath from one stop to another
+func (g *FootpathGraph) Between(fromStopID, toStopID string) (Footpath, bool) {
+	path, ok := g.paths[fromStopID][toStopID]
+	return path, ok
+}
+
+// From returns the footpaths leaving a stop, quickest first
+func (g *FootpathGraph) From(stopID string) []Footpath {
+	paths := make([]Footpath, 0, len(g.paths[stopID]))
+	for _, path := range g.paths[stopID] {
+		paths = append(paths, path)
+	}
+	sortFootpaths(paths)
+	return paths
+}
+
+// All returns every footpath in the graph, ordered by origin, then quickest first
+func (g *FootpathGraph) All() []Footpath {
+	paths := make([]Footpath, 0, g.count)
+	for _, to := range g.paths {
+		for _, path := range to {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].FromStopID != paths[j].FromStopID {
+			return paths[i].FromStopID < paths[j].FromStopID
+		}
+		return footpathLess(paths[i], paths[j])
+	})
+	return paths
+}
+
+// Len returns the number of footpaths in the graph
+func (g *FootpathGraph) Len() int {
+	return g.count
+}
+
+// sortFootpaths orders footpaths quickest first
+func sortFootpaths(paths []Footpath) {
+	sort.Slice(paths, func(i, j int) bool { return footpathLess(paths[i], paths[j]) })
+}
+
+// footpathLess orders footpaths by duration, then destination for stability
+func footpathLess(a, b Footpath) bool {
+	if a.Duration != b.Duration {
+		return a.Duration < b.Duration
+	}
+	return a.ToStopID < b.ToStopID
+}
+
+// stationID returns the station a stop belongs to, or the stop itself
+func stationID(stop *models.Stop) string {
+	if parent := stop.GetParentStationID(); parent != "" {
+		return parent
+	}
+	return stop.ID
+}
+
+// hasLocation returns whether a stop's coordinates are known
+func hasLocation(stop *models.Stop) bool {
+	return stop.Attributes.Latitude != 0 || stop.Attributes.Longitude != 0
+}
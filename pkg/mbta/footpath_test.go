@@ -0,0 +1,111 @@
+package mbta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// footpathTestStop builds a stop at a location, optionally within a station
+func footpathTestStop(id, parent string, lat, lon float64) models.Stop {
+	stop := models.Stop{
+		ID:         id,
+		Type:       "stop",
+		Attributes: models.StopAttributes{Name: id, Latitude: lat, Longitude: lon},
+	}
+	if parent != "" {
+		stop.Relationships = map[string]interface{}{"parent_station": relationship("stop", parent)}
+	}
+	return stop
+}
+
+func TestWalkingTime(t *testing.T) {
+	// 300 meters, 30% longer along the streets, at 1.2 meters per second
+	if got := WalkingTime(0.3); got != 325*time.Second {
+		t.Errorf("Expected 5m25s, got %s", got)
+	}
+	if got := WalkingTime(0); got != 0 {
+		t.Errorf("Expected no time for no distance, got %s", got)
+	}
+}
+
+func TestFootpathGraphAdd(t *testing.T) {
+	graph := NewFootpathGraph()
+	graph.Add(Footpath{FromStopID: "a", ToStopID: "b", Duration: 5 * time.Minute})
+	graph.Add(Footpath{FromStopID: "a", ToStopID: "b", Duration: 3 * time.Minute})
+	graph.Add(Footpath{FromStopID: "a", ToStopID: "b", Duration: 4 * time.Minute})
+	graph.Add(Footpath{FromStopID: "a", ToStopID: "c", Duration: 2 * time.Minute})
+	graph.Add(Footpath{FromStopID: "a", ToStopID: "a", Duration: time.Minute})
+
+	if graph.Len() != 2 {
+		t.Errorf("Expected 2 footpaths, got %d", graph.Len())
+	}
+	if path, ok := graph.Between("a", "b"); !ok || path.Duration != 3*time.Minute {
+		t.Errorf("Expected the quickest footpath to be kept, got %+v", path)
+	}
+	if _, ok := graph.Between("b", "a"); ok {
+		t.Error("Expected footpaths to be one way")
+	}
+
+	from := graph.From("a")
+	if len(from) != 2 || from[0].ToStopID != "c" || from[1].ToStopID != "b" {
+		t.Errorf("Expected footpaths to c then b, got %+v", from)
+	}
+}
+
+func TestFootpathGraphAddNearbyStops(t *testing.T) {
+	stops := []models.Stop{
+		footpathTestStop("70076", "place-pktrm", 42.3564, -71.0624),
+		footpathTestStop("70196", "place-pktrm", 42.3563, -71.0623),
+		footpathTestStop("70077", "place-dwnxg", 42.3555, -71.0602),
+		footpathTestStop("70061", "place-alfcl", 42.3962, -71.1400),
+		footpathTestStop("unknown", "", 0, 0),
+	}
+
+	graph := NewFootpathGraph()
+	explicit := Footpath{FromStopID: "70076", ToStopID: "70077", Duration: 6 * time.Minute, Source: FootpathSourcePathway}
+	graph.Add(explicit)
+	graph.AddNearbyStops(stops, DefaultMaxWalkDistance)
+
+	// Park Street's platforms each reach Downtown Crossing and back
+	if graph.Len() != 4 {
+		t.Errorf("Expected 4 footpaths, got %d: %+v", graph.Len(), graph.All())
+	}
+	if path, ok := graph.Between("70076", "70077"); !ok || path != explicit {
+		t.Errorf("Expected the existing footpath to be kept, got %+v", path)
+	}
+
+	path, ok := graph.Between("70077", "70196")
+	if !ok {
+		t.Fatal("Expected a footpath from Downtown Crossing to Park Street")
+	}
+	if path.Source != FootpathSourceCoordinates || path.Distance < 0.15 || path.Distance > 0.25 {
+		t.Errorf("Expected an estimate of about 0.2 km, got %+v", path)
+	}
+	if path.Duration != WalkingTime(path.Distance) {
+		t.Errorf("Expected %s to walk %.3f km, got %s", WalkingTime(path.Distance), path.Distance, path.Duration)
+	}
+
+	if _, ok := graph.Between("70076", "70196"); ok {
+		t.Error("Expected no footpath between platforms of the same station")
+	}
+	if len(graph.From("70061")) != 0 {
+		t.Error("Expected no footpaths from a distant stop")
+	}
+}
+
+func TestEstimateFootpath(t *testing.T) {
+	from := footpathTestStop("70077", "", 42.3555, -71.0602)
+	to := footpathTestStop("70076", "", 42.3564, -71.0624)
+
+	path, ok := EstimateFootpath(&from, &to)
+	if !ok || path.FromStopID != "70077" || path.ToStopID != "70076" || path.Duration != WalkingTime(path.Distance) {
+		t.Errorf("Unexpected estimate %+v", path)
+	}
+
+	unknown := footpathTestStop("unknown", "", 0, 0)
+	if _, ok := EstimateFootpath(&from, &unknown); ok {
+		t.Error("Expected no estimate for a stop without a location")
+	}
+}
@@ -0,0 +1,167 @@
+package mbta
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// minPlaceMatchScore is the lowest score a gazetteer place can have and
+// still be a match. It's stricter than for stops, since an address that
+// isn't in the gazetteer shouldn't resolve to a place with a similar name.
+const minPlaceMatchScore = 0.75
+
+// Location is a point riders start or end a trip at that isn't a stop, such
+// as an address
+type Location struct {
+	Name      string  `json:"name,omitempty"` // Address or place name, if known
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder finds where a free-form address or place name is
+type Geocoder interface {
+	Geocode(ctx context.Context, query string) (*Location, error)
+}
+
+// Place is a named location in a Gazetteer
+type Place struct {
+	Location
+	Aliases []string // Other names riders use, such as "MFA"
+}
+
+// BostonPlaces are well-known places in and around Boston that riders ask
+// for by name
+var BostonPlaces = []Place{
+	{Location: Location{Name: "Boston Common", Latitude: 42.3550, Longitude: -71.0656}},
+	{Location: Location{Name: "Massachusetts State House", Latitude: 42.3588, Longitude: -71.0638}, Aliases: []string{"State House"}},
+	{Location: Location{Name: "Faneuil Hall", Latitude: 42.3600, Longitude: -71.0568}, Aliases: []string{"Quincy Market"}},
+	{Location: Location{Name: "New England Aquarium", Latitude: 42.3591, Longitude: -71.0498}},
+	{Location: Location{Name: "TD Garden", Latitude: 42.3662, Longitude: -71.0621}},
+	{Location: Location{Name: "Museum of Science", Latitude: 42.3677, Longitude: -71.0709}},
+	{Location: Location{Name: "Bunker Hill Monument", Latitude: 42.3763, Longitude: -71.0608}},
+	{Location: Location{Name: "Boston Public Library", Latitude: 42.3493, Longitude: -71.0782}},
+	{Location: Location{Name: "Prudential Center", Latitude: 42.3471, Longitude: -71.0825}, Aliases: []string{"Pru"}},
+	{Location: Location{Name: "Fenway Park", Latitude: 42.3467, Longitude: -71.0972}},
+	{Location: Location{Name: "Museum of Fine Arts", Latitude: 42.3394, Longitude: -71.0940}, Aliases: []string{"MFA"}},
+	{Location: Location{Name: "Isabella Stewart Gardner Museum", Latitude: 42.3382, Longitude: -71.0991}, Aliases: []string{"Gardner Museum"}},
+	{Location: Location{Name: "Northeastern University", Latitude: 42.3398, Longitude: -71.0892}},
+	{Location: Location{Name: "Longwood Medical Area", Latitude: 42.3376, Longitude: -71.1057}},
+	{Location: Location{Name: "Boston Convention and Exhibition Center", Latitude: 42.3458, Longitude: -71.0446}, Aliases: []string{"BCEC"}},
+	{Location: Location{Name: "Institute of Contemporary Art", Latitude: 42.3528, Longitude: -71.0430}, Aliases: []string{"ICA"}},
+	{Location: Location{Name: "Massachusetts Institute of Technology", Latitude: 42.3601, Longitude: -71.0942}, Aliases: []string{"MIT"}},
+	{Location: Location{Name: "Harvard Yard", Latitude: 42.3745, Longitude: -71.1166}, Aliases: []string{"Harvard University"}},
+	{Location: Location{Name: "Logan International Airport", Latitude: 42.3656, Longitude: -71.0096}},
+}
+
+// Gazetteer is an offline Geocoder that looks places up by name in a fixed
+// list, tolerating typos and abbreviations the way stop searches do. It
+// knows nothing of street addresses that aren't in the list.
+type Gazetteer struct {
+	places []Place
+}
+
+// NewGazetteer creates a gazetteer of the given places
+func NewGazetteer(places []Place) *Gazetteer {
+	return &Gazetteer{places: append([]Place(nil), places...)}
+}
+
+// DefaultGazetteer creates a gazetteer of BostonPlaces
+func DefaultGazetteer() *Gazetteer {
+	return NewGazetteer(BostonPlaces)
+}
+
+// LoadGazetteer reads places from CSV with a header row and name, latitude,
+// longitude and optional aliases columns, aliases separated by semicolons
+func LoadGazetteer(r io.Reader) ([]Place, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading gazetteer header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("gazetteer is missing the %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var places []Place
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return places, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading gazetteer: %w", err)
+		}
+
+		place := Place{Location: Location{Name: field(record, "name")}}
+		if place.Name == "" {
+			return nil, fmt.Errorf("gazetteer line %d has no name", line)
+		}
+		if place.Latitude, err = strconv.ParseFloat(field(record, "latitude"), 64); err != nil {
+			return nil, fmt.Errorf("gazetteer line %d has an invalid latitude: %w", line, err)
+		}
+		if place.Longitude, err = strconv.ParseFloat(field(record, "longitude"), 64); err != nil {
+			return nil, fmt.Errorf("gazetteer line %d has an invalid longitude: %w", line, err)
+		}
+		for _, alias := range strings.Split(field(record, "aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				place.Aliases = append(place.Aliases, alias)
+			}
+		}
+		places = append(places, place)
+	}
+}
+
+// Add adds places to the gazetteer
+func (g *Gazetteer) Add(places ...Place) {
+	g.places = append(g.places, places...)
+}
+
+// Len returns the number of places in the gazetteer
+func (g *Gazetteer) Len() int {
+	return len(g.places)
+}
+
+// Geocode returns the place whose name or alias best matches the query
+func (g *Gazetteer) Geocode(ctx context.Context, query string) (*Location, error) {
+	normalizedQuery := normalizeStopText(query)
+	if normalizedQuery == "" {
+		return nil, fmt.Errorf("empty address")
+	}
+
+	var best *Place
+	var bestScore float64
+	for i := range g.places {
+		place := &g.places[i]
+		for _, name := range append([]string{place.Name}, place.Aliases...) {
+			if score := stopTextScore(normalizedQuery, normalizeStopText(name)); score > bestScore {
+				best, bestScore = place, score
+			}
+		}
+	}
+
+	if best == nil || bestScore < minPlaceMatchScore {
+		return nil, fmt.Errorf("no place found matching %q", query)
+	}
+	location := best.Location
+	return &location, nil
+}
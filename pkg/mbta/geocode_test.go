@@ -0,0 +1,72 @@
+package mbta
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGazetteerGeocode(t *testing.T) {
+	gazetteer := DefaultGazetteer()
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"Fenway Park", "Fenway Park"},
+		{"fenway", "Fenway Park"},
+		{"Fenwy Park", "Fenway Park"},
+		{"MFA", "Museum of Fine Arts"},
+		{"state house", "Massachusetts State House"},
+		{"Boston Convention & Exhibition Center", "Boston Convention and Exhibition Center"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			location, err := gazetteer.Geocode(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Geocode returned an error: %v", err)
+			}
+			if location.Name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, location.Name)
+			}
+		})
+	}
+
+	for _, query := range []string{"", "123 Nowhere Road, Springfield"} {
+		if location, err := gazetteer.Geocode(context.Background(), query); err == nil {
+			t.Errorf("Expected no place for %q, got %+v", query, location)
+		}
+	}
+}
+
+func TestLoadGazetteer(t *testing.T) {
+	places, err := LoadGazetteer(strings.NewReader(`name,latitude,longitude,aliases
+1 Ashburton Place,42.3586,-71.0622,McCormack Building; One Ashburton
+Home,42.3,-71.1,
+`))
+	if err != nil {
+		t.Fatalf("LoadGazetteer returned an error: %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("Expected 2 places, got %d", len(places))
+	}
+	if places[0].Name != "1 Ashburton Place" || places[0].Latitude != 42.3586 || len(places[0].Aliases) != 2 || places[0].Aliases[1] != "One Ashburton" {
+		t.Errorf("Unexpected place %+v", places[0])
+	}
+
+	gazetteer := NewGazetteer(places)
+	if location, err := gazetteer.Geocode(context.Background(), "mccormack building"); err != nil || location.Name != "1 Ashburton Place" {
+		t.Errorf("Expected to find the place by its alias, got %+v (%v)", location, err)
+	}
+
+	for _, input := range []string{
+		"name,latitude\nHome,42.3\n",
+		"name,latitude,longitude\nHome,north,-71.1\n",
+		"name,latitude,longitude\n,42.3,-71.1\n",
+	} {
+		if _, err := LoadGazetteer(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error loading %q", input)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package mbta
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MethodStats summarizes the calls made to one TransitAPI method
+type MethodStats struct {
+	Calls         int64
+	Errors        int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// AverageDuration returns the mean duration of the calls
+func (s MethodStats) AverageDuration() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Calls)
+}
+
+// Metrics counts TransitAPI calls, errors and latency per method. It is safe
+// for concurrent use.
+type Metrics struct {
+	mu      sync.Mutex
+	methods map[string]MethodStats
+}
+
+// NewMetrics creates an empty set of call metrics
+func NewMetrics() *Metrics {
+	return &Metrics{methods: make(map[string]MethodStats)}
+}
+
+// Decorator records every call passing through it
+func (m *Metrics) Decorator() Decorator {
+	return func(next CallHandler) CallHandler {
+		return func(ctx context.Context, call Call) (any, error) {
+			start := time.Now()
+			result, err := next(ctx, call)
+			m.record(call.Method, time.Since(start), err)
+			return result, err
+		}
+	}
+}
+
+// Snapshot returns the statistics for each method called so far
+func (m *Metrics) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(m.methods))
+	for method, stats := range m.methods {
+		snapshot[method] = stats
+	}
+	return snapshot
+}
+
+// record adds a call to a method's statistics
+func (m *Metrics) record(method string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.methods[method]
+	stats.Calls++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.TotalDuration += duration
+	stats.MaxDuration = max(stats.MaxDuration, duration)
+	m.methods[method] = stats
+}
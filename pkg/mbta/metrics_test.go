@@ -0,0 +1,45 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	routes := newFakeRoutes(errors.New("boom"))
+	api := Decorate(CompositeAPI{RouteDataSource: routes}, metrics.Decorator(), CacheDecorator(10, DefaultCallCacheTTLs))
+
+	for i := 0; i < 3; i++ {
+		_, _ = api.GetRoute(context.Background(), "Red")
+	}
+	_, _ = api.GetRoutes(context.Background())
+
+	snapshot := metrics.Snapshot()
+
+	// Calls answered by the cache are counted too
+	route := snapshot["GetRoute"]
+	if route.Calls != 3 || route.Errors != 1 {
+		t.Errorf("Expected 3 GetRoute calls with 1 error, got %+v", route)
+	}
+	if route.MaxDuration < route.AverageDuration() || route.TotalDuration < route.MaxDuration {
+		t.Errorf("Expected consistent durations, got %+v", route)
+	}
+	if snapshot["GetRoutes"].Calls != 1 {
+		t.Errorf("Expected 1 GetRoutes call, got %+v", snapshot["GetRoutes"])
+	}
+	if _, ok := snapshot["GetStop"]; ok {
+		t.Error("Expected no statistics for methods that weren't called")
+	}
+
+	// Snapshots don't change as calls continue
+	_, _ = api.GetRoutes(context.Background())
+	if snapshot["GetRoutes"].Calls != 1 {
+		t.Error("Expected the snapshot to be a copy")
+	}
+
+	if (MethodStats{}).AverageDuration() != 0 {
+		t.Error("Expected zero average duration without calls")
+	}
+}
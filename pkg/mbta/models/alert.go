@@ -67,6 +67,7 @@ const (
 	AlertEffectStopMoved          AlertEffect = "STOP_MOVED"
 	AlertEffectStopClosed         AlertEffect = "STOP_CLOSED"
 	AlertEffectShuttle            AlertEffect = "SHUTTLE"
+	AlertEffectSuspension         AlertEffect = "SUSPENSION"
 	AlertEffectElevatorOutage     AlertEffect = "ELEVATOR_OUTAGE"
 	AlertEffectAccessibilityIssue AlertEffect = "ACCESSIBILITY_ISSUE"
 	AlertEffectScheduleChange     AlertEffect = "SCHEDULE_CHANGE"
@@ -103,6 +104,25 @@ const (
 	AlertCauseOther              AlertCause = "OTHER_CAUSE"
 )
 
+// DisruptionEffects are the alert effects that represent significant service disruptions
+var DisruptionEffects = []AlertEffect{
+	AlertEffectNoService,
+	AlertEffectSuspension,
+	AlertEffectReducedService,
+	AlertEffectSignificantDelays,
+	AlertEffectDetour,
+	AlertEffectStationClosure,
+	AlertEffectStopClosed,
+	AlertEffectShuttle,
+}
+
+// Alert activity constants, for what riders are doing when an alert applies
+const (
+	AlertActivityBoard = "BOARD"
+	AlertActivityExit  = "EXIT"
+	AlertActivityRide  = "RIDE"
+)
+
 // GetAlertEffectDescription returns a human-readable description of an alert effect
 func GetAlertEffectDescription(effect AlertEffect) string {
 	switch effect {
@@ -122,6 +142,8 @@ func GetAlertEffectDescription(effect AlertEffect) string {
 		return "Stop Closed"
 	case AlertEffectShuttle:
 		return "Shuttle Bus Service"
+	case AlertEffectSuspension:
+		return "Suspension"
 	case AlertEffectElevatorOutage:
 		return "Elevator Outage"
 	case AlertEffectAccessibilityIssue:
@@ -207,6 +229,16 @@ func GetSeverityDescription(severity int) string {
 	}
 }
 
+// IsDisruption returns whether the alert significantly disrupts service
+func (a *Alert) IsDisruption() bool {
+	for _, effect := range DisruptionEffects {
+		if a.Attributes.Effect == effect {
+			return true
+		}
+	}
+	return false
+}
+
 // IsActive checks if the alert is currently active based on its active periods
 func (a *Alert) IsActive(currentTime time.Time) bool {
 	if len(a.Attributes.ActivePeriod) == 0 {
@@ -275,3 +307,84 @@ func (a *Alert) HasActivity(activity string) bool {
 	}
 	return false
 }
+
+// GetAffectedFacilities returns a list of facility IDs affected by this alert
+func (a *Alert) GetAffectedFacilities() []string {
+	facilityMap := make(map[string]bool)
+
+	for _, entity := range a.Attributes.InformedEntity {
+		if entity.FacilityID != "" {
+			facilityMap[entity.FacilityID] = true
+		}
+	}
+
+	facilities := make([]string, 0, len(facilityMap))
+	for facility := range facilityMap {
+		facilities = append(facilities, facility)
+	}
+
+	return facilities
+}
+
+// AffectsLeg returns whether any of the alert's informed entities matches a
+// trip leg: its route, direction and trip where given, and where a stop is
+// given, boarding at the leg's origin, getting off at its destination or
+// riding through a stop in between
+func (a *Alert) AffectsLeg(leg *TripLeg) bool {
+	for i := range a.Attributes.InformedEntity {
+		if a.Attributes.InformedEntity[i].matchesLeg(leg) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesLeg returns whether an informed entity matches a trip leg
+func (e *AlertEntity) matchesLeg(leg *TripLeg) bool {
+	if e.Route == "" && e.Trip == "" && e.Stop == "" {
+		return false
+	}
+	if (e.Route != "" && e.Route != leg.RouteID) || (e.Trip != "" && e.Trip != leg.TripID) {
+		return false
+	}
+	if e.DirectionID != nil && *e.DirectionID != leg.DirectionID {
+		return false
+	}
+
+	if e.Stop == "" {
+		return e.HasActivity(AlertActivityBoard, AlertActivityExit, AlertActivityRide)
+	}
+	if e.isStop(leg.Origin) && e.HasActivity(AlertActivityBoard) {
+		return true
+	}
+	if e.isStop(leg.Destination) && e.HasActivity(AlertActivityExit) {
+		return true
+	}
+	for i := range leg.Stops {
+		if e.isStop(&leg.Stops[i]) && e.HasActivity(AlertActivityRide) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasActivity returns whether the entity applies to any of the activities;
+// an entity without activities applies to all of them
+func (e *AlertEntity) HasActivity(activities ...string) bool {
+	if len(e.Activities) == 0 {
+		return true
+	}
+	for _, activity := range activities {
+		for _, act := range e.Activities {
+			if act == activity {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isStop returns whether the entity names a stop or its parent station
+func (e *AlertEntity) isStop(stop *Stop) bool {
+	return stop != nil && (stop.ID == e.Stop || stop.GetParentStationID() == e.Stop)
+}
@@ -325,6 +325,29 @@ func TestAlertGetAffectedStops(t *testing.T) {
 	}
 }
 
+func TestAlertGetAffectedFacilities(t *testing.T) {
+	alert := Alert{
+		Attributes: AlertAttributes{
+			InformedEntity: []AlertEntity{
+				{Stop: "place-pktrm", FacilityID: "804"},
+				{Stop: "place-pktrm", FacilityID: "823"},
+				{Stop: "place-pktrm"},                    // No facility specified
+				{Stop: "place-pktrm", FacilityID: "804"}, // Duplicate
+			},
+		},
+	}
+
+	facilities := alert.GetAffectedFacilities()
+	if len(facilities) != 2 {
+		t.Fatalf("Expected 2 unique facilities, got %v", facilities)
+	}
+	for _, facility := range facilities {
+		if facility != "804" && facility != "823" {
+			t.Errorf("Unexpected facility: %s", facility)
+		}
+	}
+}
+
 func TestAlertHasActivity(t *testing.T) {
 	alert := Alert{
 		Attributes: AlertAttributes{
@@ -363,6 +386,65 @@ func TestAlertHasActivity(t *testing.T) {
 	}
 }
 
+func TestAlertAffectsLeg(t *testing.T) {
+	inbound := 1
+	outbound := 0
+	platform := Stop{ID: "70076", Relationships: map[string]interface{}{
+		"parent_station": map[string]interface{}{"data": map[string]interface{}{"id": "place-pktrm", "type": "stop"}},
+	}}
+	leg := &TripLeg{
+		Origin:      &Stop{ID: "place-alfcl"},
+		Destination: &Stop{ID: "place-sstat"},
+		Stops:       []Stop{platform},
+		RouteID:     "Red",
+		TripID:      "trip-1",
+		DirectionID: inbound,
+	}
+
+	tests := []struct {
+		name     string
+		entity   AlertEntity
+		expected bool
+	}{
+		{"Route", AlertEntity{Route: "Red", Activities: []string{"BOARD", "EXIT", "RIDE"}}, true},
+		{"Other route", AlertEntity{Route: "Orange"}, false},
+		{"Direction", AlertEntity{Route: "Red", DirectionID: &inbound}, true},
+		{"Other direction", AlertEntity{Route: "Red", DirectionID: &outbound}, false},
+		{"Trip", AlertEntity{Trip: "trip-1"}, true},
+		{"Other trip", AlertEntity{Route: "Red", Trip: "trip-2"}, false},
+		{"Boarding", AlertEntity{Stop: "place-alfcl", Activities: []string{"BOARD"}}, true},
+		{"Exiting at the origin", AlertEntity{Stop: "place-alfcl", Activities: []string{"EXIT"}}, false},
+		{"Exiting", AlertEntity{Route: "Red", Stop: "place-sstat", Activities: []string{"EXIT"}}, true},
+		{"Riding through a station", AlertEntity{Stop: "place-pktrm", Activities: []string{"RIDE"}}, true},
+		{"Boarding where riders pass through", AlertEntity{Stop: "place-pktrm", Activities: []string{"BOARD"}}, false},
+		{"Wheelchair only", AlertEntity{Route: "Red", Activities: []string{"USING_WHEELCHAIR"}}, false},
+		{"Route type only", AlertEntity{RouteType: 1}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			alert := Alert{Attributes: AlertAttributes{InformedEntity: []AlertEntity{test.entity}}}
+			if got := alert.AffectsLeg(leg); got != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestAlertIsDisruption(t *testing.T) {
+	for effect, expected := range map[AlertEffect]bool{
+		AlertEffectShuttle:           true,
+		AlertEffectSignificantDelays: true,
+		AlertEffectElevatorOutage:    false,
+		AlertEffectTrackChange:       false,
+	} {
+		alert := Alert{Attributes: AlertAttributes{Effect: effect}}
+		if alert.IsDisruption() != expected {
+			t.Errorf("Expected IsDisruption %v for %s", expected, effect)
+		}
+	}
+}
+
 func TestGetAlertEffectDescription(t *testing.T) {
 	tests := []struct {
 		effect   AlertEffect
@@ -376,6 +458,7 @@ func TestGetAlertEffectDescription(t *testing.T) {
 		{AlertEffectStopMoved, "Stop Moved"},
 		{AlertEffectStopClosed, "Stop Closed"},
 		{AlertEffectShuttle, "Shuttle Bus Service"},
+		{AlertEffectSuspension, "Suspension"},
 		{AlertEffectElevatorOutage, "Elevator Outage"},
 		{AlertEffectAccessibilityIssue, "Accessibility Issue"},
 		{AlertEffectScheduleChange, "Schedule Change"},
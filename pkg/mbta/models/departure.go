@@ -0,0 +1,73 @@
+// Package models contains data models for MBTA API responses
+package models
+
+import "time"
+
+// DepartureStatus describes how a departure relates to the schedule
+type DepartureStatus string
+
+// Departure statuses
+const (
+	DepartureStatusScheduled DepartureStatus = "scheduled" // No real-time prediction
+	DepartureStatusPredicted DepartureStatus = "predicted" // Scheduled trip with a real-time prediction
+	DepartureStatusAdded     DepartureStatus = "added"     // Extra trip that isn't in the schedule
+	DepartureStatusCancelled DepartureStatus = "cancelled" // Trip won't run
+	DepartureStatusSkipped   DepartureStatus = "skipped"   // Trip runs but won't stop here
+)
+
+// Prediction schedule relationship values as defined by the MBTA API
+const (
+	ScheduleRelationshipAdded       = "ADDED"
+	ScheduleRelationshipCancelled   = "CANCELLED"
+	ScheduleRelationshipNoData      = "NO_DATA"
+	ScheduleRelationshipSkipped     = "SKIPPED"
+	ScheduleRelationshipUnscheduled = "UNSCHEDULED"
+)
+
+// Departure is a vehicle leaving a stop, combining its schedule with any
+// real-time prediction
+type Departure struct {
+	RouteID       string          `json:"route_id"`
+	RouteName     string          `json:"route_name,omitempty"`
+	TripID        string          `json:"trip_id"`
+	StopID        string          `json:"stop_id"`
+	VehicleID     string          `json:"vehicle_id,omitempty"`
+	DirectionID   int             `json:"direction_id"`
+	Headsign      string          `json:"headsign"`
+	Track         string          `json:"track,omitempty"`
+	ScheduledTime *time.Time      `json:"scheduled_time,omitempty"`
+	PredictedTime *time.Time      `json:"predicted_time,omitempty"`
+	Status        DepartureStatus `json:"status"`
+	StatusText    string          `json:"status_text,omitempty"` // Prediction status, such as "Boarding"
+}
+
+// Time returns the predicted departure time, or the scheduled time without a prediction
+func (d *Departure) Time() time.Time {
+	if d.PredictedTime != nil {
+		return *d.PredictedTime
+	}
+	if d.ScheduledTime != nil {
+		return *d.ScheduledTime
+	}
+	return time.Time{}
+}
+
+// MinutesUntil returns the whole minutes from now until the departure, or 0
+// once it is due
+func (d *Departure) MinutesUntil(now time.Time) int {
+	return max(0, int(d.Time().Sub(now)/time.Minute))
+}
+
+// DelayMinutes returns how many minutes later than scheduled the prediction
+// is, negative when early, or 0 without both times
+func (d *Departure) DelayMinutes() int {
+	if d.PredictedTime == nil || d.ScheduledTime == nil {
+		return 0
+	}
+	return int(d.PredictedTime.Sub(*d.ScheduledTime).Round(time.Minute) / time.Minute)
+}
+
+// IsRunning returns whether riders can board the departure
+func (d *Departure) IsRunning() bool {
+	return d.Status != DepartureStatusCancelled && d.Status != DepartureStatusSkipped
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDepartureTimes(t *testing.T) {
+	now := time.Date(2025, 5, 20, 8, 0, 0, 0, time.UTC)
+	scheduled := now.Add(5 * time.Minute)
+	predicted := now.Add(7*time.Minute + 40*time.Second)
+
+	tests := []struct {
+		name      string
+		departure Departure
+		expected  time.Time
+		minutes   int
+		delay     int
+	}{
+		{
+			name:      "Scheduled only",
+			departure: Departure{ScheduledTime: &scheduled, Status: DepartureStatusScheduled},
+			expected:  scheduled,
+			minutes:   5,
+		},
+		{
+			name:      "Predicted late",
+			departure: Departure{ScheduledTime: &scheduled, PredictedTime: &predicted, Status: DepartureStatusPredicted},
+			expected:  predicted,
+			minutes:   7,
+			delay:     3,
+		},
+		{
+			name:      "Added trip",
+			departure: Departure{PredictedTime: &predicted, Status: DepartureStatusAdded},
+			expected:  predicted,
+			minutes:   7,
+		},
+		{
+			name:      "Due",
+			departure: Departure{ScheduledTime: &now},
+			expected:  now,
+			minutes:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.departure.Time(); !got.Equal(tt.expected) {
+				t.Errorf("Expected time %v, got %v", tt.expected, got)
+			}
+			if got := tt.departure.MinutesUntil(now.Add(-10 * time.Second)); got != tt.minutes {
+				t.Errorf("Expected %d minutes, got %d", tt.minutes, got)
+			}
+			if got := tt.departure.DelayMinutes(); got != tt.delay {
+				t.Errorf("Expected %d minutes delay, got %d", tt.delay, got)
+			}
+		})
+	}
+
+	if (&Departure{}).Time() != (time.Time{}) {
+		t.Error("Expected zero time without schedule or prediction")
+	}
+}
+
+func TestDepartureIsRunning(t *testing.T) {
+	for status, expected := range map[DepartureStatus]bool{
+		DepartureStatusScheduled: true,
+		DepartureStatusPredicted: true,
+		DepartureStatusAdded:     true,
+		DepartureStatusCancelled: false,
+		DepartureStatusSkipped:   false,
+	} {
+		departure := Departure{Status: status}
+		if departure.IsRunning() != expected {
+			t.Errorf("Expected IsRunning %v for %s", expected, status)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package models contains data models for MBTA API responses
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// FacilityResponse represents a response containing facility data from the MBTA API
+type FacilityResponse struct {
+	Data []Facility `json:"data"`
+}
+
+// Facility represents an amenity at a station, such as an elevator, escalator,
+// ramp or parking lot
+type Facility struct {
+	ID            string                 `json:"id"`
+	Type          string                 `json:"type"`
+	Attributes    FacilityAttributes     `json:"attributes"`
+	Relationships map[string]interface{} `json:"relationships,omitempty"`
+}
+
+// FacilityAttributes contains the attributes of a facility
+type FacilityAttributes struct {
+	LongName   string             `json:"long_name"`
+	ShortName  string             `json:"short_name"`
+	Type       string             `json:"type"`
+	Latitude   *float64           `json:"latitude"`
+	Longitude  *float64           `json:"longitude"`
+	Properties []FacilityProperty `json:"properties"`
+}
+
+// FacilityProperty is a named value describing a facility, such as an
+// elevator's alternate route or a parking lot's capacity
+type FacilityProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// Facility type constants as defined by the MBTA API
+const (
+	FacilityTypeElevator              = "ELEVATOR"
+	FacilityTypeEscalator             = "ESCALATOR"
+	FacilityTypeRamp                  = "RAMP"
+	FacilityTypePortableBoardingLift  = "PORTABLE_BOARDING_LIFT"
+	FacilityTypeElevatedSubplatform   = "ELEVATED_SUBPLATFORM"
+	FacilityTypeFullyElevatedPlatform = "FULLY_ELEVATED_PLATFORM"
+	FacilityTypeBridgePlate           = "BRIDGE_PLATE"
+	FacilityTypeParkingArea           = "PARKING_AREA"
+	FacilityTypeBikeStorage           = "BIKE_STORAGE"
+	FacilityTypeFareVendingMachine    = "FARE_VENDING_MACHINE"
+	FacilityTypeTicketWindow          = "TICKET_WINDOW"
+	FacilityTypeFareMediaAssistant    = "FARE_MEDIA_ASSISTANT"
+	FacilityTypeElectricCarChargers   = "ELECTRIC_CAR_CHARGERS"
+	FacilityTypePickDrop              = "PICK_DROP"
+	FacilityTypeTaxiStand             = "TAXI_STAND"
+	FacilityTypeOther                 = "OTHER"
+)
+
+// AccessibilityFacilityTypes are the facility types that help riders with
+// disabilities reach or board vehicles
+var AccessibilityFacilityTypes = []string{
+	FacilityTypeElevator,
+	FacilityTypeEscalator,
+	FacilityTypeRamp,
+	FacilityTypePortableBoardingLift,
+	FacilityTypeElevatedSubplatform,
+	FacilityTypeFullyElevatedPlatform,
+	FacilityTypeBridgePlate,
+}
+
+// GetName returns the facility's long name, or its short name without one
+func (f *Facility) GetName() string {
+	if f.Attributes.LongName != "" {
+		return f.Attributes.LongName
+	}
+	return f.Attributes.ShortName
+}
+
+// GetStopID returns the ID of the stop the facility belongs to
+func (f *Facility) GetStopID() string {
+	return RelationshipID(f.Relationships, "stop")
+}
+
+// IsAccessibilityFeature returns whether the facility is an elevator,
+// escalator, ramp or other aid to boarding
+func (f *Facility) IsAccessibilityFeature() bool {
+	for _, facilityType := range AccessibilityFacilityTypes {
+		if f.Attributes.Type == facilityType {
+			return true
+		}
+	}
+	return false
+}
+
+// Property returns the first value of a named property
+func (f *Facility) Property(name string) (interface{}, bool) {
+	return findFacilityProperty(f.Attributes.Properties, name)
+}
+
+// LiveFacilityResponse represents a response containing live facility data from the MBTA API
+type LiveFacilityResponse struct {
+	Data []LiveFacility `json:"data"`
+}
+
+// LiveFacility represents the current state of a facility, such as how many
+// spaces are free in a parking lot
+type LiveFacility struct {
+	ID            string                 `json:"id"`
+	Type          string                 `json:"type"`
+	Attributes    LiveFacilityAttributes `json:"attributes"`
+	Relationships map[string]interface{} `json:"relationships,omitempty"`
+}
+
+// LiveFacilityAttributes contains the attributes of a live facility
+type LiveFacilityAttributes struct {
+	Properties []FacilityProperty `json:"properties"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// GetFacilityID returns the ID of the facility the state describes
+func (l *LiveFacility) GetFacilityID() string {
+	if id := RelationshipID(l.Relationships, "facility"); id != "" {
+		return id
+	}
+	// Live facilities share their facility's ID
+	return l.ID
+}
+
+// Property returns the first value of a named property
+func (l *LiveFacility) Property(name string) (interface{}, bool) {
+	return findFacilityProperty(l.Attributes.Properties, name)
+}
+
+// findFacilityProperty returns the first value of a named property
+func findFacilityProperty(properties []FacilityProperty, name string) (interface{}, bool) {
+	for _, property := range properties {
+		if property.Name == name {
+			return property.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Facility status constants
+const (
+	FacilityStatusInService    = "in_service"
+	FacilityStatusOutOfService = "out_of_service"
+)
+
+// FacilityStatus is a facility with its current state and any alerts about it
+type FacilityStatus struct {
+	Facility Facility      `json:"facility"`
+	Live     *LiveFacility `json:"live,omitempty"`
+	Status   string        `json:"status"`
+	Alerts   []Alert       `json:"alerts,omitempty"` // Active alerts naming the facility
+}
+
+// InService returns whether the facility is working
+func (s *FacilityStatus) InService() bool {
+	return s.Status != FacilityStatusOutOfService
+}
+
+// StationAccessibility describes the elevators, escalators and other
+// accessibility features at a station, and the alerts affecting them
+type StationAccessibility struct {
+	Station    Stop             `json:"station"`
+	Facilities []FacilityStatus `json:"facilities"`
+	Alerts     []Alert          `json:"alerts,omitempty"` // Active accessibility alerts for the station
+	AsOf       time.Time        `json:"as_of"`
+}
+
+// OutOfService returns the facilities that aren't working
+func (a *StationAccessibility) OutOfService() []FacilityStatus {
+	var out []FacilityStatus
+	for _, status := range a.Facilities {
+		if !status.InService() {
+			out = append(out, status)
+		}
+	}
+	return out
+}
+
+// ElevatorsInService returns how many of the station's elevators are working
+// and how many it has
+func (a *StationAccessibility) ElevatorsInService() (working, total int) {
+	for _, status := range a.Facilities {
+		if status.Facility.Attributes.Type != FacilityTypeElevator {
+			continue
+		}
+		total++
+		if status.InService() {
+			working++
+		}
+	}
+	return working, total
+}
+
+// Summary describes the station's elevators in a sentence
+func (a *StationAccessibility) Summary() string {
+	working, total := a.ElevatorsInService()
+	switch {
+	case total == 0:
+		return fmt.Sprintf("%s has no elevators", a.Station.Attributes.Name)
+	case working == total:
+		return fmt.Sprintf("All %d elevators at %s are in service", total, a.Station.Attributes.Name)
+	default:
+		return fmt.Sprintf("%d of %d elevators at %s are out of service", total-working, total, a.Station.Attributes.Name)
+	}
+}
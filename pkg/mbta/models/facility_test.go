@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFacilityUnmarshal(t *testing.T) {
+	facilityJSON := `{
+		"data": [
+			{
+				"id": "804",
+				"type": "facility",
+				"attributes": {
+					"long_name": "Park Street Elevator 804 (Tremont Street to Green Line westbound platform)",
+					"short_name": "Tremont Street to Green Line westbound platform",
+					"type": "ELEVATOR",
+					"latitude": 42.356468,
+					"longitude": -71.062392,
+					"properties": [
+						{"name": "alternate-service-text", "value": "Use elevator 823"},
+						{"name": "excludes-stop", "value": 70197}
+					]
+				},
+				"relationships": {
+					"stop": {"data": {"id": "place-pktrm", "type": "stop"}}
+				}
+			},
+			{
+				"id": "park-pktrm",
+				"type": "facility",
+				"attributes": {
+					"short_name": "Boston Common Garage",
+					"type": "PARKING_AREA",
+					"properties": []
+				}
+			}
+		]
+	}`
+
+	var response FacilityResponse
+	if err := json.Unmarshal([]byte(facilityJSON), &response); err != nil {
+		t.Fatalf("Failed to unmarshal facility JSON: %v", err)
+	}
+	if len(response.Data) != 2 {
+		t.Fatalf("Expected 2 facilities, got %d", len(response.Data))
+	}
+
+	elevator := response.Data[0]
+	if elevator.GetName() != "Park Street Elevator 804 (Tremont Street to Green Line westbound platform)" {
+		t.Errorf("Expected the long name, got %s", elevator.GetName())
+	}
+	if elevator.GetStopID() != "place-pktrm" {
+		t.Errorf("Expected stop place-pktrm, got %s", elevator.GetStopID())
+	}
+	if !elevator.IsAccessibilityFeature() {
+		t.Error("Expected an elevator to be an accessibility feature")
+	}
+	if elevator.Attributes.Latitude == nil || *elevator.Attributes.Latitude != 42.356468 {
+		t.Errorf("Expected latitude 42.356468, got %v", elevator.Attributes.Latitude)
+	}
+	if value, ok := elevator.Property("alternate-service-text"); !ok || value != "Use elevator 823" {
+		t.Errorf("Expected alternate service text, got %v", value)
+	}
+	if _, ok := elevator.Property("capacity"); ok {
+		t.Error("Expected no capacity property")
+	}
+
+	garage := response.Data[1]
+	if garage.GetName() != "Boston Common Garage" {
+		t.Errorf("Expected the short name without a long name, got %s", garage.GetName())
+	}
+	if garage.IsAccessibilityFeature() {
+		t.Error("Expected a parking area not to be an accessibility feature")
+	}
+	if garage.Attributes.Latitude != nil {
+		t.Errorf("Expected no latitude, got %v", *garage.Attributes.Latitude)
+	}
+}
+
+func TestLiveFacilityUnmarshal(t *testing.T) {
+	liveJSON := `{
+		"data": [
+			{
+				"id": "park-alfcl-garage",
+				"type": "live_facility",
+				"attributes": {
+					"properties": [
+						{"name": "capacity", "value": 2733},
+						{"name": "utilization", "value": 1200}
+					],
+					"updated_at": "2025-05-23T10:00:00-04:00"
+				},
+				"relationships": {
+					"facility": {"data": {"id": "park-alfcl-garage", "type": "facility"}}
+				}
+			},
+			{
+				"id": "804",
+				"type": "live_facility",
+				"attributes": {"properties": [], "updated_at": "2025-05-23T10:00:00-04:00"}
+			}
+		]
+	}`
+
+	var response LiveFacilityResponse
+	if err := json.Unmarshal([]byte(liveJSON), &response); err != nil {
+		t.Fatalf("Failed to unmarshal live facility JSON: %v", err)
+	}
+
+	garage := response.Data[0]
+	if garage.GetFacilityID() != "park-alfcl-garage" {
+		t.Errorf("Expected facility park-alfcl-garage, got %s", garage.GetFacilityID())
+	}
+	if value, ok := garage.Property("utilization"); !ok || value != float64(1200) {
+		t.Errorf("Expected utilization 1200, got %v", value)
+	}
+	if garage.Attributes.UpdatedAt.IsZero() {
+		t.Error("Expected updated_at to be parsed")
+	}
+
+	// Without a relationship, a live facility shares its facility's ID
+	if id := response.Data[1].GetFacilityID(); id != "804" {
+		t.Errorf("Expected facility 804, got %s", id)
+	}
+}
+
+func TestStationAccessibility(t *testing.T) {
+	facility := func(id, facilityType string) Facility {
+		return Facility{ID: id, Attributes: FacilityAttributes{ShortName: id, Type: facilityType}}
+	}
+	report := StationAccessibility{
+		Station: Stop{ID: "place-pktrm", Attributes: StopAttributes{Name: "Park Street"}},
+		Facilities: []FacilityStatus{
+			{Facility: facility("804", FacilityTypeElevator), Status: FacilityStatusOutOfService, Alerts: []Alert{{ID: "outage"}}},
+			{Facility: facility("823", FacilityTypeElevator), Status: FacilityStatusInService},
+			{Facility: facility("830", FacilityTypeEscalator), Status: FacilityStatusInService},
+		},
+	}
+
+	if working, total := report.ElevatorsInService(); working != 1 || total != 2 {
+		t.Errorf("Expected 1 of 2 elevators working, got %d of %d", working, total)
+	}
+	if out := report.OutOfService(); len(out) != 1 || out[0].Facility.ID != "804" {
+		t.Errorf("Expected elevator 804 out of service, got %v", out)
+	}
+	if summary := report.Summary(); summary != "1 of 2 elevators at Park Street are out of service" {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	report.Facilities[0].Status = FacilityStatusInService
+	if summary := report.Summary(); summary != "All 2 elevators at Park Street are in service" {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	report.Facilities = nil
+	if summary := report.Summary(); summary != "Park Street has no elevators" {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+}
@@ -0,0 +1,154 @@
+// Package models contains data models for MBTA API responses
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Included represents an included object in the MBTA API response.
+// Use an IncludedIndex to resolve it into a typed model.
+type Included struct {
+	ID            string      `json:"id"`
+	Type          string      `json:"type"`
+	Attributes    interface{} `json:"attributes"`
+	Relationships interface{} `json:"relationships,omitempty"`
+}
+
+// ResourceIdentifier identifies a JSON:API resource by type and ID
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// IncludedIndex indexes side-loaded resources by type and ID so they can be
+// resolved from relationships and decoded into typed models
+type IncludedIndex struct {
+	resources map[ResourceIdentifier]Included
+}
+
+// NewIncludedIndex builds an index over the included resources of a response
+func NewIncludedIndex(included []Included) *IncludedIndex {
+	index := &IncludedIndex{
+		resources: make(map[ResourceIdentifier]Included, len(included)),
+	}
+	for _, inc := range included {
+		index.resources[ResourceIdentifier{ID: inc.ID, Type: inc.Type}] = inc
+	}
+	return index
+}
+
+// Len returns the number of indexed resources
+func (i *IncludedIndex) Len() int {
+	return len(i.resources)
+}
+
+// Get returns the raw included resource with the given type and ID
+func (i *IncludedIndex) Get(resourceType, id string) (Included, bool) {
+	inc, ok := i.resources[ResourceIdentifier{ID: id, Type: resourceType}]
+	return inc, ok
+}
+
+// IDs returns the IDs of all indexed resources of the given type
+func (i *IncludedIndex) IDs(resourceType string) []string {
+	ids := make([]string, 0)
+	for key := range i.resources {
+		if key.Type == resourceType {
+			ids = append(ids, key.ID)
+		}
+	}
+	return ids
+}
+
+// Stop returns the included stop with the given ID
+func (i *IncludedIndex) Stop(id string) (*Stop, bool) {
+	return resolveIncluded[Stop](i, "stop", id)
+}
+
+// Trip returns the included trip with the given ID
+func (i *IncludedIndex) Trip(id string) (*Trip, bool) {
+	return resolveIncluded[Trip](i, "trip", id)
+}
+
+// Route returns the included route with the given ID
+func (i *IncludedIndex) Route(id string) (*Route, bool) {
+	return resolveIncluded[Route](i, "route", id)
+}
+
+// Vehicle returns the included vehicle with the given ID
+func (i *IncludedIndex) Vehicle(id string) (*Vehicle, bool) {
+	return resolveIncluded[Vehicle](i, "vehicle", id)
+}
+
+// Prediction returns the included prediction with the given ID
+func (i *IncludedIndex) Prediction(id string) (*Prediction, bool) {
+	return resolveIncluded[Prediction](i, "prediction", id)
+}
+
+// resolveIncluded looks up an included resource and decodes it into T
+func resolveIncluded[T any](i *IncludedIndex, resourceType, id string) (*T, bool) {
+	if i == nil || id == "" {
+		return nil, false
+	}
+	inc, ok := i.Get(resourceType, id)
+	if !ok {
+		return nil, false
+	}
+	value, err := DecodeIncluded[T](inc)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// DecodeIncluded decodes an included resource into a typed model such as Stop or Trip
+func DecodeIncluded[T any](inc Included) (*T, error) {
+	raw, err := json.Marshal(inc)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding included %s %s: %w", inc.Type, inc.ID, err)
+	}
+
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("error decoding included %s %s: %w", inc.Type, inc.ID, err)
+	}
+	return &value, nil
+}
+
+// RelationshipID returns the ID of a to-one relationship, or an empty string
+// when the relationship is missing or null
+func RelationshipID(relationships map[string]interface{}, name string) string {
+	ids := RelationshipIDs(relationships, name)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[0]
+}
+
+// RelationshipIDs returns the IDs of a to-many relationship, or the single ID
+// of a to-one relationship
+func RelationshipIDs(relationships map[string]interface{}, name string) []string {
+	rel, ok := relationships[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	switch data := rel["data"].(type) {
+	case map[string]interface{}:
+		if id, ok := data["id"].(string); ok {
+			return []string{id}
+		}
+	case []interface{}:
+		ids := make([]string, 0, len(data))
+		for _, item := range data {
+			if identifier, ok := item.(map[string]interface{}); ok {
+				if id, ok := identifier["id"].(string); ok {
+					ids = append(ids, id)
+				}
+			}
+		}
+		return ids
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIncludedIndex(t *testing.T) {
+	jsonData := `{
+		"data": [
+			{
+				"id": "schedule-1",
+				"type": "schedule",
+				"attributes": {"departure_time": "2023-05-20T12:02:00-04:00", "stop_sequence": 1},
+				"relationships": {
+					"route": {"data": {"id": "Red", "type": "route"}},
+					"stop": {"data": {"id": "70061", "type": "stop"}},
+					"trip": {"data": {"id": "trip-1", "type": "trip"}},
+					"prediction": {"data": null}
+				}
+			}
+		],
+		"included": [
+			{
+				"id": "trip-1",
+				"type": "trip",
+				"attributes": {"headsign": "Alewife", "direction_id": 1, "wheelchair_accessible": true},
+				"relationships": {"route": {"data": {"id": "Red", "type": "route"}}}
+			},
+			{
+				"id": "Red",
+				"type": "route",
+				"attributes": {"long_name": "Red Line", "type": 1}
+			},
+			{
+				"id": "70061",
+				"type": "stop",
+				"attributes": {"name": "Alewife", "latitude": 42.3954, "longitude": -71.1425, "wheelchair_boarding": 1}
+			}
+		]
+	}`
+
+	var response ScheduleResponse
+	if err := json.Unmarshal([]byte(jsonData), &response); err != nil {
+		t.Fatalf("Failed to unmarshal schedule response: %v", err)
+	}
+
+	index := NewIncludedIndex(response.Included)
+	if index.Len() != 3 {
+		t.Errorf("Expected 3 indexed resources, got %d", index.Len())
+	}
+
+	schedule := response.Data[0]
+
+	t.Run("Typed relationship accessors", func(t *testing.T) {
+		if id := schedule.GetRouteID(); id != "Red" {
+			t.Errorf("Expected route ID 'Red', got '%s'", id)
+		}
+		if id := schedule.GetStopID(); id != "70061" {
+			t.Errorf("Expected stop ID '70061', got '%s'", id)
+		}
+		if id := schedule.GetTripID(); id != "trip-1" {
+			t.Errorf("Expected trip ID 'trip-1', got '%s'", id)
+		}
+		if id := schedule.GetPredictionID(); id != "" {
+			t.Errorf("Expected empty prediction ID for null relationship, got '%s'", id)
+		}
+	})
+
+	t.Run("Resolve trip", func(t *testing.T) {
+		trip, ok := index.Trip(schedule.GetTripID())
+		if !ok {
+			t.Fatal("Expected to resolve included trip")
+		}
+		if trip.Attributes.Headsign != "Alewife" {
+			t.Errorf("Expected headsign 'Alewife', got '%s'", trip.Attributes.Headsign)
+		}
+		if !trip.IsWheelchairAccessible() {
+			t.Error("Expected trip to be wheelchair accessible")
+		}
+		if trip.GetRouteID() != "Red" {
+			t.Errorf("Expected trip route ID 'Red', got '%s'", trip.GetRouteID())
+		}
+	})
+
+	t.Run("Resolve route", func(t *testing.T) {
+		route, ok := index.Route("Red")
+		if !ok {
+			t.Fatal("Expected to resolve included route")
+		}
+		if route.Attributes.LongName != "Red Line" {
+			t.Errorf("Expected long name 'Red Line', got '%s'", route.Attributes.LongName)
+		}
+	})
+
+	t.Run("Resolve stop", func(t *testing.T) {
+		stop, ok := index.Stop(schedule.GetStopID())
+		if !ok {
+			t.Fatal("Expected to resolve included stop")
+		}
+		if stop.Attributes.Name != "Alewife" || !stop.IsAccessible() {
+			t.Errorf("Unexpected stop: %+v", stop)
+		}
+	})
+
+	t.Run("Missing resources", func(t *testing.T) {
+		if _, ok := index.Vehicle("y1234"); ok {
+			t.Error("Expected missing vehicle not to resolve")
+		}
+		if _, ok := index.Prediction(""); ok {
+			t.Error("Expected empty ID not to resolve")
+		}
+		// Type matters as well as ID
+		if _, ok := index.Stop("Red"); ok {
+			t.Error("Expected route ID not to resolve as a stop")
+		}
+	})
+
+	t.Run("IDs by type", func(t *testing.T) {
+		ids := index.IDs("route")
+		if len(ids) != 1 || ids[0] != "Red" {
+			t.Errorf("Expected route IDs [Red], got %v", ids)
+		}
+	})
+}
+
+func TestRelationshipIDs(t *testing.T) {
+	var relationships map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"parent_station": {"data": {"id": "place-pktrm", "type": "stop"}},
+		"child_stops": {"data": [{"id": "70075", "type": "stop"}, {"id": "70076", "type": "stop"}]},
+		"zone": {"data": null}
+	}`), &relationships)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal relationships: %v", err)
+	}
+
+	if id := RelationshipID(relationships, "parent_station"); id != "place-pktrm" {
+		t.Errorf("Expected parent station 'place-pktrm', got '%s'", id)
+	}
+
+	children := RelationshipIDs(relationships, "child_stops")
+	if len(children) != 2 || children[0] != "70075" || children[1] != "70076" {
+		t.Errorf("Expected child stops [70075 70076], got %v", children)
+	}
+
+	if id := RelationshipID(relationships, "zone"); id != "" {
+		t.Errorf("Expected empty zone for null data, got '%s'", id)
+	}
+
+	if ids := RelationshipIDs(relationships, "missing"); ids != nil {
+		t.Errorf("Expected nil for missing relationship, got %v", ids)
+	}
+
+	if id := RelationshipID(nil, "route"); id != "" {
+		t.Errorf("Expected empty ID for nil relationships, got '%s'", id)
+	}
+}
@@ -30,50 +30,22 @@ type PredictionAttributes struct {
 
 // GetRouteID extracts the route ID from the prediction's relationships
 func (p *Prediction) GetRouteID() string {
-	if route, ok := p.Relationships["route"]; ok {
-		if data, ok := route.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(p.Relationships, "route")
 }
 
 // GetStopID extracts the stop ID from the prediction's relationships
 func (p *Prediction) GetStopID() string {
-	if stop, ok := p.Relationships["stop"]; ok {
-		if data, ok := stop.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(p.Relationships, "stop")
 }
 
 // GetTripID extracts the trip ID from the prediction's relationships
 func (p *Prediction) GetTripID() string {
-	if trip, ok := p.Relationships["trip"]; ok {
-		if data, ok := trip.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(p.Relationships, "trip")
 }
 
 // GetVehicleID extracts the vehicle ID from the prediction's relationships
 func (p *Prediction) GetVehicleID() string {
-	if vehicle, ok := p.Relationships["vehicle"]; ok {
-		if data, ok := vehicle.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(p.Relationships, "vehicle")
 }
 
 // GetArrivalTime parses the arrival time string into a time.Time
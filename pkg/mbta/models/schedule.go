@@ -30,14 +30,6 @@ type ScheduleAttributes struct {
 	Timepoint     bool   `json:"timepoint"`
 }
 
-// Included represents an included object in the MBTA API response
-type Included struct {
-	ID            string      `json:"id"`
-	Type          string      `json:"type"`
-	Attributes    interface{} `json:"attributes"`
-	Relationships interface{} `json:"relationships,omitempty"`
-}
-
 // Pickup and drop-off type constants as defined by the MBTA API
 const (
 	PickupDropOffRegular              = 0
@@ -46,6 +38,26 @@ const (
 	PickupDropOffCoordinateWithDriver = 3
 )
 
+// GetRouteID extracts the route ID from the schedule's relationships
+func (s *Schedule) GetRouteID() string {
+	return RelationshipID(s.Relationships, "route")
+}
+
+// GetStopID extracts the stop ID from the schedule's relationships
+func (s *Schedule) GetStopID() string {
+	return RelationshipID(s.Relationships, "stop")
+}
+
+// GetTripID extracts the trip ID from the schedule's relationships
+func (s *Schedule) GetTripID() string {
+	return RelationshipID(s.Relationships, "trip")
+}
+
+// GetPredictionID extracts the prediction ID from the schedule's relationships
+func (s *Schedule) GetPredictionID() string {
+	return RelationshipID(s.Relationships, "prediction")
+}
+
 // GetDuration returns the duration between arrival and departure times
 func (s *Schedule) GetDuration() (time.Duration, error) {
 	arrivalTime, err := time.Parse(time.RFC3339, s.Attributes.ArrivalTime)
@@ -95,8 +95,86 @@ func (s *Stop) IsPlatform() bool {
 	return s.Attributes.LocationType == LocationTypePlatform
 }
 
+// GetParentStationID returns the ID of the station a platform, entrance or
+// boarding area belongs to, or "" for a stop without one
+func (s *Stop) GetParentStationID() string {
+	return RelationshipID(s.Relationships, "parent_station")
+}
+
+// GetChildStopIDs returns the IDs of a station's platforms, entrances and
+// other child stops, when the API included them
+func (s *Stop) GetChildStopIDs() []string {
+	return RelationshipIDs(s.Relationships, "child_stops")
+}
+
+// StationHierarchy is a station with the stops inside it, grouped by kind.
+// A stop that doesn't belong to a station is its own hierarchy with no children.
+type StationHierarchy struct {
+	Station       Stop   `json:"station"`
+	Platforms     []Stop `json:"platforms,omitempty"`
+	Entrances     []Stop `json:"entrances,omitempty"`
+	BoardingAreas []Stop `json:"boarding_areas,omitempty"`
+	Nodes         []Stop `json:"nodes,omitempty"` // Generic nodes such as stairs and fare gates
+}
+
+// NewStationHierarchy groups a station's descendants by location type
+func NewStationHierarchy(station Stop, descendants []Stop) *StationHierarchy {
+	hierarchy := &StationHierarchy{Station: station}
+	for _, stop := range descendants {
+		switch stop.Attributes.LocationType {
+		case LocationTypePlatform:
+			hierarchy.Platforms = append(hierarchy.Platforms, stop)
+		case LocationTypeEntrance:
+			hierarchy.Entrances = append(hierarchy.Entrances, stop)
+		case LocationTypeBoardingArea:
+			hierarchy.BoardingAreas = append(hierarchy.BoardingAreas, stop)
+		default:
+			hierarchy.Nodes = append(hierarchy.Nodes, stop)
+		}
+	}
+	return hierarchy
+}
+
+// PlatformIDs returns the IDs of the station's platforms
+func (h *StationHierarchy) PlatformIDs() []string {
+	ids := make([]string, len(h.Platforms))
+	for i, platform := range h.Platforms {
+		ids[i] = platform.ID
+	}
+	return ids
+}
+
+// StopIDs returns the station's ID followed by its platforms' IDs: every ID
+// that vehicles, predictions, schedules or alerts for the station may use
+func (h *StationHierarchy) StopIDs() []string {
+	return append([]string{h.Station.ID}, h.PlatformIDs()...)
+}
+
+// Contains returns whether a stop is the station or one of its descendants
+func (h *StationHierarchy) Contains(stopID string) bool {
+	if h.Station.ID == stopID {
+		return true
+	}
+	for _, group := range [][]Stop{h.Platforms, h.Entrances, h.BoardingAreas, h.Nodes} {
+		for _, stop := range group {
+			if stop.ID == stopID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // NearbyStation represents a stop with distance information from a search point
 type NearbyStation struct {
 	Stop       Stop    `json:"stop"`
 	DistanceKm float64 `json:"distance_km"`
 }
+
+// StopMatch represents a stop found by a name search, with how well it matched
+type StopMatch struct {
+	Stop        Stop    `json:"stop"`
+	Score       float64 `json:"score"`        // 0 to 1, where 1 is an exact match
+	MatchedOn   string  `json:"matched_on"`   // name, alias, description or municipality
+	MatchedText string  `json:"matched_text"` // The text that matched the query
+}
@@ -258,3 +258,70 @@ func TestStop_IsPlatform(t *testing.T) {
 		t.Error("Expected platform to return true for IsPlatform()")
 	}
 }
+
+func TestStop_Relationships(t *testing.T) {
+	var stop Stop
+	data := `{
+		"id": "place-pktrm",
+		"type": "stop",
+		"relationships": {
+			"parent_station": {"data": null},
+			"child_stops": {"data": [{"id": "70075", "type": "stop"}, {"id": "70200", "type": "stop"}]}
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &stop); err != nil {
+		t.Fatalf("Failed to unmarshal stop: %v", err)
+	}
+
+	if parent := stop.GetParentStationID(); parent != "" {
+		t.Errorf("Expected no parent station, got %s", parent)
+	}
+	if children := stop.GetChildStopIDs(); len(children) != 2 || children[0] != "70075" || children[1] != "70200" {
+		t.Errorf("Expected child stops 70075 and 70200, got %v", children)
+	}
+
+	platform := Stop{ID: "70075", Relationships: map[string]interface{}{
+		"parent_station": map[string]interface{}{"data": map[string]interface{}{"id": "place-pktrm", "type": "stop"}},
+	}}
+	if parent := platform.GetParentStationID(); parent != "place-pktrm" {
+		t.Errorf("Expected parent station place-pktrm, got %s", parent)
+	}
+}
+
+func TestStationHierarchy(t *testing.T) {
+	stop := func(id string, locationType int) Stop {
+		return Stop{ID: id, Attributes: StopAttributes{LocationType: locationType}}
+	}
+
+	hierarchy := NewStationHierarchy(stop("place-pktrm", LocationTypeStation), []Stop{
+		stop("70075", LocationTypePlatform),
+		stop("door-pktrm-tremont", LocationTypeEntrance),
+		stop("70200", LocationTypePlatform),
+		stop("node-pktrm-stairs", LocationTypeGenericNode),
+		stop("70200-01", LocationTypeBoardingArea),
+	})
+
+	if ids := hierarchy.PlatformIDs(); len(ids) != 2 || ids[0] != "70075" || ids[1] != "70200" {
+		t.Errorf("Expected platforms 70075 and 70200, got %v", ids)
+	}
+	if ids := hierarchy.StopIDs(); len(ids) != 3 || ids[0] != "place-pktrm" {
+		t.Errorf("Expected the station followed by its platforms, got %v", ids)
+	}
+	if len(hierarchy.Entrances) != 1 || len(hierarchy.Nodes) != 1 || len(hierarchy.BoardingAreas) != 1 {
+		t.Errorf("Expected one entrance, node and boarding area, got %+v", hierarchy)
+	}
+
+	for _, id := range []string{"place-pktrm", "70200", "door-pktrm-tremont", "70200-01", "node-pktrm-stairs"} {
+		if !hierarchy.Contains(id) {
+			t.Errorf("Expected hierarchy to contain %s", id)
+		}
+	}
+	if hierarchy.Contains("place-dwnxg") {
+		t.Error("Expected hierarchy not to contain place-dwnxg")
+	}
+
+	busStop := NewStationHierarchy(stop("2168", LocationTypePlatform), nil)
+	if ids := busStop.StopIDs(); len(ids) != 1 || ids[0] != "2168" {
+		t.Errorf("Expected a lone stop to expand to itself, got %v", ids)
+	}
+}
@@ -36,12 +36,34 @@ type TripPlan struct {
 	ArrivalTime    time.Time     `json:"arrival_time"`
 	Duration       time.Duration `json:"duration"`
 	Legs           []TripLeg     `json:"legs"`
+	Transfers      int           `json:"transfers"`
 	TotalDistance  float64       `json:"total_distance"`
 	AccessibleTrip bool          `json:"accessible_trip"`
+
+	// Outages that ruled out otherwise better itineraries for riders who
+	// need step-free access
+	AccessibilityIssues []AccessibilityIssue `json:"accessibility_issues,omitempty"`
+
+	// Service disruptions that ruled out the usual itineraries
+	Detours []Detour `json:"detours,omitempty"`
+
+	// Whether any leg runs into a significant service disruption
+	Disrupted bool `json:"disrupted"`
+
+	// Whether real-time predictions leave too little time for a transfer
+	MissedConnection bool `json:"missed_connection"`
+
+	// The latest time riders asked to arrive by, for plans searched backward
+	// from a deadline
+	ArriveBy *time.Time `json:"arrive_by,omitempty"`
+
+	// Whether real-time predictions have the trip arriving after ArriveBy
+	ArrivesLate bool `json:"arrives_late,omitempty"`
 }
 
 // TripLeg represents a single leg of a trip plan
 type TripLeg struct {
+	Mode           string          `json:"mode"` // LegModeTransit or LegModeWalk
 	Origin         *Stop           `json:"origin"`
 	Destination    *Stop           `json:"destination"`
 	RouteID        string          `json:"route_id"`
@@ -57,6 +79,44 @@ type TripLeg struct {
 	IsAccessible   bool            `json:"is_accessible"`
 	Instructions   string          `json:"instructions"`
 	PredictedTimes *PredictedTimes `json:"predicted_times,omitempty"`
+	Alerts         []Alert         `json:"alerts,omitempty"` // Alerts affecting the leg while it runs
+
+	Cancelled        bool `json:"cancelled,omitempty"`         // The trip won't stop to pick up or drop off riders here
+	MissedConnection bool `json:"missed_connection,omitempty"` // The previous leg is predicted to arrive too late to make this one
+}
+
+// How a trip leg is travelled
+const (
+	LegModeTransit = "transit" // Riding a trip
+	LegModeWalk    = "walk"    // Walking between stops
+)
+
+// Where an itinerary uses a station
+const (
+	StopRoleOrigin      = "origin"
+	StopRoleTransfer    = "transfer"
+	StopRoleDestination = "destination"
+)
+
+// AccessibilityIssue explains why a rider who needs step-free access can't
+// use a station on an itinerary, such as a broken elevator
+type AccessibilityIssue struct {
+	Stop        *Stop          `json:"stop"`
+	Role        string         `json:"role"`     // StopRoleOrigin, StopRoleTransfer or StopRoleDestination
+	RouteID     string         `json:"route_id"` // Route of the affected leg
+	Reason      string         `json:"reason"`
+	Alert       *Alert         `json:"alert,omitempty"`
+	Alternative *NearbyStation `json:"alternative,omitempty"` // Nearest accessible station to use instead
+}
+
+// Detour explains why a trip plan avoids part of the usual itinerary, such as
+// a suspended segment or stops replaced by shuttle buses
+type Detour struct {
+	Alert     *Alert `json:"alert"`
+	RouteID   string `json:"route_id"`
+	RouteName string `json:"route_name"`
+	Stops     []Stop `json:"stops,omitempty"` // Closed stops on the usual itinerary; none if the whole route is closed
+	Reason    string `json:"reason"`
 }
 
 // PredictedTimes contains real-time predictions for a trip leg
@@ -70,10 +130,12 @@ type PredictedTimes struct {
 // TransferPoint represents a transfer between two trip legs
 type TransferPoint struct {
 	Stop              *Stop         `json:"stop"`
+	ToStop            *Stop         `json:"to_stop,omitempty"` // Stop to walk to for ToRoute; nil when changing at Stop
 	FromRoute         string        `json:"from_route"`
 	ToRoute           string        `json:"to_route"`
 	TransferType      int           `json:"transfer_type"`
 	MinTransferTime   time.Duration `json:"min_transfer_time"`
+	WalkDistance      float64       `json:"walk_distance,omitempty"` // Kilometers walked to ToStop
 	SuggestedWaitTime time.Duration `json:"suggested_wait_time,omitempty"`
 }
 
@@ -85,16 +147,41 @@ const (
 	TransferTypeNotPossible = 3 // Transfer not possible
 )
 
-// GetRouteID extracts the route ID from the trip's relationships
-func (t *Trip) GetRouteID() string {
-	if route, ok := t.Relationships["route"]; ok {
-		if data, ok := route.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
+// IsWalk returns whether the leg is walked rather than ridden
+func (l *TripLeg) IsWalk() bool {
+	return l.Mode == LegModeWalk
+}
+
+// WalkingTime returns how long the plan's walking legs take altogether
+func (p *TripPlan) WalkingTime() time.Duration {
+	var total time.Duration
+	for i := range p.Legs {
+		if p.Legs[i].IsWalk() {
+			total += p.Legs[i].Duration
 		}
 	}
-	return ""
+	return total
+}
+
+// WalkingDistance returns how far, in kilometers, the plan's walking legs go
+func (p *TripPlan) WalkingDistance() float64 {
+	var total float64
+	for i := range p.Legs {
+		if p.Legs[i].IsWalk() {
+			total += p.Legs[i].Distance
+		}
+	}
+	return total
+}
+
+// IsWalking returns whether riders walk to another stop to make the transfer
+func (p *TransferPoint) IsWalking() bool {
+	return p.ToStop != nil && p.Stop != nil && p.ToStop.ID != p.Stop.ID
+}
+
+// GetRouteID extracts the route ID from the trip's relationships
+func (t *Trip) GetRouteID() string {
+	return RelationshipID(t.Relationships, "route")
 }
 
 // GetServiceID returns the service ID for this trip
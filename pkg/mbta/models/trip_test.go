@@ -233,3 +233,49 @@ func TestTransferPointJSON(t *testing.T) {
 		t.Errorf("Expected min transfer time 5m0s, got %v", deserializedTransfer.MinTransferTime)
 	}
 }
+
+func TestTransferPointIsWalking(t *testing.T) {
+	stop := &Stop{ID: "70077"}
+	tests := []struct {
+		name     string
+		toStop   *Stop
+		expected bool
+	}{
+		{"same stop", nil, false},
+		{"same stop named", &Stop{ID: "70077"}, false},
+		{"other stop", &Stop{ID: "70196"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfer := TransferPoint{Stop: stop, ToStop: tt.toStop}
+			if got := transfer.IsWalking(); got != tt.expected {
+				t.Errorf("Expected IsWalking %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTripLegIsWalk(t *testing.T) {
+	if leg := (TripLeg{Mode: LegModeWalk}); !leg.IsWalk() {
+		t.Error("Expected a walk leg")
+	}
+	if leg := (TripLeg{Mode: LegModeTransit}); leg.IsWalk() {
+		t.Error("Expected a transit leg not to be a walk")
+	}
+}
+
+func TestTripPlanWalking(t *testing.T) {
+	plan := TripPlan{Legs: []TripLeg{
+		{Mode: LegModeWalk, Duration: 4 * time.Minute, Distance: 0.3},
+		{Mode: LegModeTransit, Duration: 20 * time.Minute, Distance: 8},
+		{Mode: LegModeWalk, Duration: 2 * time.Minute, Distance: 0.1},
+	}}
+
+	if got := plan.WalkingTime(); got != 6*time.Minute {
+		t.Errorf("Expected 6m0s walking, got %s", got)
+	}
+	if got := plan.WalkingDistance(); got < 0.39 || got > 0.41 {
+		t.Errorf("Expected 0.4 km walking, got %.3f", got)
+	}
+}
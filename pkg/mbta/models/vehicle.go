@@ -59,38 +59,17 @@ func (v *Vehicle) GetStatusDescription() string {
 
 // GetRouteID extracts the route ID from the vehicle's relationships
 func (v *Vehicle) GetRouteID() string {
-	if route, ok := v.Relationships["route"]; ok {
-		if data, ok := route.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(v.Relationships, "route")
 }
 
 // GetStopID extracts the stop ID from the vehicle's relationships
 func (v *Vehicle) GetStopID() string {
-	if stop, ok := v.Relationships["stop"]; ok {
-		if data, ok := stop.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(v.Relationships, "stop")
 }
 
 // GetTripID extracts the trip ID from the vehicle's relationships
 func (v *Vehicle) GetTripID() string {
-	if trip, ok := v.Relationships["trip"]; ok {
-		if data, ok := trip.(map[string]interface{})["data"].(map[string]interface{}); ok {
-			if id, ok := data["id"].(string); ok {
-				return id
-			}
-		}
-	}
-	return ""
+	return RelationshipID(v.Relationships, "trip")
 }
 
 // HasOccupancyData returns true if the vehicle has occupancy data for its carriages
@@ -0,0 +1,256 @@
+package mbta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// PageOptions controls how list requests walk JSON:API pagination
+type PageOptions struct {
+	Limit    int // page[limit] sent with each request; 0 leaves page size to the API
+	Offset   int // page[offset] of the first page
+	MaxPages int // Stop after this many pages; 0 means no limit
+	MaxItems int // Stop after this many items; 0 means no limit
+}
+
+// pageOptionsKey is the context key for per-call page options
+type pageOptionsKey struct{}
+
+// WithPageOptions returns a context that applies the given page options to
+// list requests made with it, such as GetStops or GetSchedules
+func WithPageOptions(ctx context.Context, opts PageOptions) context.Context {
+	return context.WithValue(ctx, pageOptionsKey{}, opts)
+}
+
+// pageOptionsFromContext returns the page options stored in the context, if any
+func pageOptionsFromContext(ctx context.Context) PageOptions {
+	if opts, ok := ctx.Value(pageOptionsKey{}).(PageOptions); ok {
+		return opts
+	}
+	return PageOptions{}
+}
+
+// PageLinks contains the JSON:API pagination links of a collection response
+type PageLinks struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// Page is a single page of a JSON:API collection response
+type Page struct {
+	Data     []json.RawMessage `json:"data"`
+	Included []models.Included `json:"included,omitempty"`
+	Links    PageLinks         `json:"links,omitempty"`
+}
+
+// PageIterator walks the pages of a JSON:API collection by following links.next.
+// Use it like a bufio.Scanner:
+//
+//	it := client.Pages("/stops", mbta.PageOptions{Limit: 100})
+//	for it.Next(ctx) {
+//		page := it.Page()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type PageIterator struct {
+	client *Client
+	next   string
+	seen   map[string]bool
+	opts   PageOptions
+	pages  int
+	items  int
+	page   *Page
+	err    error
+}
+
+// Pages returns an iterator over the pages of the collection at path
+func (c *Client) Pages(path string, opts PageOptions) *PageIterator {
+	return &PageIterator{
+		client: c,
+		next:   applyPageOptions(path, opts),
+		seen:   make(map[string]bool),
+		opts:   opts,
+	}
+}
+
+// Next fetches the next page, returning false when the collection is exhausted,
+// a page or item cap has been reached, or an error occurred
+func (it *PageIterator) Next(ctx context.Context) bool {
+	it.page = nil
+	if it.err != nil || it.next == "" || it.seen[it.next] {
+		return false
+	}
+	if it.opts.MaxPages > 0 && it.pages >= it.opts.MaxPages {
+		return false
+	}
+	if it.opts.MaxItems > 0 && it.items >= it.opts.MaxItems {
+		return false
+	}
+
+	path := it.next
+	it.seen[path] = true
+
+	resp, err := it.client.makeRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var page Page
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		it.err = fmt.Errorf("error decoding page response: %w", err)
+		return false
+	}
+
+	// Trim the final page so the item cap is exact
+	if it.opts.MaxItems > 0 && it.items+len(page.Data) > it.opts.MaxItems {
+		page.Data = page.Data[:it.opts.MaxItems-it.items]
+	}
+
+	it.pages++
+	it.items += len(page.Data)
+	it.page = &page
+	it.next = it.client.relativePath(page.Links.Next)
+
+	return true
+}
+
+// Page returns the page fetched by the most recent call to Next
+func (it *PageIterator) Page() *Page {
+	return it.page
+}
+
+// Err returns the first error encountered while iterating, if any
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Iterate adapts a PageIterator into a range-over-func sequence of decoded items.
+// Iteration stops at the first fetch or decode error, which is yielded last.
+func Iterate[T any](ctx context.Context, it *PageIterator) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for it.Next(ctx) {
+			items, err := decodeItems[T](it.Page().Data)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// listAll collects every item across all pages of a collection along with the
+// de-duplicated included resources. Page options are taken from the context.
+func listAll[T any](ctx context.Context, c *Client, path, resource string) ([]T, []models.Included, error) {
+	it := c.Pages(path, pageOptionsFromContext(ctx))
+
+	items := make([]T, 0)
+	included := make([]models.Included, 0)
+	seenIncluded := make(map[string]bool)
+
+	for it.Next(ctx) {
+		page := it.Page()
+
+		pageItems, err := decodeItems[T](page.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decoding %s response: %w", resource, err)
+		}
+		items = append(items, pageItems...)
+
+		for _, inc := range page.Included {
+			key := inc.Type + "/" + inc.ID
+			if !seenIncluded[key] {
+				seenIncluded[key] = true
+				included = append(included, inc)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return items, included, nil
+}
+
+// decodeItems decodes the raw data entries of a page into typed items
+func decodeItems[T any](data []json.RawMessage) ([]T, error) {
+	items := make([]T, 0, len(data))
+	for _, raw := range data {
+		var item T
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// applyPageOptions adds page[limit] and page[offset] to a request path unless
+// the caller already set them explicitly
+func applyPageOptions(path string, opts PageOptions) string {
+	limit := opts.Limit
+	if limit == 0 && opts.MaxItems > 0 {
+		// Don't ask for more than we are going to keep
+		limit = opts.MaxItems
+	}
+	if limit == 0 && opts.Offset == 0 {
+		return path
+	}
+
+	base, rawQuery, _ := strings.Cut(path, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return path
+	}
+
+	if limit > 0 && query.Get("page[limit]") == "" {
+		query.Set("page[limit]", strconv.Itoa(limit))
+	}
+	if opts.Offset > 0 && query.Get("page[offset]") == "" {
+		query.Set("page[offset]", strconv.Itoa(opts.Offset))
+	}
+
+	return base + "?" + query.Encode()
+}
+
+// relativePath converts a links.next URL into a path that makeRequest can use
+func (c *Client) relativePath(link string) string {
+	if link == "" {
+		return ""
+	}
+	if strings.HasPrefix(link, c.baseURL) {
+		return strings.TrimPrefix(link, c.baseURL)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	if u.IsAbs() {
+		return u.RequestURI()
+	}
+	return link
+}
@@ -0,0 +1,212 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// newPagedStopServer returns a test server that serves total stops in pages,
+// honoring page[limit] and page[offset] and linking to the next page
+func newPagedStopServer(t *testing.T, total int, requests *int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		if r.URL.Path != "/stops" {
+			t.Errorf("Expected URL path '/stops', got '%s'", r.URL.Path)
+		}
+
+		limit := total
+		if l := r.URL.Query().Get("page[limit]"); l != "" {
+			limit, _ = strconv.Atoi(l)
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("page[offset]"))
+
+		data := ""
+		for i := offset; i < offset+limit && i < total; i++ {
+			if data != "" {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"id": "stop-%d", "type": "stop", "attributes": {"name": "Stop %d"}}`, i, i)
+		}
+
+		links := ""
+		if offset+limit < total {
+			links = fmt.Sprintf(`, "links": {"next": "%s/stops?page[limit]=%d&page[offset]=%d"}`, server.URL, limit, offset+limit)
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"data": [%s]%s}`, data, links)
+	}))
+	return server
+}
+
+func TestListFollowsNextLinks(t *testing.T) {
+	requests := 0
+	server := newPagedStopServer(t, 7, &requests)
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+	ctx := WithPageOptions(context.Background(), PageOptions{Limit: 3})
+	stops, err := client.GetStops(ctx)
+	if err != nil {
+		t.Fatalf("GetStops returned error: %v", err)
+	}
+
+	if len(stops) != 7 {
+		t.Fatalf("Expected 7 stops across all pages, got %d", len(stops))
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+	for i, stop := range stops {
+		if stop.ID != fmt.Sprintf("stop-%d", i) {
+			t.Errorf("Expected stop %d to have ID stop-%d, got %s", i, i, stop.ID)
+		}
+	}
+}
+
+func TestListRespectsCaps(t *testing.T) {
+	t.Run("Max items", func(t *testing.T) {
+		requests := 0
+		server := newPagedStopServer(t, 20, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+		ctx := WithPageOptions(context.Background(), PageOptions{Limit: 4, MaxItems: 6})
+		stops, err := client.GetStops(ctx)
+		if err != nil {
+			t.Fatalf("GetStops returned error: %v", err)
+		}
+
+		if len(stops) != 6 {
+			t.Errorf("Expected 6 stops, got %d", len(stops))
+		}
+		if requests != 2 {
+			t.Errorf("Expected 2 page requests, got %d", requests)
+		}
+	})
+
+	t.Run("Max items without limit sets page size", func(t *testing.T) {
+		requests := 0
+		server := newPagedStopServer(t, 20, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+		ctx := WithPageOptions(context.Background(), PageOptions{MaxItems: 5})
+		stops, err := client.GetStops(ctx)
+		if err != nil {
+			t.Fatalf("GetStops returned error: %v", err)
+		}
+
+		if len(stops) != 5 || requests != 1 {
+			t.Errorf("Expected 5 stops in 1 request, got %d stops in %d requests", len(stops), requests)
+		}
+	})
+
+	t.Run("Max pages and offset", func(t *testing.T) {
+		requests := 0
+		server := newPagedStopServer(t, 20, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+		ctx := WithPageOptions(context.Background(), PageOptions{Limit: 5, Offset: 10, MaxPages: 1})
+		stops, err := client.GetStops(ctx)
+		if err != nil {
+			t.Fatalf("GetStops returned error: %v", err)
+		}
+
+		if len(stops) != 5 {
+			t.Fatalf("Expected 5 stops, got %d", len(stops))
+		}
+		if stops[0].ID != "stop-10" {
+			t.Errorf("Expected first stop to be stop-10, got %s", stops[0].ID)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 page request, got %d", requests)
+		}
+	})
+}
+
+func TestIterate(t *testing.T) {
+	requests := 0
+	server := newPagedStopServer(t, 10, &requests)
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+	ctx := context.Background()
+
+	// Stop early after a few items; later pages should never be requested
+	count := 0
+	for stop, err := range Iterate[models.Stop](ctx, client.Pages("/stops", PageOptions{Limit: 2})) {
+		if err != nil {
+			t.Fatalf("Iterate returned error: %v", err)
+		}
+		if stop.ID != fmt.Sprintf("stop-%d", count) {
+			t.Errorf("Expected stop-%d, got %s", count, stop.ID)
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+}
+
+func TestPageIteratorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page[offset]") == "" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, `{"data": [{"id": "1", "type": "stop"}], "links": {"next": "/stops?page[offset]=1"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"errors":[{"status":"500","title":"Internal Server Error"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+	_, err := client.GetStops(context.Background())
+	if err == nil {
+		t.Fatal("Expected error from failing second page, got nil")
+	}
+	if _, ok := err.(*APIError); !ok {
+		t.Errorf("Expected *APIError, got %T", err)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	client := NewClient(&config.Config{APIBaseURL: "https://api-v3.mbta.com"})
+
+	tests := []struct {
+		link     string
+		expected string
+	}{
+		{"", ""},
+		{"https://api-v3.mbta.com/stops?page[offset]=10", "/stops?page[offset]=10"},
+		{"https://other.example.com/stops?page%5Boffset%5D=10", "/stops?page%5Boffset%5D=10"},
+		{"/stops?page[offset]=10", "/stops?page[offset]=10"},
+	}
+
+	for _, tt := range tests {
+		if got := client.relativePath(tt.link); got != tt.expected {
+			t.Errorf("relativePath(%q) = %q, expected %q", tt.link, got, tt.expected)
+		}
+	}
+}
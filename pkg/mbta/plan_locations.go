@@ -0,0 +1,155 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Trips planned from or to a location rather than a stop
+const (
+	DefaultMaxAccessDistance = 1.0 // Kilometers riders walk to the first stop or from the last
+	DefaultAccessStations    = 8   // Stations near a location considered for the first or last stop
+)
+
+// accessSearchResults is how many nearby stops are searched for access
+// stations, since a station's entrances and platforms are found separately
+const accessSearchResults = 50
+
+// IDs of the stops standing in for locations in trip plans
+const (
+	OriginLocationID      = "location:origin"
+	DestinationLocationID = "location:destination"
+)
+
+// LocationStop returns a stop standing in for a location in a trip plan, named
+// after the location or, failing that, its coordinates
+func LocationStop(id string, location Location) *models.Stop {
+	name := location.Name
+	if name == "" {
+		name = fmt.Sprintf("%.5f, %.5f", location.Latitude, location.Longitude)
+	}
+	return &models.Stop{
+		ID:   id,
+		Type: "location",
+		Attributes: models.StopAttributes{
+			Name:      name,
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		},
+	}
+}
+
+// tripEndpoint is where a planned trip starts or ends: a stop, or a location
+// and the stops within walking distance of it
+type tripEndpoint struct {
+	stop     *models.Stop
+	location bool
+	nearby   []models.NearbyStation
+}
+
+// tripEndpoint finds the stop a trip starts or ends at, or, if a location is
+// given, the stations riders could walk to or from it
+func (c *Client) tripEndpoint(ctx context.Context, stopID string, locationOption interface{}, locationID string) (*tripEndpoint, error) {
+	var location Location
+	switch val := locationOption.(type) {
+	case Location:
+		location = val
+	case *Location:
+		if val == nil {
+			return c.stopEndpoint(ctx, stopID)
+		}
+		location = *val
+	default:
+		return c.stopEndpoint(ctx, stopID)
+	}
+
+	endpoint := &tripEndpoint{stop: LocationStop(locationID, location), location: true}
+	nearby, err := c.FindNearbyStations(ctx, location.Latitude, location.Longitude, DefaultMaxAccessDistance, accessSearchResults, false)
+	if err != nil {
+		return nil, fmt.Errorf("error finding stops near %s: %w", endpoint.name(), err)
+	}
+	endpoint.nearby = accessStations(nearby, DefaultAccessStations)
+	if len(endpoint.nearby) == 0 {
+		return nil, fmt.Errorf("no stops within %.1f km of %s", DefaultMaxAccessDistance, endpoint.name())
+	}
+	return endpoint, nil
+}
+
+// stopEndpoint looks up the stop a trip starts or ends at
+func (c *Client) stopEndpoint(ctx context.Context, stopID string) (*tripEndpoint, error) {
+	stop, err := c.GetStop(ctx, stopID)
+	if err != nil {
+		return nil, err
+	}
+	return &tripEndpoint{stop: stop}, nil
+}
+
+// name describes the endpoint in errors
+func (e *tripEndpoint) name() string {
+	if e.location {
+		return e.stop.Attributes.Name
+	}
+	return e.stop.ID
+}
+
+// routeStopIDs returns the stops whose routes could serve the endpoint,
+// separated by commas: the stop itself, or the stops near a location and
+// their stations
+func (e *tripEndpoint) routeStopIDs() string {
+	if !e.location {
+		return e.stop.ID
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, candidate := range e.nearby {
+		for _, id := range []string{candidate.Stop.ID, stationID(&candidate.Stop)} {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return strings.Join(ids, ",")
+}
+
+// addTo registers the endpoint's stop with a timetable. A location's nearby
+// stops are registered so walks to them reach their stations, but the
+// location itself is only connected by AddAccessWalks.
+func (e *tripEndpoint) addTo(timetable *Timetable) {
+	if !e.location {
+		timetable.AddStop(e.stop)
+		return
+	}
+	for _, candidate := range e.nearby {
+		stop := candidate.Stop
+		timetable.AddStop(&stop)
+	}
+}
+
+// accessStations keeps the closest stop of each of the nearest stations,
+// up to limit, so one station's many entrances and platforms don't crowd
+// out the others
+func accessStations(nearby []models.NearbyStation, limit int) []models.NearbyStation {
+	sorted := append([]models.NearbyStation(nil), nearby...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].DistanceKm < sorted[j].DistanceKm })
+
+	seen := make(map[string]bool)
+	var stations []models.NearbyStation
+	for _, candidate := range sorted {
+		station := stationID(&candidate.Stop)
+		if seen[station] {
+			continue
+		}
+		seen[station] = true
+		stations = append(stations, candidate)
+		if len(stations) == limit {
+			break
+		}
+	}
+	return stations
+}
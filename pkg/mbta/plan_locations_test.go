@@ -0,0 +1,83 @@
+package mbta
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+func TestLocationStop(t *testing.T) {
+	stop := LocationStop(OriginLocationID, Location{Name: "Fenway Park", Latitude: 42.3467, Longitude: -71.0972})
+	if stop.ID != OriginLocationID || stop.Attributes.Name != "Fenway Park" || stop.Attributes.Latitude != 42.3467 {
+		t.Errorf("Unexpected location stop %+v", stop)
+	}
+
+	unnamed := LocationStop(DestinationLocationID, Location{Latitude: 42.3467, Longitude: -71.0972})
+	if unnamed.Attributes.Name != "42.34670, -71.09720" {
+		t.Errorf("Expected a location named by its coordinates, got %q", unnamed.Attributes.Name)
+	}
+}
+
+func TestAccessStations(t *testing.T) {
+	nearby := []models.NearbyStation{
+		{Stop: footpathTestStop("door-pktrm", "place-pktrm", 0, 0), DistanceKm: 0.1},
+		{Stop: footpathTestStop("70076", "place-pktrm", 0, 0), DistanceKm: 0.15},
+		{Stop: footpathTestStop("70077", "place-dwnxg", 0, 0), DistanceKm: 0.3},
+		{Stop: footpathTestStop("bus", "", 0, 0), DistanceKm: 0.2},
+	}
+
+	stations := accessStations(nearby, 2)
+	if len(stations) != 2 || stations[0].Stop.ID != "door-pktrm" || stations[1].Stop.ID != "bus" {
+		t.Errorf("Expected the closest stop of the two nearest stations, got %+v", stations)
+	}
+}
+
+func TestPlanTripsFromLocations(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	var scheduleRequest string
+	server := httptest.NewServer(plannerTestHandler(t, schedules, included, &scheduleRequest))
+	defer server.Close()
+
+	// Home is about 300 meters from A and the office about 300 meters from D
+	index := NewStopIndex()
+	index.Build([]models.Stop{
+		footpathTestStop("a", "", 42.3550, -71.0600),
+		footpathTestStop("d", "", 42.3950, -71.1200),
+	}, nil)
+	client := NewClient(&config.Config{APIBaseURL: server.URL}, WithStopIndex(index))
+
+	plans, err := client.PlanTrips(context.Background(), "", "", plannerTestTime("07:50"), map[string]interface{}{
+		"origin_location":      Location{Name: "Home", Latitude: 42.3577, Longitude: -71.0600},
+		"destination_location": &Location{Latitude: 42.3977, Longitude: -71.1200},
+	})
+	if err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+
+	fastest := plans[len(plans)-1]
+	if fastest.Origin.ID != OriginLocationID || fastest.Origin.Attributes.Name != "Home" || fastest.Destination.ID != DestinationLocationID {
+		t.Errorf("Expected the plan to run between the locations, got %s to %s", fastest.Origin.ID, fastest.Destination.ID)
+	}
+	if len(fastest.Legs) != 4 || !fastest.Legs[0].IsWalk() || !fastest.Legs[3].IsWalk() {
+		t.Fatalf("Expected first and last mile walks, got %+v", fastest.Legs)
+	}
+	if fastest.Legs[0].Destination.ID != "a" || fastest.Legs[3].Origin.ID != "d" {
+		t.Errorf("Expected to walk to a and from d, got %s and %s", fastest.Legs[0].Destination.ID, fastest.Legs[3].Origin.ID)
+	}
+	if !strings.Contains(fastest.Legs[3].Instructions, "42.39770, -71.12000") {
+		t.Errorf("Expected the walk to name the destination by its coordinates, got %q", fastest.Legs[3].Instructions)
+	}
+
+	// Locations without a stop nearby can't be planned from
+	_, err = client.PlanTrips(context.Background(), "", "d", plannerTestTime("07:50"), map[string]interface{}{
+		"origin_location": Location{Name: "Nowhere", Latitude: 43, Longitude: -72},
+	})
+	if err == nil || !strings.Contains(err.Error(), "no stops within 1.0 km of Nowhere") {
+		t.Errorf("Expected no stops near the origin, got %v", err)
+	}
+}
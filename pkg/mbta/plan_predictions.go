@@ -0,0 +1,121 @@
+package mbta
+
+import (
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// predictionDelayThreshold is how much later than scheduled a leg must be
+// predicted to arrive to count as delayed
+const predictionDelayThreshold = 2 * time.Minute
+
+// ApplyPredictions fills in the predicted times of legs whose trips have
+// real-time predictions, marks legs on trips that won't stop for riders, and
+// flags transfers that the predicted times leave too little time to make
+func (t *Timetable) ApplyPredictions(plans []models.TripPlan, predictions []models.Prediction) {
+	byTripStop := make(map[string]*models.Prediction, len(predictions))
+	for i := range predictions {
+		prediction := &predictions[i]
+		byTripStop[prediction.GetTripID()+"/"+prediction.GetStopID()] = prediction
+	}
+
+	for i := range plans {
+		plan := &plans[i]
+		for j := range plan.Legs {
+			leg := &plan.Legs[j]
+			if leg.IsWalk() {
+				continue
+			}
+			departure := byTripStop[leg.TripID+"/"+leg.Origin.ID]
+			arrival := byTripStop[leg.TripID+"/"+leg.Destination.ID]
+			if departure == nil && arrival == nil {
+				continue
+			}
+
+			if isCancelledPrediction(departure) || isCancelledPrediction(arrival) {
+				leg.Cancelled = true
+				plan.Disrupted = true
+				continue
+			}
+
+			// A late departure makes for a late arrival unless that's predicted too
+			times := &models.PredictedTimes{
+				PredictedDeparture: leg.DepartureTime,
+				PredictedArrival:   leg.ArrivalTime,
+			}
+			if departure != nil {
+				if predicted, err := departure.GetDepartureTime(); err == nil && predicted != nil {
+					times.PredictedDeparture = *predicted
+					times.PredictedArrival = leg.ArrivalTime.Add(predicted.Sub(leg.DepartureTime))
+				}
+			}
+			if arrival != nil {
+				if predicted, err := arrival.GetArrivalTime(); err == nil && predicted != nil {
+					times.PredictedArrival = *predicted
+				}
+			}
+
+			delay := times.PredictedArrival.Sub(leg.ArrivalTime)
+			times.DelayMinutes = int(delay.Round(time.Minute) / time.Minute)
+			times.IsDelayed = delay >= predictionDelayThreshold
+			leg.PredictedTimes = times
+		}
+
+		// Check each connection against the expected times, allowing for
+		// any walk between the rides
+		var previous *models.TripLeg
+		var walking time.Duration
+		for j := range plan.Legs {
+			next := &plan.Legs[j]
+			if next.IsWalk() {
+				walking += next.Duration
+				continue
+			}
+			if previous != nil {
+				transfer := walking
+				if walking == 0 {
+					transfer, _ = t.transferTime(t.node(next.Origin.ID), previous.RouteID, next.RouteID)
+				}
+				if expectedDeparture(next).Before(expectedArrival(previous).Add(transfer)) {
+					next.MissedConnection = true
+					plan.MissedConnection = true
+				}
+			}
+			previous, walking = next, 0
+		}
+
+		// Delays can make riders late for the time they asked to arrive by
+		if plan.ArriveBy != nil && previous != nil && expectedArrival(previous).Add(walking).After(*plan.ArriveBy) {
+			plan.ArrivesLate = true
+		}
+	}
+}
+
+// isCancelledPrediction returns whether a prediction says the trip won't stop
+func isCancelledPrediction(prediction *models.Prediction) bool {
+	if prediction == nil {
+		return false
+	}
+	switch prediction.Attributes.Schedule {
+	case models.ScheduleRelationshipCancelled, models.ScheduleRelationshipSkipped:
+		return true
+	}
+	return false
+}
+
+// expectedDeparture returns a leg's predicted departure, or its scheduled one
+func expectedDeparture(leg *models.TripLeg) time.Time {
+	if leg.PredictedTimes != nil {
+		return leg.PredictedTimes.PredictedDeparture
+	}
+	return leg.DepartureTime
+}
+
+// expectedArrival returns a leg's predicted arrival, or its scheduled one
+func expectedArrival(leg *models.TripLeg) time.Time {
+	if leg.PredictedTimes != nil {
+		return leg.PredictedTimes.PredictedArrival
+	}
+	return leg.ArrivalTime
+}
@@ -0,0 +1,156 @@
+package mbta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// plannerTestPrediction builds a prediction for a trip at a stop
+func plannerTestPrediction(tripID, stopID, arrival, departure, scheduleRelationship string) models.Prediction {
+	prediction := models.Prediction{
+		ID: "prediction-" + tripID + "-" + stopID,
+		Relationships: map[string]interface{}{
+			"trip": relationship("trip", tripID),
+			"stop": relationship("stop", stopID),
+		},
+	}
+	prediction.Attributes.Schedule = scheduleRelationship
+	if arrival != "" {
+		value := "2025-05-20T" + arrival + ":00-04:00"
+		prediction.Attributes.ArrivalTime = &value
+	}
+	if departure != "" {
+		value := "2025-05-20T" + departure + ":00-04:00"
+		prediction.Attributes.DepartureTime = &value
+	}
+	return prediction
+}
+
+func TestApplyPredictions(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	plan := func() []models.TripPlan {
+		return timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+	}
+
+	t.Run("On time", func(t *testing.T) {
+		plans := plan()
+		timetable.ApplyPredictions(plans, []models.Prediction{
+			plannerTestPrediction("r1-1", "a", "", "08:00", ""),
+			plannerTestPrediction("r1-1", "b1", "08:10", "", ""),
+		})
+
+		leg := plans[1].Legs[0]
+		if leg.PredictedTimes == nil || leg.PredictedTimes.IsDelayed || leg.PredictedTimes.DelayMinutes != 0 {
+			t.Errorf("Expected an on-time prediction, got %+v", leg.PredictedTimes)
+		}
+		if plans[1].Legs[1].PredictedTimes != nil {
+			t.Errorf("Expected no prediction for the second leg, got %+v", plans[1].Legs[1].PredictedTimes)
+		}
+		if plans[1].MissedConnection {
+			t.Error("Expected the connection to be made")
+		}
+	})
+
+	t.Run("Late arrival misses the connection", func(t *testing.T) {
+		plans := plan()
+		timetable.ApplyPredictions(plans, []models.Prediction{
+			plannerTestPrediction("r1-1", "b1", "08:14", "", ""),
+			plannerTestPrediction("r2-2", "b2", "08:15", "08:15", ""),
+		})
+
+		first, second := plans[1].Legs[0], plans[1].Legs[1]
+		if first.PredictedTimes == nil || !first.PredictedTimes.IsDelayed || first.PredictedTimes.DelayMinutes != 4 {
+			t.Errorf("Expected a 4 minute delay, got %+v", first.PredictedTimes)
+		}
+		if !second.MissedConnection || !plans[1].MissedConnection {
+			t.Error("Expected the connection to R2 to be missed")
+		}
+		if first.MissedConnection {
+			t.Error("Expected only the second leg to be flagged")
+		}
+	})
+
+	t.Run("Late departure delays the arrival", func(t *testing.T) {
+		plans := plan()
+		timetable.ApplyPredictions(plans, []models.Prediction{
+			plannerTestPrediction("r3-1", "a", "", "08:08", ""),
+		})
+
+		times := plans[0].Legs[0].PredictedTimes
+		if times == nil || !times.PredictedArrival.Equal(plannerTestTime("08:43")) || times.DelayMinutes != 3 {
+			t.Errorf("Expected arrival at 08:43, 3 minutes late, got %+v", times)
+		}
+	})
+
+	t.Run("Cancelled trip", func(t *testing.T) {
+		plans := plan()
+		timetable.ApplyPredictions(plans, []models.Prediction{
+			plannerTestPrediction("r3-1", "a", "", "", models.ScheduleRelationshipCancelled),
+		})
+
+		if !plans[0].Legs[0].Cancelled || !plans[0].Disrupted {
+			t.Errorf("Expected the direct trip to be cancelled, got %+v", plans[0].Legs[0])
+		}
+	})
+}
+
+func TestApplyPredictionsAcrossWalk(t *testing.T) {
+	schedules, included := footpathTestData()
+	timetable := NewTimetable(schedules, included)
+	graph := NewFootpathGraph()
+	graph.Add(Footpath{FromStopID: "b1", ToStopID: "e", Duration: 4 * time.Minute})
+	timetable.AddFootpaths(graph)
+
+	plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "f", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+	if len(plans) != 1 || len(plans[0].Legs) != 3 {
+		t.Fatalf("Expected one plan with a walk, got %+v", plans)
+	}
+
+	// Two minutes late leaves too little time to walk over
+	timetable.ApplyPredictions(plans, []models.Prediction{
+		plannerTestPrediction("r1-1", "b1", "08:13", "", ""),
+	})
+
+	legs := plans[0].Legs
+	if legs[1].PredictedTimes != nil || legs[1].MissedConnection {
+		t.Errorf("Expected the walk to have no predictions, got %+v", legs[1])
+	}
+	if !legs[2].MissedConnection || !plans[0].MissedConnection {
+		t.Error("Expected the connection to R4 to be missed")
+	}
+}
+
+func TestApplyPredictionsArrivesLate(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("08:45"), ArriveBy: true, MaxTransfers: 3})
+	if len(plans) != 2 {
+		t.Fatalf("Expected 2 plans, got %d", len(plans))
+	}
+
+	timetable.ApplyPredictions(plans, []models.Prediction{
+		plannerTestPrediction("r2-3", "d", "08:47", "", ""),
+		plannerTestPrediction("r3-1", "d", "08:42", "", ""),
+	})
+
+	if !plans[1].ArrivesLate {
+		t.Error("Expected the delayed plan to arrive after the deadline")
+	}
+	if plans[0].ArrivesLate {
+		t.Error("Expected the direct plan to still arrive on time")
+	}
+
+	// Plans leaving after a time have no deadline to miss
+	departing := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("08:10"), MaxTransfers: 3})
+	timetable.ApplyPredictions(departing, []models.Prediction{plannerTestPrediction("r2-3", "d", "08:55", "", "")})
+	for _, plan := range departing {
+		if plan.ArriveBy != nil || plan.ArrivesLate {
+			t.Errorf("Expected no deadline, got %v (late %v)", plan.ArriveBy, plan.ArrivesLate)
+		}
+	}
+}
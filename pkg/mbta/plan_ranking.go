@@ -0,0 +1,107 @@
+package mbta
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Criteria trip plans can be ranked by
+const (
+	OptimizeFastest         = "fastest"          // Arrive soonest, or leave latest when arriving by a deadline
+	OptimizeFewestTransfers = "fewest_transfers" // Change as few times as possible
+	OptimizeLeastWalking    = "least_walking"    // Spend as little time on foot as possible
+	OptimizeAccessible      = "accessible"       // Only wheelchair accessible plans, fastest first
+)
+
+// OptimizeCriteria lists the criteria RankTripPlans accepts
+var OptimizeCriteria = []string{OptimizeFastest, OptimizeFewestTransfers, OptimizeLeastWalking, OptimizeAccessible}
+
+// RankTripPlans orders plans best first by a criterion, dropping plans that
+// aren't wheelchair accessible when ranking for accessibility. An empty
+// criterion ranks the fastest first.
+func RankTripPlans(plans []models.TripPlan, optimizeFor string) ([]models.TripPlan, error) {
+	if optimizeFor == "" {
+		optimizeFor = OptimizeFastest
+	}
+	if !IsOptimizeCriterion(optimizeFor) {
+		return nil, fmt.Errorf("unknown ranking %q (expected %s)", optimizeFor, strings.Join(OptimizeCriteria, ", "))
+	}
+
+	ranked := make([]models.TripPlan, 0, len(plans))
+	for _, plan := range plans {
+		if optimizeFor != OptimizeAccessible || plan.AccessibleTrip {
+			ranked = append(ranked, plan)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return BetterTripPlan(&ranked[i], &ranked[j], optimizeFor)
+	})
+	return ranked, nil
+}
+
+// BetterTripPlan returns whether plan a ranks ahead of plan b by a criterion.
+// Ties are broken by speed, then transfers, then walking.
+func BetterTripPlan(a, b *models.TripPlan, optimizeFor string) bool {
+	switch optimizeFor {
+	case OptimizeFewestTransfers:
+		if a.Transfers != b.Transfers {
+			return a.Transfers < b.Transfers
+		}
+	case OptimizeLeastWalking:
+		if wa, wb := a.WalkingTime(), b.WalkingTime(); wa != wb {
+			return wa < wb
+		}
+	case OptimizeAccessible:
+		if a.AccessibleTrip != b.AccessibleTrip {
+			return a.AccessibleTrip
+		}
+	}
+
+	if less, decided := fasterTripPlan(a, b); decided {
+		return less
+	}
+	if a.Transfers != b.Transfers {
+		return a.Transfers < b.Transfers
+	}
+	return a.WalkingTime() < b.WalkingTime()
+}
+
+// fasterTripPlan compares plans by when they arrive, then when they leave.
+// Plans arriving by a deadline are compared by when they leave first, since
+// riders want to leave as late as they can.
+func fasterTripPlan(a, b *models.TripPlan) (bool, bool) {
+	arrival := func() (bool, bool) {
+		if !a.ArrivalTime.Equal(b.ArrivalTime) {
+			return a.ArrivalTime.Before(b.ArrivalTime), true
+		}
+		return false, false
+	}
+	departure := func() (bool, bool) {
+		if !a.DepartureTime.Equal(b.DepartureTime) {
+			return a.DepartureTime.After(b.DepartureTime), true
+		}
+		return false, false
+	}
+
+	first, second := arrival, departure
+	if a.ArriveBy != nil {
+		first, second = departure, arrival
+	}
+	if less, decided := first(); decided {
+		return less, true
+	}
+	return second()
+}
+
+// IsOptimizeCriterion returns whether a ranking criterion is supported
+func IsOptimizeCriterion(optimizeFor string) bool {
+	for _, criterion := range OptimizeCriteria {
+		if criterion == optimizeFor {
+			return true
+		}
+	}
+	return false
+}
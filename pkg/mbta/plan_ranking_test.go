@@ -0,0 +1,96 @@
+package mbta
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// rankingTestPlan builds a plan named by its route, leaving and arriving at
+// the given times with minutes of walking
+func rankingTestPlan(routeID, leave, arrive string, transfers, walking int, accessible bool) models.TripPlan {
+	departure, arrival := plannerTestTime(leave), plannerTestTime(arrive)
+	plan := models.TripPlan{
+		DepartureTime:  departure,
+		ArrivalTime:    arrival,
+		Duration:       arrival.Sub(departure),
+		Transfers:      transfers,
+		AccessibleTrip: accessible,
+		Legs:           []models.TripLeg{{Mode: models.LegModeTransit, RouteID: routeID}},
+	}
+	if walking > 0 {
+		plan.Legs = append(plan.Legs, models.TripLeg{Mode: models.LegModeWalk, Duration: time.Duration(walking) * time.Minute})
+	}
+	return plan
+}
+
+// rankedRoutes lists ranked plans by route
+func rankedRoutes(plans []models.TripPlan) string {
+	routes := make([]string, len(plans))
+	for i := range plans {
+		routes[i] = plans[i].Legs[0].RouteID
+	}
+	return strings.Join(routes, ",")
+}
+
+func TestRankTripPlans(t *testing.T) {
+	plans := []models.TripPlan{
+		rankingTestPlan("direct", "08:05", "08:40", 0, 0, false),
+		rankingTestPlan("transfer", "08:00", "08:25", 1, 5, true),
+		rankingTestPlan("walk", "08:10", "08:30", 1, 2, true),
+		rankingTestPlan("later", "08:12", "08:40", 1, 0, true),
+	}
+
+	tests := []struct {
+		optimizeFor string
+		expected    string
+	}{
+		{"", "transfer,walk,later,direct"},
+		{OptimizeFastest, "transfer,walk,later,direct"},
+		{OptimizeFewestTransfers, "direct,transfer,walk,later"},
+		{OptimizeLeastWalking, "later,direct,walk,transfer"},
+		{OptimizeAccessible, "transfer,walk,later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.optimizeFor, func(t *testing.T) {
+			ranked, err := RankTripPlans(plans, tt.optimizeFor)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := rankedRoutes(ranked); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if rankedRoutes(plans) != "direct,transfer,walk,later" {
+		t.Errorf("Expected ranking to leave the plans alone, got %s", rankedRoutes(plans))
+	}
+
+	if _, err := RankTripPlans(plans, "scenic"); err == nil || !strings.Contains(err.Error(), "fewest_transfers") {
+		t.Errorf("Expected an error listing the criteria, got %v", err)
+	}
+}
+
+func TestRankTripPlansArriveBy(t *testing.T) {
+	deadline := plannerTestTime("08:45")
+	plans := []models.TripPlan{
+		rankingTestPlan("early", "08:00", "08:25", 1, 0, true),
+		rankingTestPlan("direct", "08:05", "08:40", 0, 0, false),
+		rankingTestPlan("latest", "08:12", "08:40", 1, 0, true),
+	}
+	for i := range plans {
+		plans[i].ArriveBy = &deadline
+	}
+
+	// Arriving by a deadline, the fastest plan is the one leaving latest
+	ranked, err := RankTripPlans(plans, OptimizeFastest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := rankedRoutes(ranked); got != "latest,direct,early" {
+		t.Errorf("Expected latest,direct,early, got %s", got)
+	}
+}
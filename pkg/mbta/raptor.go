@@ -0,0 +1,1121 @@
+package mbta
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Trip planner defaults
+const (
+	DefaultMinTransferTime = 2 * time.Minute // Used where no transfer point says otherwise
+	DefaultMaxTransfers    = 3               // Transfers allowed when the caller doesn't say
+	DefaultSearchWindow    = 3 * time.Hour   // Schedule data loaded after (or before) the requested time
+)
+
+// JourneyQuery describes a single trip planning search over a Timetable
+type JourneyQuery struct {
+	Origin            string    // Origin stop or station ID
+	Destination       string    // Destination stop or station ID
+	Time              time.Time // Earliest departure, or latest arrival when ArriveBy is set
+	ArriveBy          bool      // Search backward from Time instead of forward
+	MaxTransfers      int       // Transfers allowed; 0 means direct trips only
+	RequireAccessible bool      // Only use wheelchair accessible trips and stops
+
+	// Stations or stops riders can't board, alight or transfer at when
+	// RequireAccessible is set, such as those with an elevator outage
+	InaccessibleStops map[string]bool
+
+	// Service that isn't running, such as suspended segments or stops
+	// replaced by shuttle buses
+	Closures []Closure
+}
+
+// Closure is service riders can't use. A closure without a stop closes the
+// whole route, and one without a route closes the stop to every route. A
+// closed stop can't be boarded or gotten off at, but trains still run
+// through it unless Ride is set, as on a suspended segment. A OneWay closure
+// only applies to trips in DirectionID, such as a suspension of inbound
+// service.
+type Closure struct {
+	RouteID     string
+	StopID      string
+	DirectionID int
+	OneWay      bool
+	Ride        bool
+}
+
+// directions returns the closure keys that apply to trips in a direction: the
+// closure in both directions and in that one. Trips of unknown direction, -1,
+// are closed in either.
+func (c Closure) directions(directionID int) []Closure {
+	both := c
+	both.DirectionID, both.OneWay = 0, false
+	keys := []Closure{both}
+	for _, direction := range []int{0, 1} {
+		if directionID < 0 || directionID == direction {
+			oneWay := both
+			oneWay.DirectionID, oneWay.OneWay = direction, true
+			keys = append(keys, oneWay)
+		}
+	}
+	return keys
+}
+
+// closureSet looks up closed routes and stops, and whether trips can't run
+// through a closed stop either
+type closureSet map[Closure]bool
+
+// newClosureSet indexes closures for lookup during a search
+func newClosureSet(closures []Closure) closureSet {
+	set := make(closureSet, len(closures))
+	for _, closure := range closures {
+		if closure.RouteID == "" && closure.StopID == "" {
+			continue
+		}
+		key := Closure{RouteID: closure.RouteID, StopID: closure.StopID}
+		if closure.OneWay {
+			key.DirectionID, key.OneWay = closure.DirectionID, true
+		}
+		set[key] = set[key] || closure.Ride
+	}
+	return set
+}
+
+// closesRoute returns whether a pattern's whole route is closed
+func (c closureSet) closesRoute(pattern *routePattern) bool {
+	for _, key := range (Closure{RouteID: pattern.routeID}).directions(pattern.directionID) {
+		if _, closed := c[key]; closed {
+			return true
+		}
+	}
+	return false
+}
+
+// closesStop returns whether riders can't board or get off trips on a pattern
+// at a position
+func (c closureSet) closesStop(pattern *routePattern, i int) bool {
+	_, closed := c.lookup(pattern, i)
+	return closed
+}
+
+// closesRide returns whether trips on a pattern can't run through a position
+func (c closureSet) closesRide(pattern *routePattern, i int) bool {
+	ride, _ := c.lookup(pattern, i)
+	return ride
+}
+
+// lookup returns whether a position of a pattern is closed to riding through
+// and whether it's closed at all
+func (c closureSet) lookup(pattern *routePattern, i int) (bool, bool) {
+	ride, closed := false, false
+	for _, routeID := range []string{pattern.routeID, ""} {
+		for _, stopID := range []string{pattern.stops[i], pattern.nodes[i]} {
+			for _, key := range (Closure{RouteID: routeID, StopID: stopID}).directions(pattern.directionID) {
+				if closesRide, ok := c[key]; ok {
+					ride, closed = ride || closesRide, true
+				}
+			}
+		}
+	}
+	return ride, closed
+}
+
+// Timetable is an in-memory index of scheduled trips used for round-based
+// (RAPTOR) journey planning. Trips are grouped into route patterns that share
+// the same stop sequence, and platforms are grouped under their parent station
+// so that transfers between platforms of one station are possible.
+type Timetable struct {
+	stops     map[string]*models.Stop
+	routes    map[string]*models.Route
+	patterns  []*routePattern
+	byNode    map[string][]patternStop
+	transfers map[transferKey]models.TransferPoint
+	walksFrom map[string]map[string]Footpath // Station -> station walked to -> quickest footpath
+	walksTo   map[string]map[string]Footpath // Station -> station walked from -> quickest footpath
+}
+
+// routePattern is a set of trips on one route that visit the same stops in the same order
+type routePattern struct {
+	routeID     string
+	directionID int      // Direction of the pattern's trips, or -1 if unknown
+	stops       []string // Stop IDs in visiting order
+	nodes       []string // Station each stop belongs to
+	trips       []*timetableTrip
+}
+
+// timetableTrip holds the stop times of one trip along its pattern
+type timetableTrip struct {
+	trip       *models.Trip
+	id         string
+	arrivals   []time.Time
+	departures []time.Time
+	pickUp     []bool
+	dropOff    []bool
+}
+
+// patternStop locates a station within a route pattern
+type patternStop struct {
+	pattern  *routePattern
+	position int
+}
+
+// transferKey identifies a transfer rule; empty routes match any route
+type transferKey struct {
+	node      string
+	fromRoute string
+	toRoute   string
+}
+
+// journeyLabel is the best time found for a station in one search round
+type journeyLabel struct {
+	time time.Time
+	leg  *journeyLeg // Ride or walk that produced the label; nil at the search's starting point
+}
+
+// journeyLeg is a ride on one trip between two positions of its pattern, or
+// a walk between stations
+type journeyLeg struct {
+	pattern *routePattern
+	trip    *timetableTrip
+	board   int
+	alight  int
+	walk    *Footpath // Set instead of a ride for walks, between stations rather than stops
+}
+
+// stopTime is a single parsed schedule entry used while building a Timetable
+type stopTime struct {
+	stopID    string
+	sequence  int
+	arrival   time.Time
+	departure time.Time
+	pickUp    bool
+	dropOff   bool
+}
+
+// NewTimetable builds a timetable from schedules and their included trips,
+// routes and stops. Schedules should cover whole trips for the period being
+// searched; trips with fewer than two stop times are ignored.
+func NewTimetable(schedules []models.Schedule, included []models.Included) *Timetable {
+	index := models.NewIncludedIndex(included)
+
+	t := &Timetable{
+		stops:     make(map[string]*models.Stop),
+		routes:    make(map[string]*models.Route),
+		byNode:    make(map[string][]patternStop),
+		transfers: make(map[transferKey]models.TransferPoint),
+		walksFrom: make(map[string]map[string]Footpath),
+		walksTo:   make(map[string]map[string]Footpath),
+	}
+
+	for _, id := range index.IDs("stop") {
+		if stop, ok := index.Stop(id); ok {
+			t.stops[id] = stop
+		}
+	}
+	for _, id := range index.IDs("route") {
+		if route, ok := index.Route(id); ok {
+			t.routes[id] = route
+		}
+	}
+
+	// Group stop times by trip
+	tripStopTimes := make(map[string][]stopTime)
+	tripRoutes := make(map[string]string)
+	for _, schedule := range schedules {
+		tripID := schedule.GetTripID()
+		stopID := schedule.GetStopID()
+		if tripID == "" || stopID == "" {
+			continue
+		}
+
+		arrival, departure, ok := parseStopTimes(schedule.Attributes.ArrivalTime, schedule.Attributes.DepartureTime)
+		if !ok {
+			continue
+		}
+
+		tripStopTimes[tripID] = append(tripStopTimes[tripID], stopTime{
+			stopID:    stopID,
+			sequence:  schedule.Attributes.StopSequence,
+			arrival:   arrival,
+			departure: departure,
+			pickUp:    schedule.IsPickupAvailable(),
+			dropOff:   schedule.IsDropOffAvailable(),
+		})
+		if routeID := schedule.GetRouteID(); routeID != "" {
+			tripRoutes[tripID] = routeID
+		}
+	}
+
+	// Group trips into patterns by route and stop sequence
+	patterns := make(map[string]*routePattern)
+	tripIDs := make([]string, 0, len(tripStopTimes))
+	for tripID := range tripStopTimes {
+		tripIDs = append(tripIDs, tripID)
+	}
+	sort.Strings(tripIDs)
+
+	for _, tripID := range tripIDs {
+		times := tripStopTimes[tripID]
+		if len(times) < 2 {
+			continue
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].sequence < times[j].sequence })
+
+		trip, _ := index.Trip(tripID)
+		routeID := tripRoutes[tripID]
+		if routeID == "" && trip != nil {
+			routeID = trip.GetRouteID()
+		}
+
+		directionID := -1
+		if trip != nil {
+			directionID = trip.Attributes.Direction
+		}
+
+		stopIDs := make([]string, len(times))
+		for i, st := range times {
+			stopIDs[i] = st.stopID
+		}
+
+		key := fmt.Sprintf("%s|%d|%s", routeID, directionID, strings.Join(stopIDs, ","))
+		pattern, ok := patterns[key]
+		if !ok {
+			pattern = &routePattern{routeID: routeID, directionID: directionID, stops: stopIDs}
+			patterns[key] = pattern
+			t.patterns = append(t.patterns, pattern)
+		}
+
+		entry := &timetableTrip{
+			trip:       trip,
+			id:         tripID,
+			arrivals:   make([]time.Time, len(times)),
+			departures: make([]time.Time, len(times)),
+			pickUp:     make([]bool, len(times)),
+			dropOff:    make([]bool, len(times)),
+		}
+		for i, st := range times {
+			entry.arrivals[i] = st.arrival
+			entry.departures[i] = st.departure
+			entry.pickUp[i] = st.pickUp
+			entry.dropOff[i] = st.dropOff
+		}
+		pattern.trips = append(pattern.trips, entry)
+	}
+
+	for _, pattern := range t.patterns {
+		sort.SliceStable(pattern.trips, func(i, j int) bool {
+			return pattern.trips[i].departures[0].Before(pattern.trips[j].departures[0])
+		})
+	}
+
+	t.indexNodes()
+
+	return t
+}
+
+// AddStop registers a stop so its name, location and parent station are known,
+// for example an origin given by a station ID that no schedule references
+func (t *Timetable) AddStop(stop *models.Stop) {
+	if stop == nil || stop.ID == "" {
+		return
+	}
+	if _, ok := t.stops[stop.ID]; ok {
+		return
+	}
+	t.stops[stop.ID] = stop
+	t.indexNodes()
+}
+
+// AddTransfer sets the rules for changing between routes at a stop. A transfer
+// point with an empty FromRoute or ToRoute applies to any route. Timed transfers
+// need no connection time and TransferTypeNotPossible forbids the transfer.
+// A transfer point that walks to another stop adds a footpath between them.
+func (t *Timetable) AddTransfer(point models.TransferPoint) {
+	if point.Stop == nil {
+		return
+	}
+	if point.IsWalking() {
+		t.addWalk(Footpath{
+			FromStopID: point.Stop.ID,
+			ToStopID:   point.ToStop.ID,
+			Distance:   point.WalkDistance,
+			Duration:   point.MinTransferTime,
+			Source:     FootpathSourceTransfer,
+		})
+		return
+	}
+	key := transferKey{node: t.node(point.Stop.ID), fromRoute: point.FromRoute, toRoute: point.ToRoute}
+	t.transfers[key] = point
+}
+
+// AddFootpaths lets riders walk between stations along the given footpaths.
+// Where several footpaths connect the same two stations the quickest is used.
+func (t *Timetable) AddFootpaths(graph *FootpathGraph) {
+	if graph == nil {
+		return
+	}
+	for _, path := range graph.All() {
+		t.addWalk(path)
+	}
+}
+
+// AddNearbyFootpaths lets riders walk between stations with stops within
+// maxDistanceKm of each other that no footpath connects yet, estimating the
+// walk from their coordinates
+func (t *Timetable) AddNearbyFootpaths(maxDistanceKm float64) {
+	graph := NewFootpathGraph()
+	graph.AddNearbyStops(t.Stops(), maxDistanceKm)
+
+	connected := make(map[[2]string]bool)
+	for from, to := range t.walksFrom {
+		for node := range to {
+			connected[[2]string{from, node}] = true
+		}
+	}
+
+	for _, path := range graph.All() {
+		if !connected[[2]string{t.node(path.FromStopID), t.node(path.ToStopID)}] {
+			t.addWalk(path)
+		}
+	}
+}
+
+// AddAccessWalks connects a location that isn't a stop, such as a rider's
+// address, to the nearby stops riders can walk between it and: from the
+// location for the origin of a trip, or to it for the destination
+func (t *Timetable) AddAccessWalks(location *models.Stop, nearby []models.NearbyStation, fromLocation bool) {
+	t.AddStop(location)
+	for _, candidate := range nearby {
+		stop := candidate.Stop
+		t.AddStop(&stop)
+
+		path := Footpath{
+			FromStopID: location.ID,
+			ToStopID:   stop.ID,
+			Distance:   candidate.DistanceKm,
+			Duration:   WalkingTime(candidate.DistanceKm),
+			Source:     FootpathSourceCoordinates,
+		}
+		if !fromLocation {
+			path.FromStopID, path.ToStopID = stop.ID, location.ID
+		}
+		t.addWalk(path)
+	}
+}
+
+// Stops returns the stops the timetable knows about, ordered by ID
+func (t *Timetable) Stops() []models.Stop {
+	ids := make([]string, 0, len(t.stops))
+	for id := range t.stops {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	stops := make([]models.Stop, len(ids))
+	for i, id := range ids {
+		stops[i] = *t.stops[id]
+	}
+	return stops
+}
+
+// addWalk records a footpath between the stations of its stops, keeping the
+// quicker one if the stations are already connected
+func (t *Timetable) addWalk(path Footpath) {
+	from, to := t.node(path.FromStopID), t.node(path.ToStopID)
+	if from == to {
+		return
+	}
+	if existing, ok := t.walksFrom[from][to]; ok && existing.Duration <= path.Duration {
+		return
+	}
+
+	path.FromStopID, path.ToStopID = from, to
+	if t.walksFrom[from] == nil {
+		t.walksFrom[from] = make(map[string]Footpath)
+	}
+	if t.walksTo[to] == nil {
+		t.walksTo[to] = make(map[string]Footpath)
+	}
+	t.walksFrom[from][to] = path
+	t.walksTo[to][from] = path
+}
+
+// Plan searches the timetable and returns Pareto-optimal trip plans: each plan
+// either needs fewer transfers or reaches the destination sooner (leaves the
+// origin later for arrive-by queries) than every other. Plans are ordered from
+// fewest transfers to fastest. No plans are returned if the trip isn't possible.
+func (t *Timetable) Plan(query JourneyQuery) []models.TripPlan {
+	var journeys [][]journeyLeg
+	if query.ArriveBy {
+		journeys = t.searchBackward(query)
+	} else {
+		journeys = t.searchForward(query)
+	}
+
+	plans := make([]models.TripPlan, 0, len(journeys))
+	for _, legs := range journeys {
+		plans = append(plans, t.tripPlan(query, legs))
+	}
+	return plans
+}
+
+// PlanDepartures plans a trip and then the next departures after it, up to
+// count searches in all. Each search starts a minute after the earliest
+// departure found so far, or for arrive-by queries ends a minute before the
+// earliest arrival. Plans are returned in the order found, each only once.
+func (t *Timetable) PlanDepartures(query JourneyQuery, count int) []models.TripPlan {
+	plans := t.Plan(query)
+	seen := make(map[string]bool, len(plans))
+	for i := range plans {
+		seen[planKey(&plans[i])] = true
+	}
+
+	found := plans
+	for search := 1; search < count && len(found) > 0; search++ {
+		next := query
+		if query.ArriveBy {
+			next.Time = earliest(found, func(plan *models.TripPlan) time.Time { return plan.ArrivalTime }).Add(-time.Minute)
+		} else {
+			next.Time = earliest(found, func(plan *models.TripPlan) time.Time { return plan.DepartureTime }).Add(time.Minute)
+		}
+
+		found = t.Plan(next)
+		for _, plan := range found {
+			if key := planKey(&plan); !seen[key] {
+				seen[key] = true
+				plans = append(plans, plan)
+			}
+		}
+	}
+
+	return plans
+}
+
+// earliest returns the earliest of a time taken from each plan
+func earliest(plans []models.TripPlan, when func(plan *models.TripPlan) time.Time) time.Time {
+	first := when(&plans[0])
+	for i := range plans[1:] {
+		if t := when(&plans[i+1]); t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
+
+// planKey identifies a plan by the trips it rides and the walks between them
+func planKey(plan *models.TripPlan) string {
+	parts := make([]string, len(plan.Legs))
+	for i, leg := range plan.Legs {
+		if leg.IsWalk() {
+			parts[i] = "walk:" + leg.Origin.ID + ">" + leg.Destination.ID
+		} else {
+			parts[i] = leg.TripID + ":" + leg.Origin.ID + ">" + leg.Destination.ID
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// searchForward finds the earliest arrival at the destination for each number
+// of rides, starting no earlier than the query time
+func (t *Timetable) searchForward(query JourneyQuery) [][]journeyLeg {
+	origin := t.node(query.Origin)
+	destination := t.node(query.Destination)
+	maxRides := max(query.MaxTransfers, 0) + 1
+	closures := newClosureSet(query.Closures)
+
+	labels := []map[string]journeyLabel{{origin: {time: query.Time}}}
+	best := map[string]time.Time{origin: query.Time}
+	marked := map[string]bool{origin: true}
+
+	// Riders may walk to a nearby station to start, but not all the way
+	t.walkForward(labels[0], best, marked, destination)
+
+	var journeys [][]journeyLeg
+	for round := 1; round <= maxRides && len(marked) > 0; round++ {
+		previous := labels[round-1]
+		current := make(map[string]journeyLabel)
+		improved := make(map[string]bool)
+
+		// Scan each pattern from the earliest marked station it serves
+		for _, ps := range t.patternsFrom(marked, false) {
+			pattern := ps.pattern
+			if closures.closesRoute(pattern) {
+				continue
+			}
+
+			var ride *timetableTrip
+			boardAt := 0
+			for i := ps.position; i < len(pattern.stops); i++ {
+				node := pattern.nodes[i]
+
+				// Trips don't run through suspended stops, and riders can't
+				// get on or off at closed ones
+				if closures.closesRide(pattern, i) {
+					ride = nil
+					continue
+				}
+
+				usable := t.usable(pattern, i, query) && !closures.closesStop(pattern, i)
+
+				// Get off here if that improves the station's best arrival
+				if ride != nil && ride.dropOff[i] && i > boardAt && usable {
+					arrival := ride.arrivals[i]
+					bestHere, seen := best[node]
+					bestThere, reached := best[destination]
+					if (!seen || arrival.Before(bestHere)) && (!reached || arrival.Before(bestThere)) {
+						current[node] = journeyLabel{
+							time: arrival,
+							leg:  &journeyLeg{pattern: pattern, trip: ride, board: boardAt, alight: i},
+						}
+						best[node] = arrival
+						improved[node] = true
+					}
+				}
+
+				// Board here, or catch an earlier trip, if the station was reached last round
+				label, ok := previous[node]
+				if !ok || !usable {
+					continue
+				}
+				ready := label.time
+				if label.leg != nil && label.leg.walk == nil {
+					transfer, allowed := t.transferTime(node, label.leg.pattern.routeID, pattern.routeID)
+					if !allowed {
+						continue
+					}
+					ready = ready.Add(transfer)
+				}
+				if candidate := pattern.earliestTrip(i, ready, query.RequireAccessible); candidate != nil {
+					if ride == nil || candidate.departures[i].Before(ride.departures[i]) {
+						ride = candidate
+						boardAt = i
+					}
+				}
+			}
+		}
+
+		// Walk on from wherever the rides reached
+		t.walkForward(current, best, improved, "")
+
+		labels = append(labels, current)
+		marked = improved
+
+		if _, ok := current[destination]; ok {
+			journeys = append(journeys, backtrackForward(labels, round, destination))
+		}
+	}
+
+	return journeys
+}
+
+// walkForward extends the marked labels of a round along footpaths, marking
+// the stations reached sooner on foot. Walks start only where a ride (or the
+// search) did, and don't replace those labels, so they never follow each
+// other. Walking to the excluded station isn't allowed.
+func (t *Timetable) walkForward(labels map[string]journeyLabel, best map[string]time.Time, marked map[string]bool, excluded string) {
+	for _, from := range sortedNodes(marked) {
+		label := labels[from]
+		for _, path := range sortedWalks(t.walksFrom[from]) {
+			if path.ToStopID == excluded || marked[path.ToStopID] {
+				continue
+			}
+			arrival := label.time.Add(path.Duration)
+			if bestHere, seen := best[path.ToStopID]; seen && !arrival.Before(bestHere) {
+				continue
+			}
+
+			walk := path
+			labels[path.ToStopID] = journeyLabel{time: arrival, leg: &journeyLeg{walk: &walk}}
+			best[path.ToStopID] = arrival
+		}
+	}
+	for node, label := range labels {
+		if label.leg != nil && label.leg.walk != nil {
+			marked[node] = true
+		}
+	}
+}
+
+// walkBackward extends the marked labels of a backward round along footpaths
+// leading to them, marking the stations that can be left later on foot.
+// Walking from the excluded station isn't allowed.
+func (t *Timetable) walkBackward(labels map[string]journeyLabel, best map[string]time.Time, marked map[string]bool, excluded string) {
+	for _, to := range sortedNodes(marked) {
+		label := labels[to]
+		for _, path := range sortedWalks(t.walksTo[to]) {
+			if path.FromStopID == excluded || marked[path.FromStopID] {
+				continue
+			}
+			departure := label.time.Add(-path.Duration)
+			if bestHere, seen := best[path.FromStopID]; seen && !departure.After(bestHere) {
+				continue
+			}
+
+			walk := path
+			labels[path.FromStopID] = journeyLabel{time: departure, leg: &journeyLeg{walk: &walk}}
+			best[path.FromStopID] = departure
+		}
+	}
+	for node, label := range labels {
+		if label.leg != nil && label.leg.walk != nil {
+			marked[node] = true
+		}
+	}
+}
+
+// sortedNodes returns the marked stations in a stable order
+func sortedNodes(marked map[string]bool) []string {
+	nodes := make([]string, 0, len(marked))
+	for node := range marked {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// sortedWalks returns a station's footpaths quickest first
+func sortedWalks(walks map[string]Footpath) []Footpath {
+	paths := make([]Footpath, 0, len(walks))
+	for _, path := range walks {
+		paths = append(paths, path)
+	}
+	sortFootpaths(paths)
+	return paths
+}
+
+// searchBackward finds the latest departure from the origin for each number of
+// rides, arriving no later than the query time
+func (t *Timetable) searchBackward(query JourneyQuery) [][]journeyLeg {
+	origin := t.node(query.Origin)
+	destination := t.node(query.Destination)
+	maxRides := max(query.MaxTransfers, 0) + 1
+	closures := newClosureSet(query.Closures)
+
+	labels := []map[string]journeyLabel{{destination: {time: query.Time}}}
+	best := map[string]time.Time{destination: query.Time}
+	marked := map[string]bool{destination: true}
+
+	// Riders may walk from a nearby station to finish, but not all the way
+	t.walkBackward(labels[0], best, marked, origin)
+
+	var journeys [][]journeyLeg
+	for round := 1; round <= maxRides && len(marked) > 0; round++ {
+		next := labels[round-1]
+		current := make(map[string]journeyLabel)
+		improved := make(map[string]bool)
+
+		// Scan each pattern backward from the latest marked station it serves
+		for _, ps := range t.patternsFrom(marked, true) {
+			pattern := ps.pattern
+			if closures.closesRoute(pattern) {
+				continue
+			}
+
+			var ride *timetableTrip
+			alightAt := 0
+			for i := ps.position; i >= 0; i-- {
+				node := pattern.nodes[i]
+
+				// Trips don't run through suspended stops, and riders can't
+				// get on or off at closed ones
+				if closures.closesRide(pattern, i) {
+					ride = nil
+					continue
+				}
+
+				usable := t.usable(pattern, i, query) && !closures.closesStop(pattern, i)
+
+				// Board here if that improves the station's latest departure
+				if ride != nil && ride.pickUp[i] && i < alightAt && usable {
+					departure := ride.departures[i]
+					bestHere, seen := best[node]
+					bestThere, reached := best[origin]
+					if (!seen || departure.After(bestHere)) && (!reached || departure.After(bestThere)) {
+						current[node] = journeyLabel{
+							time: departure,
+							leg:  &journeyLeg{pattern: pattern, trip: ride, board: i, alight: alightAt},
+						}
+						best[node] = departure
+						improved[node] = true
+					}
+				}
+
+				// Get off here, or take a later trip, if the station leads on to the destination
+				label, ok := next[node]
+				if !ok || !usable {
+					continue
+				}
+				deadline := label.time
+				if label.leg != nil && label.leg.walk == nil {
+					transfer, allowed := t.transferTime(node, pattern.routeID, label.leg.pattern.routeID)
+					if !allowed {
+						continue
+					}
+					deadline = deadline.Add(-transfer)
+				}
+				if candidate := pattern.latestTrip(i, deadline, query.RequireAccessible); candidate != nil {
+					if ride == nil || candidate.arrivals[i].After(ride.arrivals[i]) {
+						ride = candidate
+						alightAt = i
+					}
+				}
+			}
+		}
+
+		// Walk back to wherever the rides can be boarded from
+		t.walkBackward(current, best, improved, "")
+
+		labels = append(labels, current)
+		marked = improved
+
+		if _, ok := current[origin]; ok {
+			journeys = append(journeys, backtrackBackward(labels, round, origin))
+		}
+	}
+
+	return journeys
+}
+
+// patternsFrom returns each pattern serving a marked station together with the
+// first marked position to scan from (the last one when scanning in reverse)
+func (t *Timetable) patternsFrom(marked map[string]bool, reverse bool) []patternStop {
+	positions := make(map[*routePattern]int)
+	var order []*routePattern
+
+	for node := range marked {
+		for _, ps := range t.byNode[node] {
+			position, seen := positions[ps.pattern]
+			if !seen {
+				order = append(order, ps.pattern)
+			}
+			if !seen || (!reverse && ps.position < position) || (reverse && ps.position > position) {
+				positions[ps.pattern] = ps.position
+			}
+		}
+	}
+
+	// Scan in a stable order so results don't depend on map iteration
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.routeID != b.routeID {
+			return a.routeID < b.routeID
+		}
+		return strings.Join(a.stops, ",") < strings.Join(b.stops, ",")
+	})
+
+	result := make([]patternStop, len(order))
+	for i, pattern := range order {
+		result[i] = patternStop{pattern: pattern, position: positions[pattern]}
+	}
+	return result
+}
+
+// earliestTrip returns the trip leaving position i soonest at or after ready
+func (p *routePattern) earliestTrip(i int, ready time.Time, requireAccessible bool) *timetableTrip {
+	var found *timetableTrip
+	for _, trip := range p.trips {
+		if !trip.pickUp[i] || trip.departures[i].Before(ready) {
+			continue
+		}
+		if requireAccessible && !trip.isAccessible() {
+			continue
+		}
+		if found == nil || trip.departures[i].Before(found.departures[i]) {
+			found = trip
+		}
+	}
+	return found
+}
+
+// latestTrip returns the trip reaching position i last at or before deadline
+func (p *routePattern) latestTrip(i int, deadline time.Time, requireAccessible bool) *timetableTrip {
+	var found *timetableTrip
+	for _, trip := range p.trips {
+		if !trip.dropOff[i] || trip.arrivals[i].After(deadline) {
+			continue
+		}
+		if requireAccessible && !trip.isAccessible() {
+			continue
+		}
+		if found == nil || trip.arrivals[i].After(found.arrivals[i]) {
+			found = trip
+		}
+	}
+	return found
+}
+
+// usable returns whether riders can get on or off at a position of a pattern.
+// Riders who need step-free access can't use stops known to be inaccessible
+// or those the query rules out.
+func (t *Timetable) usable(pattern *routePattern, i int, query JourneyQuery) bool {
+	if !query.RequireAccessible {
+		return true
+	}
+	if query.InaccessibleStops[pattern.stops[i]] || query.InaccessibleStops[pattern.nodes[i]] {
+		return false
+	}
+	if stop, ok := t.stops[pattern.stops[i]]; ok && stop.Attributes.WheelchairBoarding == models.WheelchairBoardingInaccessible {
+		return false
+	}
+	return true
+}
+
+// isAccessible returns whether the trip is known to be wheelchair accessible
+func (tt *timetableTrip) isAccessible() bool {
+	return tt.trip != nil && tt.trip.IsWheelchairAccessible()
+}
+
+// transferTime returns the minimum time needed to change from one route to
+// another at a station and whether the transfer is allowed at all
+func (t *Timetable) transferTime(node, fromRoute, toRoute string) (time.Duration, bool) {
+	for _, key := range []transferKey{
+		{node, fromRoute, toRoute},
+		{node, fromRoute, ""},
+		{node, "", toRoute},
+		{node, "", ""},
+	} {
+		point, ok := t.transfers[key]
+		if !ok {
+			continue
+		}
+		switch point.TransferType {
+		case models.TransferTypeNotPossible:
+			return 0, false
+		case models.TransferTypeTimed:
+			return 0, true
+		}
+		if point.MinTransferTime > 0 {
+			return point.MinTransferTime, true
+		}
+		return DefaultMinTransferTime, true
+	}
+	return DefaultMinTransferTime, true
+}
+
+// node returns the station a stop belongs to: its parent station if it has one
+func (t *Timetable) node(stopID string) string {
+	if stop, ok := t.stops[stopID]; ok {
+		if parent := models.RelationshipID(stop.Relationships, "parent_station"); parent != "" {
+			return parent
+		}
+	}
+	return stopID
+}
+
+// indexNodes rebuilds the station lookup for every pattern
+func (t *Timetable) indexNodes() {
+	t.byNode = make(map[string][]patternStop)
+	for _, pattern := range t.patterns {
+		pattern.nodes = make([]string, len(pattern.stops))
+		for i, stopID := range pattern.stops {
+			node := t.node(stopID)
+			pattern.nodes[i] = node
+			t.byNode[node] = append(t.byNode[node], patternStop{pattern: pattern, position: i})
+		}
+	}
+}
+
+// backtrackForward follows labels from the destination back to the origin.
+// A walk continues from a label of the same round, a ride from the round before.
+func backtrackForward(labels []map[string]journeyLabel, round int, node string) []journeyLeg {
+	var legs []journeyLeg
+	for r := round; labels[r][node].leg != nil; {
+		leg := labels[r][node].leg
+		legs = append(legs, *leg)
+		if leg.walk != nil {
+			node = leg.walk.FromStopID
+			continue
+		}
+		node = leg.pattern.nodes[leg.board]
+		r--
+	}
+
+	for i, j := 0, len(legs)-1; i < j; i, j = i+1, j-1 {
+		legs[i], legs[j] = legs[j], legs[i]
+	}
+	return legs
+}
+
+// backtrackBackward follows labels from the origin forward to the destination
+func backtrackBackward(labels []map[string]journeyLabel, round int, node string) []journeyLeg {
+	var legs []journeyLeg
+	for r := round; labels[r][node].leg != nil; {
+		leg := labels[r][node].leg
+		legs = append(legs, *leg)
+		if leg.walk != nil {
+			node = leg.walk.ToStopID
+			continue
+		}
+		node = leg.pattern.nodes[leg.alight]
+		r--
+	}
+	return legs
+}
+
+// tripPlan converts the legs of a journey into a trip plan
+func (t *Timetable) tripPlan(query JourneyQuery, legs []journeyLeg) models.TripPlan {
+	plan := models.TripPlan{
+		Origin:         t.stop(query.Origin),
+		Destination:    t.stop(query.Destination),
+		Legs:           make([]models.TripLeg, len(legs)),
+		Transfers:      -1,
+		AccessibleTrip: true,
+	}
+
+	// Walks are timed and placed by the rides around them
+	for i, journeyLeg := range legs {
+		if journeyLeg.walk == nil {
+			plan.Legs[i] = t.tripLeg(journeyLeg)
+			plan.Transfers++
+		}
+	}
+	for i, journeyLeg := range legs {
+		if journeyLeg.walk != nil {
+			plan.Legs[i] = t.walkLeg(plan, i, *journeyLeg.walk)
+		}
+	}
+
+	for _, leg := range plan.Legs {
+		plan.TotalDistance += leg.Distance
+		plan.AccessibleTrip = plan.AccessibleTrip && leg.IsAccessible
+	}
+
+	plan.DepartureTime = plan.Legs[0].DepartureTime
+	plan.ArrivalTime = plan.Legs[len(plan.Legs)-1].ArrivalTime
+	plan.Duration = plan.ArrivalTime.Sub(plan.DepartureTime)
+	if query.ArriveBy {
+		deadline := query.Time
+		plan.ArriveBy = &deadline
+	}
+
+	return plan
+}
+
+// walkLeg converts a walk between the rides of a plan into a trip plan leg.
+// A walk to the first ride leaves just in time to catch it; others leave
+// when the previous ride arrives.
+func (t *Timetable) walkLeg(plan models.TripPlan, i int, walk Footpath) models.TripLeg {
+	leg := models.TripLeg{
+		Mode:         models.LegModeWalk,
+		Origin:       plan.Origin,
+		Destination:  plan.Destination,
+		Duration:     walk.Duration,
+		Distance:     walk.Distance,
+		IsAccessible: true,
+	}
+
+	if i > 0 {
+		leg.Origin = plan.Legs[i-1].Destination
+		leg.DepartureTime = plan.Legs[i-1].ArrivalTime
+		leg.ArrivalTime = leg.DepartureTime.Add(walk.Duration)
+	}
+	if i < len(plan.Legs)-1 {
+		leg.Destination = plan.Legs[i+1].Origin
+		if i == 0 {
+			leg.ArrivalTime = plan.Legs[i+1].DepartureTime
+			leg.DepartureTime = leg.ArrivalTime.Add(-walk.Duration)
+		}
+	}
+
+	leg.Instructions = fmt.Sprintf("Walk from %s to %s (about %d min)",
+		leg.Origin.Attributes.Name, leg.Destination.Attributes.Name, walkingMinutes(walk.Duration))
+
+	return leg
+}
+
+// walkingMinutes rounds a walk up to whole minutes
+func walkingMinutes(d time.Duration) int {
+	return int((d + time.Minute - 1) / time.Minute)
+}
+
+// tripLeg converts a ride into a trip plan leg
+func (t *Timetable) tripLeg(ride journeyLeg) models.TripLeg {
+	pattern := ride.pattern
+	origin := t.stop(pattern.stops[ride.board])
+	destination := t.stop(pattern.stops[ride.alight])
+
+	routeName := pattern.routeID
+	if route, ok := t.routes[pattern.routeID]; ok {
+		routeName = route.Attributes.LongName
+		if routeName == "" {
+			routeName = route.Attributes.ShortName
+		}
+	}
+
+	leg := models.TripLeg{
+		Mode:          models.LegModeTransit,
+		Origin:        origin,
+		Destination:   destination,
+		RouteID:       pattern.routeID,
+		RouteName:     routeName,
+		TripID:        ride.trip.id,
+		DepartureTime: ride.trip.departures[ride.board],
+		ArrivalTime:   ride.trip.arrivals[ride.alight],
+		IsAccessible:  ride.trip.isAccessible(),
+	}
+	leg.Duration = leg.ArrivalTime.Sub(leg.DepartureTime)
+
+	if ride.trip.trip != nil {
+		leg.Headsign = ride.trip.trip.Attributes.Headsign
+		leg.DirectionID = ride.trip.trip.Attributes.Direction
+	}
+
+	// Follow the stops along the way to approximate the distance travelled
+	for i := ride.board + 1; i <= ride.alight; i++ {
+		from := t.stop(pattern.stops[i-1])
+		to := t.stop(pattern.stops[i])
+		if from.Attributes.Latitude != 0 && to.Attributes.Latitude != 0 {
+			leg.Distance += calculateApproximateDistance(
+				from.Attributes.Latitude, from.Attributes.Longitude,
+				to.Attributes.Latitude, to.Attributes.Longitude,
+			)
+		}
+		if i < ride.alight {
+			leg.Stops = append(leg.Stops, *to)
+		}
+	}
+
+	if leg.Headsign != "" {
+		leg.Instructions = fmt.Sprintf("Board the %s toward %s at %s and get off at %s",
+			routeName, leg.Headsign, origin.Attributes.Name, destination.Attributes.Name)
+	} else {
+		leg.Instructions = fmt.Sprintf("Board the %s at %s and get off at %s",
+			routeName, origin.Attributes.Name, destination.Attributes.Name)
+	}
+
+	return leg
+}
+
+// stop returns a known stop, or a minimal placeholder if it isn't in the timetable
+func (t *Timetable) stop(stopID string) *models.Stop {
+	if stop, ok := t.stops[stopID]; ok {
+		return stop
+	}
+	return &models.Stop{
+		ID: stopID,
+		Attributes: models.StopAttributes{
+			Name: "Unknown Stop",
+		},
+	}
+}
+
+// parseStopTimes parses a schedule's arrival and departure times, using one
+// for the other at the first and last stops of a trip where only one is set
+func parseStopTimes(arrivalValue, departureValue string) (time.Time, time.Time, bool) {
+	arrival, arrivalErr := time.Parse(time.RFC3339, arrivalValue)
+	departure, departureErr := time.Parse(time.RFC3339, departureValue)
+
+	switch {
+	case arrivalErr != nil && departureErr != nil:
+		return time.Time{}, time.Time{}, false
+	case arrivalErr != nil:
+		arrival = departure
+	case departureErr != nil:
+		departure = arrival
+	}
+	return arrival, departure, true
+}
@@ -0,0 +1,976 @@
+package mbta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// plannerTestTrip describes a trip in the planner test network
+type plannerTestTrip struct {
+	id         string
+	route      string
+	accessible bool
+	stops      []string
+	times      []string // HH:MM on 2025-05-20, one per stop
+}
+
+// plannerTestTrips is a small network: the slow direct R3 from A to D, and R1
+// from A to station B with a change to R2 on another platform of B toward D
+var plannerTestTrips = []plannerTestTrip{
+	{id: "r1-1", route: "R1", accessible: true, stops: []string{"a", "b1"}, times: []string{"08:00", "08:10"}},
+	{id: "r1-2", route: "R1", accessible: true, stops: []string{"a", "b1"}, times: []string{"08:12", "08:22"}},
+	{id: "r2-1", route: "R2", accessible: true, stops: []string{"b2", "c", "d"}, times: []string{"08:11", "08:16", "08:21"}},
+	{id: "r2-2", route: "R2", accessible: true, stops: []string{"b2", "c", "d"}, times: []string{"08:15", "08:20", "08:25"}},
+	{id: "r2-3", route: "R2", accessible: true, stops: []string{"b2", "c", "d"}, times: []string{"08:30", "08:35", "08:40"}},
+	{id: "r3-1", route: "R3", accessible: false, stops: []string{"a", "d"}, times: []string{"08:05", "08:40"}},
+}
+
+// plannerTestTime returns a time on the test service day
+func plannerTestTime(hhmm string) time.Time {
+	t, err := time.Parse(time.RFC3339, "2025-05-20T"+hhmm+":00-04:00")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// relationship builds a to-one JSON:API relationship
+func relationship(resourceType, id string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{"id": id, "type": resourceType}}
+}
+
+// plannerTestStop describes a stop in the planner test network
+type plannerTestStop struct {
+	id, name, parent string
+	lat, lon         float64
+}
+
+// plannerTestStops are the stops of the test network; B is a station with a
+// platform for each of R1 and R2
+var plannerTestStops = []plannerTestStop{
+	{id: "a", name: "Stop A"},
+	{id: "b1", name: "Station B (R1)", parent: "place-b"},
+	{id: "b2", name: "Station B (R2)", parent: "place-b"},
+	{id: "c", name: "Stop C"},
+	{id: "d", name: "Stop D"},
+}
+
+// plannerTestData returns schedules and included resources for the test network
+func plannerTestData() ([]models.Schedule, []models.Included) {
+	return plannerTestNetwork(plannerTestTrips, plannerTestStops)
+}
+
+// plannerTestNetwork returns schedules and included resources for trips
+// between the given stops
+func plannerTestNetwork(trips []plannerTestTrip, stops []plannerTestStop) ([]models.Schedule, []models.Included) {
+	var schedules []models.Schedule
+	var included []models.Included
+	var routes []string
+	seen := make(map[string]bool)
+
+	for _, trip := range trips {
+		for i, stopID := range trip.stops {
+			schedules = append(schedules, models.Schedule{
+				ID:   fmt.Sprintf("schedule-%s-%d", trip.id, i),
+				Type: "schedule",
+				Attributes: models.ScheduleAttributes{
+					ArrivalTime:   "2025-05-20T" + trip.times[i] + ":00-04:00",
+					DepartureTime: "2025-05-20T" + trip.times[i] + ":00-04:00",
+					StopSequence:  i + 1,
+				},
+				Relationships: map[string]interface{}{
+					"route": relationship("route", trip.route),
+					"stop":  relationship("stop", stopID),
+					"trip":  relationship("trip", trip.id),
+				},
+			})
+		}
+
+		included = append(included, models.Included{
+			ID:   trip.id,
+			Type: "trip",
+			Attributes: map[string]interface{}{
+				"headsign":              "Toward " + trip.stops[len(trip.stops)-1],
+				"wheelchair_accessible": trip.accessible,
+			},
+			Relationships: map[string]interface{}{"route": relationship("route", trip.route)},
+		})
+
+		if !seen[trip.route] {
+			seen[trip.route] = true
+			routes = append(routes, trip.route)
+		}
+	}
+
+	for _, route := range routes {
+		included = append(included, models.Included{
+			ID:         route,
+			Type:       "route",
+			Attributes: map[string]interface{}{"long_name": "Route " + route},
+		})
+	}
+
+	for _, stop := range stops {
+		attributes := map[string]interface{}{"name": stop.name}
+		if stop.lat != 0 {
+			attributes["latitude"] = stop.lat
+			attributes["longitude"] = stop.lon
+		}
+		inc := models.Included{ID: stop.id, Type: "stop", Attributes: attributes}
+		if stop.parent != "" {
+			inc.Relationships = map[string]interface{}{"parent_station": relationship("stop", stop.parent)}
+		}
+		included = append(included, inc)
+	}
+
+	return schedules, included
+}
+
+func TestTimetablePlanDepartAfter(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	plans := timetable.Plan(JourneyQuery{
+		Origin:       "a",
+		Destination:  "d",
+		Time:         plannerTestTime("07:55"),
+		MaxTransfers: 3,
+	})
+
+	if len(plans) != 2 {
+		t.Fatalf("Expected 2 Pareto-optimal plans, got %d", len(plans))
+	}
+
+	direct := plans[0]
+	if direct.Transfers != 0 || len(direct.Legs) != 1 || direct.Legs[0].RouteID != "R3" {
+		t.Errorf("Expected the first plan to be the direct R3 trip, got %+v", direct)
+	}
+	if !direct.ArrivalTime.Equal(plannerTestTime("08:40")) {
+		t.Errorf("Expected direct arrival at 08:40, got %s", direct.ArrivalTime)
+	}
+
+	transfer := plans[1]
+	if transfer.Transfers != 1 || len(transfer.Legs) != 2 {
+		t.Fatalf("Expected the second plan to have one transfer, got %+v", transfer)
+	}
+	if transfer.Legs[0].TripID != "r1-1" || transfer.Legs[1].TripID != "r2-2" {
+		t.Errorf("Expected trips r1-1 and r2-2, got %s and %s", transfer.Legs[0].TripID, transfer.Legs[1].TripID)
+	}
+	if !transfer.ArrivalTime.Equal(plannerTestTime("08:25")) {
+		t.Errorf("Expected arrival at 08:25, got %s", transfer.ArrivalTime)
+	}
+	if transfer.Duration != 25*time.Minute {
+		t.Errorf("Expected duration 25m, got %s", transfer.Duration)
+	}
+
+	// The change happens between platforms of the same station
+	if transfer.Legs[0].Destination.ID != "b1" || transfer.Legs[1].Origin.ID != "b2" {
+		t.Errorf("Expected change from b1 to b2, got %s to %s", transfer.Legs[0].Destination.ID, transfer.Legs[1].Origin.ID)
+	}
+	if len(transfer.Legs[1].Stops) != 1 || transfer.Legs[1].Stops[0].ID != "c" {
+		t.Errorf("Expected intermediate stop c, got %+v", transfer.Legs[1].Stops)
+	}
+	if transfer.Legs[1].RouteName != "Route R2" || transfer.Legs[1].Headsign != "Toward d" {
+		t.Errorf("Unexpected route name or headsign: %q, %q", transfer.Legs[1].RouteName, transfer.Legs[1].Headsign)
+	}
+}
+
+func TestTimetablePlanTransferRules(t *testing.T) {
+	schedules, included := plannerTestData()
+	stationB := &models.Stop{ID: "place-b"}
+
+	t.Run("Minimum transfer time", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddTransfer(models.TransferPoint{
+			Stop:            stationB,
+			FromRoute:       "R1",
+			ToRoute:         "R2",
+			TransferType:    models.TransferTypeMinimum,
+			MinTransferTime: 6 * time.Minute,
+		})
+
+		// Missing the 08:15 connection means arriving no earlier than the direct trip
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 1 || plans[0].Transfers != 0 {
+			t.Errorf("Expected only the direct plan, got %d plans", len(plans))
+		}
+	})
+
+	t.Run("Timed transfer", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddTransfer(models.TransferPoint{Stop: stationB, TransferType: models.TransferTypeTimed})
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 2 || plans[1].Legs[1].TripID != "r2-1" {
+			t.Fatalf("Expected the timed transfer to catch r2-1, got %+v", plans)
+		}
+		if !plans[1].ArrivalTime.Equal(plannerTestTime("08:21")) {
+			t.Errorf("Expected arrival at 08:21, got %s", plans[1].ArrivalTime)
+		}
+	})
+
+	t.Run("Transfer not possible", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddTransfer(models.TransferPoint{Stop: stationB, TransferType: models.TransferTypeNotPossible})
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 1 || plans[0].Transfers != 0 {
+			t.Errorf("Expected only the direct plan, got %d plans", len(plans))
+		}
+	})
+
+	t.Run("Direct trips only", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 0})
+		if len(plans) != 1 || plans[0].Legs[0].RouteID != "R3" {
+			t.Errorf("Expected only the direct plan, got %d plans", len(plans))
+		}
+	})
+
+	t.Run("Wheelchair accessible", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, RequireAccessible: true})
+		if len(plans) != 1 || plans[0].Transfers != 1 || !plans[0].AccessibleTrip {
+			t.Errorf("Expected only the accessible transfer plan, got %+v", plans)
+		}
+	})
+
+	t.Run("No trip", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("09:00"), MaxTransfers: 3})
+		if len(plans) != 0 {
+			t.Errorf("Expected no plans after the last trip, got %d", len(plans))
+		}
+	})
+}
+
+func TestTimetablePlanArriveBy(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	plans := timetable.Plan(JourneyQuery{
+		Origin:       "a",
+		Destination:  "d",
+		Time:         plannerTestTime("08:45"),
+		ArriveBy:     true,
+		MaxTransfers: 3,
+	})
+
+	if len(plans) != 2 {
+		t.Fatalf("Expected 2 Pareto-optimal plans, got %d", len(plans))
+	}
+
+	if plans[0].Transfers != 0 || !plans[0].DepartureTime.Equal(plannerTestTime("08:05")) {
+		t.Errorf("Expected the direct trip leaving at 08:05, got %d transfers leaving %s", plans[0].Transfers, plans[0].DepartureTime)
+	}
+
+	// The transfer plan leaves later and still arrives by the deadline
+	latest := plans[1]
+	if latest.Transfers != 1 || !latest.DepartureTime.Equal(plannerTestTime("08:12")) {
+		t.Errorf("Expected a transfer plan leaving at 08:12, got %d transfers leaving %s", latest.Transfers, latest.DepartureTime)
+	}
+	if latest.Legs[0].TripID != "r1-2" || latest.Legs[1].TripID != "r2-3" {
+		t.Errorf("Expected trips r1-2 and r2-3, got %s and %s", latest.Legs[0].TripID, latest.Legs[1].TripID)
+	}
+	if latest.ArrivalTime.After(plannerTestTime("08:45")) {
+		t.Errorf("Expected arrival by 08:45, got %s", latest.ArrivalTime)
+	}
+	if latest.ArriveBy == nil || !latest.ArriveBy.Equal(plannerTestTime("08:45")) {
+		t.Errorf("Expected plans to record the 08:45 deadline, got %v", latest.ArriveBy)
+	}
+}
+
+func TestTimetablePlanDepartures(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	// The second search only finds the direct trip again, so the third
+	// starts after it
+	plans := timetable.PlanDepartures(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3}, 3)
+	if len(plans) != 3 {
+		t.Fatalf("Expected 3 distinct plans, got %d", len(plans))
+	}
+	if latest := plans[2]; latest.Legs[0].TripID != "r1-2" || latest.Legs[1].TripID != "r2-3" {
+		t.Errorf("Expected the next departure to ride r1-2 and r2-3, got %s and %s", latest.Legs[0].TripID, latest.Legs[len(latest.Legs)-1].TripID)
+	}
+
+	// Searching back from a deadline finds earlier departures
+	plans = timetable.PlanDepartures(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("08:45"), ArriveBy: true, MaxTransfers: 3}, 2)
+	if len(plans) != 3 {
+		t.Fatalf("Expected 3 distinct plans, got %d", len(plans))
+	}
+	if earlier := plans[2]; earlier.Legs[0].TripID != "r1-1" || !earlier.ArrivalTime.Before(plannerTestTime("08:40")) {
+		t.Errorf("Expected an earlier plan riding r1-1, got %+v", earlier)
+	}
+
+	// A single search is the same as planning
+	if plans := timetable.PlanDepartures(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3}, 1); len(plans) != 2 {
+		t.Errorf("Expected the 2 plans of one search, got %d", len(plans))
+	}
+}
+
+// plannerTestHandler serves the test network's stops, routes and schedules,
+// recording the query of the last schedule request
+func plannerTestHandler(t *testing.T, schedules []models.Schedule, included []models.Included, scheduleRequest *string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/stops/"):
+			id := strings.TrimPrefix(r.URL.Path, "/stops/")
+			_, _ = fmt.Fprintf(w, `{"data": {"id": %q, "type": "stop", "attributes": {"name": "Stop %s"}}}`, id, strings.ToUpper(id))
+
+		case r.URL.Path == "/routes":
+			if got := r.URL.Query().Get("filter[type]"); got != "0,1" {
+				t.Errorf("Expected rapid transit route filter 0,1, got %s", got)
+			}
+			_, _ = fmt.Fprint(w, `{"data": [{"id": "R2", "type": "route", "attributes": {"type": 1}}]}`)
+
+		case r.URL.Path == "/schedules" && r.URL.Query().Get("filter[stop]") != "":
+			// Routes serving a stop
+			routes := map[string]string{"a": "R1,R3", "d": "R2,R3"}[r.URL.Query().Get("filter[stop]")]
+			var data []string
+			for _, route := range strings.Split(routes, ",") {
+				data = append(data, fmt.Sprintf(`{"id": %q, "type": "route"}`, route))
+			}
+			_, _ = fmt.Fprintf(w, `{"data": [], "included": [%s]}`, strings.Join(data, ","))
+
+		case r.URL.Path == "/schedules":
+			*scheduleRequest = r.URL.RawQuery
+			body := map[string]interface{}{"data": schedules, "included": included}
+			if err := json.NewEncoder(w).Encode(body); err != nil {
+				t.Errorf("Failed to encode schedules: %v", err)
+			}
+
+		default:
+			// Stops served by a route, used to find transfer points
+			_, _ = fmt.Fprint(w, `{"data": []}`)
+		}
+	}
+}
+
+func TestPlanTrips(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	var scheduleRequest string
+	server := httptest.NewServer(plannerTestHandler(t, schedules, included, &scheduleRequest))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	plans, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), map[string]interface{}{
+		"max_transfers": 2,
+	})
+	if err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+
+	if len(plans) != 2 {
+		t.Fatalf("Expected 2 plans, got %d", len(plans))
+	}
+	if plans[0].Origin.Attributes.Name != "Stop A" || plans[1].Destination.Attributes.Name != "Stop D" {
+		t.Errorf("Expected plans to use the requested stops, got %s and %s", plans[0].Origin.Attributes.Name, plans[1].Destination.Attributes.Name)
+	}
+
+	for _, want := range []string{"filter%5Broute%5D=R1%2CR2%2CR3", "filter%5Bmin_time%5D=07%3A55", "filter%5Bmax_time%5D=10%3A55"} {
+		if !strings.Contains(scheduleRequest, want) {
+			t.Errorf("Expected schedule request to contain %s, got %s", want, scheduleRequest)
+		}
+	}
+
+	fastest, err := client.PlanTrip(context.Background(), "a", "d", plannerTestTime("07:55"), nil)
+	if err != nil {
+		t.Fatalf("PlanTrip returned an error: %v", err)
+	}
+	if !fastest.ArrivalTime.Equal(plannerTestTime("08:25")) {
+		t.Errorf("Expected the fastest plan to arrive at 08:25, got %s", fastest.ArrivalTime)
+	}
+}
+
+func TestPlanTripsRapidTransit(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	var scheduleRequest string
+	routeRequests := 0
+	separate := false
+	planner := plannerTestHandler(t, schedules, included, &scheduleRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/routes" {
+			routeRequests++
+		}
+		// Without R3, nothing serves both ends and they share no stop
+		if stop := r.URL.Query().Get("filter[stop]"); separate && r.URL.Path == "/schedules" && stop != "" {
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			route := map[string]string{"a": "R1", "d": "R2"}[stop]
+			_, _ = fmt.Fprintf(w, `{"data": [], "included": [{"id": %q, "type": "route"}]}`, route)
+			return
+		}
+		planner(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	// R3 runs from A to D, so the rapid transit network isn't needed
+	if _, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), nil); err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+	if routeRequests != 0 {
+		t.Errorf("Expected rapid transit routes not to be loaded, got %d requests", routeRequests)
+	}
+
+	separate = true
+	if _, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), nil); err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+	if routeRequests != 1 {
+		t.Errorf("Expected rapid transit routes to be loaded once, got %d requests", routeRequests)
+	}
+}
+
+func TestPlanTripsServiceDay(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	var scheduleRequest string
+	server := httptest.NewServer(plannerTestHandler(t, schedules, included, &scheduleRequest))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	// 11:55 UTC is 07:55 in Boston, whatever zone the caller uses
+	plans, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55").UTC(), nil)
+	if err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+	if len(plans) == 0 {
+		t.Fatal("Expected plans from a UTC departure time")
+	}
+	for _, want := range []string{"filter%5Bdate%5D=2025-05-20", "filter%5Bmin_time%5D=07%3A55"} {
+		if !strings.Contains(scheduleRequest, want) {
+			t.Errorf("Expected schedule request to contain %s, got %s", want, scheduleRequest)
+		}
+	}
+}
+
+func TestPlanTripsAcrossServiceDays(t *testing.T) {
+	// The test network's trips, run at 05:00 on the next service day
+	schedules, included := plannerTestData()
+	morning := make([]models.Schedule, len(schedules))
+	for i, schedule := range schedules {
+		departure, err := time.Parse(time.RFC3339, schedule.Attributes.DepartureTime)
+		if err != nil {
+			t.Fatalf("Invalid test schedule: %v", err)
+		}
+		schedule.Attributes.ArrivalTime = departure.Add(21 * time.Hour).Format(time.RFC3339)
+		schedule.Attributes.DepartureTime = schedule.Attributes.ArrivalTime
+		morning[i] = schedule
+	}
+
+	var scheduleRequest string
+	var requests []string
+	late := plannerTestHandler(t, nil, included, &scheduleRequest)
+	early := plannerTestHandler(t, morning, included, &scheduleRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/schedules" || query.Get("filter[stop]") != "" {
+			early(w, r)
+			return
+		}
+		requests = append(requests, fmt.Sprintf("%s %s-%s", query.Get("filter[date]"), query.Get("filter[min_time]"), query.Get("filter[max_time]")))
+		if query.Get("filter[date]") == "2025-05-21" {
+			early(w, r)
+		} else {
+			late(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	// At 02:30, the last trips of the previous service day are running and
+	// the next day's first trips are a few hours away
+	at := plannerTestTime("02:30").Add(24 * time.Hour).UTC()
+	plans, err := client.PlanTrips(context.Background(), "a", "d", at, nil)
+	if err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+
+	expected := []string{"2025-05-20 26:30-29:30", "2025-05-21 00:00-05:30"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected schedule requests %v, got %v", expected, requests)
+	}
+	first := plannerTestTime("05:00").Add(24 * time.Hour)
+	found := false
+	for _, plan := range plans {
+		found = found || plan.DepartureTime.Equal(first)
+	}
+	if !found {
+		t.Errorf("Expected a plan leaving at 05:00 on the next service day, got %+v", plans)
+	}
+
+	// Ending before the next day starts, only the running day is loaded
+	requests = nil
+	_, _ = client.PlanTrips(context.Background(), "a", "d", plannerTestTime("23:30").UTC(), nil)
+	if expected := "2025-05-20 23:30-26:30"; strings.Join(requests, ",") != expected {
+		t.Errorf("Expected schedule requests %s, got %v", expected, requests)
+	}
+}
+
+func TestTimetablePlanInaccessibleStops(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	outage := map[string]bool{"place-b": true}
+
+	t.Run("Ignored unless accessibility is required", func(t *testing.T) {
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, InaccessibleStops: outage})
+		if len(plans) != 2 {
+			t.Errorf("Expected 2 plans, got %d", len(plans))
+		}
+	})
+
+	t.Run("No transfer at the station", func(t *testing.T) {
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, RequireAccessible: true, InaccessibleStops: outage})
+		if len(plans) != 0 {
+			t.Errorf("Expected no accessible plans, got %+v", plans)
+		}
+	})
+
+	t.Run("Arrive by", func(t *testing.T) {
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("08:45"), ArriveBy: true, MaxTransfers: 3, RequireAccessible: true, InaccessibleStops: outage})
+		if len(plans) != 0 {
+			t.Errorf("Expected no accessible plans, got %+v", plans)
+		}
+	})
+
+	t.Run("Platform", func(t *testing.T) {
+		// Riders can get off at B but can't board R2 there
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, RequireAccessible: true, InaccessibleStops: map[string]bool{"b2": true}})
+		if len(plans) != 0 {
+			t.Errorf("Expected no accessible plans, got %+v", plans)
+		}
+
+		plans = timetable.Plan(JourneyQuery{Origin: "a", Destination: "b1", Time: plannerTestTime("07:55"), MaxTransfers: 3, RequireAccessible: true, InaccessibleStops: map[string]bool{"b2": true}})
+		if len(plans) != 1 || plans[0].Legs[0].RouteID != "R1" {
+			t.Errorf("Expected a plan to B on R1, got %+v", plans)
+		}
+	})
+
+	t.Run("Stop without wheelchair boarding", func(t *testing.T) {
+		included := append([]models.Included(nil), included...)
+		for i := range included {
+			if included[i].ID == "b2" {
+				included[i].Attributes = map[string]interface{}{"name": "Station B (R2)", "wheelchair_boarding": models.WheelchairBoardingInaccessible}
+				included[i].Relationships = map[string]interface{}{"parent_station": relationship("stop", "place-b")}
+			}
+		}
+		timetable := NewTimetable(schedules, included)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, RequireAccessible: true})
+		if len(plans) != 0 {
+			t.Errorf("Expected no accessible plans, got %+v", plans)
+		}
+	})
+}
+
+func TestTimetablePlanClosures(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	tests := []struct {
+		name     string
+		closures []Closure
+		arriveBy bool
+		routes   []string // Routes of each plan's first leg
+	}{
+		{name: "Stop on a route", closures: []Closure{{RouteID: "R2", StopID: "c", Ride: true}}, routes: []string{"R3"}},
+		{name: "Stop on a route arriving by", closures: []Closure{{RouteID: "R2", StopID: "c", Ride: true}}, arriveBy: true, routes: []string{"R3"}},
+		{name: "Passing through a closed stop", closures: []Closure{{RouteID: "R2", StopID: "c"}}, routes: []string{"R3", "R1"}},
+		{name: "Passing through a closed stop arriving by", closures: []Closure{{RouteID: "R2", StopID: "c"}}, arriveBy: true, routes: []string{"R3", "R1"}},
+		{name: "Getting off at a closed stop", closures: []Closure{{RouteID: "R2", StopID: "d"}}, routes: []string{"R3"}},
+		{name: "Stop on another route", closures: []Closure{{RouteID: "R1", StopID: "c"}}, routes: []string{"R3", "R1"}},
+		{name: "Whole route", closures: []Closure{{RouteID: "R3"}}, routes: []string{"R1"}},
+		{name: "Whole route in the other direction", closures: []Closure{{RouteID: "R3", DirectionID: 1, OneWay: true}}, routes: []string{"R3", "R1"}},
+		{name: "Stop in the trip's direction", closures: []Closure{{RouteID: "R2", StopID: "c", DirectionID: 0, OneWay: true, Ride: true}}, routes: []string{"R3"}},
+		{name: "Stop in the other direction", closures: []Closure{{RouteID: "R2", StopID: "c", DirectionID: 1, OneWay: true, Ride: true}}, routes: []string{"R3", "R1"}},
+		{name: "Station", closures: []Closure{{StopID: "place-b"}}, routes: []string{"R3"}},
+		{name: "Everything", closures: []Closure{{RouteID: "R3"}, {StopID: "b1"}}, routes: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := JourneyQuery{Origin: "a", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3, Closures: tt.closures}
+			if tt.arriveBy {
+				query.ArriveBy = true
+				query.Time = plannerTestTime("08:45")
+			}
+
+			plans := timetable.Plan(query)
+			routes := make([]string, len(plans))
+			for i, plan := range plans {
+				routes[i] = plan.Legs[0].RouteID
+			}
+			if strings.Join(routes, ",") != strings.Join(tt.routes, ",") {
+				t.Errorf("Expected plans starting on %v, got %v", tt.routes, routes)
+			}
+		})
+	}
+}
+
+// footpathTestData extends the test network with R4 from E to F, which
+// shares no stop with it: E is a short walk from station B, and G a short
+// walk from A
+func TestPlanTripsArriveBy(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	var scheduleRequest string
+	server := httptest.NewServer(plannerTestHandler(t, schedules, included, &scheduleRequest))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	// The 9am meeting is at D; riders can leave A as late as 08:12
+	latest, err := client.PlanTrip(context.Background(), "a", "d", plannerTestTime("09:00"), map[string]interface{}{
+		"arrive_by": true,
+	})
+	if err != nil {
+		t.Fatalf("PlanTrip returned an error: %v", err)
+	}
+	if !latest.DepartureTime.Equal(plannerTestTime("08:12")) || latest.ArrivalTime.After(plannerTestTime("09:00")) {
+		t.Errorf("Expected to leave at 08:12 and arrive by 09:00, got %s to %s", latest.DepartureTime, latest.ArrivalTime)
+	}
+	if latest.ArriveBy == nil || !latest.ArriveBy.Equal(plannerTestTime("09:00")) {
+		t.Errorf("Expected the plan to record the deadline, got %v", latest.ArriveBy)
+	}
+
+	// Schedules are loaded for the hours before the deadline
+	for _, want := range []string{"filter%5Bmin_time%5D=06%3A00", "filter%5Bmax_time%5D=09%3A00"} {
+		if !strings.Contains(scheduleRequest, want) {
+			t.Errorf("Expected schedule request to contain %s, got %s", want, scheduleRequest)
+		}
+	}
+}
+
+func footpathTestData() ([]models.Schedule, []models.Included) {
+	trips := append([]plannerTestTrip{
+		{id: "r4-1", route: "R4", accessible: true, stops: []string{"e", "f"}, times: []string{"08:12", "08:26"}},
+		{id: "r4-2", route: "R4", accessible: true, stops: []string{"e", "f"}, times: []string{"08:16", "08:30"}},
+		{id: "r4-3", route: "R4", accessible: true, stops: []string{"e", "f"}, times: []string{"08:40", "08:54"}},
+	}, plannerTestTrips...)
+
+	stops := []plannerTestStop{
+		{id: "a", name: "Stop A"},
+		{id: "b1", name: "Station B (R1)", parent: "place-b", lat: 42.3564, lon: -71.0624},
+		{id: "b2", name: "Station B (R2)", parent: "place-b", lat: 42.3564, lon: -71.0624},
+		{id: "c", name: "Stop C"},
+		{id: "d", name: "Stop D"},
+		{id: "e", name: "Stop E", lat: 42.3555, lon: -71.0602},
+		{id: "f", name: "Stop F"},
+		{id: "g", name: "Stop G"},
+	}
+
+	return plannerTestNetwork(trips, stops)
+}
+
+func TestTimetablePlanFootpaths(t *testing.T) {
+	schedules, included := footpathTestData()
+	graph := NewFootpathGraph()
+	graph.Add(Footpath{FromStopID: "b1", ToStopID: "e", Distance: 0.3, Duration: 4 * time.Minute, Source: FootpathSourceTransfer})
+	graph.Add(Footpath{FromStopID: "g", ToStopID: "a", Distance: 0.2, Duration: 3 * time.Minute, Source: FootpathSourceTransfer})
+
+	t.Run("Without footpaths", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "f", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 0 {
+			t.Errorf("Expected no plans between routes without a shared stop, got %d", len(plans))
+		}
+	})
+
+	t.Run("Walk between rides", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddFootpaths(graph)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "f", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 1 || len(plans[0].Legs) != 3 {
+			t.Fatalf("Expected one plan with a walk between two rides, got %+v", plans)
+		}
+
+		plan := plans[0]
+		walk := plan.Legs[1]
+		if !walk.IsWalk() || walk.Origin.ID != "b1" || walk.Destination.ID != "e" {
+			t.Errorf("Expected a walk from b1 to e, got %s leg from %s to %s", walk.Mode, walk.Origin.ID, walk.Destination.ID)
+		}
+		if !walk.DepartureTime.Equal(plannerTestTime("08:10")) || !walk.ArrivalTime.Equal(plannerTestTime("08:14")) {
+			t.Errorf("Expected the walk from 08:10 to 08:14, got %s to %s", walk.DepartureTime, walk.ArrivalTime)
+		}
+		if walk.Instructions != "Walk from Station B (R1) to Stop E (about 4 min)" {
+			t.Errorf("Unexpected instructions %q", walk.Instructions)
+		}
+
+		// The 08:12 trip leaves before riders can walk over
+		if plan.Legs[0].TripID != "r1-1" || plan.Legs[2].TripID != "r4-2" || plan.Legs[2].Mode != models.LegModeTransit {
+			t.Errorf("Expected trips r1-1 and r4-2, got %s and %s", plan.Legs[0].TripID, plan.Legs[2].TripID)
+		}
+		if plan.Transfers != 1 {
+			t.Errorf("Expected 1 transfer, got %d", plan.Transfers)
+		}
+		if !plan.ArrivalTime.Equal(plannerTestTime("08:30")) {
+			t.Errorf("Expected arrival at 08:30, got %s", plan.ArrivalTime)
+		}
+	})
+
+	t.Run("Arrive by", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddFootpaths(graph)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "f", Time: plannerTestTime("08:35"), ArriveBy: true, MaxTransfers: 3})
+		if len(plans) != 1 || len(plans[0].Legs) != 3 {
+			t.Fatalf("Expected one plan with a walk between two rides, got %+v", plans)
+		}
+		if !plans[0].Legs[1].IsWalk() || plans[0].Legs[0].TripID != "r1-1" || plans[0].Legs[2].TripID != "r4-2" {
+			t.Errorf("Expected r1-1, a walk and r4-2, got %+v", plans[0].Legs)
+		}
+	})
+
+	t.Run("Walk to the first ride", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddFootpaths(graph)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "g", Destination: "d", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 2 {
+			t.Fatalf("Expected 2 plans, got %d", len(plans))
+		}
+
+		direct := plans[0]
+		if direct.Transfers != 0 || len(direct.Legs) != 2 || !direct.Legs[0].IsWalk() {
+			t.Fatalf("Expected a walk to the direct trip, got %+v", direct)
+		}
+
+		// Riders leave just in time to catch the ride
+		walk := direct.Legs[0]
+		if walk.Origin.ID != "g" || walk.Destination.ID != "a" || !walk.DepartureTime.Equal(plannerTestTime("08:02")) {
+			t.Errorf("Expected a walk from g to a leaving 08:02, got %s to %s leaving %s", walk.Origin.ID, walk.Destination.ID, walk.DepartureTime)
+		}
+		if !direct.DepartureTime.Equal(plannerTestTime("08:02")) {
+			t.Errorf("Expected the plan to leave at 08:02, got %s", direct.DepartureTime)
+		}
+	})
+
+	t.Run("Nearby stops", func(t *testing.T) {
+		timetable := NewTimetable(schedules, included)
+		timetable.AddNearbyFootpaths(DefaultMaxWalkDistance)
+
+		plans := timetable.Plan(JourneyQuery{Origin: "a", Destination: "f", Time: plannerTestTime("07:55"), MaxTransfers: 3})
+		if len(plans) != 1 || len(plans[0].Legs) != 3 {
+			t.Fatalf("Expected one plan with a walk between two rides, got %+v", plans)
+		}
+		walk := plans[0].Legs[1]
+		if walk.Distance <= 0 || walk.Duration != WalkingTime(walk.Distance) {
+			t.Errorf("Expected a walk estimated from coordinates, got %.3f km in %s", walk.Distance, walk.Duration)
+		}
+	})
+}
+
+func TestTimetablePlanAccessWalks(t *testing.T) {
+	schedules, included := plannerTestData()
+	timetable := NewTimetable(schedules, included)
+
+	home := LocationStop(OriginLocationID, Location{Name: "Home"})
+	office := LocationStop(DestinationLocationID, Location{Name: "Office"})
+	timetable.AddAccessWalks(home, []models.NearbyStation{{Stop: models.Stop{ID: "a"}, DistanceKm: 0.3}}, true)
+	timetable.AddAccessWalks(office, []models.NearbyStation{{Stop: models.Stop{ID: "d"}, DistanceKm: 0.3}}, false)
+
+	plans := timetable.Plan(JourneyQuery{Origin: home.ID, Destination: office.ID, Time: plannerTestTime("07:50"), MaxTransfers: 3})
+	if len(plans) != 2 {
+		t.Fatalf("Expected 2 plans, got %d", len(plans))
+	}
+
+	fastest := plans[1]
+	if len(fastest.Legs) != 4 || !fastest.Legs[0].IsWalk() || !fastest.Legs[3].IsWalk() {
+		t.Fatalf("Expected walks either side of two rides, got %+v", fastest.Legs)
+	}
+	if fastest.Transfers != 1 {
+		t.Errorf("Expected 1 transfer, got %d", fastest.Transfers)
+	}
+
+	// Riders leave home just in time for the 08:00 and reach the office
+	// five and a half minutes after getting off
+	first, last := fastest.Legs[0], fastest.Legs[3]
+	if first.Origin.Attributes.Name != "Home" || first.Destination.ID != "a" || !first.DepartureTime.Equal(plannerTestTime("07:54").Add(35*time.Second)) {
+		t.Errorf("Expected a walk from home to a leaving 07:54:35, got %s to %s leaving %s", first.Origin.Attributes.Name, first.Destination.ID, first.DepartureTime)
+	}
+	if last.Origin.ID != "d" || last.Destination.Attributes.Name != "Office" || !last.ArrivalTime.Equal(plannerTestTime("08:30").Add(25*time.Second)) {
+		t.Errorf("Expected a walk from d to the office arriving 08:30:25, got %s to %s arriving %s", last.Origin.ID, last.Destination.Attributes.Name, last.ArrivalTime)
+	}
+	if !fastest.DepartureTime.Equal(first.DepartureTime) || !fastest.ArrivalTime.Equal(last.ArrivalTime) {
+		t.Errorf("Expected the plan to span the walks, got %s to %s", fastest.DepartureTime, fastest.ArrivalTime)
+	}
+}
+
+func TestPlanTripsAroundDisruptions(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	alerts := `{"data": [
+		{"id": "shuttle-r2", "type": "alert", "attributes": {
+			"effect": "SHUTTLE",
+			"header": "Shuttle buses replace R2 trains",
+			"active_period": [{"start": "2025-05-20T06:00:00-04:00", "end": "2025-05-20T20:00:00-04:00"}],
+			"informed_entity": [{"route": "R2"}, {"route": "R2", "stop": "c"}]
+		}},
+		{"id": "delays-r3", "type": "alert", "attributes": {
+			"effect": "SIGNIFICANT_DELAYS",
+			"active_period": [{"start": "2025-05-20T06:00:00-04:00"}],
+			"informed_entity": [{"route": "R3"}]
+		}}
+	]}`
+
+	var scheduleRequest, alertRequest string
+	planner := plannerTestHandler(t, schedules, included, &scheduleRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/alerts" {
+			if strings.Contains(r.URL.RawQuery, "filter%5Beffect%5D") {
+				alertRequest = r.URL.RawQuery
+			}
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			_, _ = fmt.Fprint(w, alerts)
+			return
+		}
+		planner(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	plans, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), map[string]interface{}{
+		"avoid_disruptions": true,
+	})
+	if err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+	if !strings.Contains(alertRequest, "SHUTTLE") {
+		t.Errorf("Expected a request for service disruptions, got %s", alertRequest)
+	}
+
+	// Only the slow direct trip avoids the shuttle
+	if len(plans) != 1 || plans[0].Legs[0].RouteID != "R3" {
+		t.Fatalf("Expected only the direct R3 plan, got %+v", plans)
+	}
+	detours := plans[0].Detours
+	if len(detours) != 1 || detours[0].Alert.ID != "shuttle-r2" {
+		t.Fatalf("Expected a detour around shuttle-r2, got %+v", detours)
+	}
+	if expected := "Shuttle Bus Service on Route R2 at Stop C: Shuttle buses replace R2 trains"; detours[0].Reason != expected {
+		t.Errorf("Expected reason %q, got %q", expected, detours[0].Reason)
+	}
+
+	// The detour is still delayed
+	if alerts := plans[0].Legs[0].Alerts; !plans[0].Disrupted || len(alerts) != 1 || alerts[0].ID != "delays-r3" {
+		t.Errorf("Expected the plan to be flagged for delays-r3, got %+v", alerts)
+	}
+
+	// Avoiding R3 as well leaves no way to travel, whether routes are given
+	// as strings or decoded from JSON
+	for _, avoid := range []interface{}{[]string{"R3"}, []interface{}{"R3"}} {
+		_, err = client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), map[string]interface{}{
+			"avoid_disruptions": true,
+			"avoid_routes":      avoid,
+		})
+		var disrupted *DisruptedTripError
+		if !errors.As(err, &disrupted) {
+			t.Fatalf("Expected a DisruptedTripError avoiding %#v, got %v", avoid, err)
+		}
+		if len(disrupted.Detours) != 1 || disrupted.Detours[0].RouteID != "R2" {
+			t.Errorf("Expected the R2 shuttle to be blamed, got %+v", disrupted.Detours)
+		}
+	}
+}
+
+func TestPlanTripsAroundElevatorOutages(t *testing.T) {
+	schedules, included := plannerTestData()
+
+	// Station B's platforms are downtown, near an accessible station C
+	for i := range included {
+		if included[i].ID == "b1" || included[i].ID == "b2" {
+			attributes := included[i].Attributes.(map[string]interface{})
+			attributes["latitude"] = 42.3564
+			attributes["longitude"] = -71.0624
+		}
+	}
+
+	alerts := `{"data": [{"id": "elevator-b", "type": "alert", "attributes": {
+		"effect": "ELEVATOR_OUTAGE",
+		"header": "Elevator 800 unavailable",
+		"active_period": [{"start": "2025-05-20T06:00:00-04:00", "end": "2025-05-20T20:00:00-04:00"}],
+		"informed_entity": [{"stop": "place-b", "activities": ["USING_WHEELCHAIR"]}]
+	}}]}`
+	stations := `{"data": [
+		{"id": "place-b", "type": "stop", "attributes": {"name": "Station B", "latitude": 42.3564, "longitude": -71.0624, "location_type": 1, "wheelchair_boarding": 1}},
+		{"id": "place-x", "type": "stop", "attributes": {"name": "Station X", "latitude": 42.3560, "longitude": -71.0620, "location_type": 1, "wheelchair_boarding": 2}},
+		{"id": "place-c", "type": "stop", "attributes": {"name": "Station C", "latitude": 42.3555, "longitude": -71.0602, "location_type": 1, "wheelchair_boarding": 1}}
+	]}`
+
+	var scheduleRequest string
+	planner := plannerTestHandler(t, schedules, included, &scheduleRequest)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/alerts":
+			_, _ = fmt.Fprint(w, alerts)
+		case "/stops":
+			_, _ = fmt.Fprint(w, stations)
+		default:
+			planner(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIBaseURL: server.URL})
+
+	// Without the wheelchair option the outage doesn't matter
+	if _, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), nil); err != nil {
+		t.Fatalf("PlanTrips returned an error: %v", err)
+	}
+
+	_, err := client.PlanTrips(context.Background(), "a", "d", plannerTestTime("07:55"), map[string]interface{}{
+		"wheelchair_accessible": true,
+	})
+
+	var inaccessible *InaccessibleTripError
+	if !errors.As(err, &inaccessible) {
+		t.Fatalf("Expected an InaccessibleTripError, got %v", err)
+	}
+	if len(inaccessible.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %+v", inaccessible.Issues)
+	}
+
+	issue := inaccessible.Issues[0]
+	if issue.Stop.ID != "b1" || issue.Role != models.StopRoleTransfer || issue.RouteID != "R1" {
+		t.Errorf("Expected a transfer from R1 at b1, got %s from %s at %s", issue.Role, issue.RouteID, issue.Stop.ID)
+	}
+	if issue.Alert == nil || issue.Alert.ID != "elevator-b" {
+		t.Errorf("Expected alert elevator-b, got %+v", issue.Alert)
+	}
+	if !strings.Contains(issue.Reason, "prevents transferring: Elevator 800 unavailable") || !strings.Contains(issue.Reason, "whole station") {
+		t.Errorf("Expected the reason to explain the outage closes the whole station, got %q", issue.Reason)
+	}
+	if issue.Alternative == nil || issue.Alternative.Stop.ID != "place-c" {
+		t.Errorf("Expected place-c as the alternative, got %+v", issue.Alternative)
+	}
+	if !strings.Contains(err.Error(), "no accessible trip found between a and d") {
+		t.Errorf("Expected the error to name the stops, got %v", err)
+	}
+}
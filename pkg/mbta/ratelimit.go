@@ -0,0 +1,210 @@
+package mbta
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default MBTA API limits in requests per minute
+const (
+	DefaultRateLimit          = 1000 // With an API key
+	DefaultAnonymousRateLimit = 20   // Without an API key
+)
+
+// Quota describes the API quota as last reported by the MBTA API and the
+// state of the client-side limiter
+type Quota struct {
+	Limit             int           // Requests allowed per window
+	Remaining         int           // Requests left in the current window
+	ResetAt           time.Time     // When the current window ends; zero if unknown
+	Observed          bool          // Whether Limit and Remaining come from API response headers
+	AvailableTokens   int           // Requests the limiter will allow without waiting
+	ThrottledRequests uint64        // Requests that had to wait for a token
+	TotalWait         time.Duration // Time spent waiting for tokens
+}
+
+// RateLimiter is a token bucket that keeps clients under the API rate limit.
+// It starts from a configured requests-per-minute budget and is corrected by
+// the x-ratelimit headers on every response. Share one RateLimiter between all
+// clients that use the same API key.
+type RateLimiter struct {
+	mu          sync.Mutex
+	capacity    float64
+	rate        float64 // Tokens per second
+	tokens      float64
+	updated     time.Time
+	pausedUntil time.Time
+
+	limit     int
+	remaining int
+	resetAt   time.Time
+	observed  bool
+
+	throttled uint64
+	waited    time.Duration
+}
+
+// NewRateLimiter creates a limiter allowing requestsPerMinute requests per minute
+func NewRateLimiter(requestsPerMinute int) *RateLimiter {
+	if requestsPerMinute <= 0 {
+		requestsPerMinute = DefaultAnonymousRateLimit
+	}
+
+	limit := float64(requestsPerMinute)
+	return &RateLimiter{
+		capacity:  limit,
+		rate:      limit / 60,
+		tokens:    limit,
+		updated:   time.Now(),
+		limit:     requestsPerMinute,
+		remaining: requestsPerMinute,
+	}
+}
+
+// WithRateLimiter makes the client wait for the given limiter before each request
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// Wait blocks until a request may be made. If the wait would outlast the
+// context's deadline it returns a RateLimitError immediately instead.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	r.mu.Lock()
+	wait := r.reserve(time.Now())
+	r.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		r.cancelReservation()
+		return &RateLimitError{
+			APIError: &APIError{
+				StatusCode: http.StatusTooManyRequests,
+				Status:     strconv.Itoa(http.StatusTooManyRequests),
+				Title:      "Rate Limit Exceeded",
+				Detail:     "client-side rate limit would delay the request past its deadline",
+			},
+			RetryAfter: int(math.Ceil(wait.Seconds())),
+		}
+	}
+
+	r.mu.Lock()
+	r.throttled++
+	r.waited += wait
+	r.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		r.cancelReservation()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Observe updates the limiter from the x-ratelimit headers of an API response
+func (r *RateLimiter) Observe(header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("X-Ratelimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-Ratelimit-Remaining"))
+	reset, resetErr := strconv.ParseInt(header.Get("X-Ratelimit-Reset"), 10, 64)
+	if limitErr != nil && remainingErr != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.refill(now)
+	r.observed = true
+
+	if limitErr == nil && limit > 0 {
+		r.limit = limit
+		if float64(limit) != r.capacity {
+			r.capacity = float64(limit)
+			r.rate = float64(limit) / 60
+			r.tokens = math.Min(r.tokens, r.capacity)
+		}
+	}
+
+	if resetErr == nil {
+		r.resetAt = time.Unix(reset, 0)
+	}
+
+	if remainingErr == nil {
+		r.remaining = remaining
+
+		// The API's count is authoritative when it is lower than ours, for
+		// example when other processes share the same key
+		r.tokens = math.Min(r.tokens, float64(remaining))
+
+		// An exhausted quota doesn't come back until the window resets
+		if remaining == 0 && r.resetAt.After(now) {
+			r.pausedUntil = r.resetAt
+		}
+	}
+}
+
+// Quota returns a snapshot of the API quota and limiter state
+func (r *RateLimiter) Quota() Quota {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refill(time.Now())
+
+	return Quota{
+		Limit:             r.limit,
+		Remaining:         r.remaining,
+		ResetAt:           r.resetAt,
+		Observed:          r.observed,
+		AvailableTokens:   int(math.Max(0, math.Floor(r.tokens))),
+		ThrottledRequests: r.throttled,
+		TotalWait:         r.waited,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+// The caller must hold r.mu.
+func (r *RateLimiter) reserve(now time.Time) time.Duration {
+	r.refill(now)
+
+	var wait time.Duration
+	if now.Before(r.pausedUntil) {
+		wait = r.pausedUntil.Sub(now)
+	}
+
+	r.tokens--
+	if r.tokens < 0 {
+		wait = max(wait, time.Duration(-r.tokens/r.rate*float64(time.Second)))
+	}
+
+	return wait
+}
+
+// cancelReservation returns a token taken by a request that was never made
+func (r *RateLimiter) cancelReservation() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = math.Min(r.capacity, r.tokens+1)
+}
+
+// refill adds the tokens accrued since the last update. The caller must hold r.mu.
+func (r *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(r.updated).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	r.tokens = math.Min(r.capacity, r.tokens+elapsed*r.rate)
+	r.updated = now
+}
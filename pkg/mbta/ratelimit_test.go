@@ -0,0 +1,149 @@
+package mbta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	t.Run("Allows a burst up to the limit", func(t *testing.T) {
+		limiter := NewRateLimiter(5)
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait returned error: %v", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("Expected burst not to wait, took %v", elapsed)
+		}
+
+		quota := limiter.Quota()
+		if quota.AvailableTokens != 0 || quota.ThrottledRequests != 0 {
+			t.Errorf("Expected no tokens left and no throttling, got %+v", quota)
+		}
+	})
+
+	t.Run("Waits for a token to refill", func(t *testing.T) {
+		// 1200 requests per minute refills a token every 50ms
+		limiter := NewRateLimiter(1200)
+		limiter.tokens = 0
+
+		start := time.Now()
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait returned error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+			t.Errorf("Expected to wait for a token, took %v", elapsed)
+		}
+		if quota := limiter.Quota(); quota.ThrottledRequests != 1 || quota.TotalWait <= 0 {
+			t.Errorf("Expected 1 throttled request, got %+v", quota)
+		}
+	})
+
+	t.Run("Fails fast when the deadline is too close", func(t *testing.T) {
+		limiter := NewRateLimiter(1)
+		limiter.tokens = 0
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := limiter.Wait(ctx)
+		rateLimitErr, ok := err.(*RateLimitError)
+		if !ok {
+			t.Fatalf("Expected *RateLimitError, got %T: %v", err, err)
+		}
+		if rateLimitErr.RetryAfter < 1 {
+			t.Errorf("Expected RetryAfter of at least 1 second, got %d", rateLimitErr.RetryAfter)
+		}
+		// The token is returned, so the bucket isn't driven further into debt
+		if limiter.tokens < -0.01 {
+			t.Errorf("Expected reservation to be returned, got %f tokens", limiter.tokens)
+		}
+	})
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	limiter := NewRateLimiter(DefaultAnonymousRateLimit)
+
+	reset := time.Now().Add(30 * time.Second).Unix()
+	limiter.Observe(http.Header{
+		"X-Ratelimit-Limit":     []string{"1000"},
+		"X-Ratelimit-Remaining": []string{"250"},
+		"X-Ratelimit-Reset":     []string{strconv.FormatInt(reset, 10)},
+	})
+
+	quota := limiter.Quota()
+	if !quota.Observed {
+		t.Error("Expected quota to be observed from headers")
+	}
+	if quota.Limit != 1000 || quota.Remaining != 250 {
+		t.Errorf("Expected 250/1000 remaining, got %d/%d", quota.Remaining, quota.Limit)
+	}
+	if quota.ResetAt.Unix() != reset {
+		t.Errorf("Expected reset at %d, got %d", reset, quota.ResetAt.Unix())
+	}
+	// The bucket started with 20 tokens, fewer than the API reports
+	if quota.AvailableTokens > DefaultAnonymousRateLimit {
+		t.Errorf("Expected at most %d tokens, got %d", DefaultAnonymousRateLimit, quota.AvailableTokens)
+	}
+
+	t.Run("Exhausted quota pauses until reset", func(t *testing.T) {
+		limiter.Observe(http.Header{
+			"X-Ratelimit-Limit":     []string{"1000"},
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{strconv.FormatInt(reset, 10)},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if _, ok := limiter.Wait(ctx).(*RateLimitError); !ok {
+			t.Error("Expected Wait to refuse a request before the window resets")
+		}
+	})
+
+	t.Run("Responses without headers are ignored", func(t *testing.T) {
+		fresh := NewRateLimiter(60)
+		fresh.Observe(http.Header{})
+		if fresh.Quota().Observed {
+			t.Error("Expected quota not to be observed without headers")
+		}
+	})
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	remaining := 100
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining--
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", strconv.Itoa(remaining))
+		_, _ = w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(100)
+	cfg := &config.Config{APIKey: "test-key", APIBaseURL: server.URL}
+
+	// Separate clients draw from the same limiter
+	for i := 0; i < 3; i++ {
+		if _, err := NewClient(cfg, WithRateLimiter(limiter)).GetRoutes(context.Background()); err != nil {
+			t.Fatalf("GetRoutes returned error: %v", err)
+		}
+	}
+
+	quota := limiter.Quota()
+	if quota.Remaining != 97 || quota.Limit != 100 {
+		t.Errorf("Expected 97/100 remaining, got %d/%d", quota.Remaining, quota.Limit)
+	}
+	if quota.AvailableTokens > 97 {
+		t.Errorf("Expected at most 97 tokens available, got %d", quota.AvailableTokens)
+	}
+}
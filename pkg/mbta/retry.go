@@ -0,0 +1,122 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 1 or less disables retries
+	BaseDelay   time.Duration // Backoff before the first retry, doubled for each later retry
+	MaxDelay    time.Duration // Upper bound for any single wait, including rate limit waits
+}
+
+// DefaultRetryPolicy retries twice with jittered backoff starting at half a second
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// WithRetry retries idempotent requests that fail with a network error,
+// a timeout, a rate limit or a server error
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		if policy.MaxAttempts <= 1 {
+			c.retry = nil
+			return
+		}
+		if policy.BaseDelay <= 0 {
+			policy.BaseDelay = DefaultRetryPolicy.BaseDelay
+		}
+		if policy.MaxDelay < policy.BaseDelay {
+			policy.MaxDelay = policy.BaseDelay
+		}
+		c.retry = &policy
+	}
+}
+
+// do calls attempt until it succeeds, fails permanently, or runs out of
+// attempts or time
+func (p *RetryPolicy) do(ctx context.Context, attempt func() (*http.Response, error)) (*http.Response, error) {
+	return retry(ctx, p, attempt)
+}
+
+// retry calls attempt according to a retry policy, returning the result of
+// the last attempt
+func retry[T any](ctx context.Context, p *RetryPolicy, attempt func() (T, error)) (T, error) {
+	var zero T
+	for i := 0; ; i++ {
+		result, err := attempt()
+		if err == nil || i+1 >= p.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return result, err
+		}
+
+		wait, ok := p.delay(i, err)
+		if !ok {
+			return zero, err
+		}
+
+		// Don't start a wait that would outlast the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return zero, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns how long to wait before the retry following the given attempt.
+// Rate limit errors wait for the period the API asked for, and are not retried
+// when that exceeds MaxDelay.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		wait := time.Duration(rateLimitErr.RetryAfter) * time.Second
+		return wait, wait <= p.MaxDelay
+	}
+
+	// Exponential backoff with jitter across the upper half of the interval
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	half := backoff / 2
+	return half + rand.N(half+1), true
+}
+
+// isRetryable reports whether a request that failed with err may succeed if retried
+func isRetryable(err error) bool {
+	var (
+		timeoutErr   *TimeoutError
+		networkErr   *NetworkError
+		rateLimitErr *RateLimitError
+		apiErr       *APIError
+	)
+	switch {
+	case errors.As(err, &timeoutErr), errors.As(err, &networkErr), errors.As(err, &rateLimitErr):
+		return true
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// isIdempotent reports whether repeating a request with the given method is safe
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package mbta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+)
+
+// fastRetry retries quickly so tests don't wait on real backoff
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+func TestRetryTransientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+	}{
+		{"Server error", http.StatusServiceUnavailable, nil},
+		{"Rate limit", http.StatusTooManyRequests, http.Header{"Retry-After": []string{"0"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests < 3 {
+					for key, values := range tt.header {
+						w.Header()[key] = values
+					}
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(`{"errors": [{"status": "error"}]}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"data": [{"id": "Red", "type": "route"}]}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithRetry(fastRetry))
+
+			routes, err := client.GetRoutes(context.Background())
+			if err != nil {
+				t.Fatalf("GetRoutes returned error: %v", err)
+			}
+			if len(routes) != 1 {
+				t.Errorf("Expected 1 route, got %d", len(routes))
+			}
+			if requests != 3 {
+				t.Errorf("Expected 3 requests, got %d", requests)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	t.Run("After max attempts", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithRetry(fastRetry))
+
+		_, err := client.GetRoutes(context.Background())
+		if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != http.StatusBadGateway {
+			t.Errorf("Expected 502 *APIError, got %T: %v", err, err)
+		}
+		if requests != 3 {
+			t.Errorf("Expected 3 requests, got %d", requests)
+		}
+	})
+
+	t.Run("On client errors", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithRetry(fastRetry))
+
+		if _, err := client.GetRoute(context.Background(), "missing"); err == nil {
+			t.Fatal("Expected error for missing route")
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+
+	t.Run("When the rate limit wait is too long", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithRetry(fastRetry))
+
+		_, err := client.GetRoutes(context.Background())
+		if rateLimitErr, ok := err.(*RateLimitError); !ok || rateLimitErr.RetryAfter != 60 {
+			t.Errorf("Expected *RateLimitError with RetryAfter 60, got %T: %v", err, err)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+
+	t.Run("When the context deadline is too close", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		slowRetry := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+		client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL}, WithRetry(slowRetry))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := client.GetRoutes(ctx); err == nil {
+			t.Fatal("Expected error")
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("Expected to give up before the deadline, took %v", elapsed)
+		}
+		if requests != 1 {
+			t.Errorf("Expected 1 request, got %d", requests)
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 0; attempt < 6; attempt++ {
+		backoff := min(policy.BaseDelay<<attempt, policy.MaxDelay)
+
+		wait, ok := policy.delay(attempt, &NetworkError{})
+		if !ok {
+			t.Fatalf("Expected attempt %d to be retried", attempt)
+		}
+		if wait < backoff/2 || wait > backoff {
+			t.Errorf("Expected attempt %d wait in [%v, %v], got %v", attempt, backoff/2, backoff, wait)
+		}
+	}
+
+	if wait, ok := policy.delay(0, &RateLimitError{APIError: &APIError{}, RetryAfter: 1}); !ok || wait != time.Second {
+		t.Errorf("Expected rate limit wait of 1s, got %v (ok=%v)", wait, ok)
+	}
+}
+
+func TestRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"Network error", &NetworkError{}, true},
+		{"Timeout", &TimeoutError{NetworkError: &NetworkError{}}, true},
+		{"Rate limit", &RateLimitError{APIError: &APIError{StatusCode: 429}}, true},
+		{"Server error", &APIError{StatusCode: 500}, true},
+		{"Not found", &APIError{StatusCode: 404}, false},
+		{"Decode error", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.retryable {
+			t.Errorf("%s: expected retryable=%v, got %v", tt.name, tt.retryable, got)
+		}
+	}
+
+	if !isIdempotent(http.MethodGet) || isIdempotent(http.MethodPost) {
+		t.Error("Expected GET to be idempotent and POST not to be")
+	}
+}
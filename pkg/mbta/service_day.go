@@ -0,0 +1,154 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	// Containers often ship without a zoneinfo database, and service days
+	// must be in Boston time wherever the server runs
+	_ "time/tzdata"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// ServiceTimezone is the timezone MBTA service is scheduled in
+const ServiceTimezone = "America/New_York"
+
+// ServiceDayStartHour is the hour of the morning a new service day begins.
+// Trips running after midnight and before then belong to the previous
+// service day, scheduled at times past 24:00.
+const ServiceDayStartHour = 3
+
+// serviceDateLayout is the YYYY-MM-DD format of filter[date]
+const serviceDateLayout = "2006-01-02"
+
+var (
+	serviceLocationOnce sync.Once
+	serviceLocation     *time.Location
+)
+
+// ServiceLocation returns the timezone MBTA service is scheduled in
+func ServiceLocation() *time.Location {
+	serviceLocationOnce.Do(func() {
+		location, err := time.LoadLocation(ServiceTimezone)
+		if err != nil {
+			// Unreachable with the embedded zoneinfo, but fall back to
+			// standard time rather than the host's zone
+			location = time.FixedZone("EST", -5*60*60)
+		}
+		serviceLocation = location
+	})
+	return serviceLocation
+}
+
+// ServiceDay is the date MBTA trips are scheduled on. A service day runs from
+// early morning until the last trips finish after midnight, so it's longer
+// than a calendar day, and shorter or longer still when daylight saving time
+// begins or ends.
+type ServiceDay struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewServiceDay returns the service day on a date, normalizing it the way
+// time.Date does
+func NewServiceDay(year int, month time.Month, day int) ServiceDay {
+	year, month, day = time.Date(year, month, day, 12, 0, 0, 0, time.UTC).Date()
+	return ServiceDay{Year: year, Month: month, Day: day}
+}
+
+// ServiceDayOf returns the service day running at t. Times after midnight
+// and before ServiceDayStartHour belong to the previous day's service.
+func ServiceDayOf(t time.Time) ServiceDay {
+	local := t.In(ServiceLocation())
+	year, month, day := local.Date()
+	if local.Hour() < ServiceDayStartHour {
+		day--
+	}
+	return NewServiceDay(year, month, day)
+}
+
+// ParseServiceDay parses a YYYY-MM-DD service date
+func ParseServiceDay(value string) (ServiceDay, error) {
+	date, err := time.Parse(serviceDateLayout, value)
+	if err != nil {
+		return ServiceDay{}, fmt.Errorf("invalid service date %q: %w", value, err)
+	}
+	return NewServiceDay(date.Date()), nil
+}
+
+// String formats the service day as YYYY-MM-DD, as filter[date] expects
+func (d ServiceDay) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Start returns the time schedule offsets on the service day count from.
+// GTFS measures them from noon minus 12 hours, which is midnight except on
+// days when daylight saving time begins or ends.
+func (d ServiceDay) Start() time.Time {
+	noon := time.Date(d.Year, d.Month, d.Day, 12, 0, 0, 0, ServiceLocation())
+	return noon.Add(-12 * time.Hour)
+}
+
+// Time returns the time of a schedule offset on the service day, such as
+// 25h30m for 1:30 the next morning
+func (d ServiceDay) Time(offset time.Duration) time.Time {
+	return d.Start().Add(offset).In(ServiceLocation())
+}
+
+// Offset returns how long after the start of the service day t is
+func (d ServiceDay) Offset(t time.Time) time.Duration {
+	return t.Sub(d.Start())
+}
+
+// TimeFilter formats t as an HH:MM schedule time on the service day, as
+// filter[min_time] and filter[max_time] expect, using hours past 24 for
+// times after midnight. Times before the service day starts are 00:00.
+func (d ServiceDay) TimeFilter(t time.Time) string {
+	minutes := int(max(d.Offset(t), 0) / time.Minute)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// getSchedulesBetween gets the schedules matching params that run between two
+// times. Schedules are requested by service day, so when the window runs past
+// ServiceDayStartHour the trips starting the next day's service are requested
+// from midnight as well, and both days are combined. Windows are a few hours
+// long, so the same trip doesn't run on both days within one.
+func getSchedulesBetween(ctx context.Context, source ScheduleDataSource, params map[string]string, from, to time.Time) ([]models.Schedule, []models.Included, error) {
+	day := ServiceDayOf(from)
+	windows := []map[string]string{{
+		"filter[date]":     day.String(),
+		"filter[min_time]": day.TimeFilter(from),
+		"filter[max_time]": day.TimeFilter(to),
+	}}
+	if next := ServiceDayOf(to); next != day {
+		windows = append(windows, map[string]string{
+			"filter[date]":     next.String(),
+			"filter[min_time]": "00:00",
+			"filter[max_time]": next.TimeFilter(to),
+		})
+	}
+
+	var schedules []models.Schedule
+	var included []models.Included
+	for _, window := range windows {
+		query := make(map[string]string, len(params)+len(window))
+		for key, value := range params {
+			query[key] = value
+		}
+		for key, value := range window {
+			query[key] = value
+		}
+
+		daySchedules, dayIncluded, err := source.GetSchedules(ctx, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		schedules = append(schedules, daySchedules...)
+		included = append(included, dayIncluded...)
+	}
+	return schedules, included, nil
+}
@@ -0,0 +1,116 @@
+package mbta
+
+import (
+	"testing"
+	"time"
+)
+
+// serviceDayTestTime parses an RFC 3339 time for service day tests
+func serviceDayTestTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("Invalid test time %q: %v", value, err)
+	}
+	return parsed
+}
+
+func TestServiceDayOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		at       string
+		expected string
+	}{
+		{"evening", "2025-05-20T22:30:00-04:00", "2025-05-20"},
+		{"after midnight", "2025-05-21T01:30:00-04:00", "2025-05-20"},
+		{"start of service", "2025-05-21T03:00:00-04:00", "2025-05-21"},
+		{"UTC host", "2025-05-21T02:30:00Z", "2025-05-20"},
+		{"UTC after midnight", "2025-05-21T05:30:00Z", "2025-05-20"},
+		{"new year", "2026-01-01T00:30:00-05:00", "2025-12-31"},
+		{"first of the month", "2025-06-01T02:00:00-04:00", "2025-05-31"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ServiceDayOf(serviceDayTestTime(t, tt.at)).String(); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServiceDayTimeFilter(t *testing.T) {
+	day := NewServiceDay(2025, time.May, 20)
+
+	tests := []struct {
+		name     string
+		at       string
+		expected string
+	}{
+		{"evening", "2025-05-20T22:30:00-04:00", "22:30"},
+		{"after midnight", "2025-05-21T01:30:00-04:00", "25:30"},
+		{"UTC", "2025-05-20T11:55:00Z", "07:55"},
+		{"before the day", "2025-05-19T23:00:00-04:00", "00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := day.TimeFilter(serviceDayTestTime(t, tt.at)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServiceDayDaylightSaving(t *testing.T) {
+	// Clocks spring forward at 2am, so the day starts at 11pm the night before
+	spring := NewServiceDay(2025, time.March, 9)
+	if start := spring.Start(); !start.Equal(serviceDayTestTime(t, "2025-03-08T23:00:00-05:00")) {
+		t.Errorf("Expected the spring day to start at 23:00 EST, got %s", start)
+	}
+	if got := spring.Time(8 * time.Hour); !got.Equal(serviceDayTestTime(t, "2025-03-09T08:00:00-04:00")) {
+		t.Errorf("Expected 08:00 to be 08:00 EDT, got %s", got)
+	}
+	if got := spring.TimeFilter(serviceDayTestTime(t, "2025-03-09T08:00:00-04:00")); got != "08:00" {
+		t.Errorf("Expected 08:00, got %s", got)
+	}
+
+	// Clocks fall back at 2am, so the day starts at 1am
+	fall := NewServiceDay(2025, time.November, 2)
+	if start := fall.Start(); !start.Equal(serviceDayTestTime(t, "2025-11-02T01:00:00-04:00")) {
+		t.Errorf("Expected the fall day to start at 01:00 EDT, got %s", start)
+	}
+	if got := fall.Time(8 * time.Hour); !got.Equal(serviceDayTestTime(t, "2025-11-02T08:00:00-05:00")) {
+		t.Errorf("Expected 08:00 to be 08:00 EST, got %s", got)
+	}
+
+	// The night before, the hour repeated after midnight still belongs to
+	// the previous day
+	repeated := serviceDayTestTime(t, "2025-11-02T01:30:00-05:00")
+	day := ServiceDayOf(repeated)
+	if day.String() != "2025-11-01" {
+		t.Fatalf("Expected the repeated hour on 2025-11-01's service, got %s", day)
+	}
+	if got := day.TimeFilter(repeated); got != "26:30" {
+		t.Errorf("Expected 26:30, got %s", got)
+	}
+	if got := day.Offset(day.Time(26*time.Hour + 30*time.Minute)); got != 26*time.Hour+30*time.Minute {
+		t.Errorf("Expected Time and Offset to round trip, got %s", got)
+	}
+}
+
+func TestParseServiceDay(t *testing.T) {
+	day, err := ParseServiceDay("2025-05-20")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if day != NewServiceDay(2025, time.May, 20) {
+		t.Errorf("Expected 2025-05-20, got %s", day)
+	}
+
+	if _, err := ParseServiceDay("05/20/2025"); err == nil {
+		t.Error("Expected an error for a malformed date")
+	}
+
+	if got := NewServiceDay(2025, time.May, 32).String(); got != "2025-06-01" {
+		t.Errorf("Expected dates to be normalized, got %s", got)
+	}
+}
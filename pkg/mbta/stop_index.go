@@ -0,0 +1,329 @@
+package mbta
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// indexedRouteTypes are the route types whose stops the index records
+var indexedRouteTypes = []int{
+	models.RouteTypeLightRail,
+	models.RouteTypeSubway,
+	models.RouteTypeCommuterRail,
+	models.RouteTypeBus,
+	models.RouteTypeFerry,
+}
+
+// StopFilter restricts which stops a nearby search returns
+type StopFilter struct {
+	LocationTypes        []int // Only stops with one of these location types; empty means any
+	RouteTypes           []int // Only stops served by one of these route types; empty means any
+	WheelchairAccessible bool  // Only stops with accessible boarding
+}
+
+// StopIndex is an in-memory k-d tree of stops for radius and nearest-neighbor
+// searches. Stops are placed on the unit sphere so straight-line distance in
+// the tree orders them exactly as great-circle distance does. It is safe for
+// concurrent use, and Build replaces the contents atomically.
+type StopIndex struct {
+	mu      sync.RWMutex
+	nodes   []indexedStop // Implicit tree: the median of each range is its root
+	updated time.Time
+}
+
+// indexedStop is a stop with its position and the route types serving it
+type indexedStop struct {
+	stop       models.Stop
+	point      [3]float64
+	routeTypes uint8 // Bit set of route types
+}
+
+// NewStopIndex creates an empty stop index
+func NewStopIndex() *StopIndex {
+	return &StopIndex{}
+}
+
+// WithStopIndex answers nearby stop searches from the given index once it has
+// been built, instead of downloading every stop for each search
+func WithStopIndex(index *StopIndex) ClientOption {
+	return func(c *Client) {
+		c.stopIndex = index
+	}
+}
+
+// Build replaces the index contents with the given stops. routeTypes maps
+// stop IDs to the types of the routes serving them.
+func (i *StopIndex) Build(stops []models.Stop, routeTypes map[string][]int) {
+	nodes := make([]indexedStop, 0, len(stops))
+	for _, stop := range stops {
+		node := indexedStop{
+			stop:  stop,
+			point: unitVector(stop.Attributes.Latitude, stop.Attributes.Longitude),
+		}
+		for _, routeType := range routeTypes[stop.ID] {
+			node.routeTypes |= routeTypeBit(routeType)
+		}
+		nodes = append(nodes, node)
+	}
+	buildTree(nodes, 0)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.nodes = nodes
+	i.updated = time.Now()
+}
+
+// Len returns the number of stops in the index
+func (i *StopIndex) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.nodes)
+}
+
+// UpdatedAt returns when the index was last built
+func (i *StopIndex) UpdatedAt() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.updated
+}
+
+// Within returns the stops matching the filter within radiusKm of a point,
+// closest first
+func (i *StopIndex) Within(lat, lon, radiusKm float64, filter StopFilter) []models.NearbyStation {
+	return i.Nearest(lat, lon, 0, radiusKm, filter)
+}
+
+// Nearest returns up to k stops matching the filter closest to a point,
+// closest first. A k of 0 returns every match, and a radiusKm of 0 doesn't
+// limit the distance.
+func (i *StopIndex) Nearest(lat, lon float64, k int, radiusKm float64, filter StopFilter) []models.NearbyStation {
+	// Compare squared chord lengths on the unit sphere
+	bound := 4.0
+	if radiusKm > 0 && radiusKm < math.Pi*earthRadiusKm {
+		chord := 2 * math.Sin(radiusKm/(2*earthRadiusKm))
+		bound = chord * chord
+	}
+
+	search := &stopSearch{
+		target: unitVector(lat, lon),
+		k:      k,
+		bound:  bound,
+		match:  filter.matcher(),
+	}
+
+	i.mu.RLock()
+	search.visit(i.nodes, 0)
+	i.mu.RUnlock()
+
+	sort.Slice(search.found, func(a, b int) bool {
+		return search.found[a].distance < search.found[b].distance
+	})
+
+	results := make([]models.NearbyStation, len(search.found))
+	for n, candidate := range search.found {
+		results[n] = models.NearbyStation{
+			Stop:       candidate.node.stop,
+			DistanceKm: 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(candidate.distance)/2)),
+		}
+	}
+	return results
+}
+
+// RefreshStopIndex rebuilds an index from every stop the API knows about and
+// the route types serving each of them
+func (c *Client) RefreshStopIndex(ctx context.Context, index *StopIndex) error {
+	stops, _, err := listAll[models.Stop](ctx, c, "/stops", "stop")
+	if err != nil {
+		return fmt.Errorf("failed to load stops for index: %w", err)
+	}
+
+	routeTypes := make(map[string][]int)
+	for _, routeType := range indexedRouteTypes {
+		query := url.Values{}
+		query.Add("filter[route_type]", strconv.Itoa(routeType))
+		query.Add("fields[stop]", "id")
+
+		served, _, err := listAll[models.Stop](ctx, c, "/stops?"+query.Encode(), "stop")
+		if err != nil {
+			return fmt.Errorf("failed to load stops for route type %d: %w", routeType, err)
+		}
+		for _, stop := range served {
+			routeTypes[stop.ID] = append(routeTypes[stop.ID], routeType)
+		}
+	}
+
+	// Stations are served by whatever serves their platforms, and entrances
+	// and other unserved children by whatever serves their station
+	for _, stop := range stops {
+		if parent := models.RelationshipID(stop.Relationships, "parent_station"); parent != "" {
+			routeTypes[parent] = mergeRouteTypes(routeTypes[parent], routeTypes[stop.ID])
+		}
+	}
+	for _, stop := range stops {
+		parent := models.RelationshipID(stop.Relationships, "parent_station")
+		if parent != "" && len(routeTypes[stop.ID]) == 0 {
+			routeTypes[stop.ID] = routeTypes[parent]
+		}
+	}
+
+	index.Build(stops, routeTypes)
+	return nil
+}
+
+// matcher returns a function reporting whether an indexed stop passes the filter
+func (f StopFilter) matcher() func(*indexedStop) bool {
+	var locationTypes map[int]bool
+	if len(f.LocationTypes) > 0 {
+		locationTypes = make(map[int]bool, len(f.LocationTypes))
+		for _, locationType := range f.LocationTypes {
+			locationTypes[locationType] = true
+		}
+	}
+
+	var routeTypes uint8
+	for _, routeType := range f.RouteTypes {
+		routeTypes |= routeTypeBit(routeType)
+	}
+
+	return func(node *indexedStop) bool {
+		if locationTypes != nil && !locationTypes[node.stop.Attributes.LocationType] {
+			return false
+		}
+		if len(f.RouteTypes) > 0 && node.routeTypes&routeTypes == 0 {
+			return false
+		}
+		if f.WheelchairAccessible && !node.stop.IsAccessible() {
+			return false
+		}
+		return true
+	}
+}
+
+// stopSearch holds the state of a radius or nearest-neighbor search
+type stopSearch struct {
+	target [3]float64
+	k      int
+	bound  float64 // Squared chord distance beyond which stops are ignored
+	match  func(*indexedStop) bool
+	found  candidateHeap
+}
+
+// visit searches the subtree stored in nodes, split on the given axis
+func (s *stopSearch) visit(nodes []indexedStop, axis int) {
+	if len(nodes) == 0 {
+		return
+	}
+
+	mid := len(nodes) / 2
+	node := &nodes[mid]
+
+	if distance := squaredDistance(node.point, s.target); distance <= s.bound && s.match(node) {
+		heap.Push(&s.found, candidate{node: node, distance: distance})
+		if s.k > 0 && s.found.Len() > s.k {
+			heap.Pop(&s.found)
+		}
+		if s.k > 0 && s.found.Len() == s.k {
+			s.bound = s.found[0].distance
+		}
+	}
+
+	next := (axis + 1) % 3
+	delta := s.target[axis] - node.point[axis]
+	near, far := nodes[:mid], nodes[mid+1:]
+	if delta > 0 {
+		near, far = far, near
+	}
+
+	s.visit(near, next)
+	if delta*delta <= s.bound {
+		s.visit(far, next)
+	}
+}
+
+// candidate is a stop found by a search with its squared chord distance
+type candidate struct {
+	node     *indexedStop
+	distance float64
+}
+
+// candidateHeap is a max-heap of candidates by distance, so the farthest of
+// the k nearest found so far can be replaced
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+func (h candidateHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// buildTree arranges nodes into an implicit k-d tree in place
+func buildTree(nodes []indexedStop, axis int) {
+	if len(nodes) <= 1 {
+		return
+	}
+	sort.Slice(nodes, func(a, b int) bool { return nodes[a].point[axis] < nodes[b].point[axis] })
+
+	mid := len(nodes) / 2
+	next := (axis + 1) % 3
+	buildTree(nodes[:mid], next)
+	buildTree(nodes[mid+1:], next)
+}
+
+// unitVector returns the position of a coordinate on the unit sphere
+func unitVector(lat, lon float64) [3]float64 {
+	latRad := lat * math.Pi / 180.0
+	lonRad := lon * math.Pi / 180.0
+	return [3]float64{
+		math.Cos(latRad) * math.Cos(lonRad),
+		math.Cos(latRad) * math.Sin(lonRad),
+		math.Sin(latRad),
+	}
+}
+
+// squaredDistance returns the squared straight-line distance between two points
+func squaredDistance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// routeTypeBit returns the bit representing a route type in a bit set
+func routeTypeBit(routeType int) uint8 {
+	if routeType < 0 || routeType > 7 {
+		return 0
+	}
+	return 1 << routeType
+}
+
+// mergeRouteTypes returns the union of two route type lists
+func mergeRouteTypes(a, b []int) []int {
+	for _, routeType := range b {
+		found := false
+		for _, existing := range a {
+			if existing == routeType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, routeType)
+		}
+	}
+	return a
+}
@@ -0,0 +1,289 @@
+package mbta
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// indexTestStop builds a stop for index tests
+func indexTestStop(id string, lat, lon float64, locationType, wheelchair int, parent string) models.Stop {
+	stop := models.Stop{
+		ID:   id,
+		Type: "stop",
+		Attributes: models.StopAttributes{
+			Name:               id,
+			Latitude:           lat,
+			Longitude:          lon,
+			LocationType:       locationType,
+			WheelchairBoarding: wheelchair,
+		},
+		Relationships: map[string]interface{}{},
+	}
+	if parent != "" {
+		stop.Relationships["parent_station"] = relationship("stop", parent)
+	}
+	return stop
+}
+
+// indexTestStops are downtown Boston stations, a Red Line platform and a bus stop
+var indexTestStops = []models.Stop{
+	indexTestStop("place-pktrm", 42.35639457, -71.0624242, models.LocationTypeStation, models.WheelchairBoardingAccessible, ""),
+	indexTestStop("place-dwnxg", 42.355518, -71.060225, models.LocationTypeStation, models.WheelchairBoardingAccessible, ""),
+	indexTestStop("place-boyls", 42.35302, -71.06459, models.LocationTypeStation, models.WheelchairBoardingInaccessible, ""),
+	indexTestStop("place-armnl", 42.351902, -71.070893, models.LocationTypeStation, models.WheelchairBoardingAccessible, ""),
+	indexTestStop("place-alfcl", 42.395428, -71.142483, models.LocationTypeStation, models.WheelchairBoardingAccessible, ""),
+	indexTestStop("70075", 42.35639457, -71.0624242, models.LocationTypePlatform, models.WheelchairBoardingAccessible, "place-pktrm"),
+	indexTestStop("8279", 42.355, -71.0605, models.LocationTypePlatform, models.WheelchairBoardingAccessible, ""),
+}
+
+// indexTestRouteTypes maps the test stops to the route types serving them
+var indexTestRouteTypes = map[string][]int{
+	"place-pktrm": {models.RouteTypeLightRail, models.RouteTypeSubway},
+	"place-dwnxg": {models.RouteTypeSubway},
+	"place-boyls": {models.RouteTypeLightRail},
+	"place-armnl": {models.RouteTypeLightRail},
+	"place-alfcl": {models.RouteTypeSubway, models.RouteTypeBus},
+	"70075":       {models.RouteTypeSubway},
+	"8279":        {models.RouteTypeBus},
+}
+
+// nearbyIDs returns the stop IDs of search results in order
+func nearbyIDs(results []models.NearbyStation) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Stop.ID
+	}
+	return ids
+}
+
+func TestStopIndexSearch(t *testing.T) {
+	index := NewStopIndex()
+	if index.Len() != 0 || !index.UpdatedAt().IsZero() {
+		t.Fatal("Expected a new index to be empty")
+	}
+	index.Build(indexTestStops, indexTestRouteTypes)
+
+	if index.Len() != len(indexTestStops) {
+		t.Errorf("Expected %d stops, got %d", len(indexTestStops), index.Len())
+	}
+	if index.UpdatedAt().IsZero() {
+		t.Error("Expected UpdatedAt to be set after Build")
+	}
+
+	stations := []int{models.LocationTypeStation}
+	tests := []struct {
+		name     string
+		k        int
+		radius   float64
+		filter   StopFilter
+		expected []string
+	}{
+		{
+			name:     "Radius",
+			radius:   0.5,
+			filter:   StopFilter{LocationTypes: stations},
+			expected: []string{"place-dwnxg", "place-pktrm", "place-boyls"},
+		},
+		{
+			name:     "Nearest",
+			k:        2,
+			filter:   StopFilter{LocationTypes: stations},
+			expected: []string{"place-dwnxg", "place-pktrm"},
+		},
+		{
+			name:     "NearestWithinRadius",
+			k:        10,
+			radius:   0.2,
+			expected: []string{"8279", "place-dwnxg"},
+		},
+		{
+			name:     "RouteType",
+			k:        3,
+			filter:   StopFilter{RouteTypes: []int{models.RouteTypeLightRail}},
+			expected: []string{"place-pktrm", "place-boyls", "place-armnl"},
+		},
+		{
+			name:     "Wheelchair",
+			radius:   1,
+			filter:   StopFilter{LocationTypes: stations, WheelchairAccessible: true},
+			expected: []string{"place-dwnxg", "place-pktrm", "place-armnl"},
+		},
+		{
+			name:     "Bus",
+			filter:   StopFilter{RouteTypes: []int{models.RouteTypeBus}},
+			expected: []string{"8279", "place-alfcl"},
+		},
+		{
+			name:     "NoMatches",
+			radius:   0.01,
+			filter:   StopFilter{RouteTypes: []int{models.RouteTypeFerry}},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := index.Nearest(42.355, -71.060, tt.k, tt.radius, tt.filter)
+			got := nearbyIDs(results)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+
+	// Distances match the haversine formula
+	results := index.Within(42.355, -71.060, 0.5, StopFilter{})
+	for _, result := range results {
+		expected := calculateApproximateDistance(42.355, -71.060, result.Stop.Attributes.Latitude, result.Stop.Attributes.Longitude)
+		if diff := result.DistanceKm - expected; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Expected distance %f to %s, got %f", expected, result.Stop.ID, result.DistanceKm)
+		}
+	}
+}
+
+func TestStopIndexMatchesLinearScan(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	stops := make([]models.Stop, 2000)
+	for i := range stops {
+		stops[i] = indexTestStop(
+			"stop-"+strconv.Itoa(i),
+			42.2+random.Float64()*0.3,
+			-71.3+random.Float64()*0.4,
+			random.Intn(2),
+			random.Intn(3),
+			"",
+		)
+	}
+
+	index := NewStopIndex()
+	index.Build(stops, nil)
+
+	for query := 0; query < 50; query++ {
+		lat := 42.2 + random.Float64()*0.3
+		lon := -71.3 + random.Float64()*0.4
+		radius := random.Float64() * 3
+		filter := StopFilter{WheelchairAccessible: query%2 == 0}
+
+		var expected []models.NearbyStation
+		for _, stop := range stops {
+			if filter.WheelchairAccessible && !stop.IsAccessible() {
+				continue
+			}
+			distance := calculateApproximateDistance(lat, lon, stop.Attributes.Latitude, stop.Attributes.Longitude)
+			if distance <= radius {
+				expected = append(expected, models.NearbyStation{Stop: stop, DistanceKm: distance})
+			}
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i].DistanceKm < expected[j].DistanceKm })
+
+		got := index.Within(lat, lon, radius, filter)
+		if len(got) != len(expected) {
+			t.Fatalf("Query %d: expected %d stops within %.2f km, got %d", query, len(expected), radius, len(got))
+		}
+		for i := range got {
+			if got[i].Stop.ID != expected[i].Stop.ID {
+				t.Fatalf("Query %d: expected %s at %d, got %s", query, expected[i].Stop.ID, i, got[i].Stop.ID)
+			}
+		}
+
+		nearest := index.Nearest(lat, lon, 5, 0, filter)
+		for i := range nearest {
+			if i < len(expected) && nearest[i].Stop.ID != expected[i].Stop.ID {
+				t.Fatalf("Query %d: expected nearest %s at %d, got %s", query, expected[i].Stop.ID, i, nearest[i].Stop.ID)
+			}
+		}
+	}
+}
+
+func TestRefreshStopIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stops" {
+			t.Errorf("Expected request to /stops, got %s", r.URL.Path)
+		}
+
+		var stops []models.Stop
+		switch r.URL.Query().Get("filter[route_type]") {
+		case "":
+			stops = indexTestStops
+		case "1":
+			// The API reports the platform, not its station
+			stops = []models.Stop{{ID: "70075", Type: "stop"}, {ID: "place-dwnxg", Type: "stop"}}
+		case "3":
+			stops = []models.Stop{{ID: "8279", Type: "stop"}}
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_ = json.NewEncoder(w).Encode(models.StopResponse{Data: stops})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Timeout:    5 * time.Second,
+		APIBaseURL: server.URL,
+	}
+	index := NewStopIndex()
+	client := NewClient(cfg, WithStopIndex(index))
+
+	if err := client.RefreshStopIndex(context.Background(), index); err != nil {
+		t.Fatalf("Failed to refresh index: %v", err)
+	}
+	if index.Len() != len(indexTestStops) {
+		t.Errorf("Expected %d stops, got %d", len(indexTestStops), index.Len())
+	}
+
+	// Park Street is found as a subway station through its platform
+	results, err := client.FindNearbyStops(context.Background(), 42.3564, -71.0624, 0, 1, StopFilter{
+		LocationTypes: []int{models.LocationTypeStation},
+		RouteTypes:    []int{models.RouteTypeSubway},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Stop.ID != "place-pktrm" {
+		t.Errorf("Expected place-pktrm, got %v", nearbyIDs(results))
+	}
+
+	// Searches are answered from the index without further requests
+	server.Close()
+	stations, err := client.FindNearbyStations(context.Background(), 42.355, -71.060, 1.0, 3, true)
+	if err != nil {
+		t.Fatalf("Expected search from the index, got error %v", err)
+	}
+	if len(stations) != 3 || stations[0].Stop.ID != "place-dwnxg" {
+		t.Errorf("Expected 3 stations starting with place-dwnxg, got %v", nearbyIDs(stations))
+	}
+}
+
+func BenchmarkStopIndexNearest(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+
+	// About as many stops as the MBTA has
+	stops := make([]models.Stop, 10000)
+	for i := range stops {
+		stops[i] = indexTestStop("stop", 41.5+random.Float64()*1.5, -71.8+random.Float64()*1.2, models.LocationTypePlatform, 1, "")
+	}
+	index := NewStopIndex()
+	index.Build(stops, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Nearest(42.355, -71.060, 5, 1.0, StopFilter{})
+	}
+}
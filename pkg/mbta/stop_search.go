@@ -0,0 +1,314 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// minStopMatchScore is the lowest score a stop can have and still be a match
+const minStopMatchScore = 0.5
+
+// StopAliases maps names riders use for stations to their stop IDs. Keys are
+// matched after normalization, so "Gov't Center" finds "gov center".
+var StopAliases = map[string]string{
+	"dtx":            "place-dwnxg",
+	"downtown xing":  "place-dwnxg",
+	"gov center":     "place-gover",
+	"dudley":         "place-nubn",
+	"dudley square":  "place-nubn",
+	"mgh":            "place-chmnl",
+	"mass ave":       "place-masta",
+	"logan":          "place-aport",
+	"logan airport":  "place-aport",
+	"bc":             "place-lake",
+	"boston college": "place-lake",
+	"mit":            "place-knncl",
+	"tufts":          "place-tumnl",
+}
+
+// stopSearchFields are the stop fields searched, with how much a match on
+// each counts
+var stopSearchFields = []struct {
+	name   string
+	weight float64
+	text   func(stop *models.Stop) string
+}{
+	{"name", 1.0, func(stop *models.Stop) string { return stop.Attributes.Name }},
+	{"description", 0.9, func(stop *models.Stop) string { return stop.Attributes.Description }},
+	{"municipality", 0.6, func(stop *models.Stop) string { return stop.Attributes.Municipality }},
+}
+
+// stopNameAbbreviations maps words in stop names to a common abbreviation, so
+// "Harvard Square" and "Harvard Sq" normalize to the same text
+var stopNameAbbreviations = map[string]string{
+	"street":     "st",
+	"saint":      "st",
+	"square":     "sq",
+	"center":     "ctr",
+	"centre":     "ctr",
+	"station":    "stn",
+	"sta":        "stn",
+	"avenue":     "ave",
+	"av":         "ave",
+	"road":       "rd",
+	"government": "gov",
+	"govt":       "gov",
+	"heights":    "hts",
+	"university": "univ",
+	"junction":   "jct",
+	"mount":      "mt",
+	"&":          "and",
+}
+
+// SearchStops finds the stations and stops whose names, descriptions,
+// municipalities or common aliases best match a query, tolerating typos and
+// abbreviations. Platforms within a station are represented by their
+// station. At most limit matches are returned, best first; a limit of zero or
+// less returns every match.
+func SearchStops(ctx context.Context, stops StopDataSource, query string, limit int) ([]models.StopMatch, error) {
+	normalizedQuery := normalizeStopText(query)
+	if normalizedQuery == "" {
+		return nil, fmt.Errorf("empty stop search query")
+	}
+
+	allStops, err := stops.GetStops(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting stops to search: %w", err)
+	}
+
+	aliased := make(map[string]string)
+	for alias, stopID := range StopAliases {
+		if normalizeStopText(alias) == normalizedQuery {
+			aliased[stopID] = alias
+		}
+	}
+
+	var matches []models.StopMatch
+	for i := range allStops {
+		stop := &allStops[i]
+		if !isSearchableStop(stop) {
+			continue
+		}
+
+		best := models.StopMatch{Stop: *stop}
+		if alias, ok := aliased[stop.ID]; ok {
+			best.Score, best.MatchedOn, best.MatchedText = 1, "alias", alias
+		}
+		for _, field := range stopSearchFields {
+			text := field.text(stop)
+			if score := field.weight * stopTextScore(normalizedQuery, normalizeStopText(text)); score > best.Score {
+				best.Score, best.MatchedOn, best.MatchedText = score, field.name, text
+			}
+		}
+
+		if best.Score >= minStopMatchScore {
+			matches = append(matches, best)
+		}
+	}
+
+	// Best matches first, preferring stations to the stops that share their name
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := &matches[i], &matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Stop.IsStation() != b.Stop.IsStation() {
+			return a.Stop.IsStation()
+		}
+		if a.Stop.Attributes.Name != b.Stop.Attributes.Name {
+			return a.Stop.Attributes.Name < b.Stop.Attributes.Name
+		}
+		return a.Stop.ID < b.Stop.ID
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// ResolveStop finds the stop a rider means, given either its ID or its name.
+// An ID is looked up directly; anything else, or an ID that doesn't exist, is
+// the best match from SearchStops. Other errors looking up an ID, such as
+// network errors or rate limits, are returned rather than guessed around.
+func ResolveStop(ctx context.Context, stops StopDataSource, query string) (*models.Stop, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty stop name or ID")
+	}
+
+	// Stop IDs never contain spaces or slashes, but names often do
+	if !strings.ContainsAny(query, " /") {
+		stop, err := stops.GetStop(ctx, query)
+		if err == nil {
+			return stop, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	matches, err := SearchStops(ctx, stops, query, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no stop found matching %q", query)
+	}
+	return &matches[0].Stop, nil
+}
+
+// SearchStops finds stations and stops by name, see SearchStops
+func (c *Client) SearchStops(ctx context.Context, query string, limit int) ([]models.StopMatch, error) {
+	return SearchStops(ctx, c, query, limit)
+}
+
+// ResolveStop finds a stop by ID or name, see ResolveStop
+func (c *Client) ResolveStop(ctx context.Context, query string) (*models.Stop, error) {
+	return ResolveStop(ctx, c, query)
+}
+
+// isSearchableStop returns whether a stop is a station or a stop outside any
+// station, rather than a platform, entrance or node inside one
+func isSearchableStop(stop *models.Stop) bool {
+	switch stop.Attributes.LocationType {
+	case models.LocationTypeStation:
+		return true
+	case models.LocationTypePlatform:
+		return stop.GetParentStationID() == ""
+	default:
+		return false
+	}
+}
+
+// normalizeStopText lowercases text, drops punctuation and abbreviates
+// common words, leaving words separated by single spaces
+func normalizeStopText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '.':
+			// "St. Mary's" is "st marys"
+		case r == '&':
+			b.WriteString(" & ")
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	for i, word := range words {
+		if abbreviation, ok := stopNameAbbreviations[word]; ok {
+			words[i] = abbreviation
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// stopTextScore rates how well normalized text matches a normalized query,
+// from 0 for no match to 1 for the same text
+func stopTextScore(query, text string) float64 {
+	switch {
+	case query == "" || text == "":
+		return 0
+	case query == text:
+		return 1
+	case strings.HasPrefix(text, query+" "):
+		// "harvard" for "harvard sq", better the more of the text it covers
+		return 0.9 + 0.05*float64(len(query))/float64(len(text))
+	}
+
+	// Otherwise every query word should match a word of the text
+	queryWords := strings.Fields(query)
+	textWords := strings.Fields(text)
+	var total float64
+	for _, queryWord := range queryWords {
+		var best float64
+		for _, textWord := range textWords {
+			best = max(best, wordSimilarity(queryWord, textWord))
+		}
+		total += best
+	}
+	wordScore := 0.85 * total / float64(len(queryWords))
+
+	// Extra words in the text make it a less specific match
+	if len(textWords) > len(queryWords) {
+		wordScore *= 0.9 + 0.1*float64(len(queryWords))/float64(len(textWords))
+	}
+
+	// Misspellings that join or split words are caught comparing whole texts
+	textScore := 0.8 * similarity(query, text)
+
+	return max(wordScore, textScore)
+}
+
+// wordSimilarity rates how well a query word matches a word of a stop name,
+// allowing the query to be a prefix and, in longer words, a typo or two
+func wordSimilarity(queryWord, textWord string) float64 {
+	if queryWord == textWord {
+		return 1
+	}
+	if len(queryWord) >= 3 && strings.HasPrefix(textWord, queryWord) {
+		return 0.9
+	}
+
+	allowed := 0
+	switch n := len([]rune(queryWord)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 3:
+		allowed = 1
+	}
+	if editDistance(queryWord, textWord) > allowed {
+		return 0
+	}
+	return similarity(queryWord, textWord)
+}
+
+// similarity is one less the edit distance between two strings relative to
+// the longer of them
+func similarity(a, b string) float64 {
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance returns the number of single character insertions, deletions,
+// substitutions or adjacent transpositions that turn a into b
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	// Three rows of the optimal string alignment table suffice
+	before := make([]int, len(t)+1)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				current[j] = min(current[j], before[j-2]+1)
+			}
+		}
+		before, previous, current = previous, current, before
+	}
+	return previous[len(t)]
+}
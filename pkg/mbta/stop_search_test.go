@@ -0,0 +1,248 @@
+package mbta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// stopListSource is a stop backend that knows a fixed list of stops
+type stopListSource struct {
+	StopDataSource
+	stops []models.Stop
+}
+
+func (s *stopListSource) GetStops(ctx context.Context) ([]models.Stop, error) {
+	return s.stops, nil
+}
+
+func (s *stopListSource) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	for i := range s.stops {
+		if s.stops[i].ID == stopID {
+			return &s.stops[i], nil
+		}
+	}
+	return nil, fmt.Errorf("stop %s %w", stopID, ErrNotFound)
+}
+
+// searchTestStop builds a named stop for search tests
+func searchTestStop(id, name, description, municipality string, locationType int, parent string) models.Stop {
+	stop := indexTestStop(id, 42.35, -71.06, locationType, 1, parent)
+	stop.Attributes.Name = name
+	stop.Attributes.Description = description
+	stop.Attributes.Municipality = municipality
+	return stop
+}
+
+// searchTestStops are stations, a platform inside one and bus stops
+var searchTestStops = []models.Stop{
+	searchTestStop("place-sstat", "South Station", "", "Boston", models.LocationTypeStation, ""),
+	searchTestStop("70079", "South Station", "South Station - Red Line - Alewife", "Boston", models.LocationTypePlatform, "place-sstat"),
+	searchTestStop("place-dwnxg", "Downtown Crossing", "", "Boston", models.LocationTypeStation, ""),
+	searchTestStop("place-gover", "Government Center", "", "Boston", models.LocationTypeStation, ""),
+	searchTestStop("place-harsq", "Harvard", "", "Cambridge", models.LocationTypeStation, ""),
+	searchTestStop("place-knncl", "Kendall/MIT", "", "Cambridge", models.LocationTypeStation, ""),
+	searchTestStop("place-smary", "Saint Mary's Street", "", "Brookline", models.LocationTypeStation, ""),
+	searchTestStop("place-nubn", "Nubian", "", "Boston", models.LocationTypeStation, ""),
+	searchTestStop("place-masta", "Massachusetts Avenue", "", "Boston", models.LocationTypeStation, ""),
+	searchTestStop("door-sstat-summer", "South Station - Summer St", "", "Boston", models.LocationTypeEntrance, "place-sstat"),
+	searchTestStop("1123", "Harvard Ave @ Brighton Ave", "", "Boston", models.LocationTypePlatform, ""),
+	searchTestStop("2231", "Quincy St @ Broadway", "Quincy St @ Broadway - Somerville", "Somerville", models.LocationTypePlatform, ""),
+}
+
+func TestSearchStops(t *testing.T) {
+	source := &stopListSource{stops: searchTestStops}
+
+	tests := []struct {
+		name      string
+		query     string
+		expected  string
+		matchedOn string
+	}{
+		{name: "ExactName", query: "South Station", expected: "place-sstat", matchedOn: "name"},
+		{name: "CaseAndPunctuation", query: "  south   STATION! ", expected: "place-sstat", matchedOn: "name"},
+		{name: "Typo", query: "Harverd", expected: "place-harsq", matchedOn: "name"},
+		{name: "Transposition", query: "Sotuh Station", expected: "place-sstat", matchedOn: "name"},
+		{name: "Prefix", query: "Kendall", expected: "place-knncl", matchedOn: "name"},
+		{name: "Abbreviation", query: "St Marys St", expected: "place-smary", matchedOn: "name"},
+		{name: "AbbreviatedName", query: "Mass Avenue", expected: "place-masta", matchedOn: "alias"},
+		{name: "Alias", query: "DTX", expected: "place-dwnxg", matchedOn: "alias"},
+		{name: "AbbreviatedAlias", query: "Gov Center", expected: "place-gover", matchedOn: "alias"},
+		{name: "FormerName", query: "Dudley Sq", expected: "place-nubn", matchedOn: "alias"},
+		{name: "Description", query: "Quincy St Somerville", expected: "2231", matchedOn: "description"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := SearchStops(context.Background(), source, tt.query, 3)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(matches) == 0 {
+				t.Fatalf("Expected %s, got no matches", tt.expected)
+			}
+			if matches[0].Stop.ID != tt.expected || matches[0].MatchedOn != tt.matchedOn {
+				t.Errorf("Expected %s on %s, got %s on %s", tt.expected, tt.matchedOn, matches[0].Stop.ID, matches[0].MatchedOn)
+			}
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Score > matches[i-1].Score {
+					t.Errorf("Expected matches in order of score, got %v", matches)
+				}
+			}
+		})
+	}
+
+	t.Run("StationsRepresentTheirStops", func(t *testing.T) {
+		matches, err := SearchStops(context.Background(), source, "South Station", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, match := range matches {
+			if match.Stop.ID == "70079" || match.Stop.ID == "door-sstat-summer" {
+				t.Errorf("Expected only the station, got %s", match.Stop.ID)
+			}
+		}
+	})
+
+	t.Run("Ranking", func(t *testing.T) {
+		matches, err := SearchStops(context.Background(), source, "harvard", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(matches) != 2 || matches[0].Stop.ID != "place-harsq" || matches[1].Stop.ID != "1123" {
+			t.Fatalf("Expected Harvard then Harvard Ave, got %v", matches)
+		}
+		if matches[0].Score != 1 || matches[1].Score >= 1 {
+			t.Errorf("Expected an exact and a partial match, got %f and %f", matches[0].Score, matches[1].Score)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		matches, err := SearchStops(context.Background(), source, "Boston", 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(matches) != 2 || matches[0].MatchedOn != "municipality" {
+			t.Errorf("Expected 2 municipality matches, got %v", matches)
+		}
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		matches, err := SearchStops(context.Background(), source, "xyzzy", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(matches) != 0 {
+			t.Errorf("Expected no matches, got %v", matches)
+		}
+	})
+
+	t.Run("EmptyQuery", func(t *testing.T) {
+		if _, err := SearchStops(context.Background(), source, " ? ", 0); err == nil {
+			t.Error("Expected an error for an empty query")
+		}
+	})
+}
+
+func TestResolveStop(t *testing.T) {
+	source := &stopListSource{stops: searchTestStops}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{query: "place-harsq", expected: "place-harsq"},
+		{query: "70079", expected: "70079"},
+		{query: "South Station", expected: "place-sstat"},
+		{query: "harvard", expected: "place-harsq"},
+		{query: "Kendall/MIT", expected: "place-knncl"},
+	}
+
+	for _, tt := range tests {
+		stop, err := ResolveStop(context.Background(), source, tt.query)
+		if err != nil {
+			t.Errorf("Unexpected error resolving %q: %v", tt.query, err)
+			continue
+		}
+		if stop.ID != tt.expected {
+			t.Errorf("Expected %q to resolve to %s, got %s", tt.query, tt.expected, stop.ID)
+		}
+	}
+
+	for _, query := range []string{"", "Nowhere Junction"} {
+		if _, err := ResolveStop(context.Background(), source, query); err == nil {
+			t.Errorf("Expected an error resolving %q", query)
+		}
+	}
+
+	// Only a missing ID falls back to search: a stop that couldn't be looked
+	// up mustn't be swapped for whichever name is closest
+	for _, lookupErr := range []error{
+		&NetworkError{Err: errors.New("connection refused")},
+		&APIError{StatusCode: http.StatusServiceUnavailable, Status: "503"},
+		&RateLimitError{APIError: &APIError{StatusCode: http.StatusTooManyRequests, Status: "429"}, RetryAfter: 30},
+	} {
+		failing := &failingStopSource{stopListSource: source, err: lookupErr}
+		if _, err := ResolveStop(context.Background(), failing, "place-sstt"); err != lookupErr {
+			t.Errorf("Expected %v, got %v", lookupErr, err)
+		}
+	}
+	missing := &failingStopSource{stopListSource: source, err: &APIError{StatusCode: http.StatusNotFound, Status: "404"}}
+	if stop, err := ResolveStop(context.Background(), missing, "harvard"); err != nil || stop.ID != "place-harsq" {
+		t.Errorf("Expected a missing ID to be searched for by name, got %v, %v", stop, err)
+	}
+}
+
+// failingStopSource fails every stop lookup with an error, but still lists stops
+type failingStopSource struct {
+	*stopListSource
+	err error
+}
+
+func (s *failingStopSource) GetStop(ctx context.Context, stopID string) (*models.Stop, error) {
+	return nil, s.err
+}
+
+func TestNormalizeStopText(t *testing.T) {
+	tests := map[string]string{
+		"Saint Mary's Street":      "st marys st",
+		"St. Mary's St":            "st marys st",
+		"Government Center":        "gov ctr",
+		"Kendall/MIT":              "kendall mit",
+		"Harvard Sq @ Garden St":   "harvard sq garden st",
+		"Back Bay & South End":     "back bay and south end",
+		"  Massachusetts  Avenue ": "massachusetts ave",
+	}
+
+	for input, expected := range tests {
+		if got := normalizeStopText(input); got != expected {
+			t.Errorf("Expected %q to normalize to %q, got %q", input, expected, got)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"harvard", "harvard", 0},
+		{"harverd", "harvard", 1},
+		{"harvad", "harvard", 1},
+		{"sotuh", "south", 1},
+		{"kendal", "kendall", 1},
+		{"alewife", "", 7},
+		{"wonderland", "wnodreland", 2},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("Expected distance %d between %q and %q, got %d", tt.expected, tt.a, tt.b, got)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package mbta
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// Stream event types sent by the MBTA API on text/event-stream endpoints
+const (
+	StreamEventReset  = "reset"
+	StreamEventAdd    = "add"
+	StreamEventUpdate = "update"
+	StreamEventRemove = "remove"
+)
+
+// StreamOptions controls reconnection and delivery for a streaming subscription
+type StreamOptions struct {
+	InitialBackoff time.Duration // Delay before the first reconnect attempt (default: 1s)
+	MaxBackoff     time.Duration // Upper bound for the reconnect delay (default: 30s)
+	BufferSize     int           // Capacity of the Updates channel (default: 64)
+}
+
+// withDefaults fills in unset stream options
+func (o StreamOptions) withDefaults() StreamOptions {
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 30 * time.Second
+	}
+	if o.MaxBackoff < o.InitialBackoff {
+		o.MaxBackoff = o.InitialBackoff
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = 64
+	}
+	return o
+}
+
+// StreamUpdate describes a change applied to a subscription's store
+type StreamUpdate[T any] struct {
+	Event   string   // One of the StreamEvent constants
+	Items   []T      // Resources added or updated; the full set after a reset
+	Removed []string // IDs of resources removed
+}
+
+// Subscription keeps a consistent in-memory copy of a streamed MBTA collection.
+// It reconnects with exponential backoff and publishes every change on the
+// Updates channel and to registered callbacks. The store is always current even
+// if a slow consumer causes channel updates to be dropped.
+type Subscription[T any] struct {
+	client *Client
+	path   string
+	idOf   func(T) string
+	opts   StreamOptions
+
+	mu       sync.RWMutex
+	items    map[string]T
+	ready    bool
+	err      error
+	handlers []func(StreamUpdate[T])
+
+	updates chan StreamUpdate[T]
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// StreamPredictions subscribes to live predictions matching the given filters
+func (c *Client) StreamPredictions(ctx context.Context, params map[string]string, opts StreamOptions) *Subscription[models.Prediction] {
+	return subscribe(ctx, c, "/predictions", params, func(p models.Prediction) string { return p.ID }, opts)
+}
+
+// StreamVehicles subscribes to live vehicle positions matching the given filters
+func (c *Client) StreamVehicles(ctx context.Context, params map[string]string, opts StreamOptions) *Subscription[models.Vehicle] {
+	return subscribe(ctx, c, "/vehicles", params, func(v models.Vehicle) string { return v.ID }, opts)
+}
+
+// StreamAlerts subscribes to live alerts matching the given filters
+func (c *Client) StreamAlerts(ctx context.Context, params map[string]string, opts StreamOptions) *Subscription[models.Alert] {
+	return subscribe(ctx, c, "/alerts", params, func(a models.Alert) string { return a.ID }, opts)
+}
+
+// subscribe starts a streaming subscription in the background
+func subscribe[T any](ctx context.Context, c *Client, resource string, params map[string]string, idOf func(T) string, opts StreamOptions) *Subscription[T] {
+	// Build query parameters
+	query := url.Values{}
+	for key, value := range params {
+		query.Add(key, value)
+	}
+
+	path := resource
+	if queryString := query.Encode(); queryString != "" {
+		path += "?" + queryString
+	}
+
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub := &Subscription[T]{
+		client:  c,
+		path:    path,
+		idOf:    idOf,
+		opts:    opts,
+		items:   make(map[string]T),
+		updates: make(chan StreamUpdate[T], opts.BufferSize),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go sub.run(ctx)
+
+	return sub
+}
+
+// Updates returns the channel on which changes are published.
+// It is closed when the subscription stops.
+func (s *Subscription[T]) Updates() <-chan StreamUpdate[T] {
+	return s.updates
+}
+
+// OnUpdate registers a callback invoked synchronously for every change
+func (s *Subscription[T]) OnUpdate(fn func(StreamUpdate[T])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, fn)
+}
+
+// Snapshot returns a copy of every resource currently in the store
+func (s *Subscription[T]) Snapshot() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]T, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Get returns the resource with the given ID from the store
+func (s *Subscription[T]) Get(id string) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[id]
+	return item, ok
+}
+
+// Ready returns whether the store has received its initial reset event
+func (s *Subscription[T]) Ready() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ready
+}
+
+// Err returns the most recent connection error, if any
+func (s *Subscription[T]) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// Done returns a channel that is closed when the subscription has stopped
+func (s *Subscription[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close stops the subscription and waits for it to shut down
+func (s *Subscription[T]) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// run connects and reconnects until the context is cancelled
+func (s *Subscription[T]) run(ctx context.Context) {
+	defer close(s.done)
+	defer close(s.updates)
+
+	backoff := s.opts.InitialBackoff
+	for {
+		receivedReset, err := s.connect(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+
+		// A connection that delivered data resets the backoff
+		if receivedReset {
+			backoff = s.opts.InitialBackoff
+		}
+
+		// Wait at least as long as a rate-limited API asked us to
+		wait := backoff
+		if rateLimitErr, ok := err.(*RateLimitError); ok {
+			wait = max(wait, time.Duration(rateLimitErr.RetryAfter)*time.Second)
+		}
+
+		if err != nil {
+			log.Printf("MBTA stream %s disconnected: %v (reconnecting in %v)", s.path, err, wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		backoff *= 2
+		if backoff > s.opts.MaxBackoff {
+			backoff = s.opts.MaxBackoff
+		}
+	}
+}
+
+// connect opens one streaming connection and processes events until it ends.
+// It reports whether a reset event was received.
+func (s *Subscription[T]) connect(ctx context.Context) (bool, error) {
+	resp, err := s.client.openStream(ctx, s.path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	receivedReset := false
+	reader := bufio.NewReader(resp.Body)
+
+	var event string
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return receivedReset, fmt.Errorf("stream closed by server")
+			}
+			return receivedReset, &NetworkError{Err: fmt.Errorf("error reading stream: %w", err)}
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// A blank line dispatches the accumulated event
+		if line == "" {
+			if event != "" && data.Len() > 0 {
+				if err := s.apply(event, []byte(data.String())); err != nil {
+					log.Printf("MBTA stream %s: ignoring malformed %s event: %v", s.path, event, err)
+				} else if event == StreamEventReset {
+					receivedReset = true
+				}
+			}
+			event = ""
+			data.Reset()
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
+
+// apply updates the store from a single event and publishes the change
+func (s *Subscription[T]) apply(event string, payload []byte) error {
+	update := StreamUpdate[T]{Event: event}
+
+	switch event {
+	case StreamEventReset:
+		var items []T
+		if err := json.Unmarshal(payload, &items); err != nil {
+			return err
+		}
+		update.Items = items
+
+		s.mu.Lock()
+		s.items = make(map[string]T, len(items))
+		for _, item := range items {
+			s.items[s.idOf(item)] = item
+		}
+		s.ready = true
+		s.err = nil
+		s.mu.Unlock()
+
+	case StreamEventAdd, StreamEventUpdate:
+		var item T
+		if err := json.Unmarshal(payload, &item); err != nil {
+			return err
+		}
+		update.Items = []T{item}
+
+		s.mu.Lock()
+		s.items[s.idOf(item)] = item
+		s.mu.Unlock()
+
+	case StreamEventRemove:
+		var identifier models.ResourceIdentifier
+		if err := json.Unmarshal(payload, &identifier); err != nil {
+			return err
+		}
+		update.Removed = []string{identifier.ID}
+
+		s.mu.Lock()
+		delete(s.items, identifier.ID)
+		s.mu.Unlock()
+
+	default:
+		// Unknown events are ignored so new server event types don't break clients
+		return nil
+	}
+
+	s.publish(update)
+	return nil
+}
+
+// publish delivers an update to callbacks and, if there is room, the channel
+func (s *Subscription[T]) publish(update StreamUpdate[T]) {
+	s.mu.RLock()
+	handlers := append([]func(StreamUpdate[T]){}, s.handlers...)
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(update)
+	}
+
+	select {
+	case s.updates <- update:
+	default:
+		log.Printf("MBTA stream %s: update channel full, dropping %s event", s.path, update.Event)
+	}
+}
+
+// openStream opens a text/event-stream connection to the MBTA API
+func (c *Client) openStream(ctx context.Context, path string) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &NetworkError{Err: fmt.Errorf("error creating request: %w", err)}
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	// Opening a stream counts against the API rate limit
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Streams are long-lived, so don't apply the client's request timeout
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, &NetworkError{Err: fmt.Errorf("error opening stream: %w", err)}
+	}
+
+	if c.limiter != nil {
+		c.limiter.Observe(resp.Header)
+	}
+
+	if resp.StatusCode >= 400 {
+		respBody, readErr := io.ReadAll(resp.Body)
+		defer func() { _ = resp.Body.Close() }()
+
+		if readErr != nil {
+			return nil, &NetworkError{Err: fmt.Errorf("HTTP error %d and failed to read error body: %w", resp.StatusCode, readErr)}
+		}
+		return nil, parseAPIErrorResponse(resp.StatusCode, resp.Header, respBody)
+	}
+
+	return resp, nil
+}
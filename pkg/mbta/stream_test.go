@@ -0,0 +1,162 @@
+package mbta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crdant/mbta-mcp-server/internal/config"
+	"github.com/crdant/mbta-mcp-server/pkg/mbta/models"
+)
+
+// writeEvent writes a single server-sent event and flushes it to the client
+func writeEvent(w http.ResponseWriter, event, data string) {
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	w.(http.Flusher).Flush()
+}
+
+// waitFor polls until cond is true or the timeout elapses
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for condition")
+}
+
+func TestStreamVehicles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vehicles" {
+			t.Errorf("Expected URL path '/vehicles', got '%s'", r.URL.Path)
+		}
+		if r.URL.Query().Get("filter[route]") != "Red" {
+			t.Errorf("Expected filter[route]=Red, got '%s'", r.URL.Query().Get("filter[route]"))
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Accept header 'text/event-stream', got '%s'", r.Header.Get("Accept"))
+		}
+		if r.Header.Get("X-API-Key") != "test-key" {
+			t.Errorf("Expected X-API-Key header 'test-key', got '%s'", r.Header.Get("X-API-Key"))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "reset", `[{"id": "v1", "type": "vehicle", "attributes": {"label": "1800"}}, {"id": "v2", "type": "vehicle", "attributes": {"label": "1801"}}]`)
+		writeEvent(w, "update", `{"id": "v1", "type": "vehicle", "attributes": {"label": "1800", "current_status": "STOPPED_AT"}}`)
+		writeEvent(w, "add", `{"id": "v3", "type": "vehicle", "attributes": {"label": "1802"}}`)
+		writeEvent(w, "remove", `{"id": "v2", "type": "vehicle"}`)
+
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+	var callbacks atomic.Int32
+	sub := client.StreamVehicles(context.Background(), map[string]string{"filter[route]": "Red"}, StreamOptions{})
+	sub.OnUpdate(func(StreamUpdate[models.Vehicle]) { callbacks.Add(1) })
+	defer sub.Close()
+
+	events := make([]string, 0, 4)
+	for update := range sub.Updates() {
+		events = append(events, update.Event)
+		if len(events) == 4 {
+			break
+		}
+	}
+
+	expected := []string{StreamEventReset, StreamEventUpdate, StreamEventAdd, StreamEventRemove}
+	for i, event := range expected {
+		if events[i] != event {
+			t.Errorf("Expected event %d to be '%s', got '%s'", i, event, events[i])
+		}
+	}
+
+	if !sub.Ready() {
+		t.Error("Expected subscription to be ready after reset")
+	}
+	if len(sub.Snapshot()) != 2 {
+		t.Errorf("Expected 2 vehicles in store, got %d", len(sub.Snapshot()))
+	}
+	if _, ok := sub.Get("v2"); ok {
+		t.Error("Expected v2 to be removed from store")
+	}
+	v1, ok := sub.Get("v1")
+	if !ok || v1.Attributes.CurrentStatus != "STOPPED_AT" {
+		t.Errorf("Expected v1 to be updated to STOPPED_AT, got %+v", v1)
+	}
+	if _, ok := sub.Get("v3"); !ok {
+		t.Error("Expected v3 to be added to store")
+	}
+}
+
+func TestStreamReconnects(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		if n == 1 {
+			// First connection delivers stale data and drops
+			writeEvent(w, "reset", `[{"id": "a1", "type": "alert", "attributes": {"header": "Old"}}]`)
+			return
+		}
+
+		// Reconnect receives a full reset that replaces the store
+		writeEvent(w, "reset", `[{"id": "a2", "type": "alert", "attributes": {"header": "New"}}]`)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "test-key", APIBaseURL: server.URL})
+
+	sub := client.StreamAlerts(context.Background(), nil, StreamOptions{InitialBackoff: 10 * time.Millisecond})
+	defer sub.Close()
+
+	waitFor(t, func() bool {
+		_, ok := sub.Get("a2")
+		return ok
+	})
+
+	if connections.Load() != 2 {
+		t.Errorf("Expected 2 connections, got %d", connections.Load())
+	}
+	if _, ok := sub.Get("a1"); ok {
+		t.Error("Expected reset to drop alert a1")
+	}
+}
+
+func TestStreamErrorsAndClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":[{"status":"403","title":"Forbidden"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{APIKey: "bad-key", APIBaseURL: server.URL})
+
+	sub := client.StreamPredictions(context.Background(), nil, StreamOptions{InitialBackoff: time.Hour})
+
+	waitFor(t, func() bool { return sub.Err() != nil })
+	if _, ok := sub.Err().(*APIError); !ok {
+		t.Errorf("Expected *APIError, got %T", sub.Err())
+	}
+
+	sub.Close()
+
+	select {
+	case <-sub.Done():
+	default:
+		t.Error("Expected subscription to be done after Close")
+	}
+	if _, open := <-sub.Updates(); open {
+		t.Error("Expected updates channel to be closed after Close")
+	}
+}